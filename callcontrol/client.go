@@ -0,0 +1,64 @@
+// Package callcontrol is a client for the Telnyx Call Control API, which controls calls
+// through commands sent to the REST API and events delivered to a webhook.
+//
+// https://developers.telnyx.com/docs/voice/programmable-voice/receiving-webhooks
+package callcontrol
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"reflect"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+)
+
+// Client sends Call Control commands through the Telnyx REST API.
+type Client struct {
+	client *telnyx.Client
+}
+
+// NewClient returns a Client that sends its requests through client.
+func NewClient(client *telnyx.Client) *Client {
+	return &Client{client: client}
+}
+
+// Call identifies a call created by Dial.
+type Call struct {
+	CallControlID string `json:"call_control_id"`
+	CallLegID     string `json:"call_leg_id"`
+	CallSessionID string `json:"call_session_id"`
+	IsAlive       bool   `json:"is_alive"`
+	RecordType    string `json:"record_type"`
+	CallDuration  int    `json:"call_duration"`
+	ClientState   string `json:"client_state"`
+}
+
+// Dial places an outbound call from the Call Control application or connection set in
+// params. The call is controlled with Execute once the call.answered event arrives.
+func (c *Client) Dial(ctx context.Context, params *Dial) (*Call, error) {
+	var resp telnyx.DataResponse[Call]
+	if err := c.client.Do(ctx, http.MethodPost, "calls", params, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// GetCall returns the status of a call.
+func (c *Client) GetCall(ctx context.Context, callControlID string) (*Call, error) {
+	var resp telnyx.DataResponse[Call]
+	if err := c.client.Do(ctx, http.MethodGet, "calls/"+url.PathEscape(callControlID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// Execute sends cmd to the call identified by callControlID. A nil cmd returns
+// telnyx.ErrNilParams.
+func (c *Client) Execute(ctx context.Context, callControlID string, cmd Command) error {
+	if v := reflect.ValueOf(cmd); !v.IsValid() || v.Kind() == reflect.Pointer && v.IsNil() {
+		return telnyx.ErrNilParams
+	}
+	path := "calls/" + url.PathEscape(callControlID) + "/actions/" + cmd.Action()
+	return c.client.Do(ctx, http.MethodPost, path, cmd, nil)
+}
@@ -0,0 +1,43 @@
+package callcontrol
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// EncodeClientState encodes v as the base64 JSON string expected in the ClientState of
+// a command. Telnyx echoes it back in every webhook caused by the command.
+func EncodeClientState(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("callcontrol: encode client state: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// DecodeClientState decodes a client state produced by EncodeClientState into v.
+func DecodeClientState(state string, v interface{}) error {
+	data, err := base64.StdEncoding.DecodeString(state)
+	if err != nil {
+		return fmt.Errorf("callcontrol: decode client state: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("callcontrol: decode client state: %w", err)
+	}
+	return nil
+}
+
+// NewCommandID returns a random UUID for the CommandID of a command. Telnyx ignores a
+// command whose ID was already used on the same call, so a command can safely be
+// retried with the same ID.
+func NewCommandID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
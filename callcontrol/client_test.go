@@ -0,0 +1,167 @@
+package callcontrol
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"regexp"
+	"testing"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+	"github.com/andersryanc/telnyx-go/internal/apitest"
+)
+
+func TestDial(t *testing.T) {
+	rec := apitest.NewRecorder(t, apitest.Reply(http.StatusOK, `{"data":{"call_control_id":"v3:abc","call_leg_id":"leg1","is_alive":false,"record_type":"call"}}`))
+	c := NewClient(rec.Client())
+
+	call, err := c.Dial(context.Background(), &Dial{
+		CommandOptions: CommandOptions{ClientState: "c3RhdGU=", CommandID: "cmd1"},
+		ConnectionID:   "conn1",
+		To:             "+13125550199",
+		From:           "+13125550100",
+		TimeoutSecs:    30,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rec.Expect(http.MethodPost, "/calls")
+	want := map[string]interface{}{
+		"client_state":  "c3RhdGU=",
+		"command_id":    "cmd1",
+		"connection_id": "conn1",
+		"to":            "+13125550199",
+		"from":          "+13125550100",
+		"timeout_secs":  float64(30),
+	}
+	if !reflect.DeepEqual(r.Body, want) {
+		t.Errorf("body = %v, want %v", r.Body, want)
+	}
+	if call.CallControlID != "v3:abc" || call.CallLegID != "leg1" {
+		t.Errorf("Dial = %+v", call)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		cmd    Command
+		action string
+		body   map[string]interface{}
+	}{
+		{Answer{}, "answer", map[string]interface{}{}},
+		{Hangup{CommandOptions{CommandID: "cmd1"}}, "hangup", map[string]interface{}{"command_id": "cmd1"}},
+		{Bridge{CallControlID: "v3:other"}, "bridge", map[string]interface{}{"call_control_id": "v3:other"}},
+		{Transfer{To: "+13125550122"}, "transfer", map[string]interface{}{"to": "+13125550122"}},
+		{Speak{Payload: "Hello", Voice: "female"}, "speak", map[string]interface{}{"payload": "Hello", "voice": "female"}},
+		{PlaybackStart{AudioUrl: "https://example.com/a.mp3", Loop: "infinity"}, "playback_start",
+			map[string]interface{}{"audio_url": "https://example.com/a.mp3", "loop": "infinity"}},
+		{PlaybackStop{Stop: "all"}, "playback_stop", map[string]interface{}{"stop": "all"}},
+		{GatherUsingAudio{GatherOptions: GatherOptions{MaximumDigits: 4, TerminatingDigit: "#"}, AudioUrl: "https://example.com/menu.mp3"},
+			"gather_using_audio", map[string]interface{}{"maximum_digits": float64(4), "terminating_digit": "#", "audio_url": "https://example.com/menu.mp3"}},
+		{GatherUsingSpeak{Payload: "Enter your PIN", Voice: "male"}, "gather_using_speak", map[string]interface{}{"payload": "Enter your PIN", "voice": "male"}},
+		{RecordStart{Format: "mp3", Channels: "dual"}, "record_start", map[string]interface{}{"format": "mp3", "channels": "dual"}},
+		{RecordStop{}, "record_stop", map[string]interface{}{}},
+		{ForkStart{Target: "udp:192.0.2.1:9000"}, "fork_start", map[string]interface{}{"target": "udp:192.0.2.1:9000"}},
+		{ForkStop{}, "fork_stop", map[string]interface{}{}},
+		{StreamingStart{StreamUrl: "wss://example.com/media", StreamTrack: "both_tracks"}, "streaming_start",
+			map[string]interface{}{"stream_url": "wss://example.com/media", "stream_track": "both_tracks"}},
+		{StreamingStop{StreamID: "s1"}, "streaming_stop", map[string]interface{}{"stream_id": "s1"}},
+		{TranscriptionStart{Language: "en", InterimResults: true}, "transcription_start", map[string]interface{}{"language": "en", "interim_results": true}},
+		{TranscriptionStop{}, "transcription_stop", map[string]interface{}{}},
+		{SendDTMF{Digits: "1w2", DurationMillis: 250}, "send_dtmf", map[string]interface{}{"digits": "1w2", "duration_millis": float64(250)}},
+	}
+	for _, tt := range tests {
+		rec := apitest.NewRecorder(t, apitest.Reply(http.StatusOK, `{"data":{"result":"ok"}}`))
+		if err := NewClient(rec.Client()).Execute(context.Background(), "v3:abc", tt.cmd); err != nil {
+			t.Fatalf("%s: %v", tt.action, err)
+		}
+		r := rec.Last()
+		if want := "/calls/v3:abc/actions/" + tt.action; r.Method != http.MethodPost || r.Path != want {
+			t.Errorf("%T sent %s %s, want POST %s", tt.cmd, r.Method, r.Path, want)
+		}
+		if !reflect.DeepEqual(r.Body, tt.body) {
+			t.Errorf("%T body = %v, want %v", tt.cmd, r.Body, tt.body)
+		}
+	}
+}
+
+func TestExecuteRetriesWithCommandID(t *testing.T) {
+	rec := apitest.NewRecorder(t, apitest.Reply(http.StatusBadGateway, ""))
+	NewClient(rec.Client()).Execute(context.Background(), "v3:abc", Hangup{})
+	if n := len(rec.Requests()); n != 1 {
+		t.Errorf("%d attempts without a command ID, want 1", n)
+	}
+
+	rec = apitest.NewRecorder(t, apitest.Reply(http.StatusBadGateway, ""))
+	NewClient(rec.Client()).Execute(context.Background(), "v3:abc", Hangup{CommandOptions{CommandID: NewCommandID()}})
+	requests := rec.Requests()
+	if len(requests) != telnyx.DefaultMaxRetries+1 {
+		t.Errorf("%d attempts with a command ID, want %d", len(requests), telnyx.DefaultMaxRetries+1)
+	}
+	if requests[0].Body["command_id"] != requests[1].Body["command_id"] {
+		t.Error("the retry changed the command ID")
+	}
+}
+
+func TestNilCommand(t *testing.T) {
+	rec := apitest.NewRecorder(t, apitest.Reply(http.StatusOK, `{"data":{"result":"ok"}}`))
+	c := NewClient(rec.Client())
+	if err := c.Execute(context.Background(), "v3:abc", nil); !errors.Is(err, telnyx.ErrNilParams) {
+		t.Errorf("Execute(nil) = %v, want telnyx.ErrNilParams", err)
+	}
+	if err := c.Execute(context.Background(), "v3:abc", (*Speak)(nil)); !errors.Is(err, telnyx.ErrNilParams) {
+		t.Errorf("Execute of a nil *Speak = %v, want telnyx.ErrNilParams", err)
+	}
+	if _, err := c.Dial(context.Background(), nil); !errors.Is(err, telnyx.ErrNilParams) {
+		t.Errorf("Dial(nil) = %v, want telnyx.ErrNilParams", err)
+	}
+	if n := len(rec.Requests()); n != 0 {
+		t.Errorf("%d requests were sent", n)
+	}
+}
+
+func TestClientState(t *testing.T) {
+	type state struct {
+		Step   string `json:"step"`
+		Tries  int    `json:"tries"`
+		Caller string `json:"caller"`
+	}
+	in := state{"menu", 2, "+13125550100"}
+	encoded, err := EncodeClientState(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out state
+	if err := DecodeClientState(encoded, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+
+	if err := DecodeClientState("not base64!", &out); err == nil {
+		t.Error("invalid base64 was decoded")
+	}
+	if err := DecodeClientState("bm90IGpzb24=", &out); err == nil {
+		t.Error("a state that is not JSON was decoded")
+	}
+	if _, err := EncodeClientState(func() {}); err == nil {
+		t.Error("a func was encoded")
+	}
+}
+
+func TestNewCommandID(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		id := NewCommandID()
+		if !uuid.MatchString(id) {
+			t.Fatalf("NewCommandID() = %q, not a version 4 UUID", id)
+		}
+		if seen[id] {
+			t.Fatalf("NewCommandID() returned %q twice", id)
+		}
+		seen[id] = true
+	}
+}
@@ -0,0 +1,335 @@
+package callcontrol
+
+// Command is a Call Control command sent to a call with Client.Execute. Action is the
+// last element of the command path, e.g. "answer" for /calls/{id}/actions/answer.
+type Command interface {
+	Action() string
+}
+
+// CommandOptions are accepted by every command.
+type CommandOptions struct {
+	// ClientState is echoed back in the webhooks caused by the command. Build it with
+	// EncodeClientState.
+	ClientState string `json:"client_state,omitempty"`
+	// CommandID makes the command idempotent: a command with an ID that was already
+	// used on the same call is ignored. See NewCommandID.
+	CommandID string `json:"command_id,omitempty"`
+}
+
+// AnsweringMachineDetectionConfig tunes the answering machine detection of Dial and
+// Transfer. Durations are in milliseconds.
+type AnsweringMachineDetectionConfig struct {
+	TotalAnalysisTimeMillis         int `json:"total_analysis_time_millis,omitempty"`
+	AfterGreetingSilenceMillis      int `json:"after_greeting_silence_millis,omitempty"`
+	BetweenWordsSilenceMillis       int `json:"between_words_silence_millis,omitempty"`
+	GreetingDurationMillis          int `json:"greeting_duration_millis,omitempty"`
+	InitialSilenceMillis            int `json:"initial_silence_millis,omitempty"`
+	MaximumNumberOfWords            int `json:"maximum_number_of_words,omitempty"`
+	MaximumWordLengthMillis         int `json:"maximum_word_length_millis,omitempty"`
+	SilenceThreshold                int `json:"silence_threshold,omitempty"`
+	GreetingTotalAnalysisTimeMillis int `json:"greeting_total_analysis_time_millis,omitempty"`
+	GreetingSilenceDurationMillis   int `json:"greeting_silence_duration_millis,omitempty"`
+}
+
+// CustomHeader is a SIP header added to an outbound call.
+type CustomHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Dial places an outbound call. It is sent with Client.Dial rather than Execute.
+//
+// https://developers.telnyx.com/api/call-control/dial-call
+type Dial struct {
+	CommandOptions
+	ConnectionID                    string                           `json:"connection_id"`
+	To                              string                           `json:"to"`
+	From                            string                           `json:"from"`
+	FromDisplayName                 string                           `json:"from_display_name,omitempty"`
+	AudioUrl                        string                           `json:"audio_url,omitempty"`
+	MediaName                       string                           `json:"media_name,omitempty"`
+	TimeoutSecs                     int                              `json:"timeout_secs,omitempty"`
+	TimeLimitSecs                   int                              `json:"time_limit_secs,omitempty"`
+	AnsweringMachineDetection       string                           `json:"answering_machine_detection,omitempty"`
+	AnsweringMachineDetectionConfig *AnsweringMachineDetectionConfig `json:"answering_machine_detection_config,omitempty"`
+	CustomHeaders                   []CustomHeader                   `json:"custom_headers,omitempty"`
+	SipAuthUsername                 string                           `json:"sip_auth_username,omitempty"`
+	SipAuthPassword                 string                           `json:"sip_auth_password,omitempty"`
+	WebhookUrl                      string                           `json:"webhook_url,omitempty"`
+	WebhookUrlMethod                string                           `json:"webhook_url_method,omitempty"`
+	LinkTo                          string                           `json:"link_to,omitempty"`
+	StreamUrl                       string                           `json:"stream_url,omitempty"`
+	StreamTrack                     string                           `json:"stream_track,omitempty"`
+	Record                          string                           `json:"record,omitempty"`
+	RecordFormat                    string                           `json:"record_format,omitempty"`
+	RecordChannels                  string                           `json:"record_channels,omitempty"`
+}
+
+// Answer answers an incoming call.
+type Answer struct {
+	CommandOptions
+	WebhookUrl          string         `json:"webhook_url,omitempty"`
+	WebhookUrlMethod    string         `json:"webhook_url_method,omitempty"`
+	StreamUrl           string         `json:"stream_url,omitempty"`
+	StreamTrack         string         `json:"stream_track,omitempty"`
+	SendSilenceWhenIdle bool           `json:"send_silence_when_idle,omitempty"`
+	BillingGroupID      string         `json:"billing_group_id,omitempty"`
+	CustomHeaders       []CustomHeader `json:"custom_headers,omitempty"`
+	PreferredCodecs     string         `json:"preferred_codecs,omitempty"`
+}
+
+func (Answer) Action() string {
+	return "answer"
+}
+
+// Hangup ends a call.
+type Hangup struct {
+	CommandOptions
+}
+
+func (Hangup) Action() string {
+	return "hangup"
+}
+
+// Bridge connects the audio of the call with the call identified by CallControlID.
+type Bridge struct {
+	CommandOptions
+	CallControlID     string `json:"call_control_id"`
+	ParkAfterUnbridge string `json:"park_after_unbridge,omitempty"`
+	Queue             string `json:"queue,omitempty"`
+	PlayRingtone      bool   `json:"play_ringtone,omitempty"`
+	Ringtone          string `json:"ringtone,omitempty"`
+}
+
+func (Bridge) Action() string {
+	return "bridge"
+}
+
+// Transfer transfers the call to a new destination. The original call is hung up once
+// the new leg answers.
+type Transfer struct {
+	CommandOptions
+	To                              string                           `json:"to"`
+	From                            string                           `json:"from,omitempty"`
+	FromDisplayName                 string                           `json:"from_display_name,omitempty"`
+	AudioUrl                        string                           `json:"audio_url,omitempty"`
+	TimeoutSecs                     int                              `json:"timeout_secs,omitempty"`
+	TimeLimitSecs                   int                              `json:"time_limit_secs,omitempty"`
+	AnsweringMachineDetection       string                           `json:"answering_machine_detection,omitempty"`
+	AnsweringMachineDetectionConfig *AnsweringMachineDetectionConfig `json:"answering_machine_detection_config,omitempty"`
+	CustomHeaders                   []CustomHeader                   `json:"custom_headers,omitempty"`
+	SipAuthUsername                 string                           `json:"sip_auth_username,omitempty"`
+	SipAuthPassword                 string                           `json:"sip_auth_password,omitempty"`
+	TargetLegClientState            string                           `json:"target_leg_client_state,omitempty"`
+	WebhookUrl                      string                           `json:"webhook_url,omitempty"`
+	WebhookUrlMethod                string                           `json:"webhook_url_method,omitempty"`
+}
+
+func (Transfer) Action() string {
+	return "transfer"
+}
+
+// Speak speaks text or SSML on the call.
+type Speak struct {
+	CommandOptions
+	Payload string `json:"payload"`
+	// PayloadType is "text" or "ssml".
+	PayloadType string `json:"payload_type,omitempty"`
+	// ServiceLevel is "basic" or "premium".
+	ServiceLevel string `json:"service_level,omitempty"`
+	Voice        string `json:"voice"`
+	Language     string `json:"language,omitempty"`
+	Stop         string `json:"stop,omitempty"`
+}
+
+func (Speak) Action() string {
+	return "speak"
+}
+
+// PlaybackStart plays an audio file on the call.
+type PlaybackStart struct {
+	CommandOptions
+	AudioUrl  string `json:"audio_url,omitempty"`
+	MediaName string `json:"media_name,omitempty"`
+	// Loop is a number of repetitions or "infinity".
+	Loop       string `json:"loop,omitempty"`
+	Overlay    bool   `json:"overlay,omitempty"`
+	Stop       string `json:"stop,omitempty"`
+	TargetLegs string `json:"target_legs,omitempty"`
+	CacheAudio *bool  `json:"cache_audio,omitempty"`
+	AudioType  string `json:"audio_type,omitempty"`
+}
+
+func (PlaybackStart) Action() string {
+	return "playback_start"
+}
+
+// PlaybackStop stops audio started by PlaybackStart.
+type PlaybackStop struct {
+	CommandOptions
+	Overlay bool `json:"overlay,omitempty"`
+	// Stop is "current" or "all".
+	Stop string `json:"stop,omitempty"`
+}
+
+func (PlaybackStop) Action() string {
+	return "playback_stop"
+}
+
+// GatherOptions are the digit collection settings shared by GatherUsingAudio and
+// GatherUsingSpeak.
+type GatherOptions struct {
+	MinimumDigits           int    `json:"minimum_digits,omitempty"`
+	MaximumDigits           int    `json:"maximum_digits,omitempty"`
+	MaximumTries            int    `json:"maximum_tries,omitempty"`
+	TimeoutMillis           int    `json:"timeout_millis,omitempty"`
+	InterDigitTimeoutMillis int    `json:"inter_digit_timeout_millis,omitempty"`
+	TerminatingDigit        string `json:"terminating_digit,omitempty"`
+	ValidDigits             string `json:"valid_digits,omitempty"`
+}
+
+// GatherUsingAudio plays an audio file and collects DTMF digits.
+type GatherUsingAudio struct {
+	CommandOptions
+	GatherOptions
+	AudioUrl         string `json:"audio_url,omitempty"`
+	MediaName        string `json:"media_name,omitempty"`
+	InvalidAudioUrl  string `json:"invalid_audio_url,omitempty"`
+	InvalidMediaName string `json:"invalid_media_name,omitempty"`
+}
+
+func (GatherUsingAudio) Action() string {
+	return "gather_using_audio"
+}
+
+// GatherUsingSpeak speaks text and collects DTMF digits.
+type GatherUsingSpeak struct {
+	CommandOptions
+	GatherOptions
+	Payload        string `json:"payload"`
+	InvalidPayload string `json:"invalid_payload,omitempty"`
+	PayloadType    string `json:"payload_type,omitempty"`
+	ServiceLevel   string `json:"service_level,omitempty"`
+	Voice          string `json:"voice"`
+	Language       string `json:"language,omitempty"`
+}
+
+func (GatherUsingSpeak) Action() string {
+	return "gather_using_speak"
+}
+
+// RecordStart starts recording the call.
+type RecordStart struct {
+	CommandOptions
+	// Format is "wav" or "mp3".
+	Format string `json:"format"`
+	// Channels is "single" or "dual".
+	Channels       string `json:"channels"`
+	PlayBeep       bool   `json:"play_beep,omitempty"`
+	MaxLength      int    `json:"max_length,omitempty"`
+	TimeoutSecs    int    `json:"timeout_secs,omitempty"`
+	Trim           string `json:"trim,omitempty"`
+	RecordingTrack string `json:"recording_track,omitempty"`
+	CustomFileName string `json:"custom_file_name,omitempty"`
+}
+
+func (RecordStart) Action() string {
+	return "record_start"
+}
+
+// RecordStop stops a recording started by RecordStart.
+type RecordStop struct {
+	CommandOptions
+}
+
+func (RecordStop) Action() string {
+	return "record_stop"
+}
+
+// ForkStart forks the media of the call to a UDP target.
+type ForkStart struct {
+	CommandOptions
+	Target string `json:"target,omitempty"`
+	Rx     string `json:"rx,omitempty"`
+	Tx     string `json:"tx,omitempty"`
+	// StreamType is "decrypted".
+	StreamType string `json:"stream_type,omitempty"`
+}
+
+func (ForkStart) Action() string {
+	return "fork_start"
+}
+
+// ForkStop stops a media fork started by ForkStart.
+type ForkStop struct {
+	CommandOptions
+	StreamType string `json:"stream_type,omitempty"`
+}
+
+func (ForkStop) Action() string {
+	return "fork_stop"
+}
+
+// StreamingStart streams the media of the call to a WebSocket, like the TeXML <Stream>
+// verb.
+type StreamingStart struct {
+	CommandOptions
+	StreamUrl   string `json:"stream_url"`
+	StreamTrack string `json:"stream_track,omitempty"`
+	// StreamCodec is "PCMU", "PCMA", "G722", "OPUS", "AMR-WB" or "L16".
+	StreamCodec              string `json:"stream_codec,omitempty"`
+	StreamBidirectionalMode  string `json:"stream_bidirectional_mode,omitempty"`
+	StreamBidirectionalCodec string `json:"stream_bidirectional_codec,omitempty"`
+	EnableDialogflow         bool   `json:"enable_dialogflow,omitempty"`
+}
+
+func (StreamingStart) Action() string {
+	return "streaming_start"
+}
+
+// StreamingStop stops a stream started by StreamingStart. An empty StreamID stops every
+// stream of the call.
+type StreamingStop struct {
+	CommandOptions
+	StreamID string `json:"stream_id,omitempty"`
+}
+
+func (StreamingStop) Action() string {
+	return "streaming_stop"
+}
+
+// TranscriptionStart starts real-time transcription of the call, delivered as
+// call.transcription events.
+type TranscriptionStart struct {
+	CommandOptions
+	Language            string `json:"language,omitempty"`
+	TranscriptionEngine string `json:"transcription_engine,omitempty"`
+	// TranscriptionTracks is "inbound", "outbound" or "both".
+	TranscriptionTracks string `json:"transcription_tracks,omitempty"`
+	InterimResults      bool   `json:"interim_results,omitempty"`
+}
+
+func (TranscriptionStart) Action() string {
+	return "transcription_start"
+}
+
+// TranscriptionStop stops transcription started by TranscriptionStart.
+type TranscriptionStop struct {
+	CommandOptions
+}
+
+func (TranscriptionStop) Action() string {
+	return "transcription_stop"
+}
+
+// SendDTMF sends DTMF tones on the call. Digits may contain 0-9, *, #, A-D and "w" or
+// "W" for a 0.5 or 1 second pause.
+type SendDTMF struct {
+	CommandOptions
+	Digits         string `json:"digits"`
+	DurationMillis int    `json:"duration_millis,omitempty"`
+}
+
+func (SendDTMF) Action() string {
+	return "send_dtmf"
+}
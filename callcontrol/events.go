@@ -0,0 +1,303 @@
+package callcontrol
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+)
+
+// Event is a Call Control webhook.
+//
+// https://developers.telnyx.com/docs/voice/programmable-voice/receiving-webhooks
+type Event = telnyx.Event
+
+// EventMeta describes the delivery of an Event.
+type EventMeta = telnyx.EventMeta
+
+// Payload is implemented by the event payload types. EventType is the event_type the
+// payload is delivered with.
+type Payload = telnyx.EventPayload
+
+var payloadTypes = telnyx.EventTypes{}
+
+func init() {
+	telnyx.RegisterEventPayload[CallInitiated](payloadTypes)
+	telnyx.RegisterEventPayload[CallAnswered](payloadTypes)
+	telnyx.RegisterEventPayload[CallHangup](payloadTypes)
+	telnyx.RegisterEventPayload[CallBridged](payloadTypes)
+	telnyx.RegisterEventPayload[CallGatherEnded](payloadTypes)
+	telnyx.RegisterEventPayload[CallRecordingSaved](payloadTypes)
+	telnyx.RegisterEventPayload[CallMachineDetectionEnded](payloadTypes)
+	telnyx.RegisterEventPayload[CallPlaybackStarted](payloadTypes)
+	telnyx.RegisterEventPayload[CallPlaybackEnded](payloadTypes)
+	telnyx.RegisterEventPayload[CallSpeakStarted](payloadTypes)
+	telnyx.RegisterEventPayload[CallSpeakEnded](payloadTypes)
+	telnyx.RegisterEventPayload[StreamingStarted](payloadTypes)
+	telnyx.RegisterEventPayload[StreamingStopped](payloadTypes)
+	telnyx.RegisterEventPayload[StreamingFailed](payloadTypes)
+	telnyx.RegisterEventPayload[CallTranscription](payloadTypes)
+}
+
+// ParseEvent decodes the body of a webhook, with the payload types of this package.
+func ParseEvent(body []byte) (*Event, error) {
+	return telnyx.ParseEvent(body, payloadTypes)
+}
+
+// HandlerFunc handles an Event delivered to a Dispatcher.
+type HandlerFunc = telnyx.HandlerFunc
+
+// Dispatcher receives Call Control webhooks and routes each event to the handler
+// registered for its event type, see telnyx.Dispatcher.
+type Dispatcher = telnyx.Dispatcher
+
+// NewDispatcher returns a Dispatcher that decodes the payload types of this package
+// and verifies the signature of every webhook with publicKey, see
+// telnyx.ParsePublicKey. A nil publicKey disables the verification.
+func NewDispatcher(publicKey ed25519.PublicKey) *Dispatcher {
+	return telnyx.NewDispatcher(publicKey, payloadTypes)
+}
+
+// On registers fn for the event type of P, e.g.
+//
+//	callcontrol.On(d, func(ctx context.Context, e *callcontrol.Event, p *callcontrol.CallAnswered) error {
+//		return client.Execute(ctx, p.CallControlID, callcontrol.Speak{Payload: "Hello", Voice: "female"})
+//	})
+func On[P Payload](d *Dispatcher, fn func(ctx context.Context, e *Event, p *P) error) {
+	telnyx.On(d, fn)
+}
+
+// ClientState is the client state echoed back in a webhook, already decoded from
+// base64. A client state that is not valid base64 is kept as is.
+type ClientState []byte
+
+func (s *ClientState) UnmarshalJSON(data []byte) error {
+	var encoded string
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		decoded = []byte(encoded)
+	}
+	*s = decoded
+	return nil
+}
+
+func (s ClientState) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte(`""`), nil
+	}
+	return json.Marshal(base64.StdEncoding.EncodeToString(s))
+}
+
+// Decode decodes a client state built with EncodeClientState into v.
+func (s ClientState) Decode(v interface{}) error {
+	if err := json.Unmarshal(s, v); err != nil {
+		return fmt.Errorf("callcontrol: decode client state: %w", err)
+	}
+	return nil
+}
+
+// CallInfo identifies the call an event is about. It is embedded in every payload.
+type CallInfo struct {
+	CallControlID string      `json:"call_control_id"`
+	CallLegID     string      `json:"call_leg_id"`
+	CallSessionID string      `json:"call_session_id"`
+	ConnectionID  string      `json:"connection_id"`
+	From          string      `json:"from"`
+	To            string      `json:"to"`
+	ClientState   ClientState `json:"client_state"`
+}
+
+// CallInitiated is sent when an inbound call arrives or an outbound call is dialed. An
+// inbound call must be answered with Answer.
+type CallInitiated struct {
+	CallInfo
+	// Direction is "incoming" or "outgoing".
+	Direction     string         `json:"direction"`
+	State         string         `json:"state"`
+	CallerIDName  string         `json:"caller_id_name"`
+	StartTime     time.Time      `json:"start_time"`
+	CustomHeaders []CustomHeader `json:"custom_headers"`
+	// ShakenStirAttestation is "A", "B" or "C" for inbound calls signed with
+	// SHAKEN/STIR.
+	ShakenStirAttestation string `json:"shaken_stir_attestation"`
+	ShakenStirValidated   bool   `json:"shaken_stir_validated"`
+}
+
+func (CallInitiated) EventType() string {
+	return "call.initiated"
+}
+
+type CallAnswered struct {
+	CallInfo
+	StartTime     time.Time      `json:"start_time"`
+	CustomHeaders []CustomHeader `json:"custom_headers"`
+}
+
+func (CallAnswered) EventType() string {
+	return "call.answered"
+}
+
+type CallHangup struct {
+	CallInfo
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	// HangupCause is e.g. "normal_clearing", "user_busy", "timeout" or "call_rejected".
+	HangupCause string `json:"hangup_cause"`
+	// HangupSource is "caller", "callee" or "unknown".
+	HangupSource   string `json:"hangup_source"`
+	SipHangupCause string `json:"sip_hangup_cause"`
+}
+
+func (CallHangup) EventType() string {
+	return "call.hangup"
+}
+
+// CallBridged is sent to both calls connected by Bridge or Transfer.
+type CallBridged struct {
+	CallInfo
+}
+
+func (CallBridged) EventType() string {
+	return "call.bridged"
+}
+
+type CallGatherEnded struct {
+	CallInfo
+	Digits string `json:"digits"`
+	// Status is "valid", "invalid", "call_hangup", "cancelled" or "timeout".
+	Status string `json:"status"`
+}
+
+func (CallGatherEnded) EventType() string {
+	return "call.gather.ended"
+}
+
+// RecordingURLs hold the download links of a recording, by format. They expire after
+// 10 minutes.
+type RecordingURLs struct {
+	MP3 string `json:"mp3"`
+	WAV string `json:"wav"`
+}
+
+type CallRecordingSaved struct {
+	CallInfo
+	// Channels is "single" or "dual".
+	Channels            string        `json:"channels"`
+	RecordingStartedAt  time.Time     `json:"recording_started_at"`
+	RecordingEndedAt    time.Time     `json:"recording_ended_at"`
+	RecordingURLs       RecordingURLs `json:"recording_urls"`
+	PublicRecordingURLs RecordingURLs `json:"public_recording_urls"`
+}
+
+func (CallRecordingSaved) EventType() string {
+	return "call.recording.saved"
+}
+
+type CallMachineDetectionEnded struct {
+	CallInfo
+	// Result is "human", "machine" or "not_sure".
+	Result string `json:"result"`
+}
+
+func (CallMachineDetectionEnded) EventType() string {
+	return "call.machine.detection.ended"
+}
+
+type CallPlaybackStarted struct {
+	CallInfo
+	MediaURL  string `json:"media_url"`
+	MediaName string `json:"media_name"`
+	Overlay   bool   `json:"overlay"`
+}
+
+func (CallPlaybackStarted) EventType() string {
+	return "call.playback.started"
+}
+
+type CallPlaybackEnded struct {
+	CallInfo
+	MediaURL  string `json:"media_url"`
+	MediaName string `json:"media_name"`
+	Overlay   bool   `json:"overlay"`
+	// Status is "completed", "call_hangup", "cancelled" or "file_not_found".
+	Status string `json:"status"`
+}
+
+func (CallPlaybackEnded) EventType() string {
+	return "call.playback.ended"
+}
+
+type CallSpeakStarted struct {
+	CallInfo
+}
+
+func (CallSpeakStarted) EventType() string {
+	return "call.speak.started"
+}
+
+type CallSpeakEnded struct {
+	CallInfo
+	// Status is "completed", "call_hangup" or "cancelled".
+	Status string `json:"status"`
+}
+
+func (CallSpeakEnded) EventType() string {
+	return "call.speak.ended"
+}
+
+// StreamingEvent is the payload shared by the streaming events started by
+// StreamingStart.
+type StreamingEvent struct {
+	CallInfo
+	StreamID  string `json:"stream_id"`
+	StreamURL string `json:"stream_url"`
+}
+
+type StreamingStarted struct {
+	StreamingEvent
+}
+
+func (StreamingStarted) EventType() string {
+	return "streaming.started"
+}
+
+type StreamingStopped struct {
+	StreamingEvent
+}
+
+func (StreamingStopped) EventType() string {
+	return "streaming.stopped"
+}
+
+type StreamingFailed struct {
+	StreamingEvent
+	FailureReason string `json:"failure_reason"`
+}
+
+func (StreamingFailed) EventType() string {
+	return "streaming.failed"
+}
+
+// TranscriptionData is a piece of transcript produced by TranscriptionStart.
+type TranscriptionData struct {
+	Transcript string  `json:"transcript"`
+	Confidence float64 `json:"confidence"`
+	// IsFinal is false for interim results, which are replaced by later events.
+	IsFinal bool `json:"is_final"`
+}
+
+type CallTranscription struct {
+	CallInfo
+	TranscriptionData TranscriptionData `json:"transcription_data"`
+}
+
+func (CallTranscription) EventType() string {
+	return "call.transcription"
+}
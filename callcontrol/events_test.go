@@ -0,0 +1,192 @@
+package callcontrol
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+)
+
+// event returns the body of a webhook of eventType with payload.
+func event(eventType, payload string) []byte {
+	return []byte(`{"data":{"record_type":"event","id":"ev1","event_type":"` + eventType + `",
+		"occurred_at":"2025-05-06T12:00:00.000000Z","payload":` + payload + `},"meta":{"attempt":2,"delivered_to":"https://example.com/webhooks"}}`)
+}
+
+func TestParseEvent(t *testing.T) {
+	state, _ := EncodeClientState(map[string]string{"step": "menu"})
+	e, err := ParseEvent(event("call.gather.ended", `{"call_control_id":"v3:abc","from":"+13125550100","to":"+13125550199",
+		"client_state":"`+state+`","digits":"1234","status":"valid"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.ID != "ev1" || e.EventType != "call.gather.ended" || e.Meta.Attempt != 2 || e.OccurredAt.IsZero() {
+		t.Errorf("event = %+v", e)
+	}
+	p, ok := e.Payload.(*CallGatherEnded)
+	if !ok {
+		t.Fatalf("Payload = %T, want *CallGatherEnded", e.Payload)
+	}
+	if p.CallControlID != "v3:abc" || p.Digits != "1234" || p.Status != "valid" {
+		t.Errorf("payload = %+v", p)
+	}
+	var decoded map[string]string
+	if err := p.ClientState.Decode(&decoded); err != nil || decoded["step"] != "menu" {
+		t.Errorf("client state = %v, %v", decoded, err)
+	}
+}
+
+func TestParseEventPayloads(t *testing.T) {
+	tests := []struct {
+		eventType string
+		payload   string
+		check     func(p interface{}) bool
+	}{
+		{"call.initiated", `{"direction":"incoming","shaken_stir_attestation":"A"}`,
+			func(p interface{}) bool { return p.(*CallInitiated).ShakenStirAttestation == "A" }},
+		{"call.answered", `{"call_control_id":"v3:abc"}`, func(p interface{}) bool { return p.(*CallAnswered).CallControlID == "v3:abc" }},
+		{"call.hangup", `{"hangup_cause":"user_busy","hangup_source":"callee"}`,
+			func(p interface{}) bool { return p.(*CallHangup).HangupCause == "user_busy" }},
+		{"call.bridged", `{"call_leg_id":"leg1"}`, func(p interface{}) bool { return p.(*CallBridged).CallLegID == "leg1" }},
+		{"call.recording.saved", `{"channels":"dual","recording_urls":{"mp3":"https://example.com/r.mp3"}}`,
+			func(p interface{}) bool {
+				return p.(*CallRecordingSaved).RecordingURLs.MP3 == "https://example.com/r.mp3"
+			}},
+		{"call.machine.detection.ended", `{"result":"machine"}`,
+			func(p interface{}) bool { return p.(*CallMachineDetectionEnded).Result == "machine" }},
+		{"call.playback.ended", `{"status":"file_not_found"}`, func(p interface{}) bool { return p.(*CallPlaybackEnded).Status == "file_not_found" }},
+		{"call.speak.ended", `{"status":"completed"}`, func(p interface{}) bool { return p.(*CallSpeakEnded).Status == "completed" }},
+		{"streaming.failed", `{"stream_id":"s1","failure_reason":"connection_failed"}`,
+			func(p interface{}) bool {
+				f := p.(*StreamingFailed)
+				return f.StreamID == "s1" && f.FailureReason == "connection_failed"
+			}},
+		{"call.transcription", `{"transcription_data":{"transcript":"hello","confidence":0.9,"is_final":true}}`,
+			func(p interface{}) bool { return p.(*CallTranscription).TranscriptionData.IsFinal }},
+	}
+	for _, tt := range tests {
+		e, err := ParseEvent(event(tt.eventType, tt.payload))
+		if err != nil {
+			t.Errorf("%s: %v", tt.eventType, err)
+			continue
+		}
+		if p, ok := e.Payload.(Payload); !ok || p.EventType() != tt.eventType || !tt.check(e.Payload) {
+			t.Errorf("%s: payload = %+v", tt.eventType, e.Payload)
+		}
+	}
+}
+
+func TestParseEventErrors(t *testing.T) {
+	e, err := ParseEvent(event("call.dtmf.received", `{"digit":"5"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw, ok := e.Payload.(json.RawMessage); !ok || string(raw) != `{"digit":"5"}` {
+		t.Errorf("unknown event payload = %#v, want the raw JSON", e.Payload)
+	}
+
+	for _, body := range []string{`not json`, `{"data":{"payload":{}}}`, string(event("call.hangup", `{"start_time":"yesterday"}`))} {
+		if _, err := ParseEvent([]byte(body)); err == nil {
+			t.Errorf("ParseEvent(%s) succeeded", body)
+		}
+	}
+}
+
+func TestClientStateJSON(t *testing.T) {
+	var info CallInfo
+	if err := json.Unmarshal([]byte(`{"client_state":"not base64!"}`), &info); err != nil {
+		t.Fatal(err)
+	}
+	if string(info.ClientState) != "not base64!" {
+		t.Errorf("ClientState = %q, want the state kept as is", info.ClientState)
+	}
+	data, _ := json.Marshal(ClientState("hi"))
+	if string(data) != `"aGk="` {
+		t.Errorf("Marshal = %s", data)
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	d := NewDispatcher(pub)
+
+	var answered, fallback []string
+	On(d, func(ctx context.Context, e *Event, p *CallAnswered) error {
+		answered = append(answered, p.CallControlID)
+		return nil
+	})
+	d.Handle("call.hangup", func(ctx context.Context, e *Event) error {
+		return errors.New("database down")
+	})
+
+	post := func(body []byte, sign bool) int {
+		r := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(body))
+		if sign {
+			sig, ts := telnyx.SignWebhook(body, priv, time.Now())
+			r.Header.Set(telnyx.SignatureHeader, sig)
+			r.Header.Set(telnyx.TimestampHeader, ts)
+		}
+		w := httptest.NewRecorder()
+		d.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := post(event("call.answered", `{"call_control_id":"v3:abc"}`), true); code != http.StatusOK {
+		t.Errorf("call.answered: %d", code)
+	}
+	if len(answered) != 1 || answered[0] != "v3:abc" {
+		t.Errorf("answered = %v", answered)
+	}
+	if code := post(event("call.answered", `{"call_control_id":"v3:forged"}`), false); code != http.StatusUnauthorized {
+		t.Errorf("unsigned webhook: %d, want 401", code)
+	}
+	if len(answered) != 1 {
+		t.Error("an unsigned webhook was dispatched")
+	}
+	if code := post(event("call.hangup", `{}`), true); code != http.StatusInternalServerError {
+		t.Errorf("handler error: %d, want 500 so that Telnyx retries", code)
+	}
+	if code := post(event("call.speak.started", `{}`), true); code != http.StatusOK {
+		t.Errorf("unhandled event: %d, want 200", code)
+	}
+	if code := post([]byte(`{}`), true); code != http.StatusBadRequest {
+		t.Errorf("event without a type: %d, want 400", code)
+	}
+
+	d.HandleDefault(func(ctx context.Context, e *Event) error {
+		fallback = append(fallback, e.EventType)
+		return nil
+	})
+	post(event("call.speak.started", `{}`), true)
+	post(event("call.answered", `{"call_control_id":"v3:def"}`), true)
+	if len(fallback) != 1 || fallback[0] != "call.speak.started" || len(answered) != 2 {
+		t.Errorf("fallback = %v, answered = %v", fallback, answered)
+	}
+
+	w := httptest.NewRecorder()
+	d.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/webhooks", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("GET: %d, Allow %q", w.Code, w.Header().Get("Allow"))
+	}
+}
+
+func TestDispatcherWithoutKey(t *testing.T) {
+	d := NewDispatcher(nil)
+	var got *CallMachineDetectionEnded
+	On(d, func(ctx context.Context, e *Event, p *CallMachineDetectionEnded) error {
+		got = p
+		return nil
+	})
+	w := httptest.NewRecorder()
+	d.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(event("call.machine.detection.ended", `{"result":"human"}`))))
+	if w.Code != http.StatusOK || got == nil || got.Result != "human" {
+		t.Errorf("%d, payload %+v", w.Code, got)
+	}
+}
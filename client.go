@@ -0,0 +1,298 @@
+// Package telnyx is a client for the Telnyx v2 REST API.
+//
+// The Client in this package handles authentication, retries, errors and pagination.
+// The resources of the API are implemented by the packages built on top of it.
+package telnyx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Version is the version of this library, sent in the User-Agent header.
+	Version = "0.0.1"
+
+	// DefaultBaseURL is the base URL of the Telnyx v2 API.
+	DefaultBaseURL = "https://api.telnyx.com/v2/"
+
+	DefaultMaxRetries   = 3
+	DefaultRetryWaitMin = 500 * time.Millisecond
+	DefaultRetryWaitMax = 10 * time.Second
+
+	// IdempotencyKeyHeader marks a POST request as safe to retry after a 5xx response.
+	// Set it on a request built with NewRequest before passing it to Send.
+	IdempotencyKeyHeader = "Idempotency-Key"
+)
+
+// ClientParams configures a Client. Zero values are replaced by the defaults above.
+type ClientParams struct {
+	APIKey string
+	// BaseURL is the URL every request path is resolved against. Point it at a local
+	// server to test against a mock of the API.
+	BaseURL    string
+	HTTPClient *http.Client
+	// MaxRetries is the number of times a request is retried after a 429 response, or
+	// after a 5xx response to an idempotent request. Set it to a negative value to
+	// disable retries.
+	MaxRetries   int
+	RetryWaitMin time.Duration
+	RetryWaitMax time.Duration
+}
+
+// Client sends authenticated requests to the Telnyx API. It is safe for concurrent use.
+type Client struct {
+	apiKey       string
+	baseURL      *url.URL
+	httpClient   *http.Client
+	maxRetries   int
+	retryWaitMin time.Duration
+	retryWaitMax time.Duration
+}
+
+// NewClient returns a Client for the production API using apiKey.
+func NewClient(apiKey string) *Client {
+	client, _ := NewClientWithParams(ClientParams{APIKey: apiKey})
+	return client
+}
+
+// NewClientWithParams returns a Client configured by params. It fails only if
+// params.BaseURL cannot be parsed.
+func NewClientWithParams(params ClientParams) (*Client, error) {
+	rawBaseURL := params.BaseURL
+	if rawBaseURL == "" {
+		rawBaseURL = DefaultBaseURL
+	}
+	if !strings.HasSuffix(rawBaseURL, "/") {
+		rawBaseURL += "/"
+	}
+	baseURL, err := url.Parse(rawBaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("telnyx: invalid base URL: %w", err)
+	}
+
+	c := &Client{
+		apiKey:       params.APIKey,
+		baseURL:      baseURL,
+		httpClient:   params.HTTPClient,
+		maxRetries:   params.MaxRetries,
+		retryWaitMin: params.RetryWaitMin,
+		retryWaitMax: params.RetryWaitMax,
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	if c.maxRetries == 0 {
+		c.maxRetries = DefaultMaxRetries
+	} else if c.maxRetries < 0 {
+		c.maxRetries = 0
+	}
+	if c.retryWaitMin <= 0 {
+		c.retryWaitMin = DefaultRetryWaitMin
+	}
+	if c.retryWaitMax <= 0 {
+		c.retryWaitMax = DefaultRetryWaitMax
+	}
+	return c, nil
+}
+
+// BaseURL returns the URL request paths are resolved against.
+func (c *Client) BaseURL() *url.URL {
+	u := *c.baseURL
+	return &u
+}
+
+// ErrNilParams is returned for requests whose body is a nil pointer, such as the nil
+// params of a method that requires them.
+var ErrNilParams = errors.New("telnyx: nil params")
+
+// NewRequest returns an authenticated request for path, which is resolved against the
+// base URL and may include a query string. A non-nil body is encoded as JSON; a nil
+// pointer body returns ErrNilParams.
+func (c *Client) NewRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	u, err := c.baseURL.Parse(strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("telnyx: invalid path %q: %w", path, err)
+	}
+
+	var r io.Reader
+	if body != nil {
+		if v := reflect.ValueOf(body); v.Kind() == reflect.Pointer && v.IsNil() {
+			return nil, ErrNilParams
+		}
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("telnyx: encode request: %w", err)
+		}
+		r = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "telnyx-go/"+Version)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// Send sends req, retrying with exponential backoff on 429 responses, which the API
+// rejected before processing them. 5xx responses may come after the API acted on the
+// request, so they are only retried for GET, HEAD, PUT and DELETE requests and for
+// POST requests that carry an IdempotencyKeyHeader or a JSON body with a command_id.
+// Responses with any other non-2xx status are returned as an *Error. On success the
+// caller must close the response body.
+func (c *Client) Send(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp, nil
+		}
+
+		apiErr := newError(resp)
+		if attempt >= c.maxRetries || !retryable(req, resp.StatusCode) {
+			return nil, apiErr
+		}
+		if err := sleep(req.Context(), c.backoff(attempt, resp)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Do sends a request built by NewRequest and decodes the JSON response into out, which
+// may be nil to discard the response.
+func (c *Client) Do(ctx context.Context, method, path string, body, out interface{}) error {
+	req, err := c.NewRequest(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.Send(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && err != io.EOF {
+		return fmt.Errorf("telnyx: decode response: %w", err)
+	}
+	return nil
+}
+
+// Download streams the resource at rawURL, which is resolved against the base URL. The
+// API key is only sent when rawURL points at the API host, since media URLs returned by
+// the API are usually presigned storage URLs that reject other credentials. The caller
+// must close the returned reader.
+func (c *Client) Download(ctx context.Context, rawURL string) (io.ReadCloser, error) {
+	u, err := c.baseURL.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("telnyx: invalid URL %q: %w", rawURL, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "telnyx-go/"+Version)
+	if u.Host == c.baseURL.Host {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	resp, err := c.Send(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// DataResponse is the envelope of a v2 response holding a single resource.
+type DataResponse[T any] struct {
+	Data T `json:"data"`
+}
+
+func retryable(req *http.Request, status int) bool {
+	if status == http.StatusTooManyRequests {
+		return true
+	}
+	return status >= 500 && idempotent(req)
+}
+
+// idempotent reports whether sending req twice has the same effect as sending it once.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	if req.Header.Get(IdempotencyKeyHeader) != "" {
+		return true
+	}
+	// Call Control ignores a command whose command_id was already used on the call.
+	if req.GetBody == nil {
+		return false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	defer body.Close()
+	var command struct {
+		CommandID string `json:"command_id"`
+	}
+	return json.NewDecoder(body).Decode(&command) == nil && command.CommandID != ""
+}
+
+// backoff returns the delay before retry attempt+1. A Retry-After header given in
+// seconds takes precedence over the exponential delay.
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		if d := time.Duration(seconds) * time.Second; d < c.retryWaitMax {
+			return d
+		}
+		return c.retryWaitMax
+	}
+
+	d := c.retryWaitMin << attempt
+	if d <= 0 || d > c.retryWaitMax {
+		d = c.retryWaitMax
+	}
+	// Add up to 25% of jitter so concurrent clients do not retry in lockstep.
+	return d + time.Duration(rand.Int63n(int64(d)/4+1))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,299 @@
+package telnyx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a Client for srv that retries without waiting.
+func newTestClient(t *testing.T, srv *httptest.Server) *Client {
+	t.Helper()
+	c, err := NewClientWithParams(ClientParams{
+		APIKey:       "KEY123",
+		BaseURL:      srv.URL + "/v2",
+		RetryWaitMin: time.Millisecond,
+		RetryWaitMax: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestClientDo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/things/1" || r.URL.Query().Get("expand") != "all" {
+			t.Errorf("request to %s", r.URL)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer KEY123" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		if !strings.HasPrefix(r.Header.Get("User-Agent"), "telnyx-go/") {
+			t.Errorf("User-Agent = %q", r.Header.Get("User-Agent"))
+		}
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["name"] != "thing" {
+			t.Errorf("body = %v, %v", body, err)
+		}
+		w.Write([]byte(`{"data":{"id":"1","name":"thing"}}`))
+	}))
+	defer srv.Close()
+
+	var resp DataResponse[struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}]
+	err := newTestClient(t, srv).Do(context.Background(), http.MethodPatch, "/things/1?expand=all", map[string]string{"name": "thing"}, &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.ID != "1" || resp.Data.Name != "thing" {
+		t.Errorf("Do decoded %+v", resp.Data)
+	}
+}
+
+func TestClientNilParams(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	type params struct {
+		Name string `json:"name"`
+	}
+	if err := c.Do(context.Background(), http.MethodPost, "/things", (*params)(nil), nil); !errors.Is(err, ErrNilParams) {
+		t.Errorf("Do with nil params = %v, want ErrNilParams", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Errorf("%d requests were sent with nil params", n)
+	}
+	// An untyped nil body sends no body at all.
+	if err := c.Do(context.Background(), http.MethodDelete, "/things/1", nil, nil); err != nil {
+		t.Errorf("Do without a body = %v", err)
+	}
+}
+
+func TestClientError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[{"code":"10005","title":"Resource not found","detail":"No thing 1.","source":{"pointer":"/id"}}]}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("bad request\n"))
+		}
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	err := c.Do(context.Background(), http.MethodGet, "missing", nil, nil)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Do error = %v, want an *Error", err)
+	}
+	if !IsNotFound(err) || IsRateLimited(err) {
+		t.Errorf("IsNotFound = %v, IsRateLimited = %v", IsNotFound(err), IsRateLimited(err))
+	}
+	if apiErr.Code() != "10005" || !apiErr.HasCode("10005") || apiErr.HasCode("10015") {
+		t.Errorf("Code() = %q", apiErr.Code())
+	}
+	if apiErr.Errors[0].Source == nil || apiErr.Errors[0].Source.Pointer != "/id" {
+		t.Errorf("Source = %+v", apiErr.Errors[0].Source)
+	}
+	if want := "telnyx: 404 Resource not found: No thing 1. (code 10005)"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	err = c.Do(context.Background(), http.MethodGet, "other", nil, nil)
+	if want := "telnyx: 400 Bad Request: bad request"; err == nil || err.Error() != want {
+		t.Errorf("Error() = %v, want %q", err, want)
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		body    interface{}
+		header  string
+		status  int
+		retried bool
+	}{
+		{"GET 502", http.MethodGet, nil, "", http.StatusBadGateway, true},
+		{"DELETE 503", http.MethodDelete, nil, "", http.StatusServiceUnavailable, true},
+		{"PUT 500", http.MethodPut, map[string]string{"a": "b"}, "", http.StatusInternalServerError, true},
+		{"POST 429", http.MethodPost, map[string]string{"to": "+13125550100"}, "", http.StatusTooManyRequests, true},
+		{"POST 502", http.MethodPost, map[string]string{"to": "+13125550100"}, "", http.StatusBadGateway, false},
+		{"POST 504", http.MethodPost, nil, "", http.StatusGatewayTimeout, false},
+		{"PATCH 502", http.MethodPatch, map[string]string{"a": "b"}, "", http.StatusBadGateway, false},
+		{"POST 502 with command_id", http.MethodPost, map[string]string{"command_id": "c1"}, "", http.StatusBadGateway, true},
+		{"POST 502 with empty command_id", http.MethodPost, map[string]string{"command_id": ""}, "", http.StatusBadGateway, false},
+		{"POST 502 with idempotency key", http.MethodPost, map[string]string{"to": "+13125550100"}, "k1", http.StatusBadGateway, true},
+		{"GET 404", http.MethodGet, nil, "", http.StatusNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			var bodies []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(data))
+				if atomic.AddInt32(&attempts, 1) == 1 {
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.Write([]byte(`{}`))
+			}))
+			defer srv.Close()
+			c := newTestClient(t, srv)
+
+			req, err := c.NewRequest(context.Background(), tt.method, "things", tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.header != "" {
+				req.Header.Set(IdempotencyKeyHeader, tt.header)
+			}
+			resp, err := c.Send(req)
+			if tt.retried {
+				if err != nil {
+					t.Fatalf("Send: %v", err)
+				}
+				resp.Body.Close()
+				if attempts != 2 {
+					t.Errorf("%d attempts, want 2", attempts)
+				}
+				if bodies[0] != bodies[1] {
+					t.Errorf("retry sent body %q, first attempt %q", bodies[1], bodies[0])
+				}
+				return
+			}
+			var apiErr *Error
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Errorf("Send error = %v, want status %d", err, tt.status)
+			}
+			if attempts != 1 {
+				t.Errorf("%d attempts, want 1", attempts)
+			}
+		})
+	}
+}
+
+func TestClientMaxRetries(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv)
+	if err := c.Do(context.Background(), http.MethodGet, "things", nil, nil); !IsRateLimited(err) {
+		t.Errorf("Do error = %v, want rate limited", err)
+	}
+	if attempts != DefaultMaxRetries+1 {
+		t.Errorf("%d attempts, want %d", attempts, DefaultMaxRetries+1)
+	}
+
+	atomic.StoreInt32(&attempts, 0)
+	c, _ = NewClientWithParams(ClientParams{BaseURL: srv.URL, MaxRetries: -1})
+	c.Do(context.Background(), http.MethodGet, "things", nil, nil)
+	if attempts != 1 {
+		t.Errorf("%d attempts with retries disabled, want 1", attempts)
+	}
+}
+
+func TestClientRetryCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	c, _ := NewClientWithParams(ClientParams{BaseURL: srv.URL, RetryWaitMin: time.Hour, RetryWaitMax: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := c.Do(ctx, http.MethodGet, "things", nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do error = %v, want the context error", err)
+	}
+}
+
+func TestClientBackoff(t *testing.T) {
+	c, _ := NewClientWithParams(ClientParams{RetryWaitMin: time.Second, RetryWaitMax: 4 * time.Second})
+	resp := &http.Response{Header: http.Header{}}
+	for attempt, base := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		if d := c.backoff(attempt, resp); d < base || d > base+base/4 {
+			t.Errorf("backoff(%d) = %v, want %v plus up to 25%%", attempt, d, base)
+		}
+	}
+	resp.Header.Set("Retry-After", "2")
+	if d := c.backoff(0, resp); d != 2*time.Second {
+		t.Errorf("backoff with Retry-After: 2 = %v", d)
+	}
+	resp.Header.Set("Retry-After", "60")
+	if d := c.backoff(0, resp); d != 4*time.Second {
+		t.Errorf("backoff with Retry-After: 60 = %v, want RetryWaitMax", d)
+	}
+}
+
+func TestClientDownload(t *testing.T) {
+	var apiAuth, mediaAuth string
+	media := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaAuth = r.Header.Get("Authorization")
+		w.Write([]byte("RIFF"))
+	}))
+	defer media.Close()
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiAuth = r.Header.Get("Authorization")
+		w.Write([]byte("ID3"))
+	}))
+	defer api.Close()
+	c := newTestClient(t, api)
+
+	for _, tt := range []struct{ url, want string }{{"recordings/1.mp3", "ID3"}, {media.URL + "/1.wav", "RIFF"}} {
+		body, err := c.Download(context.Background(), tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(body)
+		body.Close()
+		if string(data) != tt.want {
+			t.Errorf("Download(%s) = %q, want %q", tt.url, data, tt.want)
+		}
+	}
+	if apiAuth != "Bearer KEY123" {
+		t.Errorf("API download Authorization = %q", apiAuth)
+	}
+	if mediaAuth != "" {
+		t.Errorf("the API key was sent to another host: %q", mediaAuth)
+	}
+}
+
+func TestNewClientWithParams(t *testing.T) {
+	if _, err := NewClientWithParams(ClientParams{BaseURL: "http://[::1"}); err == nil {
+		t.Error("an invalid base URL was accepted")
+	}
+	c := NewClient("KEY")
+	if got := c.BaseURL().String(); got != DefaultBaseURL {
+		t.Errorf("BaseURL() = %s, want %s", got, DefaultBaseURL)
+	}
+	c.BaseURL().Path = "/changed"
+	if got := c.BaseURL().String(); got != DefaultBaseURL {
+		t.Errorf("BaseURL() returned the client's own URL")
+	}
+}
@@ -0,0 +1,116 @@
+package telnyx
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// HandlerFunc handles an Event delivered to a Dispatcher.
+type HandlerFunc func(ctx context.Context, e *Event) error
+
+// Dispatcher is an http.Handler that receives webhooks and routes each event to the
+// handler registered for its event type. The callcontrol and messaging packages return
+// Dispatchers that decode their own payload types.
+//
+// A handler error is answered with a 500, which makes Telnyx deliver the event again.
+// Events without a handler are acknowledged and dropped, unless a default handler is
+// set with HandleDefault.
+type Dispatcher struct {
+	publicKey ed25519.PublicKey
+	types     EventTypes
+
+	mu       sync.RWMutex
+	handlers map[string]HandlerFunc
+	fallback HandlerFunc
+}
+
+// NewDispatcher returns a Dispatcher that decodes payloads with types and verifies the
+// signature of every webhook with publicKey, see ParsePublicKey. A nil publicKey
+// disables the verification.
+func NewDispatcher(publicKey ed25519.PublicKey, types EventTypes) *Dispatcher {
+	return &Dispatcher{
+		publicKey: publicKey,
+		types:     types,
+		handlers:  map[string]HandlerFunc{},
+	}
+}
+
+// Handle registers fn for eventType, replacing any previous handler.
+func (d *Dispatcher) Handle(eventType string, fn HandlerFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[eventType] = fn
+}
+
+// HandleDefault registers fn for the events that have no handler of their own.
+func (d *Dispatcher) HandleDefault(fn HandlerFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.fallback = fn
+}
+
+// On registers fn for the event type of P.
+func On[P EventPayload](d *Dispatcher, fn func(ctx context.Context, e *Event, p *P) error) {
+	var zero P
+	d.Handle(zero.EventType(), func(ctx context.Context, e *Event) error {
+		p, ok := e.Payload.(*P)
+		if !ok {
+			return fmt.Errorf("telnyx: unexpected %T payload for %s", e.Payload, e.EventType)
+		}
+		return fn(ctx, e, p)
+	})
+}
+
+// Dispatch calls the handler registered for e.
+func (d *Dispatcher) Dispatch(ctx context.Context, e *Event) error {
+	d.mu.RLock()
+	fn, ok := d.handlers[e.EventType]
+	if !ok {
+		fn = d.fallback
+	}
+	d.mu.RUnlock()
+
+	if fn == nil {
+		return nil
+	}
+	return fn(ctx, e)
+}
+
+func (d *Dispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body []byte
+	var err error
+	if d.publicKey != nil {
+		body, err = VerifyWebhookRequest(r, d.publicKey)
+		if err == ErrInvalidSignature {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+	} else {
+		body, err = io.ReadAll(r.Body)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	e, err := ParseEvent(body, d.types)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := d.Dispatch(r.Context(), e); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
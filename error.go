@@ -0,0 +1,111 @@
+package telnyx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Error is returned for every API response with a non-2xx status. The API describes
+// failures as a JSON:API error list:
+//
+//	{"errors": [{"code": "10015", "title": "Invalid value", "detail": "...", "source": {"pointer": "/to"}}]}
+type Error struct {
+	StatusCode int
+	Errors     []ErrorDetail
+	// Body holds the raw response when it is not a JSON:API error document.
+	Body string
+}
+
+// ErrorDetail is a single entry of an API error response.
+type ErrorDetail struct {
+	Code   string                 `json:"code"`
+	Title  string                 `json:"title"`
+	Detail string                 `json:"detail"`
+	Source *ErrorSource           `json:"source,omitempty"`
+	Meta   map[string]interface{} `json:"meta,omitempty"`
+}
+
+// ErrorSource points at the part of the request that caused an error.
+type ErrorSource struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Errors) == 0 {
+		if e.Body != "" {
+			return fmt.Sprintf("telnyx: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+		}
+		return fmt.Sprintf("telnyx: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+
+	details := make([]string, 0, len(e.Errors))
+	for _, d := range e.Errors {
+		s := d.Title
+		if d.Detail != "" && d.Detail != d.Title {
+			s += ": " + d.Detail
+		}
+		if d.Code != "" {
+			s = fmt.Sprintf("%s (code %s)", s, d.Code)
+		}
+		details = append(details, s)
+	}
+	return fmt.Sprintf("telnyx: %d %s", e.StatusCode, strings.Join(details, "; "))
+}
+
+// Code returns the code of the first error, or an empty string.
+func (e *Error) Code() string {
+	if len(e.Errors) == 0 {
+		return ""
+	}
+	return e.Errors[0].Code
+}
+
+// HasCode reports whether any of the errors has the given code.
+func (e *Error) HasCode(code string) bool {
+	for _, d := range e.Errors {
+		if d.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNotFound reports whether err is an API error with status 404.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsRateLimited reports whether err is an API error with status 429.
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// newError reads and closes the body of a failed response.
+func newError(resp *http.Response) *Error {
+	defer resp.Body.Close()
+	e := &Error{StatusCode: resp.StatusCode}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return e
+	}
+	var doc struct {
+		Errors []ErrorDetail `json:"errors"`
+	}
+	if json.Unmarshal(data, &doc) == nil && len(doc.Errors) != 0 {
+		e.Errors = doc.Errors
+	} else {
+		e.Body = strings.TrimSpace(string(data))
+	}
+	return e
+}
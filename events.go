@@ -0,0 +1,79 @@
+package telnyx
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Event is a webhook delivered by the Call Control or messaging APIs, which share the
+// same envelope.
+//
+// https://developers.telnyx.com/docs/voice/programmable-voice/receiving-webhooks
+type Event struct {
+	ID         string
+	EventType  string
+	OccurredAt time.Time
+	// Payload points to the payload type registered for EventType, e.g.
+	// *callcontrol.CallAnswered for "call.answered". Events without a registered type
+	// are left as a json.RawMessage.
+	Payload interface{}
+	Meta    EventMeta
+}
+
+// EventMeta describes the delivery of an Event.
+type EventMeta struct {
+	Attempt     int    `json:"attempt"`
+	DeliveredTo string `json:"delivered_to"`
+}
+
+// EventPayload is implemented by the event payload types. EventType is the event_type
+// the payload is delivered with.
+type EventPayload interface {
+	EventType() string
+}
+
+// EventTypes maps event types to functions returning a new payload of their type.
+type EventTypes map[string]func() interface{}
+
+// RegisterEventPayload adds the payload type P to types.
+func RegisterEventPayload[P EventPayload](types EventTypes) {
+	var zero P
+	types[zero.EventType()] = func() interface{} { return new(P) }
+}
+
+// ParseEvent decodes the body of a webhook, with the payload type types registers for
+// its event type.
+func ParseEvent(body []byte, types EventTypes) (*Event, error) {
+	var envelope struct {
+		Data struct {
+			ID         string          `json:"id"`
+			EventType  string          `json:"event_type"`
+			OccurredAt time.Time       `json:"occurred_at"`
+			Payload    json.RawMessage `json:"payload"`
+		} `json:"data"`
+		Meta EventMeta `json:"meta"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("telnyx: decode event: %w", err)
+	}
+	if envelope.Data.EventType == "" {
+		return nil, fmt.Errorf("telnyx: decode event: missing event_type")
+	}
+
+	e := &Event{
+		ID:         envelope.Data.ID,
+		EventType:  envelope.Data.EventType,
+		OccurredAt: envelope.Data.OccurredAt,
+		Payload:    envelope.Data.Payload,
+		Meta:       envelope.Meta,
+	}
+	if newPayload, ok := types[e.EventType]; ok {
+		payload := newPayload()
+		if err := json.Unmarshal(envelope.Data.Payload, payload); err != nil {
+			return nil, fmt.Errorf("telnyx: decode %s payload: %w", e.EventType, err)
+		}
+		e.Payload = payload
+	}
+	return e, nil
+}
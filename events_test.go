@@ -0,0 +1,112 @@
+package telnyx
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type thingCreated struct {
+	Name string `json:"name"`
+}
+
+func (thingCreated) EventType() string {
+	return "thing.created"
+}
+
+type thingDeleted struct{}
+
+func (thingDeleted) EventType() string {
+	return "thing.deleted"
+}
+
+func testEventTypes() EventTypes {
+	types := EventTypes{}
+	RegisterEventPayload[thingCreated](types)
+	RegisterEventPayload[thingDeleted](types)
+	return types
+}
+
+func thingEvent(eventType, payload string) []byte {
+	return []byte(`{"data":{"id":"ev1","event_type":"` + eventType + `","occurred_at":"2025-05-06T12:00:00Z",
+		"payload":` + payload + `},"meta":{"attempt":1,"delivered_to":"https://example.com/hook"}}`)
+}
+
+func TestParseEvent(t *testing.T) {
+	e, err := ParseEvent(thingEvent("thing.created", `{"name":"a"}`), testEventTypes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2025, 5, 6, 12, 0, 0, 0, time.UTC)
+	if e.ID != "ev1" || !e.OccurredAt.Equal(want) || e.Meta.Attempt != 1 || e.Meta.DeliveredTo != "https://example.com/hook" {
+		t.Errorf("event = %+v", e)
+	}
+	if p, ok := e.Payload.(*thingCreated); !ok || p.Name != "a" {
+		t.Errorf("Payload = %#v", e.Payload)
+	}
+
+	e, err = ParseEvent(thingEvent("thing.updated", `{"name":"b"}`), testEventTypes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw, ok := e.Payload.(json.RawMessage); !ok || string(raw) != `{"name":"b"}` {
+		t.Errorf("unregistered payload = %#v, want the raw JSON", e.Payload)
+	}
+
+	for _, body := range []string{`[]`, `{"data":{}}`, string(thingEvent("thing.created", `{"name":1}`))} {
+		if _, err := ParseEvent([]byte(body), testEventTypes()); err == nil || !strings.HasPrefix(err.Error(), "telnyx: decode") {
+			t.Errorf("ParseEvent(%s) error = %v", body, err)
+		}
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	d := NewDispatcher(pub, testEventTypes())
+
+	var names, other []string
+	On(d, func(ctx context.Context, e *Event, p *thingCreated) error {
+		names = append(names, p.Name)
+		return nil
+	})
+	d.HandleDefault(func(ctx context.Context, e *Event) error {
+		other = append(other, e.EventType)
+		return nil
+	})
+
+	post := func(d *Dispatcher, body []byte, at time.Time) int {
+		r := httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(body))
+		sig, ts := SignWebhook(body, priv, at)
+		r.Header.Set(SignatureHeader, sig)
+		r.Header.Set(TimestampHeader, ts)
+		w := httptest.NewRecorder()
+		d.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := post(d, thingEvent("thing.created", `{"name":"a"}`), time.Now()); code != http.StatusOK {
+		t.Errorf("thing.created: %d", code)
+	}
+	if code := post(d, thingEvent("thing.deleted", `{}`), time.Now()); code != http.StatusOK {
+		t.Errorf("thing.deleted: %d", code)
+	}
+	if code := post(d, thingEvent("thing.created", `{"name":"replayed"}`), time.Now().Add(-time.Hour)); code != http.StatusUnauthorized {
+		t.Errorf("old webhook: %d, want 401", code)
+	}
+	if len(names) != 1 || names[0] != "a" || len(other) != 1 || other[0] != "thing.deleted" {
+		t.Errorf("names = %v, other = %v", names, other)
+	}
+
+	// On fails the delivery when the dispatcher decodes another payload type.
+	untyped := NewDispatcher(pub, EventTypes{})
+	On(untyped, func(ctx context.Context, e *Event, p *thingCreated) error { return nil })
+	if code := post(untyped, thingEvent("thing.created", `{"name":"a"}`), time.Now()); code != http.StatusInternalServerError {
+		t.Errorf("payload of another type: %d, want 500", code)
+	}
+}
@@ -2,4 +2,9 @@ module github.com/andersryanc/telnyx-go
 
 go 1.20
 
-require github.com/beevik/etree v1.2.0
+require (
+	github.com/beevik/etree v1.2.0
+	github.com/gorilla/websocket v1.5.3
+)
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,113 @@
+// Package apitest records the requests the API clients make, for the tests of the
+// packages built on telnyx.Client that check the exact requests sent. End-to-end tests
+// use the telnyxtest fake server instead.
+package apitest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+)
+
+// Request is a request received by a Recorder.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Body   map[string]interface{}
+}
+
+// Recorder is a test server that records the requests made to it and answers each
+// with the response returned by Respond.
+type Recorder struct {
+	// Respond returns the status and JSON body of the response to r. It may be
+	// replaced between requests.
+	Respond func(r Request) (int, string)
+
+	t        *testing.T
+	srv      *httptest.Server
+	mu       sync.Mutex
+	requests []Request
+}
+
+// NewRecorder starts a Recorder answering with respond. It is closed when the test
+// ends.
+func NewRecorder(t *testing.T, respond func(r Request) (int, string)) *Recorder {
+	t.Helper()
+	rec := &Recorder{Respond: respond, t: t}
+	rec.srv = httptest.NewServer(rec)
+	t.Cleanup(rec.srv.Close)
+	return rec
+}
+
+// Reply returns a Respond func that answers every request with status and body.
+func Reply(status int, body string) func(Request) (int, string) {
+	return func(Request) (int, string) { return status, body }
+}
+
+func (rec *Recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req := Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query()}
+	if data, _ := io.ReadAll(r.Body); len(data) != 0 {
+		if err := json.Unmarshal(data, &req.Body); err != nil {
+			rec.t.Errorf("%s %s: body %s is not a JSON object: %v", r.Method, r.URL.Path, data, err)
+		}
+	}
+	rec.mu.Lock()
+	rec.requests = append(rec.requests, req)
+	respond := rec.Respond
+	rec.mu.Unlock()
+
+	status, body := respond(req)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	io.WriteString(w, body)
+}
+
+// Client returns a telnyx.Client for the Recorder that retries without waiting.
+func (rec *Recorder) Client() *telnyx.Client {
+	rec.t.Helper()
+	client, err := telnyx.NewClientWithParams(telnyx.ClientParams{
+		APIKey:       "KEY123",
+		BaseURL:      rec.srv.URL,
+		RetryWaitMin: time.Millisecond,
+		RetryWaitMax: time.Millisecond,
+	})
+	if err != nil {
+		rec.t.Fatal(err)
+	}
+	return client
+}
+
+// Requests returns the requests received, in order.
+func (rec *Recorder) Requests() []Request {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]Request(nil), rec.requests...)
+}
+
+// Last returns the latest request received.
+func (rec *Recorder) Last() Request {
+	rec.t.Helper()
+	requests := rec.Requests()
+	if len(requests) == 0 {
+		rec.t.Fatal("no request was made")
+	}
+	return requests[len(requests)-1]
+}
+
+// Expect fails the test unless the latest request has method and path, and returns it.
+func (rec *Recorder) Expect(method, path string) Request {
+	rec.t.Helper()
+	r := rec.Last()
+	if r.Method != method || r.Path != path {
+		rec.t.Errorf("request = %s %s, want %s %s", r.Method, r.Path, method, path)
+	}
+	return r
+}
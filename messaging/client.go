@@ -0,0 +1,130 @@
+// Package messaging is a client for the Telnyx Messaging API, which sends SMS and MMS
+// and delivers inbound messages and delivery reports to a webhook.
+//
+// https://developers.telnyx.com/docs/messaging
+package messaging
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+)
+
+// Client sends messages through the Telnyx REST API.
+type Client struct {
+	client *telnyx.Client
+}
+
+// NewClient returns a Client that sends its requests through client.
+func NewClient(client *telnyx.Client) *Client {
+	return &Client{client: client}
+}
+
+// Message is an SMS or MMS, sent or received.
+//
+// https://developers.telnyx.com/api/messaging/send-message
+type Message struct {
+	ID         string `json:"id"`
+	RecordType string `json:"record_type"`
+	// Direction is "inbound" or "outbound".
+	Direction string `json:"direction"`
+	// Type is "SMS" or "MMS".
+	Type               string     `json:"type"`
+	MessagingProfileID string     `json:"messaging_profile_id"`
+	OrganizationID     string     `json:"organization_id"`
+	From               Endpoint   `json:"from"`
+	To                 []Endpoint `json:"to"`
+	Cc                 []Endpoint `json:"cc"`
+	Text               string     `json:"text"`
+	Subject            string     `json:"subject"`
+	Media              []Media    `json:"media"`
+	// Encoding is "GSM-7" or "UCS-2". Together with Text it decides how many parts the
+	// message is split into, see CountParts.
+	Encoding string `json:"encoding"`
+	// Parts is the number of SMS segments the message was sent or received as.
+	Parts              int                  `json:"parts"`
+	Tags               []string             `json:"tags"`
+	Cost               *Cost                `json:"cost"`
+	Errors             []telnyx.ErrorDetail `json:"errors"`
+	WebhookURL         string               `json:"webhook_url"`
+	WebhookFailoverURL string               `json:"webhook_failover_url"`
+	ReceivedAt         time.Time            `json:"received_at"`
+	SentAt             time.Time            `json:"sent_at"`
+	CompletedAt        time.Time            `json:"completed_at"`
+	ValidUntil         time.Time            `json:"valid_until"`
+}
+
+// Endpoint is the sender or a recipient of a Message.
+type Endpoint struct {
+	PhoneNumber string `json:"phone_number"`
+	Carrier     string `json:"carrier"`
+	LineType    string `json:"line_type"`
+	// Status is the delivery status of a recipient: "queued", "sending", "sent",
+	// "delivered", "sending_failed", "delivery_failed" or "delivery_unconfirmed".
+	Status string `json:"status,omitempty"`
+}
+
+// Media is a file attached to an MMS.
+type Media struct {
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Sha256      string `json:"sha256"`
+	Size        int    `json:"size"`
+}
+
+// Cost is the price of a Message.
+type Cost struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// SendParams are the fields of a message sent with Send. Setting MediaURLs sends an MMS.
+//
+// https://developers.telnyx.com/api/messaging/send-message
+type SendParams struct {
+	// From is a phone number, short code or alphanumeric sender ID. It is left empty by
+	// SendFromNumberPool.
+	From               string   `json:"from,omitempty"`
+	To                 string   `json:"to"`
+	Text               string   `json:"text,omitempty"`
+	Subject            string   `json:"subject,omitempty"`
+	MediaURLs          []string `json:"media_urls,omitempty"`
+	MessagingProfileID string   `json:"messaging_profile_id,omitempty"`
+	// Type forces "SMS" or "MMS"; it is inferred from MediaURLs otherwise.
+	Type               string `json:"type,omitempty"`
+	WebhookURL         string `json:"webhook_url,omitempty"`
+	WebhookFailoverURL string `json:"webhook_failover_url,omitempty"`
+	UseProfileWebhooks *bool  `json:"use_profile_webhooks,omitempty"`
+	AutoDetect         *bool  `json:"auto_detect,omitempty"`
+}
+
+// Send sends an SMS or MMS.
+func (c *Client) Send(ctx context.Context, params *SendParams) (*Message, error) {
+	var resp telnyx.DataResponse[Message]
+	if err := c.client.Do(ctx, http.MethodPost, "messages", params, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// SendFromNumberPool sends a message from one of the numbers of the messaging profile
+// set in params, chosen by the number pool settings of the profile.
+func (c *Client) SendFromNumberPool(ctx context.Context, params *SendParams) (*Message, error) {
+	var resp telnyx.DataResponse[Message]
+	if err := c.client.Do(ctx, http.MethodPost, "messages/number_pool", params, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// GetMessage returns a message with the current delivery status of its recipients.
+func (c *Client) GetMessage(ctx context.Context, id string) (*Message, error) {
+	var resp telnyx.DataResponse[Message]
+	if err := c.client.Do(ctx, http.MethodGet, "messages/"+url.PathEscape(id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
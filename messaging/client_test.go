@@ -0,0 +1,97 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+	"github.com/andersryanc/telnyx-go/internal/apitest"
+)
+
+func TestSend(t *testing.T) {
+	rec := apitest.NewRecorder(t, apitest.Reply(http.StatusOK, `{"data":{"id":"msg1","type":"MMS","parts":1,"to":[{"phone_number":"+13125550111","status":"queued"}]}}`))
+	c := NewClient(rec.Client())
+	ctx := context.Background()
+
+	msg, err := c.Send(ctx, &SendParams{
+		From:      "+13125550100",
+		To:        "+13125550111",
+		Text:      "Your receipt",
+		MediaURLs: []string{"https://example.com/receipt.png"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"from":       "+13125550100",
+		"to":         "+13125550111",
+		"text":       "Your receipt",
+		"media_urls": []interface{}{"https://example.com/receipt.png"},
+	}
+	if r := rec.Expect(http.MethodPost, "/messages"); !reflect.DeepEqual(r.Body, want) {
+		t.Errorf("body = %v, want %v", r.Body, want)
+	}
+	if msg.ID != "msg1" || msg.Type != "MMS" || msg.To[0].Status != "queued" {
+		t.Errorf("Send = %+v", msg)
+	}
+
+	if _, err := c.SendFromNumberPool(ctx, &SendParams{To: "+13125550111", Text: "Hi", MessagingProfileID: "p1"}); err != nil {
+		t.Fatal(err)
+	}
+	if r := rec.Last(); r.Path != "/messages/number_pool" || r.Body["from"] != nil || r.Body["messaging_profile_id"] != "p1" {
+		t.Errorf("number pool request = %s %v", r.Path, r.Body)
+	}
+
+	if _, err := c.GetMessage(ctx, "msg1"); err != nil {
+		t.Fatal(err)
+	}
+	rec.Expect(http.MethodGet, "/messages/msg1")
+
+	// Nil params are rejected without a request.
+	if _, err := c.Send(ctx, nil); !errors.Is(err, telnyx.ErrNilParams) {
+		t.Errorf("Send(nil) = %v, want telnyx.ErrNilParams", err)
+	}
+	if _, err := c.SendFromNumberPool(ctx, nil); !errors.Is(err, telnyx.ErrNilParams) {
+		t.Errorf("SendFromNumberPool(nil) = %v, want telnyx.ErrNilParams", err)
+	}
+	if n := len(rec.Requests()); n != 3 {
+		t.Errorf("%d requests were sent, want 3", n)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	rec := apitest.NewRecorder(t, apitest.Reply(http.StatusOK, `{"data":{"id":"p1","name":"alerts","enabled":true,"number_pool_settings":{"long_code_weight":1,"sticky_sender":true}}}`))
+	c := NewClient(rec.Client())
+	ctx := context.Background()
+
+	p, err := c.CreateProfile(ctx, &ProfileParams{Name: "alerts", WhitelistedDestinations: []string{"US"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.NumberPoolSettings == nil || !p.NumberPoolSettings.StickySender {
+		t.Errorf("CreateProfile = %+v", p)
+	}
+	if _, err := c.GetProfile(ctx, "p1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.UpdateProfile(ctx, "p1", &ProfileParams{Enabled: telnyx.Bool(false)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.DeleteProfile(ctx, "p1"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []apitest.Request{
+		{Method: http.MethodPost, Path: "/messaging_profiles", Query: url.Values{}, Body: map[string]interface{}{"name": "alerts", "whitelisted_destinations": []interface{}{"US"}}},
+		{Method: http.MethodGet, Path: "/messaging_profiles/p1", Query: url.Values{}},
+		{Method: http.MethodPatch, Path: "/messaging_profiles/p1", Query: url.Values{}, Body: map[string]interface{}{"enabled": false}},
+		{Method: http.MethodDelete, Path: "/messaging_profiles/p1", Query: url.Values{}},
+	}
+	if requests := rec.Requests(); !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+}
@@ -0,0 +1,86 @@
+package messaging
+
+import (
+	"context"
+	"crypto/ed25519"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+)
+
+// Event is a messaging webhook.
+//
+// https://developers.telnyx.com/docs/messaging/messages/receiving-webhooks
+type Event = telnyx.Event
+
+// EventMeta describes the delivery of an Event.
+type EventMeta = telnyx.EventMeta
+
+// Payload is implemented by the event payload types. EventType is the event_type the
+// payload is delivered with.
+type Payload = telnyx.EventPayload
+
+// MessageReceived is sent for an inbound message. Parts holds the number of segments it
+// arrived in.
+type MessageReceived struct {
+	Message
+}
+
+func (MessageReceived) EventType() string {
+	return "message.received"
+}
+
+// MessageSent is sent when an outbound message has been handed to the carrier.
+type MessageSent struct {
+	Message
+}
+
+func (MessageSent) EventType() string {
+	return "message.sent"
+}
+
+// MessageFinalized is sent when an outbound message reaches its final delivery status,
+// reported per recipient in To.
+type MessageFinalized struct {
+	Message
+}
+
+func (MessageFinalized) EventType() string {
+	return "message.finalized"
+}
+
+var payloadTypes = telnyx.EventTypes{}
+
+func init() {
+	telnyx.RegisterEventPayload[MessageReceived](payloadTypes)
+	telnyx.RegisterEventPayload[MessageSent](payloadTypes)
+	telnyx.RegisterEventPayload[MessageFinalized](payloadTypes)
+}
+
+// ParseEvent decodes the body of a webhook, with the payload types of this package.
+func ParseEvent(body []byte) (*Event, error) {
+	return telnyx.ParseEvent(body, payloadTypes)
+}
+
+// HandlerFunc handles an Event delivered to a Dispatcher.
+type HandlerFunc = telnyx.HandlerFunc
+
+// Dispatcher receives messaging webhooks and routes each event to the handler
+// registered for its event type, see telnyx.Dispatcher.
+type Dispatcher = telnyx.Dispatcher
+
+// NewDispatcher returns a Dispatcher that decodes the payload types of this package
+// and verifies the signature of every webhook with publicKey, see
+// telnyx.ParsePublicKey. A nil publicKey disables the verification.
+func NewDispatcher(publicKey ed25519.PublicKey) *Dispatcher {
+	return telnyx.NewDispatcher(publicKey, payloadTypes)
+}
+
+// On registers fn for the event type of P, e.g.
+//
+//	messaging.On(d, func(ctx context.Context, e *messaging.Event, m *messaging.MessageReceived) error {
+//		log.Printf("%s: %s", m.From.PhoneNumber, m.Text)
+//		return nil
+//	})
+func On[P Payload](d *Dispatcher, fn func(ctx context.Context, e *Event, p *P) error) {
+	telnyx.On(d, fn)
+}
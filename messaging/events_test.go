@@ -0,0 +1,52 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const receivedJSON = `{"data":{"id":"ev1","event_type":"message.received","occurred_at":"2025-05-06T12:00:00Z",
+	"payload":{"id":"msg1","direction":"inbound","type":"MMS","from":{"phone_number":"+13125550100","carrier":"T-Mobile"},
+	"to":[{"phone_number":"+13125550199","status":"webhook_delivered"}],"text":"Photo attached","parts":1,
+	"media":[{"url":"https://example.com/photo.jpg","content_type":"image/jpeg","size":2048}]}},"meta":{"attempt":1}}`
+
+func TestParseEvent(t *testing.T) {
+	e, err := ParseEvent([]byte(receivedJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, ok := e.Payload.(*MessageReceived)
+	if !ok {
+		t.Fatalf("Payload = %T, want *MessageReceived", e.Payload)
+	}
+	if m.From.PhoneNumber != "+13125550100" || m.To[0].Status != "webhook_delivered" || m.Parts != 1 || m.Media[0].Size != 2048 {
+		t.Errorf("message = %+v", m)
+	}
+
+	for eventType, want := range map[string]Payload{"message.sent": &MessageSent{}, "message.finalized": &MessageFinalized{}} {
+		e, err := ParseEvent([]byte(`{"data":{"event_type":"` + eventType + `","payload":{"id":"msg2","to":[{"status":"delivered"}]}}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p, ok := e.Payload.(Payload); !ok || p.EventType() != want.EventType() {
+			t.Errorf("%s: Payload = %T", eventType, e.Payload)
+		}
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	d := NewDispatcher(nil)
+	var texts []string
+	On(d, func(ctx context.Context, e *Event, m *MessageReceived) error {
+		texts = append(texts, m.Text)
+		return nil
+	})
+	w := httptest.NewRecorder()
+	d.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhooks/messaging", bytes.NewReader([]byte(receivedJSON))))
+	if w.Code != http.StatusOK || len(texts) != 1 || texts[0] != "Photo attached" {
+		t.Errorf("%d, texts = %v", w.Code, texts)
+	}
+}
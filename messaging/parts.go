@@ -0,0 +1,44 @@
+package messaging
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+		"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+	// gsm7Extension characters take two septets: an escape and the character.
+	gsm7Extension = "\f^{}\\[~]|€"
+)
+
+// CountParts returns the number of SMS segments text is sent as, and the encoding
+// Telnyx picks for it: "GSM-7" when every character is in the GSM 03.38 alphabet and
+// "UCS-2" otherwise. A single segment holds 160 GSM-7 or 70 UCS-2 characters; longer
+// messages are split into segments of 153 or 67 characters.
+func CountParts(text string) (parts int, encoding string) {
+	if text == "" {
+		return 0, "GSM-7"
+	}
+
+	septets := 0
+	for _, r := range text {
+		switch {
+		case strings.ContainsRune(gsm7Basic, r):
+			septets++
+		case strings.ContainsRune(gsm7Extension, r):
+			septets += 2
+		default:
+			units := len(utf16.Encode([]rune(text)))
+			return split(units, 70, 67), "UCS-2"
+		}
+	}
+	return split(septets, 160, 153), "GSM-7"
+}
+
+func split(n, single, multi int) int {
+	if n <= single {
+		return 1
+	}
+	return (n + multi - 1) / multi
+}
@@ -0,0 +1,38 @@
+package messaging
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCountParts(t *testing.T) {
+	tests := []struct {
+		text     string
+		parts    int
+		encoding string
+	}{
+		{"", 0, "GSM-7"},
+		{"Thanks for calling!", 1, "GSM-7"},
+		{strings.Repeat("a", 160), 1, "GSM-7"},
+		{strings.Repeat("a", 161), 2, "GSM-7"},
+		{strings.Repeat("a", 306), 2, "GSM-7"},
+		{strings.Repeat("a", 307), 3, "GSM-7"},
+		// Extension characters take two septets.
+		{strings.Repeat("€", 80), 1, "GSM-7"},
+		{strings.Repeat("€", 81), 2, "GSM-7"},
+		{"Café à Zürich", 1, "GSM-7"},
+		{strings.Repeat("é", 69) + "ç", 1, "UCS-2"},
+		{strings.Repeat("ж", 70), 1, "UCS-2"},
+		{strings.Repeat("ж", 71), 2, "UCS-2"},
+		{strings.Repeat("ж", 134), 2, "UCS-2"},
+		// An emoji outside the BMP takes two UTF-16 code units.
+		{strings.Repeat("😀", 35), 1, "UCS-2"},
+		{strings.Repeat("😀", 36), 2, "UCS-2"},
+	}
+	for _, tt := range tests {
+		parts, encoding := CountParts(tt.text)
+		if parts != tt.parts || encoding != tt.encoding {
+			t.Errorf("CountParts(%.20q... %d runes) = %d, %s; want %d, %s", tt.text, len([]rune(tt.text)), parts, encoding, tt.parts, tt.encoding)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package messaging
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+)
+
+// Profile is a messaging profile. Phone numbers assigned to a profile send and receive
+// messages with its settings, and deliver their webhooks to WebhookURL.
+//
+// https://developers.telnyx.com/api/messaging/create-messaging-profile
+type Profile struct {
+	ID                      string              `json:"id"`
+	RecordType              string              `json:"record_type"`
+	Name                    string              `json:"name"`
+	Enabled                 bool                `json:"enabled"`
+	WebhookURL              string              `json:"webhook_url"`
+	WebhookFailoverURL      string              `json:"webhook_failover_url"`
+	WebhookAPIVersion       string              `json:"webhook_api_version"`
+	WhitelistedDestinations []string            `json:"whitelisted_destinations"`
+	NumberPoolSettings      *NumberPoolSettings `json:"number_pool_settings"`
+	CreatedAt               time.Time           `json:"created_at"`
+	UpdatedAt               time.Time           `json:"updated_at"`
+}
+
+// NumberPoolSettings configure how SendFromNumberPool picks the sending number. The
+// weights are relative to each other.
+type NumberPoolSettings struct {
+	LongCodeWeight float64 `json:"long_code_weight"`
+	TollFreeWeight float64 `json:"toll_free_weight"`
+	SkipUnhealthy  bool    `json:"skip_unhealthy"`
+	StickySender   bool    `json:"sticky_sender"`
+	Geomatch       bool    `json:"geomatch"`
+}
+
+// ProfileParams are the fields sent when creating or updating a Profile. Nil and empty
+// fields are left out of the request. Name and WhitelistedDestinations are required on
+// create.
+type ProfileParams struct {
+	Name                    string              `json:"name,omitempty"`
+	Enabled                 *bool               `json:"enabled,omitempty"`
+	WebhookURL              string              `json:"webhook_url,omitempty"`
+	WebhookFailoverURL      string              `json:"webhook_failover_url,omitempty"`
+	WebhookAPIVersion       string              `json:"webhook_api_version,omitempty"`
+	WhitelistedDestinations []string            `json:"whitelisted_destinations,omitempty"`
+	NumberPoolSettings      *NumberPoolSettings `json:"number_pool_settings,omitempty"`
+}
+
+func (c *Client) CreateProfile(ctx context.Context, params *ProfileParams) (*Profile, error) {
+	var resp telnyx.DataResponse[Profile]
+	if err := c.client.Do(ctx, http.MethodPost, "messaging_profiles", params, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+func (c *Client) GetProfile(ctx context.Context, id string) (*Profile, error) {
+	var resp telnyx.DataResponse[Profile]
+	if err := c.client.Do(ctx, http.MethodGet, "messaging_profiles/"+url.PathEscape(id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+func (c *Client) ListProfiles(ctx context.Context, pageSize int) *telnyx.Iter[Profile] {
+	return telnyx.List[Profile](ctx, c.client, "messaging_profiles", telnyx.PageQuery(pageSize))
+}
+
+func (c *Client) UpdateProfile(ctx context.Context, id string, params *ProfileParams) (*Profile, error) {
+	var resp telnyx.DataResponse[Profile]
+	if err := c.client.Do(ctx, http.MethodPatch, "messaging_profiles/"+url.PathEscape(id), params, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// DeleteProfile deletes a profile and returns it as it was before deletion.
+func (c *Client) DeleteProfile(ctx context.Context, id string) (*Profile, error) {
+	var resp telnyx.DataResponse[Profile]
+	if err := c.client.Do(ctx, http.MethodDelete, "messaging_profiles/"+url.PathEscape(id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
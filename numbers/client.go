@@ -0,0 +1,17 @@
+// Package numbers is a client for searching, ordering and configuring Telnyx phone
+// numbers.
+//
+// https://developers.telnyx.com/docs/numbers
+package numbers
+
+import telnyx "github.com/andersryanc/telnyx-go"
+
+// Client manages phone numbers through the Telnyx REST API.
+type Client struct {
+	client *telnyx.Client
+}
+
+// NewClient returns a Client that sends its requests through client.
+func NewClient(client *telnyx.Client) *Client {
+	return &Client{client: client}
+}
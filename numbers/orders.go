@@ -0,0 +1,122 @@
+package numbers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+)
+
+// OrderStatus is the state of a number order.
+type OrderStatus string
+
+const (
+	OrderStatusPending OrderStatus = "pending"
+	OrderStatusSuccess OrderStatus = "success"
+	OrderStatusFailure OrderStatus = "failure"
+)
+
+// Order is an order for one or more phone numbers.
+//
+// https://developers.telnyx.com/api/numbers/create-number-order
+type Order struct {
+	ID                 string        `json:"id"`
+	RecordType         string        `json:"record_type"`
+	Status             OrderStatus   `json:"status"`
+	PhoneNumbersCount  int           `json:"phone_numbers_count"`
+	PhoneNumbers       []OrderNumber `json:"phone_numbers"`
+	ConnectionID       string        `json:"connection_id"`
+	MessagingProfileID string        `json:"messaging_profile_id"`
+	BillingGroupID     string        `json:"billing_group_id"`
+	CustomerReference  string        `json:"customer_reference"`
+	// RequirementsMet is false while regulatory requirements of some numbers are
+	// missing; such orders stay pending until they are fulfilled.
+	RequirementsMet bool      `json:"requirements_met"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// OrderNumber is a phone number of an Order.
+type OrderNumber struct {
+	ID              string `json:"id"`
+	PhoneNumber     string `json:"phone_number"`
+	Status          string `json:"status"`
+	RequirementsMet bool   `json:"requirements_met"`
+}
+
+// OrderParams are the fields of a number order. ConnectionID can be the ID of a TeXML
+// application, which then answers calls to the numbers.
+type OrderParams struct {
+	PhoneNumbers       []string `json:"-"`
+	ConnectionID       string   `json:"connection_id,omitempty"`
+	MessagingProfileID string   `json:"messaging_profile_id,omitempty"`
+	BillingGroupID     string   `json:"billing_group_id,omitempty"`
+	CustomerReference  string   `json:"customer_reference,omitempty"`
+}
+
+func (p *OrderParams) body() (interface{}, error) {
+	if p == nil {
+		// Do rejects the nil params.
+		return p, nil
+	}
+	type params OrderParams
+	type number struct {
+		PhoneNumber string `json:"phone_number"`
+	}
+	numbers := make([]number, len(p.PhoneNumbers))
+	for i, n := range p.PhoneNumbers {
+		numbers[i] = number{n}
+	}
+	return struct {
+		*params
+		PhoneNumbers []number `json:"phone_numbers"`
+	}{(*params)(p), numbers}, nil
+}
+
+// CreateOrder orders the phone numbers in params, as returned by SearchNumbers. Orders
+// are fulfilled asynchronously; see WaitForOrder.
+func (c *Client) CreateOrder(ctx context.Context, params *OrderParams) (*Order, error) {
+	body, err := params.body()
+	if err != nil {
+		return nil, err
+	}
+	var resp telnyx.DataResponse[Order]
+	if err := c.client.Do(ctx, http.MethodPost, "number_orders", body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+func (c *Client) GetOrder(ctx context.Context, id string) (*Order, error) {
+	var resp telnyx.DataResponse[Order]
+	if err := c.client.Do(ctx, http.MethodGet, "number_orders/"+url.PathEscape(id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// WaitForOrder polls an order every interval until it is no longer pending, and
+// returns it. A zero interval polls every 5 seconds. It gives up when ctx is done.
+func (c *Client) WaitForOrder(ctx context.Context, id string, interval time.Duration) (*Order, error) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		order, err := c.GetOrder(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if order.Status != OrderStatusPending {
+			return order, nil
+		}
+		select {
+		case <-ctx.Done():
+			return order, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package numbers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+	"github.com/andersryanc/telnyx-go/internal/apitest"
+)
+
+func TestCreateOrder(t *testing.T) {
+	rec := apitest.NewRecorder(t, apitest.Reply(http.StatusOK, `{"data":{"id":"o1","status":"pending","phone_numbers_count":2,"requirements_met":true,
+		"phone_numbers":[{"phone_number":"+13125550100","status":"pending"},{"phone_number":"+13125550101","status":"pending"}]}}`))
+	c := NewClient(rec.Client())
+
+	order, err := c.CreateOrder(context.Background(), &OrderParams{
+		PhoneNumbers: []string{"+13125550100", "+13125550101"},
+		ConnectionID: "app1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"connection_id": "app1",
+		"phone_numbers": []interface{}{
+			map[string]interface{}{"phone_number": "+13125550100"},
+			map[string]interface{}{"phone_number": "+13125550101"},
+		},
+	}
+	if r := rec.Expect(http.MethodPost, "/number_orders"); !reflect.DeepEqual(r.Body, want) {
+		t.Errorf("body = %v, want %v", r.Body, want)
+	}
+	if order.Status != OrderStatusPending || len(order.PhoneNumbers) != 2 {
+		t.Errorf("CreateOrder = %+v", order)
+	}
+
+	if _, err := c.CreateOrder(context.Background(), nil); !errors.Is(err, telnyx.ErrNilParams) {
+		t.Errorf("CreateOrder(nil) error = %v", err)
+	}
+	if len(rec.Requests()) != 1 {
+		t.Error("CreateOrder(nil) sent a request")
+	}
+}
+
+func TestWaitForOrder(t *testing.T) {
+	polls := 0
+	rec := apitest.NewRecorder(t, func(apitest.Request) (int, string) {
+		polls++
+		if polls < 3 {
+			return http.StatusOK, `{"data":{"id":"o1","status":"pending"}}`
+		}
+		return http.StatusOK, `{"data":{"id":"o1","status":"success"}}`
+	})
+	c := NewClient(rec.Client())
+
+	order, err := c.WaitForOrder(context.Background(), "o1", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != OrderStatusSuccess || polls != 3 {
+		t.Errorf("WaitForOrder = %+v after %d polls", order, polls)
+	}
+	rec.Expect(http.MethodGet, "/number_orders/o1")
+}
+
+func TestWaitForOrderCanceled(t *testing.T) {
+	rec := apitest.NewRecorder(t, apitest.Reply(http.StatusOK, `{"data":{"id":"o1","status":"pending"}}`))
+	c := NewClient(rec.Client())
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.WaitForOrder(ctx, "o1", time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForOrder error = %v, want the context error", err)
+	}
+}
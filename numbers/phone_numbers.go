@@ -0,0 +1,125 @@
+package numbers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+)
+
+// PhoneNumber is a phone number owned by the account.
+//
+// https://developers.telnyx.com/api/numbers/list-phone-numbers
+type PhoneNumber struct {
+	ID              string `json:"id"`
+	RecordType      string `json:"record_type"`
+	PhoneNumber     string `json:"phone_number"`
+	PhoneNumberType string `json:"phone_number_type"`
+	// Status is e.g. "active", "purchase-pending" or "port-pending".
+	Status               string    `json:"status"`
+	Tags                 []string  `json:"tags"`
+	ConnectionID         string    `json:"connection_id"`
+	ConnectionName       string    `json:"connection_name"`
+	MessagingProfileID   string    `json:"messaging_profile_id"`
+	MessagingProfileName string    `json:"messaging_profile_name"`
+	BillingGroupID       string    `json:"billing_group_id"`
+	CustomerReference    string    `json:"customer_reference"`
+	EmergencyEnabled     bool      `json:"emergency_enabled"`
+	PurchasedAt          time.Time `json:"purchased_at"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// ListPhoneNumbersParams filter the numbers returned by ListPhoneNumbers.
+type ListPhoneNumbersParams struct {
+	PhoneNumber  string
+	Status       string
+	Tag          string
+	ConnectionID string
+	PageSize     int
+}
+
+func (p *ListPhoneNumbersParams) query() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := telnyx.PageQuery(p.PageSize)
+	for k, v := range map[string]string{
+		"filter[phone_number]":  p.PhoneNumber,
+		"filter[status]":        p.Status,
+		"filter[tag]":           p.Tag,
+		"filter[connection_id]": p.ConnectionID,
+	} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	return q
+}
+
+// UpdatePhoneNumberParams change a phone number. Nil and empty fields are left
+// unchanged.
+type UpdatePhoneNumberParams struct {
+	ConnectionID      string   `json:"connection_id,omitempty"`
+	BillingGroupID    string   `json:"billing_group_id,omitempty"`
+	CustomerReference string   `json:"customer_reference,omitempty"`
+	Tags              []string `json:"tags,omitempty"`
+}
+
+func (c *Client) ListPhoneNumbers(ctx context.Context, params *ListPhoneNumbersParams) *telnyx.Iter[PhoneNumber] {
+	return telnyx.List[PhoneNumber](ctx, c.client, "phone_numbers", params.query())
+}
+
+// GetPhoneNumber returns an owned number by ID or in +E.164 format.
+func (c *Client) GetPhoneNumber(ctx context.Context, id string) (*PhoneNumber, error) {
+	var resp telnyx.DataResponse[PhoneNumber]
+	if err := c.client.Do(ctx, http.MethodGet, "phone_numbers/"+url.PathEscape(id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+func (c *Client) UpdatePhoneNumber(ctx context.Context, id string, params *UpdatePhoneNumberParams) (*PhoneNumber, error) {
+	var resp telnyx.DataResponse[PhoneNumber]
+	if err := c.client.Do(ctx, http.MethodPatch, "phone_numbers/"+url.PathEscape(id), params, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// AssignConnection routes the calls to a number to a connection, which can be a SIP
+// connection, a Call Control application or a TeXML application.
+func (c *Client) AssignConnection(ctx context.Context, id, connectionID string) (*PhoneNumber, error) {
+	return c.UpdatePhoneNumber(ctx, id, &UpdatePhoneNumberParams{ConnectionID: connectionID})
+}
+
+// AssignTeXMLApplication makes a TeXML application answer the calls to a number.
+func (c *Client) AssignTeXMLApplication(ctx context.Context, id, applicationID string) (*PhoneNumber, error) {
+	return c.AssignConnection(ctx, id, applicationID)
+}
+
+// AssignMessagingProfile makes a number send and receive messages with a messaging
+// profile. An empty profileID unassigns the current profile.
+func (c *Client) AssignMessagingProfile(ctx context.Context, id, profileID string) (*PhoneNumber, error) {
+	params := struct {
+		MessagingProfileID string `json:"messaging_profile_id"`
+	}{profileID}
+
+	var resp telnyx.DataResponse[PhoneNumber]
+	if err := c.client.Do(ctx, http.MethodPatch, "phone_numbers/"+url.PathEscape(id)+"/messaging", params, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// ReleasePhoneNumber deletes a number from the account and returns it as it was
+// before deletion.
+func (c *Client) ReleasePhoneNumber(ctx context.Context, id string) (*PhoneNumber, error) {
+	var resp telnyx.DataResponse[PhoneNumber]
+	if err := c.client.Do(ctx, http.MethodDelete, "phone_numbers/"+url.PathEscape(id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
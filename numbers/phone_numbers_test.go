@@ -0,0 +1,62 @@
+package numbers
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/andersryanc/telnyx-go/internal/apitest"
+)
+
+func TestPhoneNumbers(t *testing.T) {
+	rec := apitest.NewRecorder(t, func(r apitest.Request) (int, string) {
+		if r.Method == http.MethodGet && r.Path == "/phone_numbers" {
+			return http.StatusOK, `{"data":[{"id":"n1","phone_number":"+13125550100","status":"active"}],"meta":{"page_number":1,"total_pages":1}}`
+		}
+		return http.StatusOK, `{"data":{"id":"n1","phone_number":"+13125550100","connection_id":"app1","messaging_profile_id":"p1"}}`
+	})
+	c := NewClient(rec.Client())
+	ctx := context.Background()
+
+	owned, err := c.ListPhoneNumbers(ctx, &ListPhoneNumbersParams{Status: "active", Tag: "ivr"}).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(owned) != 1 || owned[0].ID != "n1" {
+		t.Errorf("ListPhoneNumbers = %+v", owned)
+	}
+	if q := rec.Expect(http.MethodGet, "/phone_numbers").Query; q.Get("filter[status]") != "active" || q.Get("filter[tag]") != "ivr" {
+		t.Errorf("query = %s", q.Encode())
+	}
+
+	if _, err := c.GetPhoneNumber(ctx, "+13125550100"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.AssignTeXMLApplication(ctx, "n1", "app1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.AssignMessagingProfile(ctx, "n1", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ReleasePhoneNumber(ctx, "n1"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		method, path string
+		body         map[string]interface{}
+	}{
+		{http.MethodGet, "/phone_numbers/+13125550100", nil},
+		{http.MethodPatch, "/phone_numbers/n1", map[string]interface{}{"connection_id": "app1"}},
+		// An empty profile ID is sent to unassign the profile.
+		{http.MethodPatch, "/phone_numbers/n1/messaging", map[string]interface{}{"messaging_profile_id": ""}},
+		{http.MethodDelete, "/phone_numbers/n1", nil},
+	}
+	for i, w := range want {
+		r := rec.Requests()[i+1]
+		if r.Method != w.method || r.Path != w.path || !reflect.DeepEqual(r.Body, w.body) {
+			t.Errorf("request %d = %s %s %v, want %s %s %v", i+1, r.Method, r.Path, r.Body, w.method, w.path, w.body)
+		}
+	}
+}
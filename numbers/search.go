@@ -0,0 +1,147 @@
+package numbers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+)
+
+// Feature is a capability of a phone number.
+type Feature string
+
+const (
+	FeatureVoice     Feature = "voice"
+	FeatureSMS       Feature = "sms"
+	FeatureMMS       Feature = "mms"
+	FeatureFax       Feature = "fax"
+	FeatureEmergency Feature = "emergency"
+)
+
+// AvailableNumber is a phone number that can be ordered.
+//
+// https://developers.telnyx.com/api/numbers/list-available-phone-numbers
+type AvailableNumber struct {
+	RecordType        string          `json:"record_type"`
+	PhoneNumber       string          `json:"phone_number"`
+	VanityFormat      string          `json:"vanity_format"`
+	BestEffort        bool            `json:"best_effort"`
+	Quickship         bool            `json:"quickship"`
+	Reservable        bool            `json:"reservable"`
+	RegionInformation []Region        `json:"region_information"`
+	CostInformation   CostInformation `json:"cost_information"`
+	Features          []NumberFeature `json:"features"`
+}
+
+// Region locates an AvailableNumber. RegionType is e.g. "country_code", "state",
+// "location" or "rate_center".
+type Region struct {
+	RegionType string `json:"region_type"`
+	RegionName string `json:"region_name"`
+}
+
+// CostInformation is the price of an AvailableNumber.
+type CostInformation struct {
+	UpfrontCost string `json:"upfront_cost"`
+	MonthlyCost string `json:"monthly_cost"`
+	Currency    string `json:"currency"`
+}
+
+// NumberFeature names a Feature of an AvailableNumber.
+type NumberFeature struct {
+	Name Feature `json:"name"`
+}
+
+// HasFeature reports whether n supports f.
+func (n *AvailableNumber) HasFeature(f Feature) bool {
+	for _, feature := range n.Features {
+		if feature.Name == f {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchParams filter the numbers returned by SearchNumbers. Only one of Contains,
+// StartsWith and EndsWith is applied, in that order.
+type SearchParams struct {
+	// CountryCode is an ISO 3166-1 alpha-2 code such as "US".
+	CountryCode string
+	// AdministrativeArea is a state or province, e.g. "IL".
+	AdministrativeArea string
+	Locality           string
+	RateCenter         string
+	// NationalDestinationCode is the area code.
+	NationalDestinationCode string
+	// NumberType is "local", "toll_free", "mobile", "national" or "shared_cost".
+	NumberType string
+	Features   []Feature
+	Contains   string
+	StartsWith string
+	EndsWith   string
+	Limit      int
+	// BestEffort also returns numbers near the requested region when there are not
+	// enough exact matches.
+	BestEffort bool
+	Quickship  bool
+	Reservable bool
+}
+
+func (p *SearchParams) query() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	for k, v := range map[string]string{
+		"filter[country_code]":              p.CountryCode,
+		"filter[administrative_area]":       p.AdministrativeArea,
+		"filter[locality]":                  p.Locality,
+		"filter[rate_center]":               p.RateCenter,
+		"filter[national_destination_code]": p.NationalDestinationCode,
+		"filter[phone_number_type]":         p.NumberType,
+	} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	for _, f := range p.Features {
+		q.Add("filter[features][]", string(f))
+	}
+	switch {
+	case p.Contains != "":
+		q.Set("filter[phone_number][contains]", p.Contains)
+	case p.StartsWith != "":
+		q.Set("filter[phone_number][starts_with]", p.StartsWith)
+	case p.EndsWith != "":
+		q.Set("filter[phone_number][ends_with]", p.EndsWith)
+	}
+	if p.Limit > 0 {
+		q.Set("filter[limit]", strconv.Itoa(p.Limit))
+	}
+	for k, v := range map[string]bool{
+		"filter[best_effort]": p.BestEffort,
+		"filter[quickship]":   p.Quickship,
+		"filter[reservable]":  p.Reservable,
+	} {
+		if v {
+			q.Set(k, "true")
+		}
+	}
+	return q
+}
+
+// SearchNumbers returns the phone numbers available for ordering that match params.
+// The results are not paginated; use Limit to get more of them.
+func (c *Client) SearchNumbers(ctx context.Context, params *SearchParams) ([]AvailableNumber, error) {
+	path := "available_phone_numbers"
+	if q := params.query(); len(q) != 0 {
+		path += "?" + q.Encode()
+	}
+	var resp telnyx.DataResponse[[]AvailableNumber]
+	if err := c.client.Do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
@@ -0,0 +1,54 @@
+package numbers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/andersryanc/telnyx-go/internal/apitest"
+)
+
+func TestSearchNumbers(t *testing.T) {
+	rec := apitest.NewRecorder(t, apitest.Reply(http.StatusOK, `{"data":[{"phone_number":"+13125550100","features":[{"name":"voice"},{"name":"sms"}],
+		"cost_information":{"monthly_cost":"1.00","currency":"USD"},"region_information":[{"region_type":"state","region_name":"IL"}]}]}`))
+	c := NewClient(rec.Client())
+
+	found, err := c.SearchNumbers(context.Background(), &SearchParams{
+		CountryCode:             "US",
+		NationalDestinationCode: "312",
+		Features:                []Feature{FeatureVoice, FeatureSMS},
+		Contains:                "555",
+		EndsWith:                "00",
+		Limit:                   5,
+		BestEffort:              true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rec.Last()
+	want := url.Values{
+		"filter[country_code]":              {"US"},
+		"filter[national_destination_code]": {"312"},
+		"filter[features][]":                {"voice", "sms"},
+		"filter[phone_number][contains]":    {"555"},
+		"filter[limit]":                     {"5"},
+		"filter[best_effort]":               {"true"},
+	}
+	if r.Path != "/available_phone_numbers" || r.Query.Encode() != want.Encode() {
+		t.Errorf("request = %s?%s, want the query %s", r.Path, r.Query.Encode(), want.Encode())
+	}
+	if len(found) != 1 || found[0].CostInformation.MonthlyCost != "1.00" || found[0].RegionInformation[0].RegionName != "IL" {
+		t.Errorf("SearchNumbers = %+v", found)
+	}
+	if !found[0].HasFeature(FeatureSMS) || found[0].HasFeature(FeatureMMS) {
+		t.Errorf("features = %v", found[0].Features)
+	}
+
+	if _, err := c.SearchNumbers(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if r := rec.Last(); len(r.Query) != 0 {
+		t.Errorf("search without params sent %s", r.Query.Encode())
+	}
+}
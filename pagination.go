@@ -0,0 +1,149 @@
+package telnyx
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// PageFunc fetches the page identified by cursor, which is empty for the first page,
+// and returns its items with the cursor of the following page, or an empty cursor when
+// there are no more pages.
+type PageFunc[T any] func(ctx context.Context, cursor string) (items []T, next string, err error)
+
+// Iter iterates over the items of a paginated list, fetching pages as needed:
+//
+//	it := telnyx.List[Thing](ctx, client, "things", nil)
+//	for it.Next() {
+//		fmt.Println(it.Current())
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iter[T any] struct {
+	ctx     context.Context
+	fetch   PageFunc[T]
+	cursor  string
+	items   []T
+	current T
+	started bool
+	done    bool
+	err     error
+}
+
+// NewIter returns an Iter that fetches pages with fetch.
+func NewIter[T any](ctx context.Context, fetch PageFunc[T]) *Iter[T] {
+	return &Iter[T]{ctx: ctx, fetch: fetch}
+}
+
+// Next advances to the next item and reports whether there is one.
+func (it *Iter[T]) Next() bool {
+	for len(it.items) == 0 {
+		if it.err != nil || (it.started && it.cursor == "") {
+			return false
+		}
+		it.started = true
+		it.items, it.cursor, it.err = it.fetch(it.ctx, it.cursor)
+		if it.err != nil {
+			it.items = nil
+			return false
+		}
+	}
+	it.current, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// Current returns the item Next advanced to.
+func (it *Iter[T]) Current() T {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iter[T]) Err() error {
+	return it.err
+}
+
+// All collects the remaining items.
+func (it *Iter[T]) All() ([]T, error) {
+	var items []T
+	for it.Next() {
+		items = append(items, it.Current())
+	}
+	return items, it.Err()
+}
+
+// PageMeta is the pagination metadata of a list response. Page-number paginated
+// endpoints fill in the page fields, cursor paginated endpoints fill in Cursors.
+type PageMeta struct {
+	PageNumber   int          `json:"page_number"`
+	PageSize     int          `json:"page_size"`
+	TotalPages   int          `json:"total_pages"`
+	TotalResults int          `json:"total_results"`
+	Cursors      *PageCursors `json:"cursors,omitempty"`
+}
+
+// PageCursors holds the cursors of the neighbouring pages.
+type PageCursors struct {
+	After  string `json:"after"`
+	Before string `json:"before"`
+}
+
+// ListResponse is the envelope of a v2 list response.
+type ListResponse[T any] struct {
+	Data []T      `json:"data"`
+	Meta PageMeta `json:"meta"`
+}
+
+// List iterates over a v2 list endpoint at path, with query holding any filters and
+// page[size]. It follows page[after] cursors when the response has them and page[number]
+// otherwise.
+func List[T any](ctx context.Context, c *Client, path string, query url.Values) *Iter[T] {
+	return NewIter(ctx, func(ctx context.Context, cursor string) ([]T, string, error) {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		if cursor != "" {
+			page, err := url.ParseQuery(cursor)
+			if err != nil {
+				return nil, "", err
+			}
+			for k, v := range page {
+				q[k] = v
+			}
+		}
+
+		p := path
+		if len(q) != 0 {
+			p += "?" + q.Encode()
+		}
+		var resp ListResponse[T]
+		if err := c.Do(ctx, http.MethodGet, p, nil, &resp); err != nil {
+			return nil, "", err
+		}
+		return resp.Data, resp.Meta.next(len(resp.Data)), nil
+	})
+}
+
+func (m PageMeta) next(count int) string {
+	if m.Cursors != nil {
+		if m.Cursors.After == "" || count == 0 {
+			return ""
+		}
+		return url.Values{"page[after]": {m.Cursors.After}}.Encode()
+	}
+	if m.PageNumber == 0 || m.PageNumber >= m.TotalPages {
+		return ""
+	}
+	return url.Values{"page[number]": {strconv.Itoa(m.PageNumber + 1)}}.Encode()
+}
+
+// PageQuery returns query parameters selecting the page size. They are empty when size
+// is 0, leaving the page size to the API.
+func PageQuery(size int) url.Values {
+	if size <= 0 {
+		return url.Values{}
+	}
+	return url.Values{"page[size]": {strconv.Itoa(size)}}
+}
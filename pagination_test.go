@@ -0,0 +1,95 @@
+package telnyx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type item struct {
+	ID string `json:"id"`
+}
+
+func TestListPageNumbers(t *testing.T) {
+	pages := map[string]string{
+		"":  `{"data":[{"id":"1"},{"id":"2"}],"meta":{"page_number":1,"page_size":2,"total_pages":2,"total_results":3}}`,
+		"2": `{"data":[{"id":"3"}],"meta":{"page_number":2,"page_size":2,"total_pages":2,"total_results":3}}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("filter[status]") != "active" || r.URL.Query().Get("page[size]") != "2" {
+			t.Errorf("query = %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(pages[r.URL.Query().Get("page[number]")]))
+	}))
+	defer srv.Close()
+
+	query := PageQuery(2)
+	query.Set("filter[status]", "active")
+	items, err := List[item](context.Background(), newTestClient(t, srv), "things", query).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []item{{"1"}, {"2"}, {"3"}}; !reflect.DeepEqual(items, want) {
+		t.Errorf("All() = %v, want %v", items, want)
+	}
+}
+
+func TestListCursors(t *testing.T) {
+	pages := map[string]string{
+		"":   `{"data":[{"id":"1"}],"meta":{"cursors":{"after":"c1"}}}`,
+		"c1": `{"data":[{"id":"2"}],"meta":{"cursors":{"after":"c2","before":"c1"}}}`,
+		"c2": `{"data":[],"meta":{"cursors":{"after":"c3"}}}`,
+	}
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(pages[r.URL.Query().Get("page[after]")]))
+	}))
+	defer srv.Close()
+
+	it := List[item](context.Background(), newTestClient(t, srv), "things", nil)
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Current().ID)
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+	if want := []string{"1", "2"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ids = %v, want %v", ids, want)
+	}
+	if requests != 3 {
+		t.Errorf("%d requests, want 3: an empty page ends the list", requests)
+	}
+}
+
+func TestIterError(t *testing.T) {
+	boom := errors.New("boom")
+	calls := 0
+	it := NewIter(context.Background(), func(ctx context.Context, cursor string) ([]int, string, error) {
+		calls++
+		if cursor == "" {
+			return []int{1}, "next", nil
+		}
+		return nil, "", boom
+	})
+	items, err := it.All()
+	if !errors.Is(err, boom) || !reflect.DeepEqual(items, []int{1}) {
+		t.Errorf("All() = %v, %v", items, err)
+	}
+	if it.Next() || calls != 2 {
+		t.Errorf("Next after an error fetched again: %d calls", calls)
+	}
+}
+
+func TestPageQuery(t *testing.T) {
+	if q := PageQuery(0); len(q) != 0 {
+		t.Errorf("PageQuery(0) = %v", q)
+	}
+	if q := PageQuery(25).Encode(); q != "page%5Bsize%5D=25" {
+		t.Errorf("PageQuery(25) = %s", q)
+	}
+}
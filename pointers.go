@@ -0,0 +1,16 @@
+package telnyx
+
+// Bool returns a pointer to v, for optional fields of request parameters.
+func Bool(v bool) *bool {
+	return &v
+}
+
+// Int returns a pointer to v, for optional fields of request parameters.
+func Int(v int) *int {
+	return &v
+}
+
+// String returns a pointer to v, for optional fields of request parameters.
+func String(v string) *string {
+	return &v
+}
@@ -0,0 +1,132 @@
+package telnyxtest
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/andersryanc/telnyx-go/texml"
+)
+
+func (s *Server) createApplication(w http.ResponseWriter, r *http.Request, args []string) {
+	var params texml.ApplicationParams
+	if !decode(w, r, &params) {
+		return
+	}
+	switch {
+	case params.FriendlyName == "":
+		invalid(w, "friendly_name", "friendly_name is required.")
+		return
+	case params.VoiceUrl == "":
+		invalid(w, "voice_url", "voice_url is required.")
+		return
+	}
+	app := &texml.Application{
+		ID:          newID(),
+		RecordType:  "texml_application",
+		Active:      true,
+		VoiceMethod: "post",
+		CreatedAt:   now(),
+	}
+	applyApplicationParams(app, &params)
+
+	s.mu.Lock()
+	s.applications = append(s.applications, app)
+	resp := *app
+	s.mu.Unlock()
+	writeData(w, http.StatusCreated, resp)
+}
+
+func applyApplicationParams(app *texml.Application, p *texml.ApplicationParams) {
+	set := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+		}
+	}
+	set(&app.FriendlyName, p.FriendlyName)
+	set(&app.AnchorsiteOverride, p.AnchorsiteOverride)
+	set(&app.DtmfType, p.DtmfType)
+	set(&app.VoiceUrl, p.VoiceUrl)
+	set(&app.VoiceFallbackUrl, p.VoiceFallbackUrl)
+	set(&app.VoiceMethod, p.VoiceMethod)
+	set(&app.StatusCallback, p.StatusCallback)
+	set(&app.StatusCallbackMethod, p.StatusCallbackMethod)
+	if p.Active != nil {
+		app.Active = *p.Active
+	}
+	if p.FirstCommandTimeout != nil {
+		app.FirstCommandTimeout = *p.FirstCommandTimeout
+	}
+	if p.FirstCommandTimeoutSecs != nil {
+		app.FirstCommandTimeoutSecs = *p.FirstCommandTimeoutSecs
+	}
+	if p.Inbound != nil {
+		app.Inbound = *p.Inbound
+	}
+	if p.Outbound != nil {
+		app.Outbound = *p.Outbound
+	}
+	if p.Tags != nil {
+		app.Tags = p.Tags
+	}
+	app.UpdatedAt = now()
+}
+
+func (s *Server) listApplications(w http.ResponseWriter, r *http.Request, args []string) {
+	q := r.URL.Query()
+	s.mu.Lock()
+	var apps []texml.Application
+	for _, app := range s.applications {
+		if name := q.Get("filter[friendly_name]"); name != "" && !strings.Contains(app.FriendlyName, name) {
+			continue
+		}
+		if id := q.Get("filter[outbound_voice_profile_id]"); id != "" && app.Outbound.OutboundVoiceProfileID != id {
+			continue
+		}
+		apps = append(apps, *app)
+	}
+	s.mu.Unlock()
+	writeList(w, r, apps)
+}
+
+func (s *Server) findApplicationLocked(id string) *texml.Application {
+	return find(s.applications, func(app *texml.Application) bool { return app.ID == id })
+}
+
+func (s *Server) getApplication(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	app := s.findApplicationLocked(args[0])
+	if app == nil {
+		notFound(w, "TeXML application", args[0])
+		return
+	}
+	writeData(w, http.StatusOK, *app)
+}
+
+func (s *Server) updateApplication(w http.ResponseWriter, r *http.Request, args []string) {
+	var params texml.ApplicationParams
+	if !decode(w, r, &params) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	app := s.findApplicationLocked(args[0])
+	if app == nil {
+		notFound(w, "TeXML application", args[0])
+		return
+	}
+	applyApplicationParams(app, &params)
+	writeData(w, http.StatusOK, *app)
+}
+
+func (s *Server) deleteApplication(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	app := s.findApplicationLocked(args[0])
+	if app == nil {
+		notFound(w, "TeXML application", args[0])
+		return
+	}
+	s.applications = remove(s.applications, app)
+	writeData(w, http.StatusOK, *app)
+}
@@ -0,0 +1,322 @@
+package telnyxtest
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/andersryanc/telnyx-go/callcontrol"
+	"github.com/andersryanc/telnyx-go/texml"
+)
+
+// Command is a Call Control command received by the server.
+type Command struct {
+	// Action is the last element of the command path, e.g. "speak".
+	Action string
+	// Body is the JSON body of the command; decode it into the command type of Action.
+	Body json.RawMessage
+}
+
+// ccCall is a Call Control call. Its events go to webhookURL, in order.
+type ccCall struct {
+	info         callcontrol.Call
+	connectionID string
+	from, to     string
+	webhookURL   string
+	caller       Caller
+	inputs       int
+	answered     bool
+	started      time.Time
+	recording    time.Time
+	commandIDs   map[string]bool
+	commands     []Command
+}
+
+// IncomingCall delivers the call.initiated webhook of an inbound Call Control call from
+// from to to, and returns the call. The call is scripted like TeXML calls with
+// SetCaller on from, and waits for an Answer command.
+func (s *Server) IncomingCall(ctx context.Context, webhookURL, from, to string) (*callcontrol.Call, error) {
+	s.mu.Lock()
+	c := s.newCCCallLocked("", from, to, webhookURL, s.callers[from])
+	info := c.info
+	payload := callcontrol.CallInitiated{
+		CallInfo:  c.callInfo(""),
+		Direction: "incoming",
+		State:     "parked",
+		StartTime: now(),
+	}
+	s.mu.Unlock()
+	if err := s.SendWebhook(ctx, webhookURL, payload.EventType(), payload); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Commands returns the commands sent to the Call Control call callControlID, in order.
+func (s *Server) Commands(callControlID string) []Command {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c := s.findCCCallLocked(callControlID); c != nil {
+		return append([]Command(nil), c.commands...)
+	}
+	return nil
+}
+
+func (s *Server) newCCCallLocked(connectionID, from, to, webhookURL string, caller Caller) *ccCall {
+	c := &ccCall{
+		info: callcontrol.Call{
+			CallControlID: "v3:" + newID(),
+			CallLegID:     newID(),
+			CallSessionID: newID(),
+			IsAlive:       true,
+			RecordType:    "call",
+		},
+		connectionID: connectionID,
+		from:         from,
+		to:           to,
+		webhookURL:   webhookURL,
+		caller:       caller,
+		commandIDs:   map[string]bool{},
+	}
+	s.ccCalls = append(s.ccCalls, c)
+	return c
+}
+
+func (s *Server) findCCCallLocked(id string) *ccCall {
+	return find(s.ccCalls, func(c *ccCall) bool { return c.info.CallControlID == id })
+}
+
+// callInfo returns the CallInfo of the events of c, with the base64 clientState of the
+// command that caused them.
+func (c *ccCall) callInfo(clientState string) callcontrol.CallInfo {
+	info := callcontrol.CallInfo{
+		CallControlID: c.info.CallControlID,
+		CallLegID:     c.info.CallLegID,
+		CallSessionID: c.info.CallSessionID,
+		ConnectionID:  c.connectionID,
+		From:          c.from,
+		To:            c.to,
+	}
+	if clientState != "" {
+		state, err := base64.StdEncoding.DecodeString(clientState)
+		if err != nil {
+			state = []byte(clientState)
+		}
+		info.ClientState = state
+	}
+	return info
+}
+
+// hangupLocked ends c and queues its call.hangup event.
+func (s *Server) hangupLocked(c *ccCall, cause, source, clientState string) {
+	c.info.IsAlive = false
+	if !c.started.IsZero() {
+		c.info.CallDuration = int(time.Since(c.started).Seconds())
+	}
+	payload := callcontrol.CallHangup{
+		CallInfo:     c.callInfo(clientState),
+		StartTime:    c.started,
+		EndTime:      now(),
+		HangupCause:  cause,
+		HangupSource: source,
+	}
+	s.webhooks.send(c.webhookURL, payload.EventType(), payload)
+}
+
+// answerLocked answers c and queues its call.answered event.
+func (s *Server) answerLocked(c *ccCall, clientState string) {
+	c.answered = true
+	c.started = now()
+	payload := callcontrol.CallAnswered{CallInfo: c.callInfo(clientState), StartTime: c.started}
+	s.webhooks.send(c.webhookURL, payload.EventType(), payload)
+}
+
+// hangupCauses are the hangup causes of the calls a Caller does not answer.
+var hangupCauses = map[texml.CallStatus]string{
+	texml.CallStatusBusy:     "user_busy",
+	texml.CallStatusNoAnswer: "timeout",
+	texml.CallStatusFailed:   "call_rejected",
+}
+
+// dial places an outbound Call Control call. The callee, scripted with SetCaller,
+// answers at once unless its Status says otherwise.
+func (s *Server) dial(w http.ResponseWriter, r *http.Request, args []string) {
+	var params callcontrol.Dial
+	if !decode(w, r, &params) {
+		return
+	}
+	switch {
+	case params.ConnectionID == "":
+		invalid(w, "connection_id", "connection_id is required.")
+		return
+	case params.To == "":
+		invalid(w, "to", "to is required.")
+		return
+	case params.From == "":
+		invalid(w, "from", "from is required.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.newCCCallLocked(params.ConnectionID, params.From, params.To, params.WebhookUrl, s.callers[params.To])
+	initiated := callcontrol.CallInitiated{
+		CallInfo:  c.callInfo(params.ClientState),
+		Direction: "outgoing",
+		State:     "bridging",
+		StartTime: now(),
+	}
+	s.webhooks.send(c.webhookURL, initiated.EventType(), initiated)
+	if status := c.caller.Status; status != "" {
+		s.hangupLocked(c, hangupCauses[status], "callee", params.ClientState)
+	} else {
+		s.answerLocked(c, params.ClientState)
+		if amd := params.AnsweringMachineDetection; amd != "" && amd != "disabled" {
+			result := c.caller.AnsweredBy
+			switch {
+			case result == "":
+				result = "human"
+			case result != "human" && result != "not_sure":
+				result = "machine"
+			}
+			payload := callcontrol.CallMachineDetectionEnded{CallInfo: c.callInfo(params.ClientState), Result: result}
+			s.webhooks.send(c.webhookURL, payload.EventType(), payload)
+		}
+	}
+	info := c.info
+	info.ClientState = params.ClientState
+	writeData(w, http.StatusOK, info)
+}
+
+func (s *Server) getCCCall(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.findCCCallLocked(args[0])
+	if c == nil {
+		notFound(w, "Call", args[0])
+		return
+	}
+	info := c.info
+	if info.IsAlive && !c.started.IsZero() {
+		info.CallDuration = int(time.Since(c.started).Seconds())
+	}
+	writeData(w, http.StatusOK, info)
+}
+
+// executeCommand records a command and queues the events it causes.
+func (s *Server) executeCommand(w http.ResponseWriter, r *http.Request, args []string) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "10002", "Invalid JSON", err.Error())
+		return
+	}
+	var params struct {
+		callcontrol.CommandOptions
+		AudioUrl      string `json:"audio_url"`
+		MediaName     string `json:"media_name"`
+		Overlay       bool   `json:"overlay"`
+		WebhookUrl    string `json:"webhook_url"`
+		Channels      string `json:"channels"`
+		CallControlID string `json:"call_control_id"`
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &params); err != nil {
+			writeError(w, http.StatusBadRequest, "10002", "Invalid JSON", err.Error())
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.findCCCallLocked(args[0])
+	if c == nil {
+		notFound(w, "Call", args[0])
+		return
+	}
+	if !c.info.IsAlive {
+		writeError(w, http.StatusUnprocessableEntity, "90018", "Call has already ended",
+			fmt.Sprintf("Call %s has already ended.", args[0]))
+		return
+	}
+	if params.CommandID != "" && c.commandIDs[params.CommandID] {
+		writeData(w, http.StatusOK, map[string]string{"result": "ok"})
+		return
+	}
+	var other *ccCall
+	if args[1] == "bridge" {
+		other = s.findCCCallLocked(params.CallControlID)
+		if other == nil || !other.info.IsAlive {
+			invalid(w, "call_control_id", "Call "+params.CallControlID+" cannot be bridged.")
+			return
+		}
+	}
+	if params.CommandID != "" {
+		c.commandIDs[params.CommandID] = true
+	}
+	c.commands = append(c.commands, Command{Action: args[1], Body: json.RawMessage(body)})
+
+	state := params.ClientState
+	info := c.callInfo(state)
+	switch args[1] {
+	case "answer":
+		if params.WebhookUrl != "" {
+			c.webhookURL = params.WebhookUrl
+		}
+		if !c.answered {
+			s.answerLocked(c, state)
+		}
+	case "hangup":
+		s.hangupLocked(c, "normal_clearing", "caller", state)
+	case "reject":
+		s.hangupLocked(c, "call_rejected", "caller", state)
+	case "speak":
+		s.webhooks.send(c.webhookURL, "call.speak.started", callcontrol.CallSpeakStarted{CallInfo: info})
+		s.webhooks.send(c.webhookURL, "call.speak.ended", callcontrol.CallSpeakEnded{CallInfo: info, Status: "completed"})
+	case "playback_start":
+		s.webhooks.send(c.webhookURL, "call.playback.started", callcontrol.CallPlaybackStarted{
+			CallInfo: info, MediaURL: params.AudioUrl, MediaName: params.MediaName, Overlay: params.Overlay,
+		})
+		s.webhooks.send(c.webhookURL, "call.playback.ended", callcontrol.CallPlaybackEnded{
+			CallInfo: info, MediaURL: params.AudioUrl, MediaName: params.MediaName, Overlay: params.Overlay, Status: "completed",
+		})
+	case "gather_using_audio", "gather_using_speak":
+		digits, status := "", "timeout"
+		if c.inputs < len(c.caller.Inputs) {
+			digits = c.caller.Inputs[c.inputs]
+			c.inputs++
+		}
+		if digits != "" {
+			status = "valid"
+		}
+		s.webhooks.send(c.webhookURL, "call.gather.ended", callcontrol.CallGatherEnded{CallInfo: info, Digits: digits, Status: status})
+	case "record_start":
+		c.recording = now()
+	case "record_stop":
+		if c.recording.IsZero() {
+			break
+		}
+		seconds := int(time.Since(c.recording).Seconds())
+		rec := s.addRecordingLocked(c.info.CallLegID, "", seconds)
+		channels := params.Channels
+		if channels == "" {
+			channels = "single"
+		}
+		s.webhooks.send(c.webhookURL, "call.recording.saved", callcontrol.CallRecordingSaved{
+			CallInfo:            info,
+			Channels:            channels,
+			RecordingStartedAt:  c.recording,
+			RecordingEndedAt:    now(),
+			RecordingURLs:       callcontrol.RecordingURLs{WAV: rec.MediaUrl},
+			PublicRecordingURLs: callcontrol.RecordingURLs{WAV: rec.MediaUrl},
+		})
+		c.recording = time.Time{}
+	case "bridge":
+		s.webhooks.send(c.webhookURL, "call.bridged", callcontrol.CallBridged{CallInfo: info})
+		s.webhooks.send(other.webhookURL, "call.bridged", callcontrol.CallBridged{CallInfo: other.callInfo("")})
+	}
+	writeData(w, http.StatusOK, map[string]string{"result": "ok"})
+}
@@ -0,0 +1,110 @@
+package telnyxtest
+
+import (
+	"net/http"
+
+	"github.com/andersryanc/telnyx-go/texml"
+)
+
+// createCall places an outbound call from a TeXML application. The callee is scripted
+// with SetCaller; the call runs the inline Texml of the request, its Url, or the
+// VoiceUrl of the application.
+func (s *Server) createCall(w http.ResponseWriter, r *http.Request, args []string) {
+	var params struct {
+		texml.CallParams
+		Texml string `json:"Texml"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+	switch {
+	case params.To == "":
+		invalid(w, "To", "To is required.")
+		return
+	case params.From == "":
+		invalid(w, "From", "From is required.")
+		return
+	}
+	s.mu.Lock()
+	var app texml.Application
+	found := s.findApplicationLocked(args[0])
+	if found != nil {
+		app = *found
+	}
+	s.mu.Unlock()
+	if found == nil {
+		notFound(w, "TeXML application", args[0])
+		return
+	}
+
+	src := &source{url: app.VoiceUrl, method: app.VoiceMethod}
+	switch {
+	case params.Texml != "":
+		src = &source{texml: params.Texml}
+	case params.Url != "":
+		src = &source{url: params.Url, method: params.UrlMethod}
+	}
+	if src.texml != "" {
+		if _, err := parseTeXML([]byte(src.texml)); err != nil {
+			invalid(w, "Texml", err.Error())
+			return
+		}
+	}
+
+	c := s.newCall("outbound-api", params.From, params.To)
+	c.statusCallback, c.statusCallbackMethod = app.StatusCallback, app.StatusCallbackMethod
+	if params.StatusCallback != "" {
+		c.statusCallback, c.statusCallbackMethod = params.StatusCallback, params.StatusCallbackMethod
+	}
+	if params.MachineDetection != "" && params.MachineDetection != "Disable" {
+		c.machineDetection = true
+		c.amdCallback, c.amdCallbackMethod = params.AsyncAmdStatusCallback, params.AsyncAmdStatusCallbackMethod
+	}
+	resp := c.snapshot()
+	go c.start(src)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) getCall(w http.ResponseWriter, r *http.Request, args []string) {
+	c := s.findCall(args[1])
+	if c == nil {
+		notFound(w, "Call", args[1])
+		return
+	}
+	writeJSON(w, http.StatusOK, c.snapshot())
+}
+
+// updateCall redirects a call in progress to new TeXML, or hangs it up.
+func (s *Server) updateCall(w http.ResponseWriter, r *http.Request, args []string) {
+	var params struct {
+		texml.UpdateCallParams
+		Texml string `json:"Texml"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+	c := s.findCall(args[1])
+	if c == nil {
+		notFound(w, "Call", args[1])
+		return
+	}
+	if info := c.snapshot(); info.Status.Ended() {
+		invalid(w, "CallSid", "Call "+args[1]+" has already ended.")
+		return
+	}
+
+	s.mu.Lock()
+	if params.StatusCallback != "" {
+		c.statusCallback, c.statusCallbackMethod = params.StatusCallback, params.StatusCallbackMethod
+	}
+	s.mu.Unlock()
+	switch {
+	case params.Status == texml.CallStatusCompleted || params.Status == texml.CallStatusCanceled:
+		c.redirect(nil)
+	case params.Texml != "":
+		c.redirect(&source{texml: params.Texml})
+	case params.Url != "":
+		c.redirect(&source{url: params.Url, method: params.Method})
+	}
+	writeJSON(w, http.StatusOK, c.snapshot())
+}
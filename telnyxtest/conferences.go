@@ -0,0 +1,500 @@
+package telnyxtest
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andersryanc/telnyx-go/texml"
+	"github.com/beevik/etree"
+)
+
+type conference struct {
+	info         texml.Conference
+	participants []*participant
+
+	statusCallback       string
+	statusCallbackMethod string
+	statusCallbackEvents []string
+	// sequence numbers the status callbacks of the conference.
+	sequence int
+	// recording and recordingStarted are set while the conference is recorded.
+	recording        *texml.Recording
+	recordingStarted time.Time
+}
+
+// participant is a call in a conference. release is signaled when the participant is
+// removed by the REST API or the conference ends.
+type participant struct {
+	info    texml.Participant
+	call    *call
+	release chan struct{}
+}
+
+// conferenceOptions are the settings of a participant joining a conference.
+type conferenceOptions struct {
+	muted                bool
+	coaching             bool
+	callSidToCoach       string
+	startOnEnter         bool
+	endOnExit            bool
+	statusCallback       string
+	statusCallbackMethod string
+	statusCallbackEvents []string
+}
+
+func conferenceOptionsFromTeXML(el *etree.Element, base *url.URL) conferenceOptions {
+	opts := conferenceOptions{
+		muted:                el.SelectAttrValue("muted", "false") == "true",
+		callSidToCoach:       el.SelectAttrValue("coach", ""),
+		startOnEnter:         el.SelectAttrValue("startConferenceOnEnter", "true") != "false",
+		endOnExit:            el.SelectAttrValue("endConferenceOnExit", "false") == "true",
+		statusCallbackMethod: el.SelectAttrValue("statusCallbackMethod", ""),
+		statusCallbackEvents: strings.Fields(el.SelectAttrValue("statusCallbackEvent", "")),
+	}
+	opts.coaching = opts.callSidToCoach != ""
+	if cb := el.SelectAttrValue("statusCallback", ""); cb != "" {
+		opts.statusCallback = resolve(base, cb, "", nil).url
+	}
+	return opts
+}
+
+// joinConference adds c to the conference called name, which is created if it is not
+// in progress.
+func (s *Server) joinConference(c *call, name string, opts conferenceOptions) (*conference, *participant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conf := find(s.conferences, func(conf *conference) bool {
+		return conf.info.FriendlyName == name && conf.info.Status != "completed"
+	})
+	if conf == nil {
+		sid := newID()
+		uri := "/v2/texml/Accounts/" + s.AccountSid + "/Conferences/" + sid
+		conf = &conference{info: texml.Conference{
+			Sid:             sid,
+			AccountSid:      s.AccountSid,
+			FriendlyName:    name,
+			Status:          "init",
+			DateCreated:     texmlDate(now()),
+			DateUpdated:     texmlDate(now()),
+			SubresourceUris: map[string]string{"participants": uri + "/Participants"},
+			Uri:             uri,
+		}}
+		s.conferences = append(s.conferences, conf)
+	}
+	if conf.statusCallback == "" && opts.statusCallback != "" {
+		conf.statusCallback = opts.statusCallback
+		conf.statusCallbackMethod = opts.statusCallbackMethod
+		conf.statusCallbackEvents = opts.statusCallbackEvents
+		if len(conf.statusCallbackEvents) == 0 {
+			conf.statusCallbackEvents = []string{"start", "end"}
+		}
+	}
+
+	p := &participant{
+		info: texml.Participant{
+			CallSid:             c.info.CallSid,
+			AccountSid:          s.AccountSid,
+			ConferenceSid:       conf.info.Sid,
+			Status:              "connected",
+			Muted:               opts.muted,
+			Coaching:            opts.coaching,
+			CoachingCallSid:     opts.callSidToCoach,
+			EndConferenceOnExit: opts.endOnExit,
+			DateCreated:         texmlDate(now()),
+			DateUpdated:         texmlDate(now()),
+			Uri:                 conf.info.Uri + "/Participants/" + c.info.CallSid,
+		},
+		call:    c,
+		release: make(chan struct{}, 1),
+	}
+	conf.participants = append(conf.participants, p)
+	s.conferenceEventLocked(conf, "join", p)
+	if conf.info.Status == "init" && opts.startOnEnter {
+		conf.info.Status = "in-progress"
+		conf.info.DateUpdated = texmlDate(now())
+		s.conferenceEventLocked(conf, "start", nil)
+	}
+	return conf, p
+}
+
+// waitInConference keeps c in a conference until it is removed or the conference ends,
+// and then returns false. It returns true, with the next document, when the call is
+// updated through the REST API instead.
+func (c *call) waitInConference(conf *conference, p *participant) (*source, bool) {
+	s := c.s
+	for {
+		select {
+		case <-p.release:
+			return nil, false
+		case <-c.wake:
+			if next, ok := c.takeUpdate(); ok {
+				s.mu.Lock()
+				s.removeParticipantLocked(conf, p)
+				s.mu.Unlock()
+				return next, true
+			}
+		case <-s.ctx.Done():
+			return nil, true
+		}
+	}
+}
+
+// removeParticipantLocked takes p out of conf, which ends when p ends it on exit or
+// was the last participant.
+func (s *Server) removeParticipantLocked(conf *conference, p *participant) {
+	if !contains(participantSids(conf.participants), p.info.CallSid) {
+		return
+	}
+	conf.participants = remove(conf.participants, p)
+	p.release <- struct{}{}
+	s.conferenceEventLocked(conf, "leave", p)
+
+	switch {
+	case p.info.EndConferenceOnExit:
+		s.endConferenceLocked(conf, "participant-with-end-conference-on-exit-left", p.info.CallSid)
+	case len(conf.participants) == 0:
+		s.endConferenceLocked(conf, "last-participant-left", p.info.CallSid)
+	}
+}
+
+func (s *Server) endConferenceLocked(conf *conference, reason, callSid string) {
+	if conf.info.Status == "completed" {
+		return
+	}
+	for _, p := range conf.participants {
+		p.release <- struct{}{}
+		s.conferenceEventLocked(conf, "leave", p)
+	}
+	conf.participants = nil
+	if conf.recording != nil {
+		s.stopConferenceRecordingLocked(conf)
+	}
+	conf.info.Status = "completed"
+	conf.info.ReasonConferenceEnded = reason
+	conf.info.CallSidEndingConference = callSid
+	conf.info.DateUpdated = texmlDate(now())
+	s.conferenceEventLocked(conf, "end", nil)
+}
+
+func participantSids(participants []*participant) []string {
+	sids := make([]string, len(participants))
+	for i, p := range participants {
+		sids[i] = p.info.CallSid
+	}
+	return sids
+}
+
+// conferenceEventLocked sends a conference status callback for event, one of the
+// values of the statusCallbackEvent attribute of <Conference>, if it was requested.
+func (s *Server) conferenceEventLocked(conf *conference, event string, p *participant) {
+	// Unmute and unhold are requested together with mute and hold.
+	if conf.statusCallback == "" || !contains(conf.statusCallbackEvents, strings.TrimPrefix(event, "un")) {
+		return
+	}
+	conf.sequence++
+	prefix := "participant-"
+	if event == "start" || event == "end" {
+		prefix = "conference-"
+	}
+	params := url.Values{
+		"AccountSid":          {s.AccountSid},
+		"ConferenceSid":       {conf.info.Sid},
+		"FriendlyName":        {conf.info.FriendlyName},
+		"StatusCallbackEvent": {prefix + event},
+		"SequenceNumber":      {strconv.Itoa(conf.sequence)},
+		"Timestamp":           {texmlDate(now())},
+	}
+	if event == "end" {
+		params.Set("ReasonConferenceEnded", conf.info.ReasonConferenceEnded)
+		params.Set("CallSidEndingConference", conf.info.CallSidEndingConference)
+	}
+	if p != nil {
+		params.Set("CallSid", p.info.CallSid)
+		params.Set("Muted", strconv.FormatBool(p.info.Muted))
+		params.Set("Hold", strconv.FormatBool(p.info.Hold))
+		params.Set("Coaching", strconv.FormatBool(p.info.Coaching))
+		params.Set("EndConferenceOnExit", strconv.FormatBool(p.info.EndConferenceOnExit))
+	}
+	s.webhooks.sendForm(conf.statusCallbackMethod, conf.statusCallback, params)
+}
+
+func (s *Server) findConferenceLocked(sid string) *conference {
+	return find(s.conferences, func(conf *conference) bool { return conf.info.Sid == sid })
+}
+
+func (s *Server) listConferences(w http.ResponseWriter, r *http.Request, args []string) {
+	q := r.URL.Query()
+	s.mu.Lock()
+	var conferences []texml.Conference
+	for _, conf := range s.conferences {
+		if name := q.Get("FriendlyName"); name != "" && conf.info.FriendlyName != name {
+			continue
+		}
+		if status := q.Get("Status"); status != "" && conf.info.Status != status {
+			continue
+		}
+		conferences = append(conferences, conf.info)
+	}
+	s.mu.Unlock()
+	writeAccountList(w, r, "conferences", conferences)
+}
+
+func (s *Server) getConference(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conf := s.findConferenceLocked(args[1])
+	if conf == nil {
+		notFound(w, "Conference", args[1])
+		return
+	}
+	writeJSON(w, http.StatusOK, conf.info)
+}
+
+func (s *Server) updateConference(w http.ResponseWriter, r *http.Request, args []string) {
+	var params texml.UpdateConferenceParams
+	if !decode(w, r, &params) {
+		return
+	}
+	s.mu.Lock()
+	conf := s.findConferenceLocked(args[1])
+	if conf == nil {
+		s.mu.Unlock()
+		notFound(w, "Conference", args[1])
+		return
+	}
+	if params.Status == "completed" {
+		s.endConferenceLocked(conf, "conference-ended-via-api", "")
+	}
+	calls := make([]*call, len(conf.participants))
+	for i, p := range conf.participants {
+		calls[i] = p.call
+	}
+	info := conf.info
+	s.mu.Unlock()
+
+	if params.AnnounceUrl != "" {
+		for _, c := range calls {
+			c.play(&source{url: params.AnnounceUrl, method: params.AnnounceMethod}, url.Values{"ConferenceSid": {info.Sid}})
+		}
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (s *Server) listParticipants(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	conf := s.findConferenceLocked(args[1])
+	if conf == nil {
+		s.mu.Unlock()
+		notFound(w, "Conference", args[1])
+		return
+	}
+	participants := make([]texml.Participant, len(conf.participants))
+	for i, p := range conf.participants {
+		participants[i] = p.info
+	}
+	s.mu.Unlock()
+	writeAccountList(w, r, "participants", participants)
+}
+
+func (s *Server) findParticipantLocked(w http.ResponseWriter, args []string) (*conference, *participant) {
+	conf := s.findConferenceLocked(args[1])
+	if conf == nil {
+		notFound(w, "Conference", args[1])
+		return nil, nil
+	}
+	p := find(conf.participants, func(p *participant) bool { return p.info.CallSid == args[2] })
+	if p == nil {
+		notFound(w, "Participant", args[2])
+		return nil, nil
+	}
+	return conf, p
+}
+
+func (s *Server) getParticipant(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, p := s.findParticipantLocked(w, args); p != nil {
+		writeJSON(w, http.StatusOK, p.info)
+	}
+}
+
+// createParticipant dials a call into a conference. The call has no TeXML of its own:
+// it stays in the conference until it is removed or the conference ends.
+func (s *Server) createParticipant(w http.ResponseWriter, r *http.Request, args []string) {
+	var params texml.CreateParticipantParams
+	if !decode(w, r, &params) {
+		return
+	}
+	switch {
+	case params.From == "":
+		invalid(w, "From", "From is required.")
+		return
+	case params.To == "":
+		invalid(w, "To", "To is required.")
+		return
+	}
+	s.mu.Lock()
+	conf := s.findConferenceLocked(args[1])
+	var name string
+	if conf != nil && conf.info.Status != "completed" {
+		name = conf.info.FriendlyName
+	}
+	s.mu.Unlock()
+	if name == "" {
+		notFound(w, "Conference", args[1])
+		return
+	}
+
+	c := s.newCall("outbound-api", params.From, params.To)
+	c.statusCallback, c.statusCallbackMethod = params.StatusCallback, params.StatusCallbackMethod
+	c.setStatus(texml.CallStatusRinging)
+	if c.caller.Status != "" {
+		c.setStatus(c.caller.Status)
+		go c.finish()
+		writeJSON(w, http.StatusCreated, texml.Participant{
+			CallSid:       c.info.CallSid,
+			AccountSid:    s.AccountSid,
+			ConferenceSid: args[1],
+			Status:        string(c.caller.Status),
+		})
+		return
+	}
+
+	c.setStatus(texml.CallStatusInProgress)
+	opts := conferenceOptions{
+		muted:          params.Muted != nil && *params.Muted,
+		coaching:       params.Coaching != nil && *params.Coaching,
+		callSidToCoach: params.CallSidToCoach,
+		startOnEnter:   params.StartConferenceOnEnter == nil || *params.StartConferenceOnEnter,
+		endOnExit:      params.EndConferenceOnExit != nil && *params.EndConferenceOnExit,
+	}
+	conf, p := s.joinConference(c, name, opts)
+	s.mu.Lock()
+	info := p.info
+	s.mu.Unlock()
+	go func() {
+		defer c.finish()
+		c.waitInConference(conf, p)
+	}()
+	writeJSON(w, http.StatusCreated, info)
+}
+
+func (s *Server) updateParticipant(w http.ResponseWriter, r *http.Request, args []string) {
+	var params texml.UpdateParticipantParams
+	if !decode(w, r, &params) {
+		return
+	}
+	s.mu.Lock()
+	conf, p := s.findParticipantLocked(w, args)
+	if p == nil {
+		s.mu.Unlock()
+		return
+	}
+	if params.Muted != nil && *params.Muted != p.info.Muted {
+		p.info.Muted = *params.Muted
+		event := "unmute"
+		if p.info.Muted {
+			event = "mute"
+		}
+		s.conferenceEventLocked(conf, event, p)
+	}
+	if params.Hold != nil && *params.Hold != p.info.Hold {
+		p.info.Hold = *params.Hold
+		event := "unhold"
+		if p.info.Hold {
+			event = "hold"
+		}
+		s.conferenceEventLocked(conf, event, p)
+	}
+	if params.Coaching != nil {
+		p.info.Coaching = *params.Coaching
+		p.info.CoachingCallSid = ""
+		if p.info.Coaching {
+			p.info.CoachingCallSid = params.CallSidToCoach
+		}
+	}
+	if params.EndConferenceOnExit != nil {
+		p.info.EndConferenceOnExit = *params.EndConferenceOnExit
+	}
+	p.info.DateUpdated = texmlDate(now())
+	info := p.info
+	s.mu.Unlock()
+
+	if params.AnnounceUrl != "" {
+		p.call.play(&source{url: params.AnnounceUrl, method: params.AnnounceMethod}, url.Values{"ConferenceSid": {info.ConferenceSid}})
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (s *Server) deleteParticipant(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conf, p := s.findParticipantLocked(w, args)
+	if p == nil {
+		return
+	}
+	s.removeParticipantLocked(conf, p)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) startConferenceRecording(w http.ResponseWriter, r *http.Request, args []string) {
+	var params texml.ConferenceRecordingParams
+	if !decode(w, r, &params) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conf := s.findConferenceLocked(args[1])
+	if conf == nil || conf.info.Status == "completed" {
+		notFound(w, "Conference", args[1])
+		return
+	}
+	if conf.recording != nil {
+		invalid(w, "ConferenceSid", "Conference "+args[1]+" is already being recorded.")
+		return
+	}
+	conf.recording = s.startRecordingLocked("", conf.info.Sid)
+	if params.RecordingChannels == "dual" {
+		conf.recording.Channels = 2
+	}
+	conf.recordingStarted = now()
+	writeJSON(w, http.StatusCreated, conf.recording)
+}
+
+func (s *Server) stopConferenceRecording(w http.ResponseWriter, r *http.Request, args []string) {
+	var params struct{ Status string }
+	if !decode(w, r, &params) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conf := s.findConferenceLocked(args[1])
+	if conf == nil {
+		notFound(w, "Conference", args[1])
+		return
+	}
+	if conf.recording == nil || conf.recording.Sid != args[2] {
+		notFound(w, "Recording", args[2])
+		return
+	}
+	if params.Status != "stopped" {
+		invalid(w, "Status", "Status must be stopped.")
+		return
+	}
+	rec := conf.recording
+	s.stopConferenceRecordingLocked(conf)
+	writeJSON(w, http.StatusOK, rec)
+}
+
+func (s *Server) stopConferenceRecordingLocked(conf *conference) {
+	seconds := int(time.Since(conf.recordingStarted).Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	s.finishRecordingLocked(conf.recording, seconds)
+	conf.recording = nil
+	conf.recordingStarted = time.Time{}
+}
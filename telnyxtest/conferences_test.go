@@ -0,0 +1,145 @@
+package telnyxtest
+
+import (
+	"reflect"
+	"testing"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+	"github.com/andersryanc/telnyx-go/texml"
+)
+
+func TestConference(t *testing.T) {
+	srv := newServer(t)
+	ctx := testContext(t)
+	a := newTeXMLApp(t, map[string]string{
+		"/voice":    `<Dial><Conference statusCallback="/conference" statusCallbackEvent="start end join leave mute">standup</Conference></Dial><Say>Bye</Say>`,
+		"/announce": `<Say>Recording starts now</Say>`,
+	})
+	client, _ := newApplication(t, srv, a, "+13125550100")
+
+	first, _ := srv.Call(ctx, "+13125550198", "+13125550100")
+	second, _ := srv.Call(ctx, "+13125550199", "+13125550100")
+	var conf texml.Conference
+	waitFor(t, "both calls to join the conference", func() bool {
+		confs, _ := client.ListConferences(ctx, &texml.ListConferencesParams{FriendlyName: "standup", Status: "in-progress"}).All()
+		if len(confs) != 1 {
+			return false
+		}
+		conf = confs[0]
+		participants, _ := client.ListParticipants(ctx, conf.Sid).All()
+		return len(participants) == 2
+	})
+
+	if _, err := client.MuteParticipant(ctx, conf.Sid, second.Sid); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := client.GetParticipant(ctx, conf.Sid, second.Sid); err != nil || !p.Muted {
+		t.Errorf("GetParticipant = %+v, %v", p, err)
+	}
+	if _, err := client.AnnounceConference(ctx, conf.Sid, a.URL+"/announce", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err := client.StartConferenceRecording(ctx, conf.Sid, &texml.ConferenceRecordingParams{RecordingChannels: "dual"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Status != "in-progress" || rec.ConferenceSid != conf.Sid || rec.Channels != 2 {
+		t.Errorf("StartConferenceRecording = %+v", rec)
+	}
+	if _, err := client.StartConferenceRecording(ctx, conf.Sid, nil); err == nil {
+		t.Error("a second recording of the conference was started")
+	}
+	if _, err := client.StopConferenceRecording(ctx, conf.Sid, "missing"); err == nil {
+		t.Error("a missing recording was stopped")
+	}
+	stopped, err := client.StopConferenceRecording(ctx, conf.Sid, rec.Sid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stopped.Sid != rec.Sid || stopped.Status != "completed" || stopped.MediaUrl == "" {
+		t.Errorf("StopConferenceRecording = %+v", stopped)
+	}
+	if recs, _ := client.ListRecordings(ctx, &texml.ListRecordingsParams{ConferenceSid: conf.Sid}).All(); len(recs) != 1 {
+		t.Errorf("%d recordings of the conference, want 1", len(recs))
+	}
+
+	// Participants hear the announcement in the conference, and continue with the rest
+	// of their TeXML when they leave it.
+	if err := client.KickParticipant(ctx, conf.Sid, first.Sid); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.EndConference(ctx, conf.Sid); err != nil {
+		t.Fatal(err)
+	}
+	for _, sid := range []string{first.Sid, second.Sid} {
+		log, _ := srv.WaitCall(ctx, sid)
+		if want := []string{"Recording starts now", "Bye"}; !reflect.DeepEqual(log.Verbs(), []string{"Say", "Dial", "Say"}) || !reflect.DeepEqual(log.Said(), want) {
+			t.Errorf("call verbs = %q, said %q", log.Verbs(), log.Said())
+		}
+	}
+	got, err := client.GetConference(ctx, conf.Sid)
+	if err != nil || got.Status != "completed" || got.ReasonConferenceEnded != "conference-ended-via-api" {
+		t.Errorf("GetConference = %+v, %v", got, err)
+	}
+
+	srv.Flush(ctx)
+	var events []string
+	for _, cb := range a.received("/conference") {
+		events = append(events, cb.Get("StatusCallbackEvent"))
+	}
+	want := []string{
+		"participant-join", "conference-start", "participant-join", "participant-mute",
+		"participant-leave", "participant-leave", "conference-end",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("status callback events = %q, want %q", events, want)
+	}
+}
+
+func TestCreateParticipant(t *testing.T) {
+	srv := newServer(t)
+	ctx := testContext(t)
+	a := newTeXMLApp(t, map[string]string{"/voice": `<Dial><Conference>support</Conference></Dial>`})
+	client, _ := newApplication(t, srv, a, "+13125550100")
+
+	caller, _ := srv.Call(ctx, "+13125550199", "+13125550100")
+	var confSid string
+	waitFor(t, "the conference to start", func() bool {
+		confs, _ := client.ListConferences(ctx, &texml.ListConferencesParams{Status: "in-progress"}).All()
+		if len(confs) == 1 {
+			confSid = confs[0].Sid
+		}
+		return confSid != ""
+	})
+
+	srv.SetCaller("+13125550111", Caller{Status: texml.CallStatusNoAnswer})
+	p, err := client.CreateParticipant(ctx, confSid, &texml.CreateParticipantParams{From: "+13125550100", To: "+13125550111"})
+	if err != nil || p.Status != "no-answer" {
+		t.Errorf("CreateParticipant of an unanswered number = %+v, %v", p, err)
+	}
+	supervisor, err := client.CreateParticipant(ctx, confSid, &texml.CreateParticipantParams{
+		From:                "+13125550100",
+		To:                  "+13125550112",
+		Coaching:            telnyx.Bool(true),
+		CallSidToCoach:      caller.Sid,
+		EndConferenceOnExit: telnyx.Bool(true),
+	})
+	if err != nil || !supervisor.Coaching || supervisor.CoachingCallSid != caller.Sid || supervisor.Status != "connected" {
+		t.Fatalf("CreateParticipant = %+v, %v", supervisor, err)
+	}
+
+	// The supervisor ends the conference on leaving, which releases the caller.
+	if err := client.KickParticipant(ctx, confSid, supervisor.CallSid); err != nil {
+		t.Fatal(err)
+	}
+	if log, _ := srv.WaitCall(ctx, caller.Sid); log.Call.Status != texml.CallStatusCompleted {
+		t.Errorf("caller = %+v", log.Call)
+	}
+	if log, _ := srv.WaitCall(ctx, supervisor.CallSid); log.Call.Direction != "outbound-api" {
+		t.Errorf("supervisor = %+v", log.Call)
+	}
+	if _, err := client.CreateParticipant(ctx, confSid, &texml.CreateParticipantParams{From: "+13125550100", To: "+13125550113"}); err == nil {
+		t.Error("a participant was added to a conference that had ended")
+	}
+}
@@ -0,0 +1,601 @@
+package telnyxtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andersryanc/telnyx-go/numbers"
+	"github.com/andersryanc/telnyx-go/texml"
+	"github.com/beevik/etree"
+)
+
+// maxDocuments bounds the TeXML documents a call fetches, to end redirect loops.
+const maxDocuments = 100
+
+// recordDuration is the duration, in seconds, of the recordings made by <Record>.
+const recordDuration = 5
+
+// Caller scripts the party at the other end of the calls to or from a number: the
+// callee of outbound calls and of <Dial>, the caller of inbound calls.
+type Caller struct {
+	// Status ends outbound calls and <Dial> attempts to the number without answering:
+	// CallStatusBusy, CallStatusNoAnswer or CallStatusFailed. The zero value answers.
+	Status texml.CallStatus
+	// AnsweredBy is reported by answering machine detection, "human" by default.
+	AnsweredBy string
+	// Inputs answer the <Gather> verbs of a call in turn, with digits or, when an input
+	// is not made of digits, speech. They also give the transcripts of <Record>. An
+	// empty input, or running out of them, lets a <Gather> time out.
+	Inputs []string
+}
+
+// SetCaller scripts the calls to or from number.
+func (s *Server) SetCaller(number string, c Caller) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callers[number] = c
+}
+
+// Step is a verb executed by a TeXML call.
+type Step struct {
+	// Verb is the element name, e.g. "Say".
+	Verb string
+	// Attrs holds the attributes of the element.
+	Attrs map[string]string
+	// Text is the text of the element: what <Say> said, the URL <Play> played, the
+	// number <Dial> called or the queue <Enqueue> joined.
+	Text string
+	// Result is the outcome of the verbs that have one: the input of <Gather>, the
+	// transcript of <Record>, the DialCallStatus of <Dial> and the QueueResult of
+	// <Enqueue>.
+	Result string
+}
+
+// CallLog is the history of a TeXML call.
+type CallLog struct {
+	Call  texml.Call
+	Steps []Step
+	// Err is the error that ended the call early, such as TeXML that could not be
+	// fetched or parsed.
+	Err error
+}
+
+// Said returns the text of the <Say> verbs of the call, in order.
+func (l *CallLog) Said() []string {
+	var said []string
+	for _, st := range l.Steps {
+		if st.Verb == "Say" {
+			said = append(said, st.Text)
+		}
+	}
+	return said
+}
+
+// Verbs returns the names of the verbs executed by the call, in order.
+func (l *CallLog) Verbs() []string {
+	verbs := make([]string, len(l.Steps))
+	for i, st := range l.Steps {
+		verbs[i] = st.Verb
+	}
+	return verbs
+}
+
+// Call places an inbound call from from to the number to, which must be assigned to a
+// TeXML application, and runs the TeXML at its VoiceUrl. Follow it with WaitCall to
+// get the log of the call.
+func (s *Server) Call(ctx context.Context, from, to string) (*texml.Call, error) {
+	s.mu.Lock()
+	number := find(s.phoneNumbers, func(n *numbers.PhoneNumber) bool { return n.PhoneNumber == to })
+	var app *texml.Application
+	if number != nil {
+		app = find(s.applications, func(a *texml.Application) bool { return a.ID == number.ConnectionID })
+	}
+	s.mu.Unlock()
+	if app == nil {
+		return nil, fmt.Errorf("telnyxtest: %s is not assigned to a TeXML application", to)
+	}
+
+	c := s.newCall("inbound", from, to)
+	c.statusCallback, c.statusCallbackMethod = app.StatusCallback, app.StatusCallbackMethod
+	go c.start(&source{url: app.VoiceUrl, method: app.VoiceMethod})
+	return c.snapshot(), nil
+}
+
+// WaitCall waits for the TeXML call callSid to end and returns its log.
+func (s *Server) WaitCall(ctx context.Context, callSid string) (*CallLog, error) {
+	c := s.findCall(callSid)
+	if c == nil {
+		return nil, fmt.Errorf("telnyxtest: no call %s", callSid)
+	}
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &CallLog{Call: c.info, Steps: append([]Step(nil), c.steps...), Err: c.err}, nil
+}
+
+// call is a TeXML call and its interpreter.
+type call struct {
+	s      *Server
+	info   texml.Call
+	caller Caller
+	inputs int
+
+	statusCallback       string
+	statusCallbackMethod string
+	machineDetection     bool
+	amdCallback          string
+	amdCallbackMethod    string
+
+	steps   []Step
+	err     error
+	started time.Time
+
+	// pending is the latest update of the call through the REST API, and wake is
+	// signaled when it is set.
+	pending *update
+	wake    chan struct{}
+	done    chan struct{}
+}
+
+// update redirects a call to next, or hangs it up when next is nil.
+type update struct {
+	next *source
+}
+
+// source is where a call gets its next TeXML document: a URL, or inline TeXML.
+type source struct {
+	url    string
+	method string
+	params url.Values
+	texml  string
+}
+
+func (s *Server) newCall(direction, from, to string) *call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sid := newID()
+	far := to
+	if direction == "inbound" {
+		far = from
+	}
+	c := &call{
+		s: s,
+		info: texml.Call{
+			Sid:           sid,
+			CallSid:       sid,
+			AccountSid:    s.AccountSid,
+			DateCreated:   texmlDate(now()),
+			DateUpdated:   texmlDate(now()),
+			Direction:     direction,
+			Duration:      "0",
+			From:          from,
+			FromFormatted: from,
+			To:            to,
+			ToFormatted:   to,
+			Status:        texml.CallStatusQueued,
+			Uri:           "/v2/texml/Accounts/" + s.AccountSid + "/Calls/" + sid,
+		},
+		caller: s.callers[far],
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	s.calls = append(s.calls, c)
+	return c
+}
+
+func (s *Server) findCall(sid string) *call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return find(s.calls, func(c *call) bool { return c.info.Sid == sid })
+}
+
+func (c *call) snapshot() *texml.Call {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	info := c.info
+	return &info
+}
+
+func (c *call) setStatus(status texml.CallStatus) {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	c.setStatusLocked(status)
+}
+
+func (c *call) setStatusLocked(status texml.CallStatus) {
+	t := now()
+	c.info.Status = status
+	c.info.DateUpdated = texmlDate(t)
+	switch {
+	case status == texml.CallStatusInProgress:
+		c.started = t
+		c.info.StartTime = texmlDate(t)
+	case status.Ended():
+		c.info.EndTime = texmlDate(t)
+		if !c.started.IsZero() {
+			c.info.Duration = strconv.Itoa(int(t.Sub(c.started).Seconds()))
+		}
+	}
+}
+
+// redirect makes the call continue with next, or hang up when next is nil, at the end
+// of the verb it is executing or as soon as it is waiting.
+func (c *call) redirect(next *source) {
+	c.s.mu.Lock()
+	c.pending = &update{next: next}
+	c.s.mu.Unlock()
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (c *call) takeUpdate() (*source, bool) {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	if c.pending == nil {
+		return nil, false
+	}
+	next := c.pending.next
+	c.pending = nil
+	return next, true
+}
+
+// params returns the parameters sent with every request about the call.
+func (c *call) params() url.Values {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	p := url.Values{
+		"AccountSid": {c.info.AccountSid},
+		"CallSid":    {c.info.CallSid},
+		"From":       {c.info.From},
+		"To":         {c.info.To},
+		"CallStatus": {string(c.info.Status)},
+		"Direction":  {c.info.Direction},
+	}
+	if c.info.AnsweredBy != "" {
+		p.Set("AnsweredBy", c.info.AnsweredBy)
+	}
+	return p
+}
+
+func (c *call) nextInput() string {
+	if c.inputs >= len(c.caller.Inputs) {
+		return ""
+	}
+	c.inputs++
+	return c.caller.Inputs[c.inputs-1]
+}
+
+func (c *call) fail(err error) {
+	c.s.mu.Lock()
+	c.err = err
+	c.s.mu.Unlock()
+	c.s.addErr(err)
+}
+
+func (c *call) addStep(el *etree.Element, text, result string) {
+	st := Step{Verb: el.Tag, Attrs: map[string]string{}, Text: text, Result: result}
+	for _, a := range el.Attr {
+		st.Attrs[a.Key] = a.Value
+	}
+	c.s.mu.Lock()
+	c.steps = append(c.steps, st)
+	c.s.mu.Unlock()
+}
+
+// start rings the call, and runs src once it is answered.
+func (c *call) start(src *source) {
+	defer c.finish()
+	c.setStatus(texml.CallStatusRinging)
+	if c.info.Direction != "inbound" && c.caller.Status != "" {
+		c.setStatus(c.caller.Status)
+		return
+	}
+	c.answer()
+	c.run(src)
+}
+
+func (c *call) answer() {
+	c.setStatus(texml.CallStatusInProgress)
+	if !c.machineDetection {
+		return
+	}
+	answeredBy := c.caller.AnsweredBy
+	if answeredBy == "" {
+		answeredBy = "human"
+	}
+	c.s.mu.Lock()
+	c.info.AnsweredBy = answeredBy
+	c.s.mu.Unlock()
+	c.s.webhooks.sendForm(c.amdCallbackMethod, c.amdCallback, c.params())
+}
+
+// finish ends the call, if the TeXML did not, and reports its final status.
+func (c *call) finish() {
+	c.s.mu.Lock()
+	if !c.info.Status.Ended() {
+		c.setStatusLocked(texml.CallStatusCompleted)
+	}
+	duration := c.info.Duration
+	method, callback := c.statusCallbackMethod, c.statusCallback
+	c.s.mu.Unlock()
+
+	params := c.params()
+	params.Set("CallDuration", duration)
+	c.s.webhooks.sendForm(method, callback, params)
+	close(c.done)
+}
+
+// run executes the TeXML of src and the documents it leads to.
+func (c *call) run(src *source) {
+	var base *url.URL
+	for n := 0; src != nil; n++ {
+		if n == maxDocuments {
+			c.fail(fmt.Errorf("telnyxtest: call %s fetched more than %d documents", c.info.Sid, maxDocuments))
+			return
+		}
+		verbs, docURL, err := c.s.fetch(c.s.ctx, src, c.params())
+		if err != nil {
+			c.fail(err)
+			return
+		}
+		if docURL != nil {
+			base = docURL
+		}
+		src = c.execute(verbs, base)
+	}
+}
+
+// fetch returns the verbs of the document of src, and its URL unless it is inline.
+func (s *Server) fetch(ctx context.Context, src *source, params url.Values) ([]*etree.Element, *url.URL, error) {
+	if src.url == "" {
+		verbs, err := parseTeXML([]byte(src.texml))
+		return verbs, nil, err
+	}
+	u, err := url.Parse(src.url)
+	if err != nil {
+		return nil, nil, fmt.Errorf("telnyxtest: invalid TeXML URL %q: %w", src.url, err)
+	}
+	for k, v := range src.params {
+		params[k] = v
+	}
+	data, err := s.postForm(ctx, src.method, src.url, params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("telnyxtest: fetch TeXML: %w", err)
+	}
+	verbs, err := parseTeXML(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("telnyxtest: TeXML from %s: %w", src.url, err)
+	}
+	return verbs, u, nil
+}
+
+func parseTeXML(data []byte) ([]*etree.Element, error) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(data); err != nil {
+		return nil, fmt.Errorf("telnyxtest: parse TeXML: %w", err)
+	}
+	root := doc.Root()
+	if root == nil || root.Tag != "Response" {
+		return nil, errors.New("telnyxtest: TeXML document has no <Response>")
+	}
+	return root.ChildElements(), nil
+}
+
+// resolve returns a source for rawURL, relative to the document at base.
+func resolve(base *url.URL, rawURL, method string, params url.Values) *source {
+	if base != nil {
+		if u, err := base.Parse(rawURL); err == nil {
+			rawURL = u.String()
+		}
+	}
+	return &source{url: rawURL, method: method, params: params}
+}
+
+// execute runs verbs and returns the source of the next document, or nil when the call
+// ends.
+func (c *call) execute(verbs []*etree.Element, base *url.URL) *source {
+	for _, el := range verbs {
+		if next, ok := c.takeUpdate(); ok {
+			return next
+		}
+		if next, leave := c.exec(el, base); leave {
+			return next
+		}
+	}
+	return nil
+}
+
+// exec runs a verb. It returns whether the call leaves the document, and the source of
+// the next document if it does not end.
+func (c *call) exec(el *etree.Element, base *url.URL) (*source, bool) {
+	text := strings.TrimSpace(el.Text())
+	switch el.Tag {
+	case "Gather":
+		return c.gather(el, base)
+	case "Record":
+		return c.record(el, base)
+	case "Dial":
+		return c.dial(el, base)
+	case "Enqueue":
+		return c.enqueue(el, base)
+	case "Redirect":
+		c.addStep(el, text, "")
+		return resolve(base, text, el.SelectAttrValue("method", ""), nil), true
+	case "Hangup":
+		c.addStep(el, text, "")
+		return nil, true
+	case "Reject":
+		c.addStep(el, text, "")
+		status := texml.CallStatusFailed
+		if el.SelectAttrValue("reason", "") == "busy" {
+			status = texml.CallStatusBusy
+		}
+		c.setStatus(status)
+		return nil, true
+	}
+	c.addStep(el, text, "")
+	return nil, false
+}
+
+func (c *call) gather(el *etree.Element, base *url.URL) (*source, bool) {
+	for _, prompt := range el.ChildElements() {
+		c.addStep(prompt, strings.TrimSpace(prompt.Text()), "")
+	}
+	input := c.nextInput()
+	c.addStep(el, "", input)
+	if input == "" && el.SelectAttrValue("actionOnEmptyResult", "false") != "true" {
+		return nil, false
+	}
+
+	params := url.Values{}
+	if strings.Trim(input, "0123456789*#") == "" {
+		params.Set("Digits", input)
+	} else {
+		params.Set("SpeechResult", input)
+		params.Set("Confidence", "0.9")
+	}
+	action := el.SelectAttrValue("action", "")
+	if action == "" {
+		if base == nil {
+			return nil, false
+		}
+		action = base.String()
+	}
+	return resolve(base, action, el.SelectAttrValue("method", ""), params), true
+}
+
+func (c *call) record(el *etree.Element, base *url.URL) (*source, bool) {
+	transcript := c.nextInput()
+	rec := c.s.addRecording(c.info.CallSid, "", recordDuration)
+	c.addStep(el, "", transcript)
+
+	params := url.Values{
+		"RecordingSid":      {rec.Sid},
+		"RecordingUrl":      {rec.MediaUrl},
+		"RecordingDuration": {rec.Duration},
+	}
+	if cb := el.SelectAttrValue("recordingStatusCallback", ""); cb != "" {
+		p := c.params()
+		for k, v := range params {
+			p[k] = v
+		}
+		p.Set("RecordingStatus", "completed")
+		cbSource := resolve(base, cb, el.SelectAttrValue("recordingStatusCallbackMethod", ""), nil)
+		c.s.webhooks.sendForm(cbSource.method, cbSource.url, p)
+	}
+	if el.SelectAttrValue("transcribe", "false") == "true" {
+		tr := c.s.addTranscription(rec, transcript)
+		if cb := el.SelectAttrValue("transcribeCallback", ""); cb != "" {
+			p := c.params()
+			for k, v := range params {
+				p[k] = v
+			}
+			p.Set("TranscriptionSid", tr.Sid)
+			p.Set("TranscriptionText", tr.TranscriptionText)
+			p.Set("TranscriptionStatus", tr.Status)
+			c.s.webhooks.sendForm(http.MethodPost, resolve(base, cb, "", nil).url, p)
+		}
+	}
+
+	if action := el.SelectAttrValue("action", ""); action != "" {
+		return resolve(base, action, el.SelectAttrValue("method", ""), params), true
+	}
+	return nil, false
+}
+
+func (c *call) dial(el *etree.Element, base *url.URL) (*source, bool) {
+	var targets []string
+	result := string(texml.CallStatusCompleted)
+	children := el.ChildElements()
+	switch {
+	case len(children) == 1 && children[0].Tag == "Conference":
+		name := strings.TrimSpace(children[0].Text())
+		targets = append(targets, name)
+		conf, p := c.s.joinConference(c, name, conferenceOptionsFromTeXML(children[0], base))
+		if next, interrupted := c.waitInConference(conf, p); interrupted {
+			c.addStep(el, name, result)
+			return next, true
+		}
+	case len(children) == 1 && children[0].Tag == "Queue":
+		name := strings.TrimSpace(children[0].Text())
+		targets = append(targets, name)
+		result = c.dialQueue(children[0], name, base)
+	default:
+		for _, child := range children {
+			targets = append(targets, strings.TrimSpace(child.Text()))
+		}
+		if len(targets) == 0 {
+			targets = append(targets, strings.TrimSpace(el.Text()))
+		}
+		result = c.s.dialResult(targets)
+	}
+	c.addStep(el, strings.Join(targets, ","), result)
+
+	if action := el.SelectAttrValue("action", ""); action != "" {
+		params := url.Values{
+			"DialCallStatus":   {result},
+			"DialCallSid":      {newID()},
+			"DialCallDuration": {"0"},
+		}
+		return resolve(base, action, el.SelectAttrValue("method", ""), params), true
+	}
+	return nil, false
+}
+
+// dialResult returns the DialCallStatus of a <Dial> to targets: completed if any of
+// them answers, and the status of the last one otherwise.
+func (s *Server) dialResult(targets []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := string(texml.CallStatusNoAnswer)
+	for _, t := range targets {
+		status := s.callers[t].Status
+		if status == "" {
+			return string(texml.CallStatusCompleted)
+		}
+		result = string(status)
+	}
+	return result
+}
+
+// play fetches a document played to the call without taking control of it, such as
+// the waitUrl of <Enqueue>, and records its verbs. It returns the verbs, so that the
+// caller can act on <Leave> and <Hangup>.
+func (c *call) play(src *source, params url.Values) []string {
+	p := c.params()
+	for k, v := range params {
+		p[k] = v
+	}
+	verbs, _, err := c.s.fetch(c.s.ctx, src, p)
+	if err != nil {
+		c.s.addErr(err)
+		return nil
+	}
+	var names []string
+	for _, el := range verbs {
+		c.addStep(el, strings.TrimSpace(el.Text()), "")
+		names = append(names, el.Tag)
+	}
+	return names
+}
+
+func contains(items []string, item string) bool {
+	for _, it := range items {
+		if it == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,229 @@
+package telnyxtest
+
+import (
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/andersryanc/telnyx-go/texml"
+)
+
+// waitFor polls cond until it holds, and fails the test after a few seconds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+func TestInboundCall(t *testing.T) {
+	srv := newServer(t)
+	ctx := testContext(t)
+	a := newTeXMLApp(t, map[string]string{
+		"/voice":    `<Say>Welcome</Say><Gather action="/menu" numDigits="1"><Say>Press 1 for sales</Say></Gather><Say>Goodbye</Say>`,
+		"/menu":     `<Say>Leave a message</Say><Record action="/recorded" transcribe="true" recordingStatusCallback="/recording-status"/>`,
+		"/recorded": `<Hangup/>`,
+	})
+	client, _ := newApplication(t, srv, a, "+13125550100")
+	srv.SetCaller("+13125550199", Caller{Inputs: []string{"1", "call me back"}})
+
+	call, err := srv.Call(ctx, "+13125550199", "+13125550100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	log, err := srv.WaitCall(ctx, call.Sid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"Welcome", "Press 1 for sales", "Leave a message"}; !reflect.DeepEqual(log.Said(), want) {
+		t.Errorf("Said() = %q, want %q", log.Said(), want)
+	}
+	if want := []string{"Say", "Say", "Gather", "Say", "Record", "Hangup"}; !reflect.DeepEqual(log.Verbs(), want) {
+		t.Errorf("Verbs() = %q, want %q", log.Verbs(), want)
+	}
+	if gather := log.Steps[2]; gather.Result != "1" || gather.Attrs["numDigits"] != "1" {
+		t.Errorf("Gather step = %+v", gather)
+	}
+	if log.Call.Status != texml.CallStatusCompleted || log.Call.Direction != "inbound" || log.Err != nil {
+		t.Errorf("call = %+v, %v", log.Call, log.Err)
+	}
+
+	voice := a.received("/voice")
+	if len(voice) != 1 || voice[0].Get("CallSid") != call.Sid || voice[0].Get("From") != "+13125550199" || voice[0].Get("CallStatus") != "in-progress" {
+		t.Errorf("/voice requests = %v", voice)
+	}
+	if menu := a.received("/menu"); len(menu) != 1 || menu[0].Get("Digits") != "1" {
+		t.Errorf("/menu requests = %v", menu)
+	}
+	status := a.received("/status")
+	if len(status) != 1 || status[0].Get("CallStatus") != "completed" {
+		t.Errorf("/status requests = %v", status)
+	}
+
+	recorded := a.received("/recorded")
+	if len(recorded) != 1 || recorded[0].Get("RecordingDuration") != "5" {
+		t.Fatalf("/recorded requests = %v", recorded)
+	}
+	if cb := a.received("/recording-status"); len(cb) != 1 || cb[0].Get("RecordingStatus") != "completed" {
+		t.Errorf("/recording-status requests = %v", cb)
+	}
+	sid := recorded[0].Get("RecordingSid")
+	rec, err := client.GetRecording(ctx, sid)
+	if err != nil || rec.CallSid != call.Sid || rec.Status != "completed" {
+		t.Fatalf("GetRecording = %+v, %v", rec, err)
+	}
+	transcriptions, err := client.ListRecordingTranscriptions(ctx, sid).All()
+	if err != nil || len(transcriptions) != 1 || transcriptions[0].TranscriptionText != "call me back" {
+		t.Errorf("ListRecordingTranscriptions = %+v, %v", transcriptions, err)
+	}
+	body, err := client.DownloadRecording(ctx, sid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if len(data) != 44+2*8000*5 {
+		t.Errorf("recording is %d bytes, want 5 s of 8 kHz WAV", len(data))
+	}
+
+	if errs := srv.Errors(); len(errs) != 0 {
+		t.Errorf("Errors() = %v", errs)
+	}
+}
+
+func TestOutboundCall(t *testing.T) {
+	srv := newServer(t)
+	ctx := testContext(t)
+	a := newTeXMLApp(t, map[string]string{
+		"/outbound": `<Dial action="/dialed"><Number>+13125550111</Number><Number>+13125550112</Number></Dial>`,
+		"/dialed":   `<Say>Nobody answered</Say>`,
+	})
+	client, app := newApplication(t, srv, a, "")
+	srv.SetCaller("+13125550199", Caller{AnsweredBy: "machine_end_beep"})
+	srv.SetCaller("+13125550111", Caller{Status: texml.CallStatusBusy})
+	srv.SetCaller("+13125550112", Caller{Status: texml.CallStatusNoAnswer})
+
+	call, err := client.CreateCall(ctx, app.ID, &texml.CallParams{
+		From:                   "+13125550100",
+		To:                     "+13125550199",
+		Url:                    a.URL + "/outbound",
+		MachineDetection:       "Enable",
+		AsyncAmdStatusCallback: a.URL + "/amd",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if call.Status != texml.CallStatusQueued || call.Direction != "outbound-api" {
+		t.Errorf("CreateCall = %+v", call)
+	}
+	log, err := srv.WaitCall(ctx, call.Sid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Flush(ctx)
+
+	if dial := log.Steps[0]; dial.Text != "+13125550111,+13125550112" || dial.Result != "no-answer" {
+		t.Errorf("Dial step = %+v", dial)
+	}
+	if dialed := a.received("/dialed"); len(dialed) != 1 || dialed[0].Get("DialCallStatus") != "no-answer" {
+		t.Errorf("/dialed requests = %v", dialed)
+	}
+	if amd := a.received("/amd"); len(amd) != 1 || amd[0].Get("AnsweredBy") != "machine_end_beep" {
+		t.Errorf("/amd requests = %v", amd)
+	}
+	if got, err := client.GetCall(ctx, call.Sid); err != nil || got.Status != texml.CallStatusCompleted || got.AnsweredBy != "machine_end_beep" {
+		t.Errorf("GetCall = %+v, %v", got, err)
+	}
+}
+
+func TestOutboundCallNotAnswered(t *testing.T) {
+	srv := newServer(t)
+	ctx := testContext(t)
+	a := newTeXMLApp(t, map[string]string{"/voice": `<Say>Hello</Say>`})
+	client, app := newApplication(t, srv, a, "")
+	srv.SetCaller("+13125550199", Caller{Status: texml.CallStatusBusy})
+
+	call, err := client.CreateCall(ctx, app.ID, &texml.CallParams{From: "+13125550100", To: "+13125550199"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log, _ := srv.WaitCall(ctx, call.Sid)
+	srv.Flush(ctx)
+	if log.Call.Status != texml.CallStatusBusy || len(log.Steps) != 0 || len(a.received("/voice")) != 0 {
+		t.Errorf("call = %+v, steps = %v", log.Call, log.Steps)
+	}
+	if status := a.received("/status"); len(status) != 1 || status[0].Get("CallStatus") != "busy" {
+		t.Errorf("/status requests = %v", status)
+	}
+
+	if _, err := client.CreateCall(ctx, "missing", &texml.CallParams{From: "+13125550100", To: "+13125550199"}); err == nil {
+		t.Error("a call was created from a missing application")
+	}
+	if _, err := client.CreateCall(ctx, app.ID, &texml.CallParams{From: "+13125550100"}); err == nil {
+		t.Error("a call was created without To")
+	}
+}
+
+func TestUpdateCall(t *testing.T) {
+	srv := newServer(t)
+	ctx := testContext(t)
+	a := newTeXMLApp(t, map[string]string{
+		"/voice": `<Enqueue>support</Enqueue><Say>Not reached</Say>`,
+		"/agent": `<Say>An agent will call you back</Say>`,
+	})
+	client, _ := newApplication(t, srv, a, "+13125550100")
+
+	first, _ := srv.Call(ctx, "+13125550198", "+13125550100")
+	second, _ := srv.Call(ctx, "+13125550199", "+13125550100")
+	waitFor(t, "both calls to be queued", func() bool {
+		queues, _ := client.ListQueues(ctx, 0).All()
+		return len(queues) == 1 && queues[0].CurrentSize == 2
+	})
+
+	if _, err := client.RedirectCall(ctx, first.Sid, a.URL+"/agent", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.HangupCall(ctx, second.Sid); err != nil {
+		t.Fatal(err)
+	}
+
+	log, _ := srv.WaitCall(ctx, first.Sid)
+	if want := []string{"Enqueue", "Say"}; !reflect.DeepEqual(log.Verbs(), want) || log.Steps[0].Result != "redirected" {
+		t.Errorf("redirected call steps = %+v", log.Steps)
+	}
+	log, _ = srv.WaitCall(ctx, second.Sid)
+	if len(log.Steps) != 1 || log.Steps[0].Result != "hangup" || log.Call.Status != texml.CallStatusCompleted {
+		t.Errorf("hung up call = %+v, steps = %+v", log.Call, log.Steps)
+	}
+
+	if _, err := client.HangupCall(ctx, second.Sid); err == nil {
+		t.Error("a call that had ended was updated")
+	}
+}
+
+func TestRedirectLoop(t *testing.T) {
+	srv := newServer(t)
+	ctx := testContext(t)
+	a := newTeXMLApp(t, map[string]string{"/voice": `<Redirect>/voice</Redirect>`})
+	newApplication(t, srv, a, "+13125550100")
+
+	call, _ := srv.Call(ctx, "+13125550199", "+13125550100")
+	log, _ := srv.WaitCall(ctx, call.Sid)
+	if log.Err == nil || len(log.Steps) != maxDocuments {
+		t.Errorf("Err = %v after %d steps", log.Err, len(log.Steps))
+	}
+	if errs := srv.Errors(); len(errs) != 1 {
+		t.Errorf("Errors() = %v", errs)
+	}
+
+	if _, err := srv.Call(ctx, "+13125550199", "+13125550101"); err == nil {
+		t.Error("a call to an unassigned number was placed")
+	}
+}
@@ -0,0 +1,267 @@
+package telnyxtest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/andersryanc/telnyx-go/messaging"
+	"github.com/andersryanc/telnyx-go/numbers"
+)
+
+// ReceiveMessage delivers the message.received webhook of an SMS from from to to, which
+// must be assigned to a messaging profile with a webhook URL, and returns the message.
+func (s *Server) ReceiveMessage(ctx context.Context, from, to, text string) (*messaging.Message, error) {
+	s.mu.Lock()
+	number := find(s.phoneNumbers, func(n *numbers.PhoneNumber) bool { return n.PhoneNumber == to })
+	var profile *messaging.Profile
+	if number != nil {
+		profile = s.findProfileLocked(number.MessagingProfileID)
+	}
+	if profile == nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("telnyxtest: %s is not assigned to a messaging profile", to)
+	}
+	parts, encoding := messaging.CountParts(text)
+	msg := &messaging.Message{
+		ID:                 newID(),
+		RecordType:         "message",
+		Direction:          "inbound",
+		Type:               "SMS",
+		MessagingProfileID: profile.ID,
+		From:               messaging.Endpoint{PhoneNumber: from},
+		To:                 []messaging.Endpoint{{PhoneNumber: to, Status: "webhook_delivered"}},
+		Text:               text,
+		Encoding:           encoding,
+		Parts:              parts,
+		WebhookURL:         profile.WebhookURL,
+		ReceivedAt:         now(),
+	}
+	s.messages = append(s.messages, msg)
+	payload := messaging.MessageReceived{Message: *msg}
+	s.mu.Unlock()
+
+	if err := s.SendWebhook(ctx, payload.WebhookURL, payload.EventType(), payload); err != nil {
+		return nil, err
+	}
+	return &payload.Message, nil
+}
+
+func (s *Server) sendMessage(w http.ResponseWriter, r *http.Request, args []string) {
+	var params messaging.SendParams
+	if !decode(w, r, &params) {
+		return
+	}
+	if params.From == "" {
+		invalid(w, "from", "from is required.")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	profileID := params.MessagingProfileID
+	if number := find(s.phoneNumbers, func(n *numbers.PhoneNumber) bool { return n.PhoneNumber == params.From }); number != nil && profileID == "" {
+		profileID = number.MessagingProfileID
+	}
+	profile := s.findProfileLocked(profileID)
+	if profile == nil {
+		invalid(w, "from", "The sender "+params.From+" is not associated with a messaging profile.")
+		return
+	}
+	s.sendLocked(w, &params, profile)
+}
+
+// sendFromNumberPool sends from the first number assigned to the messaging profile.
+func (s *Server) sendFromNumberPool(w http.ResponseWriter, r *http.Request, args []string) {
+	var params messaging.SendParams
+	if !decode(w, r, &params) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	profile := s.findProfileLocked(params.MessagingProfileID)
+	if profile == nil {
+		invalid(w, "messaging_profile_id", "Messaging profile "+params.MessagingProfileID+" not found.")
+		return
+	}
+	number := find(s.phoneNumbers, func(n *numbers.PhoneNumber) bool { return n.MessagingProfileID == profile.ID })
+	if number == nil {
+		invalid(w, "messaging_profile_id", "Messaging profile "+profile.ID+" has no phone numbers.")
+		return
+	}
+	params.From = number.PhoneNumber
+	s.sendLocked(w, &params, profile)
+}
+
+// sendLocked stores an outbound message, replies with it, and queues its message.sent
+// and message.finalized webhooks. Messages are delivered at once.
+func (s *Server) sendLocked(w http.ResponseWriter, params *messaging.SendParams, profile *messaging.Profile) {
+	switch {
+	case params.To == "":
+		invalid(w, "to", "to is required.")
+		return
+	case params.Text == "" && len(params.MediaURLs) == 0:
+		invalid(w, "text", "text or media_urls is required.")
+		return
+	}
+	msgType := params.Type
+	if msgType == "" {
+		msgType = "SMS"
+		if len(params.MediaURLs) > 0 {
+			msgType = "MMS"
+		}
+	}
+	webhookURL := params.WebhookURL
+	if webhookURL == "" && (params.UseProfileWebhooks == nil || *params.UseProfileWebhooks) {
+		webhookURL = profile.WebhookURL
+	}
+	parts, encoding := messaging.CountParts(params.Text)
+	msg := messaging.Message{
+		ID:                 newID(),
+		RecordType:         "message",
+		Direction:          "outbound",
+		Type:               msgType,
+		MessagingProfileID: profile.ID,
+		From:               messaging.Endpoint{PhoneNumber: params.From},
+		To:                 []messaging.Endpoint{{PhoneNumber: params.To, Status: "queued"}},
+		Text:               params.Text,
+		Subject:            params.Subject,
+		Encoding:           encoding,
+		Parts:              parts,
+		WebhookURL:         webhookURL,
+		WebhookFailoverURL: params.WebhookFailoverURL,
+	}
+	for _, u := range params.MediaURLs {
+		msg.Media = append(msg.Media, messaging.Media{URL: u})
+	}
+	writeData(w, http.StatusOK, msg)
+
+	msg.To = []messaging.Endpoint{{PhoneNumber: params.To, Status: "sent"}}
+	msg.SentAt = now()
+	s.webhooks.send(webhookURL, "message.sent", messaging.MessageSent{Message: msg})
+	msg.To = []messaging.Endpoint{{PhoneNumber: params.To, Status: "delivered"}}
+	msg.CompletedAt = now()
+	s.webhooks.send(webhookURL, "message.finalized", messaging.MessageFinalized{Message: msg})
+	s.messages = append(s.messages, &msg)
+}
+
+func (s *Server) getMessage(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg := find(s.messages, func(m *messaging.Message) bool { return m.ID == args[0] })
+	if msg == nil {
+		notFound(w, "Message", args[0])
+		return
+	}
+	writeData(w, http.StatusOK, *msg)
+}
+
+func (s *Server) findProfileLocked(id string) *messaging.Profile {
+	return find(s.profiles, func(p *messaging.Profile) bool { return p.ID == id })
+}
+
+func applyProfileParams(p *messaging.Profile, params *messaging.ProfileParams) {
+	if params.Name != "" {
+		p.Name = params.Name
+	}
+	if params.Enabled != nil {
+		p.Enabled = *params.Enabled
+	}
+	if params.WebhookURL != "" {
+		p.WebhookURL = params.WebhookURL
+	}
+	if params.WebhookFailoverURL != "" {
+		p.WebhookFailoverURL = params.WebhookFailoverURL
+	}
+	if params.WebhookAPIVersion != "" {
+		p.WebhookAPIVersion = params.WebhookAPIVersion
+	}
+	if params.WhitelistedDestinations != nil {
+		p.WhitelistedDestinations = params.WhitelistedDestinations
+	}
+	if params.NumberPoolSettings != nil {
+		p.NumberPoolSettings = params.NumberPoolSettings
+	}
+	p.UpdatedAt = now()
+}
+
+func (s *Server) createProfile(w http.ResponseWriter, r *http.Request, args []string) {
+	var params messaging.ProfileParams
+	if !decode(w, r, &params) {
+		return
+	}
+	switch {
+	case params.Name == "":
+		invalid(w, "name", "name is required.")
+		return
+	case len(params.WhitelistedDestinations) == 0:
+		invalid(w, "whitelisted_destinations", "whitelisted_destinations is required.")
+		return
+	}
+	p := &messaging.Profile{
+		ID:                newID(),
+		RecordType:        "messaging_profile",
+		Enabled:           true,
+		WebhookAPIVersion: "2",
+		CreatedAt:         now(),
+	}
+	applyProfileParams(p, &params)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profiles = append(s.profiles, p)
+	writeData(w, http.StatusCreated, *p)
+}
+
+func (s *Server) listProfiles(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	profiles := make([]messaging.Profile, len(s.profiles))
+	for i, p := range s.profiles {
+		profiles[i] = *p
+	}
+	s.mu.Unlock()
+	writeList(w, r, profiles)
+}
+
+func (s *Server) getProfile(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.findProfileLocked(args[0])
+	if p == nil {
+		notFound(w, "Messaging profile", args[0])
+		return
+	}
+	writeData(w, http.StatusOK, *p)
+}
+
+func (s *Server) updateProfile(w http.ResponseWriter, r *http.Request, args []string) {
+	var params messaging.ProfileParams
+	if !decode(w, r, &params) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.findProfileLocked(args[0])
+	if p == nil {
+		notFound(w, "Messaging profile", args[0])
+		return
+	}
+	applyProfileParams(p, &params)
+	writeData(w, http.StatusOK, *p)
+}
+
+func (s *Server) deleteProfile(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.findProfileLocked(args[0])
+	if p == nil {
+		notFound(w, "Messaging profile", args[0])
+		return
+	}
+	s.profiles = remove(s.profiles, p)
+	for _, n := range s.phoneNumbers {
+		if n.MessagingProfileID == p.ID {
+			n.MessagingProfileID, n.MessagingProfileName = "", ""
+		}
+	}
+	writeData(w, http.StatusOK, *p)
+}
@@ -0,0 +1,286 @@
+package telnyxtest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andersryanc/telnyx-go/numbers"
+)
+
+// AddPhoneNumber adds number to the account, assigned to connectionID, which may be
+// empty or the ID of a TeXML application, and returns it.
+func (s *Server) AddPhoneNumber(number, connectionID string) *numbers.PhoneNumber {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.addPhoneNumberLocked(number, connectionID, "")
+	info := *n
+	return &info
+}
+
+func (s *Server) addPhoneNumberLocked(number, connectionID, messagingProfileID string) *numbers.PhoneNumber {
+	n := &numbers.PhoneNumber{
+		ID:              newID(),
+		RecordType:      "phone_number",
+		PhoneNumber:     number,
+		PhoneNumberType: "local",
+		Status:          "active",
+		PurchasedAt:     now(),
+		CreatedAt:       now(),
+		UpdatedAt:       now(),
+	}
+	s.setConnectionLocked(n, connectionID)
+	s.setMessagingProfileLocked(n, messagingProfileID)
+	s.phoneNumbers = append(s.phoneNumbers, n)
+	return n
+}
+
+func (s *Server) setConnectionLocked(n *numbers.PhoneNumber, connectionID string) {
+	n.ConnectionID, n.ConnectionName = connectionID, ""
+	if app := s.findApplicationLocked(connectionID); app != nil {
+		n.ConnectionName = app.FriendlyName
+	}
+}
+
+func (s *Server) setMessagingProfileLocked(n *numbers.PhoneNumber, profileID string) {
+	n.MessagingProfileID, n.MessagingProfileName = profileID, ""
+	if p := s.findProfileLocked(profileID); p != nil {
+		n.MessagingProfileName = p.Name
+	}
+}
+
+func (s *Server) findPhoneNumberLocked(id string) *numbers.PhoneNumber {
+	return find(s.phoneNumbers, func(n *numbers.PhoneNumber) bool { return n.ID == id || n.PhoneNumber == id })
+}
+
+// searchNumbers returns made-up numbers in the 555-01XX range of the requested area
+// code, 312 by default, that are not owned yet.
+func (s *Server) searchNumbers(w http.ResponseWriter, r *http.Request, args []string) {
+	q := r.URL.Query()
+	if country := q.Get("filter[country_code]"); country != "" && country != "US" {
+		writeData(w, http.StatusOK, []numbers.AvailableNumber{})
+		return
+	}
+	limit, _ := strconv.Atoi(q.Get("filter[limit]"))
+	if limit <= 0 {
+		limit = 10
+	}
+	numberType := q.Get("filter[phone_number_type]")
+	ndc := q.Get("filter[national_destination_code]")
+	switch {
+	case ndc != "":
+	case numberType == "toll_free":
+		ndc = "888"
+	default:
+		ndc = "312"
+		numberType = "local"
+	}
+	state, locality := q.Get("filter[administrative_area]"), q.Get("filter[locality]")
+	if state == "" {
+		state = "IL"
+	}
+	if locality == "" {
+		locality = "Chicago"
+	}
+	features := []numbers.NumberFeature{{Name: numbers.FeatureVoice}, {Name: numbers.FeatureSMS}, {Name: numbers.FeatureMMS}}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	available := []numbers.AvailableNumber{}
+	for i := 0; i < 100 && len(available) < limit; i++ {
+		national := fmt.Sprintf("%s55501%02d", ndc, i)
+		number := "+1" + national
+		switch {
+		case s.findPhoneNumberLocked(number) != nil:
+			continue
+		case !strings.Contains(national, q.Get("filter[phone_number][contains]")):
+			continue
+		case !strings.HasPrefix(national, q.Get("filter[phone_number][starts_with]")):
+			continue
+		case !strings.HasSuffix(national, q.Get("filter[phone_number][ends_with]")):
+			continue
+		}
+		available = append(available, numbers.AvailableNumber{
+			RecordType:  "available_phone_number",
+			PhoneNumber: number,
+			Reservable:  true,
+			RegionInformation: []numbers.Region{
+				{RegionType: "country_code", RegionName: "US"},
+				{RegionType: "state", RegionName: state},
+				{RegionType: "location", RegionName: locality},
+			},
+			CostInformation: numbers.CostInformation{UpfrontCost: "1.00", MonthlyCost: "1.00", Currency: "USD"},
+			Features:        features,
+		})
+	}
+	writeData(w, http.StatusOK, available)
+}
+
+// createOrder fulfills an order at once: its numbers are active when it returns.
+func (s *Server) createOrder(w http.ResponseWriter, r *http.Request, args []string) {
+	var params struct {
+		numbers.OrderParams
+		PhoneNumbers []struct {
+			PhoneNumber string `json:"phone_number"`
+		} `json:"phone_numbers"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+	if len(params.PhoneNumbers) == 0 {
+		invalid(w, "phone_numbers", "phone_numbers is required.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, n := range params.PhoneNumbers {
+		if s.findPhoneNumberLocked(n.PhoneNumber) != nil {
+			invalid(w, "phone_numbers", n.PhoneNumber+" is not available.")
+			return
+		}
+	}
+	if params.ConnectionID != "" && s.findApplicationLocked(params.ConnectionID) == nil {
+		invalid(w, "connection_id", "Connection "+params.ConnectionID+" not found.")
+		return
+	}
+	if params.MessagingProfileID != "" && s.findProfileLocked(params.MessagingProfileID) == nil {
+		invalid(w, "messaging_profile_id", "Messaging profile "+params.MessagingProfileID+" not found.")
+		return
+	}
+
+	order := &numbers.Order{
+		ID:                 newID(),
+		RecordType:         "number_order",
+		Status:             numbers.OrderStatusSuccess,
+		PhoneNumbersCount:  len(params.PhoneNumbers),
+		ConnectionID:       params.ConnectionID,
+		MessagingProfileID: params.MessagingProfileID,
+		BillingGroupID:     params.BillingGroupID,
+		CustomerReference:  params.CustomerReference,
+		RequirementsMet:    true,
+		CreatedAt:          now(),
+		UpdatedAt:          now(),
+	}
+	for _, n := range params.PhoneNumbers {
+		number := s.addPhoneNumberLocked(n.PhoneNumber, params.ConnectionID, params.MessagingProfileID)
+		number.BillingGroupID = params.BillingGroupID
+		number.CustomerReference = params.CustomerReference
+		order.PhoneNumbers = append(order.PhoneNumbers, numbers.OrderNumber{
+			ID:              number.ID,
+			PhoneNumber:     number.PhoneNumber,
+			Status:          string(numbers.OrderStatusSuccess),
+			RequirementsMet: true,
+		})
+	}
+	s.orders = append(s.orders, order)
+	writeData(w, http.StatusOK, *order)
+}
+
+func (s *Server) getOrder(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	order := find(s.orders, func(o *numbers.Order) bool { return o.ID == args[0] })
+	if order == nil {
+		notFound(w, "Number order", args[0])
+		return
+	}
+	writeData(w, http.StatusOK, *order)
+}
+
+func (s *Server) listPhoneNumbers(w http.ResponseWriter, r *http.Request, args []string) {
+	q := r.URL.Query()
+	s.mu.Lock()
+	var list []numbers.PhoneNumber
+	for _, n := range s.phoneNumbers {
+		switch {
+		case !strings.Contains(n.PhoneNumber, q.Get("filter[phone_number]")):
+			continue
+		case q.Get("filter[status]") != "" && n.Status != q.Get("filter[status]"):
+			continue
+		case q.Get("filter[tag]") != "" && !contains(n.Tags, q.Get("filter[tag]")):
+			continue
+		case q.Get("filter[connection_id]") != "" && n.ConnectionID != q.Get("filter[connection_id]"):
+			continue
+		}
+		list = append(list, *n)
+	}
+	s.mu.Unlock()
+	writeList(w, r, list)
+}
+
+func (s *Server) getPhoneNumber(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.findPhoneNumberLocked(args[0])
+	if n == nil {
+		notFound(w, "Phone number", args[0])
+		return
+	}
+	writeData(w, http.StatusOK, *n)
+}
+
+func (s *Server) updatePhoneNumber(w http.ResponseWriter, r *http.Request, args []string) {
+	var params numbers.UpdatePhoneNumberParams
+	if !decode(w, r, &params) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.findPhoneNumberLocked(args[0])
+	if n == nil {
+		notFound(w, "Phone number", args[0])
+		return
+	}
+	if params.ConnectionID != "" {
+		s.setConnectionLocked(n, params.ConnectionID)
+	}
+	if params.BillingGroupID != "" {
+		n.BillingGroupID = params.BillingGroupID
+	}
+	if params.CustomerReference != "" {
+		n.CustomerReference = params.CustomerReference
+	}
+	if params.Tags != nil {
+		n.Tags = params.Tags
+	}
+	n.UpdatedAt = now()
+	writeData(w, http.StatusOK, *n)
+}
+
+func (s *Server) updatePhoneNumberMessaging(w http.ResponseWriter, r *http.Request, args []string) {
+	var params struct {
+		MessagingProfileID string `json:"messaging_profile_id"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.findPhoneNumberLocked(args[0])
+	if n == nil {
+		notFound(w, "Phone number", args[0])
+		return
+	}
+	if params.MessagingProfileID != "" && s.findProfileLocked(params.MessagingProfileID) == nil {
+		invalid(w, "messaging_profile_id", "Messaging profile "+params.MessagingProfileID+" not found.")
+		return
+	}
+	s.setMessagingProfileLocked(n, params.MessagingProfileID)
+	n.UpdatedAt = now()
+	writeData(w, http.StatusOK, *n)
+}
+
+func (s *Server) deletePhoneNumber(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.findPhoneNumberLocked(args[0])
+	if n == nil {
+		notFound(w, "Phone number", args[0])
+		return
+	}
+	s.phoneNumbers = remove(s.phoneNumbers, n)
+	n.Status = "deleted"
+	writeData(w, http.StatusOK, *n)
+}
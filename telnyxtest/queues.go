@@ -0,0 +1,392 @@
+package telnyxtest
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andersryanc/telnyx-go/texml"
+	"github.com/beevik/etree"
+)
+
+// defaultQueueSize is the MaxSize of queues created without one.
+const defaultQueueSize = 100
+
+type queue struct {
+	info    texml.Queue
+	members []*member
+}
+
+// member is a call waiting in a queue. release receives how it leaves the queue when
+// it is taken out by an agent or the REST API.
+type member struct {
+	call     *call
+	enqueued time.Time
+	release  chan queueRelease
+}
+
+type queueRelease struct {
+	result string
+	// next is the document of a call dequeued through the REST API.
+	next *source
+}
+
+func (s *Server) newQueueLocked(name string, maxSize int) *queue {
+	sid := newID()
+	q := &queue{info: texml.Queue{
+		Sid:          sid,
+		AccountSid:   s.AccountSid,
+		FriendlyName: name,
+		MaxSize:      maxSize,
+		DateCreated:  texmlDate(now()),
+		DateUpdated:  texmlDate(now()),
+		Uri:          "/v2/texml/Accounts/" + s.AccountSid + "/Queues/" + sid,
+	}}
+	s.queues = append(s.queues, q)
+	return q
+}
+
+// snapshotLocked returns the queue with its current size and average wait time.
+func (q *queue) snapshotLocked() texml.Queue {
+	info := q.info
+	info.CurrentSize = len(q.members)
+	if len(q.members) > 0 {
+		total := 0
+		for _, m := range q.members {
+			total += m.waitTime()
+		}
+		info.AverageWaitTime = total / len(q.members)
+	}
+	return info
+}
+
+func (q *queue) memberLocked(i int) texml.QueueMember {
+	m := q.members[i]
+	return texml.QueueMember{
+		CallSid:      m.call.info.CallSid,
+		QueueSid:     q.info.Sid,
+		Position:     i + 1,
+		WaitTime:     m.waitTime(),
+		DateEnqueued: texmlDate(m.enqueued),
+		Uri:          q.info.Uri + "/Members/" + m.call.info.CallSid,
+	}
+}
+
+func (m *member) waitTime() int {
+	return int(time.Since(m.enqueued).Seconds())
+}
+
+// removeLocked takes m out of the queue and reports whether it was still in it.
+func (q *queue) removeLocked(m *member) bool {
+	for i, it := range q.members {
+		if it == m {
+			q.members = append(q.members[:i], q.members[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// enqueue runs <Enqueue>: the call waits in the queue until an agent or the REST API
+// takes it out, the call is updated, or the waitUrl makes it <Leave>.
+func (c *call) enqueue(el *etree.Element, base *url.URL) (*source, bool) {
+	name := strings.TrimSpace(el.Text())
+	s := c.s
+
+	s.mu.Lock()
+	q := find(s.queues, func(q *queue) bool { return q.info.FriendlyName == name })
+	if q == nil {
+		q = s.newQueueLocked(name, defaultQueueSize)
+	}
+	m := &member{call: c, enqueued: now(), release: make(chan queueRelease, 1)}
+	full := len(q.members) >= q.info.MaxSize
+	if !full {
+		q.members = append(q.members, m)
+	}
+	waitParams := url.Values{
+		"QueueSid":         {q.info.Sid},
+		"QueuePosition":    {strconv.Itoa(len(q.members))},
+		"CurrentQueueSize": {strconv.Itoa(len(q.members))},
+		"MaxQueueSize":     {strconv.Itoa(q.info.MaxSize)},
+		"QueueTime":        {"0"},
+		"AvgQueueTime":     {strconv.Itoa(q.snapshotLocked().AverageWaitTime)},
+	}
+	queueSid := q.info.Sid
+	s.mu.Unlock()
+
+	result := "queue-full"
+	if !full {
+		var next *source
+		var leave bool
+		result, next, leave = c.waitInQueue(el, base, q, m, waitParams)
+		if leave {
+			c.addStep(el, name, result)
+			return next, true
+		}
+	}
+	c.addStep(el, name, result)
+
+	if action := el.SelectAttrValue("action", ""); action != "" {
+		params := url.Values{
+			"QueueResult": {result},
+			"QueueSid":    {queueSid},
+			"QueueTime":   {strconv.Itoa(m.waitTime())},
+		}
+		next := resolve(base, action, el.SelectAttrValue("method", ""), params)
+		if result != "hangup" {
+			return next, true
+		}
+		// The action of a caller that hung up is notified, but its document is not run.
+		p := c.params()
+		for k, v := range next.params {
+			p[k] = v
+		}
+		s.webhooks.sendForm(next.method, next.url, p)
+	}
+	return nil, result == "hangup"
+}
+
+// waitInQueue returns the QueueResult of a call in a queue. leave is set, with the next
+// document, when the call continues without calling the action of <Enqueue>. The
+// waitUrl is requested once, with params.
+func (c *call) waitInQueue(el *etree.Element, base *url.URL, q *queue, m *member, params url.Values) (result string, next *source, leave bool) {
+	s := c.s
+	if waitURL := el.SelectAttrValue("waitUrl", ""); waitURL != "" {
+		verbs := c.play(resolve(base, waitURL, el.SelectAttrValue("waitUrlMethod", ""), nil), params)
+		if contains(verbs, "Leave") || contains(verbs, "Hangup") {
+			s.mu.Lock()
+			removed := q.removeLocked(m)
+			s.mu.Unlock()
+			if removed {
+				if contains(verbs, "Hangup") {
+					return "hangup", nil, false
+				}
+				return "leave", nil, false
+			}
+		}
+	}
+
+	for {
+		select {
+		case rel := <-m.release:
+			if rel.next != nil {
+				return rel.result, rel.next, true
+			}
+			return rel.result, nil, false
+		case <-c.wake:
+			if next, ok := c.takeUpdate(); ok {
+				s.mu.Lock()
+				q.removeLocked(m)
+				s.mu.Unlock()
+				if next == nil {
+					return "hangup", nil, false
+				}
+				return "redirected", next, true
+			}
+		case <-s.ctx.Done():
+			return "system-shutdown", nil, true
+		}
+	}
+}
+
+// dialQueue runs <Dial><Queue>: the agent call takes the caller waiting the longest,
+// who first hears the document at the url attribute.
+func (c *call) dialQueue(el *etree.Element, name string, base *url.URL) string {
+	s := c.s
+	s.mu.Lock()
+	q := find(s.queues, func(q *queue) bool { return q.info.FriendlyName == name })
+	var m *member
+	if q != nil && len(q.members) > 0 {
+		m = q.members[0]
+		q.members = q.members[1:]
+	}
+	s.mu.Unlock()
+	if m == nil {
+		return string(texml.CallStatusNoAnswer)
+	}
+
+	if whisper := el.SelectAttrValue("url", ""); whisper != "" {
+		m.call.play(resolve(base, whisper, el.SelectAttrValue("method", ""), nil), url.Values{
+			"QueueSid":          {q.info.Sid},
+			"DequeueingCallSid": {c.info.CallSid},
+			"QueueTime":         {strconv.Itoa(m.waitTime())},
+		})
+	}
+	m.release <- queueRelease{result: "bridged"}
+	return string(texml.CallStatusCompleted)
+}
+
+type queueParams struct {
+	FriendlyName string `json:"FriendlyName"`
+	MaxSize      *int   `json:"MaxSize"`
+}
+
+func (s *Server) findQueueLocked(sid string) *queue {
+	return find(s.queues, func(q *queue) bool { return q.info.Sid == sid })
+}
+
+func (s *Server) createQueue(w http.ResponseWriter, r *http.Request, args []string) {
+	var params queueParams
+	if !decode(w, r, &params) {
+		return
+	}
+	if params.FriendlyName == "" {
+		invalid(w, "FriendlyName", "FriendlyName is required.")
+		return
+	}
+	maxSize := defaultQueueSize
+	if params.MaxSize != nil {
+		maxSize = *params.MaxSize
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if find(s.queues, func(q *queue) bool { return q.info.FriendlyName == params.FriendlyName }) != nil {
+		invalid(w, "FriendlyName", "A queue named "+params.FriendlyName+" already exists.")
+		return
+	}
+	q := s.newQueueLocked(params.FriendlyName, maxSize)
+	writeJSON(w, http.StatusCreated, q.snapshotLocked())
+}
+
+func (s *Server) listQueues(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	queues := make([]texml.Queue, len(s.queues))
+	for i, q := range s.queues {
+		queues[i] = q.snapshotLocked()
+	}
+	s.mu.Unlock()
+	writeAccountList(w, r, "queues", queues)
+}
+
+func (s *Server) getQueue(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := s.findQueueLocked(args[1])
+	if q == nil {
+		notFound(w, "Queue", args[1])
+		return
+	}
+	writeJSON(w, http.StatusOK, q.snapshotLocked())
+}
+
+func (s *Server) updateQueue(w http.ResponseWriter, r *http.Request, args []string) {
+	var params queueParams
+	if !decode(w, r, &params) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := s.findQueueLocked(args[1])
+	if q == nil {
+		notFound(w, "Queue", args[1])
+		return
+	}
+	if params.FriendlyName != "" {
+		q.info.FriendlyName = params.FriendlyName
+	}
+	if params.MaxSize != nil {
+		q.info.MaxSize = *params.MaxSize
+	}
+	q.info.DateUpdated = texmlDate(now())
+	writeJSON(w, http.StatusOK, q.snapshotLocked())
+}
+
+func (s *Server) deleteQueue(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := s.findQueueLocked(args[1])
+	if q == nil {
+		notFound(w, "Queue", args[1])
+		return
+	}
+	if len(q.members) > 0 {
+		invalid(w, "QueueSid", "Queue "+args[1]+" is not empty.")
+		return
+	}
+	s.queues = remove(s.queues, q)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listQueueMembers(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	q := s.findQueueLocked(args[1])
+	if q == nil {
+		s.mu.Unlock()
+		notFound(w, "Queue", args[1])
+		return
+	}
+	members := make([]texml.QueueMember, len(q.members))
+	for i := range q.members {
+		members[i] = q.memberLocked(i)
+	}
+	s.mu.Unlock()
+	writeAccountList(w, r, "queue_members", members)
+}
+
+// findMemberLocked returns the position of the member callSid, which may be
+// texml.FrontOfQueue, or -1.
+func (q *queue) findMemberLocked(callSid string) int {
+	if callSid == texml.FrontOfQueue {
+		if len(q.members) == 0 {
+			return -1
+		}
+		return 0
+	}
+	for i, m := range q.members {
+		if m.call.info.CallSid == callSid {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) getQueueMember(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := s.findQueueLocked(args[1])
+	if q == nil {
+		notFound(w, "Queue", args[1])
+		return
+	}
+	i := q.findMemberLocked(args[2])
+	if i < 0 {
+		notFound(w, "Queue member", args[2])
+		return
+	}
+	writeJSON(w, http.StatusOK, q.memberLocked(i))
+}
+
+func (s *Server) dequeueMember(w http.ResponseWriter, r *http.Request, args []string) {
+	var params struct {
+		Url    string `json:"Url"`
+		Method string `json:"Method"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+	if params.Url == "" {
+		invalid(w, "Url", "Url is required.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := s.findQueueLocked(args[1])
+	if q == nil {
+		notFound(w, "Queue", args[1])
+		return
+	}
+	i := q.findMemberLocked(args[2])
+	if i < 0 {
+		notFound(w, "Queue member", args[2])
+		return
+	}
+	resp := q.memberLocked(i)
+	m := q.members[i]
+	q.members = append(q.members[:i], q.members[i+1:]...)
+	m.release <- queueRelease{result: "redirected", next: &source{url: params.Url, method: params.Method}}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,108 @@
+package telnyxtest
+
+import (
+	"reflect"
+	"testing"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+	"github.com/andersryanc/telnyx-go/texml"
+)
+
+func TestQueue(t *testing.T) {
+	srv := newServer(t)
+	ctx := testContext(t)
+	a := newTeXMLApp(t, map[string]string{
+		"/voice":   `<Enqueue waitUrl="/hold" action="/after">support</Enqueue>`,
+		"/hold":    `<Play>https://example.com/music.mp3</Play>`,
+		"/agent":   `<Dial><Queue url="/whisper">support</Queue></Dial>`,
+		"/whisper": `<Say>Connecting you to an agent</Say>`,
+		"/after":   `<Hangup/>`,
+	})
+	client, app := newApplication(t, srv, a, "+13125550100")
+
+	queue, err := client.CreateQueue(ctx, &texml.QueueParams{FriendlyName: "support", MaxSize: telnyx.Int(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateQueue(ctx, &texml.QueueParams{FriendlyName: "support"}); err == nil {
+		t.Error("a second queue with the same name was created")
+	}
+
+	caller, _ := srv.Call(ctx, "+13125550198", "+13125550100")
+	waitFor(t, "the caller to be queued", func() bool {
+		_, err := client.GetQueueMember(ctx, queue.Sid, caller.Sid)
+		return err == nil
+	})
+	// The queue holds a single call.
+	full, _ := srv.Call(ctx, "+13125550199", "+13125550100")
+	log, _ := srv.WaitCall(ctx, full.Sid)
+	if log.Steps[0].Result != "queue-full" {
+		t.Errorf("Enqueue into a full queue = %+v", log.Steps[0])
+	}
+	if err := client.DeleteQueue(ctx, queue.Sid); err == nil {
+		t.Error("a queue with a caller in it was deleted")
+	}
+
+	agent, err := client.CreateCall(ctx, app.ID, &texml.CallParams{From: "+13125550100", To: "+13125550111", Url: a.URL + "/agent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if log, _ := srv.WaitCall(ctx, agent.Sid); log.Steps[0].Text != "support" || log.Steps[0].Result != "completed" {
+		t.Errorf("agent Dial = %+v", log.Steps[0])
+	}
+	log, _ = srv.WaitCall(ctx, caller.Sid)
+	if want := []string{"Play", "Say", "Enqueue", "Hangup"}; !reflect.DeepEqual(log.Verbs(), want) {
+		t.Errorf("caller verbs = %q, want %q", log.Verbs(), want)
+	}
+	if hold := a.received("/hold"); len(hold) != 1 || hold[0].Get("QueueSid") != queue.Sid || hold[0].Get("QueuePosition") != "1" {
+		t.Errorf("/hold requests = %v", hold)
+	}
+	whisper := a.received("/whisper")
+	if len(whisper) != 1 || whisper[0].Get("DequeueingCallSid") != agent.Sid || whisper[0].Get("CallSid") != caller.Sid {
+		t.Errorf("/whisper requests = %v", whisper)
+	}
+	after := a.received("/after")
+	if len(after) != 2 || after[0].Get("QueueResult") != "queue-full" || after[1].Get("QueueResult") != "bridged" {
+		t.Errorf("/after requests = %v", after)
+	}
+
+	if err := client.DeleteQueue(ctx, queue.Sid); err != nil {
+		t.Errorf("DeleteQueue of an empty queue = %v", err)
+	}
+}
+
+func TestDequeueMember(t *testing.T) {
+	srv := newServer(t)
+	ctx := testContext(t)
+	a := newTeXMLApp(t, map[string]string{
+		"/voice":    `<Enqueue>sales</Enqueue>`,
+		"/callback": `<Say>We will call you back</Say>`,
+	})
+	client, _ := newApplication(t, srv, a, "+13125550100")
+
+	caller, _ := srv.Call(ctx, "+13125550199", "+13125550100")
+	var queue texml.Queue
+	waitFor(t, "the caller to be queued", func() bool {
+		queues, _ := client.ListQueues(ctx, 0).All()
+		if len(queues) == 1 && queues[0].CurrentSize == 1 {
+			queue = queues[0]
+			return true
+		}
+		return false
+	})
+
+	members, err := client.ListQueueMembers(ctx, queue.Sid, 0).All()
+	if err != nil || len(members) != 1 || members[0].CallSid != caller.Sid || members[0].Position != 1 {
+		t.Errorf("ListQueueMembers = %+v, %v", members, err)
+	}
+	if _, err := client.DequeueMember(ctx, queue.Sid, texml.FrontOfQueue, a.URL+"/callback", ""); err != nil {
+		t.Fatal(err)
+	}
+	log, _ := srv.WaitCall(ctx, caller.Sid)
+	if log.Steps[0].Result != "redirected" || !reflect.DeepEqual(log.Said(), []string{"We will call you back"}) {
+		t.Errorf("dequeued call steps = %+v", log.Steps)
+	}
+	if _, err := client.DequeueMember(ctx, queue.Sid, texml.FrontOfQueue, a.URL+"/callback", ""); err == nil {
+		t.Error("a member was dequeued from an empty queue")
+	}
+}
@@ -0,0 +1,189 @@
+package telnyxtest
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andersryanc/telnyx-go/texml"
+	"github.com/andersryanc/telnyx-go/texml/stream/audio"
+)
+
+// addRecording stores a recording of seconds of silence, made by a call or a
+// conference.
+func (s *Server) addRecording(callSid, conferenceSid string, seconds int) *texml.Recording {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addRecordingLocked(callSid, conferenceSid, seconds)
+}
+
+func (s *Server) addRecordingLocked(callSid, conferenceSid string, seconds int) *texml.Recording {
+	rec := s.startRecordingLocked(callSid, conferenceSid)
+	s.finishRecordingLocked(rec, seconds)
+	return rec
+}
+
+// startRecordingLocked stores a recording in progress; finishRecordingLocked completes
+// it with its audio.
+func (s *Server) startRecordingLocked(callSid, conferenceSid string) *texml.Recording {
+	sid := newID()
+	uri := "/v2/texml/Accounts/" + s.AccountSid + "/Recordings/" + sid
+	rec := &texml.Recording{
+		Sid:             sid,
+		AccountSid:      s.AccountSid,
+		CallSid:         callSid,
+		ConferenceSid:   conferenceSid,
+		Channels:        1,
+		Source:          "StartCallRecordingAPI",
+		Status:          "in-progress",
+		StartTime:       texmlDate(now()),
+		DateCreated:     texmlDate(now()),
+		DateUpdated:     texmlDate(now()),
+		SubresourceUris: map[string]string{"transcriptions": uri + "/Transcriptions.json"},
+		Uri:             uri + ".json",
+	}
+	if conferenceSid != "" {
+		rec.Source = "StartConferenceRecordingAPI"
+	}
+	s.recordings = append(s.recordings, rec)
+	return rec
+}
+
+func (s *Server) finishRecordingLocked(rec *texml.Recording, seconds int) {
+	var wav bytes.Buffer
+	if w, err := audio.NewWAVWriter(&wav, 8000, 1); err == nil {
+		w.WriteSamples(make([]int16, 8000*seconds))
+	}
+	rec.Duration = strconv.Itoa(seconds)
+	rec.Status = "completed"
+	rec.MediaUrl = s.server.URL + "/media/" + rec.Sid + ".wav"
+	rec.DateUpdated = texmlDate(now())
+	s.recordingAudio[rec.Sid] = wav.Bytes()
+}
+
+func (s *Server) addTranscription(rec *texml.Recording, text string) *texml.Transcription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sid := newID()
+	tr := &texml.Transcription{
+		Sid:               sid,
+		AccountSid:        s.AccountSid,
+		CallSid:           rec.CallSid,
+		RecordingSid:      rec.Sid,
+		Duration:          rec.Duration,
+		Status:            "completed",
+		TranscriptionText: text,
+		DateCreated:       texmlDate(now()),
+		DateUpdated:       texmlDate(now()),
+		Uri:               "/v2/texml/Accounts/" + s.AccountSid + "/Transcriptions/" + sid + ".json",
+	}
+	s.transcriptions = append(s.transcriptions, tr)
+	return tr
+}
+
+// serveRecording serves the media of a recording, named {sid}.wav. Like the presigned
+// URLs of the API, it needs no API key.
+func (s *Server) serveRecording(w http.ResponseWriter, name string) {
+	s.mu.Lock()
+	data, ok := s.recordingAudio[strings.TrimSuffix(name, ".wav")]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, nil)
+		return
+	}
+	w.Header().Set("Content-Type", "audio/wav")
+	w.Write(data)
+}
+
+func (s *Server) listRecordings(w http.ResponseWriter, r *http.Request, args []string) {
+	q := r.URL.Query()
+	after, _ := time.Parse(time.RFC3339, q.Get("DateCreated>"))
+	before, _ := time.Parse(time.RFC3339, q.Get("DateCreated<"))
+
+	s.mu.Lock()
+	var recordings []texml.Recording
+	for _, rec := range s.recordings {
+		if sid := q.Get("CallSid"); sid != "" && rec.CallSid != sid {
+			continue
+		}
+		if sid := q.Get("ConferenceSid"); sid != "" && rec.ConferenceSid != sid {
+			continue
+		}
+		created, _ := time.Parse(time.RFC1123Z, rec.DateCreated)
+		if (!after.IsZero() && created.Before(after)) || (!before.IsZero() && created.After(before)) {
+			continue
+		}
+		recordings = append(recordings, *rec)
+	}
+	s.mu.Unlock()
+	writeAccountList(w, r, "recordings", recordings)
+}
+
+func (s *Server) getRecording(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec := find(s.recordings, func(rec *texml.Recording) bool { return rec.Sid == args[1] })
+	if rec == nil {
+		notFound(w, "Recording", args[1])
+		return
+	}
+	writeJSON(w, http.StatusOK, rec)
+}
+
+func (s *Server) deleteRecording(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec := find(s.recordings, func(rec *texml.Recording) bool { return rec.Sid == args[1] })
+	if rec == nil {
+		notFound(w, "Recording", args[1])
+		return
+	}
+	s.recordings = remove(s.recordings, rec)
+	delete(s.recordingAudio, rec.Sid)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listTranscriptions(w http.ResponseWriter, r *http.Request, args []string) {
+	s.writeTranscriptions(w, r, "")
+}
+
+func (s *Server) listRecordingTranscriptions(w http.ResponseWriter, r *http.Request, args []string) {
+	s.writeTranscriptions(w, r, args[1])
+}
+
+func (s *Server) writeTranscriptions(w http.ResponseWriter, r *http.Request, recordingSid string) {
+	s.mu.Lock()
+	var transcriptions []texml.Transcription
+	for _, tr := range s.transcriptions {
+		if recordingSid == "" || tr.RecordingSid == recordingSid {
+			transcriptions = append(transcriptions, *tr)
+		}
+	}
+	s.mu.Unlock()
+	writeAccountList(w, r, "transcriptions", transcriptions)
+}
+
+func (s *Server) getTranscription(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tr := find(s.transcriptions, func(tr *texml.Transcription) bool { return tr.Sid == args[1] })
+	if tr == nil {
+		notFound(w, "Transcription", args[1])
+		return
+	}
+	writeJSON(w, http.StatusOK, tr)
+}
+
+func (s *Server) deleteTranscription(w http.ResponseWriter, r *http.Request, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tr := find(s.transcriptions, func(tr *texml.Transcription) bool { return tr.Sid == args[1] })
+	if tr == nil {
+		notFound(w, "Transcription", args[1])
+		return
+	}
+	s.transcriptions = remove(s.transcriptions, tr)
+	w.WriteHeader(http.StatusNoContent)
+}
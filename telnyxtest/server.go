@@ -0,0 +1,416 @@
+// Package telnyxtest is a fake Telnyx platform for integration tests that cannot reach
+// the network. A Server implements the REST endpoints of this module with in-memory
+// state, sends signed webhooks, and runs TeXML calls through a small interpreter:
+//
+//	srv := telnyxtest.NewServer()
+//	defer srv.Close()
+//
+//	client := texml.NewClient(srv.Client(), srv.AccountSid)
+//	call, err := client.CreateCall(ctx, appID, &texml.CallParams{From: "+13125550100", To: "+13125550199"})
+//	log, err := srv.WaitCall(ctx, call.Sid)
+//	fmt.Println(log.Said())
+package telnyxtest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+	"github.com/andersryanc/telnyx-go/messaging"
+	"github.com/andersryanc/telnyx-go/numbers"
+	"github.com/andersryanc/telnyx-go/texml"
+)
+
+// Server is a fake Telnyx API listening on a local address. It is safe for concurrent
+// use.
+type Server struct {
+	// URL is the base URL of the API, to be used as telnyx.ClientParams.BaseURL.
+	URL string
+	// APIKey is the only API key the server accepts.
+	APIKey string
+	// AccountSid is the account of the TeXML calls the server creates. Requests below
+	// /texml/Accounts are accepted for any account.
+	AccountSid string
+	// PublicKey verifies the webhooks the server sends, e.g. with
+	// callcontrol.NewDispatcher.
+	PublicKey ed25519.PublicKey
+
+	server     *httptest.Server
+	privateKey ed25519.PrivateKey
+	// ctx is canceled by Close, which ends the calls waiting in queues and conferences.
+	ctx      context.Context
+	cancel   context.CancelFunc
+	routes   []route
+	webhooks *webhookQueue
+
+	mu           sync.Mutex
+	errs         []error
+	callers      map[string]Caller
+	applications []*texml.Application
+	calls        []*call
+	queues       []*queue
+	conferences  []*conference
+	recordings   []*texml.Recording
+	// recordingAudio holds the WAV files served at the MediaUrl of recordings.
+	recordingAudio map[string][]byte
+	transcriptions []*texml.Transcription
+	ccCalls        []*ccCall
+	messages       []*messaging.Message
+	profiles       []*messaging.Profile
+	phoneNumbers   []*numbers.PhoneNumber
+	orders         []*numbers.Order
+}
+
+// NewServer starts a Server. Close it when done.
+func NewServer() *Server {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic("telnyxtest: generate key: " + err.Error())
+	}
+	s := &Server{
+		APIKey:         "KEYtelnyxtest",
+		AccountSid:     "ACtelnyxtest",
+		PublicKey:      pub,
+		privateKey:     priv,
+		callers:        map[string]Caller{},
+		recordingAudio: map[string][]byte{},
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.routes = s.apiRoutes()
+	s.webhooks = newWebhookQueue(s)
+	s.server = httptest.NewServer(s)
+	s.URL = s.server.URL + "/v2/"
+	return s
+}
+
+// Close stops the server and the calls in progress.
+func (s *Server) Close() {
+	s.cancel()
+	s.webhooks.close()
+	s.server.Close()
+}
+
+// Client returns a telnyx.Client for the server, with retries disabled.
+func (s *Server) Client() *telnyx.Client {
+	client, err := telnyx.NewClientWithParams(telnyx.ClientParams{
+		APIKey:     s.APIKey,
+		BaseURL:    s.URL,
+		HTTPClient: s.server.Client(),
+		MaxRetries: -1,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+// Errors returns the errors of the work the server does in the background: webhooks
+// that could not be delivered, TeXML that could not be fetched or parsed. A test
+// usually checks that there are none once its calls have ended.
+func (s *Server) Errors() []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]error(nil), s.errs...)
+}
+
+func (s *Server) addErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errs = append(s.errs, err)
+}
+
+// route is an endpoint of the API. A "*" segment of pattern matches any segment, and a
+// "*.json" segment any segment with that suffix; handle receives the matched segments,
+// without the suffix.
+type route struct {
+	method  string
+	pattern []string
+	handle  func(w http.ResponseWriter, r *http.Request, args []string)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if sid, ok := strings.CutPrefix(r.URL.Path, "/media/"); ok {
+		s.serveRecording(w, sid)
+		return
+	}
+	path, ok := strings.CutPrefix(r.URL.EscapedPath(), "/v2/")
+	if !ok {
+		writeError(w, http.StatusNotFound, "10005", "Resource not found", "No API at "+r.URL.Path)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer "+s.APIKey {
+		writeError(w, http.StatusUnauthorized, "10009", "Authentication failed", "The API key is missing or invalid.")
+		return
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, seg := range segments {
+		if unescaped, err := url.PathUnescape(seg); err == nil {
+			segments[i] = unescaped
+		}
+	}
+	pathFound := false
+	for _, rt := range s.routes {
+		args, ok := rt.match(segments)
+		if !ok {
+			continue
+		}
+		pathFound = true
+		if rt.method == r.Method {
+			rt.handle(w, r, args)
+			return
+		}
+	}
+	if pathFound {
+		writeError(w, http.StatusMethodNotAllowed, "10006", "Method not allowed", r.Method+" is not supported on "+r.URL.Path)
+		return
+	}
+	writeError(w, http.StatusNotFound, "10005", "Resource not found", "No API at "+r.URL.Path)
+}
+
+func (rt route) match(segments []string) ([]string, bool) {
+	if len(segments) != len(rt.pattern) {
+		return nil, false
+	}
+	var args []string
+	for i, p := range rt.pattern {
+		switch {
+		case p == "*":
+			args = append(args, segments[i])
+		case strings.HasPrefix(p, "*."):
+			arg, ok := strings.CutSuffix(segments[i], p[1:])
+			if !ok {
+				return nil, false
+			}
+			args = append(args, arg)
+		case p != segments[i]:
+			return nil, false
+		}
+	}
+	return args, true
+}
+
+func (s *Server) apiRoutes() []route {
+	var routes []route
+	add := func(method, pattern string, handle func(w http.ResponseWriter, r *http.Request, args []string)) {
+		routes = append(routes, route{method, strings.Split(pattern, "/"), handle})
+	}
+
+	add(http.MethodPost, "texml_applications", s.createApplication)
+	add(http.MethodGet, "texml_applications", s.listApplications)
+	add(http.MethodGet, "texml_applications/*", s.getApplication)
+	add(http.MethodPatch, "texml_applications/*", s.updateApplication)
+	add(http.MethodDelete, "texml_applications/*", s.deleteApplication)
+
+	add(http.MethodPost, "texml/calls/*", s.createCall)
+	add(http.MethodGet, "texml/Accounts/*/Calls/*", s.getCall)
+	add(http.MethodPost, "texml/Accounts/*/Calls/*", s.updateCall)
+
+	add(http.MethodGet, "texml/Accounts/*/Conferences", s.listConferences)
+	add(http.MethodGet, "texml/Accounts/*/Conferences/*", s.getConference)
+	add(http.MethodPost, "texml/Accounts/*/Conferences/*", s.updateConference)
+	add(http.MethodGet, "texml/Accounts/*/Conferences/*/Participants", s.listParticipants)
+	add(http.MethodPost, "texml/Accounts/*/Conferences/*/Participants", s.createParticipant)
+	add(http.MethodGet, "texml/Accounts/*/Conferences/*/Participants/*", s.getParticipant)
+	add(http.MethodPost, "texml/Accounts/*/Conferences/*/Participants/*", s.updateParticipant)
+	add(http.MethodDelete, "texml/Accounts/*/Conferences/*/Participants/*", s.deleteParticipant)
+	add(http.MethodPost, "texml/Accounts/*/Conferences/*/Recordings", s.startConferenceRecording)
+	add(http.MethodPost, "texml/Accounts/*/Conferences/*/Recordings/*", s.stopConferenceRecording)
+
+	add(http.MethodPost, "texml/Accounts/*/Queues", s.createQueue)
+	add(http.MethodGet, "texml/Accounts/*/Queues", s.listQueues)
+	add(http.MethodGet, "texml/Accounts/*/Queues/*", s.getQueue)
+	add(http.MethodPost, "texml/Accounts/*/Queues/*", s.updateQueue)
+	add(http.MethodDelete, "texml/Accounts/*/Queues/*", s.deleteQueue)
+	add(http.MethodGet, "texml/Accounts/*/Queues/*/Members", s.listQueueMembers)
+	add(http.MethodGet, "texml/Accounts/*/Queues/*/Members/*", s.getQueueMember)
+	add(http.MethodPost, "texml/Accounts/*/Queues/*/Members/*", s.dequeueMember)
+
+	add(http.MethodGet, "texml/Accounts/*/Recordings.json", s.listRecordings)
+	add(http.MethodGet, "texml/Accounts/*/Recordings/*.json", s.getRecording)
+	add(http.MethodDelete, "texml/Accounts/*/Recordings/*.json", s.deleteRecording)
+	add(http.MethodGet, "texml/Accounts/*/Recordings/*/Transcriptions.json", s.listRecordingTranscriptions)
+	add(http.MethodGet, "texml/Accounts/*/Transcriptions.json", s.listTranscriptions)
+	add(http.MethodGet, "texml/Accounts/*/Transcriptions/*.json", s.getTranscription)
+	add(http.MethodDelete, "texml/Accounts/*/Transcriptions/*.json", s.deleteTranscription)
+
+	add(http.MethodPost, "calls", s.dial)
+	add(http.MethodGet, "calls/*", s.getCCCall)
+	add(http.MethodPost, "calls/*/actions/*", s.executeCommand)
+
+	add(http.MethodPost, "messages", s.sendMessage)
+	add(http.MethodPost, "messages/number_pool", s.sendFromNumberPool)
+	add(http.MethodGet, "messages/*", s.getMessage)
+	add(http.MethodPost, "messaging_profiles", s.createProfile)
+	add(http.MethodGet, "messaging_profiles", s.listProfiles)
+	add(http.MethodGet, "messaging_profiles/*", s.getProfile)
+	add(http.MethodPatch, "messaging_profiles/*", s.updateProfile)
+	add(http.MethodDelete, "messaging_profiles/*", s.deleteProfile)
+
+	add(http.MethodGet, "available_phone_numbers", s.searchNumbers)
+	add(http.MethodPost, "number_orders", s.createOrder)
+	add(http.MethodGet, "number_orders/*", s.getOrder)
+	add(http.MethodGet, "phone_numbers", s.listPhoneNumbers)
+	add(http.MethodGet, "phone_numbers/*", s.getPhoneNumber)
+	add(http.MethodPatch, "phone_numbers/*", s.updatePhoneNumber)
+	add(http.MethodPatch, "phone_numbers/*/messaging", s.updatePhoneNumberMessaging)
+	add(http.MethodDelete, "phone_numbers/*", s.deletePhoneNumber)
+	return routes
+}
+
+// newID returns a random UUID.
+func newID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// now returns the current time, rounded like the timestamps of the API.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// texmlDate formats t like the dates of the TeXML REST API.
+func texmlDate(t time.Time) string {
+	return t.Format(time.RFC1123Z)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeData(w http.ResponseWriter, status int, v interface{}) {
+	writeJSON(w, status, telnyx.DataResponse[interface{}]{Data: v})
+}
+
+func writeError(w http.ResponseWriter, status int, code, title, detail string) {
+	writeJSON(w, status, map[string][]telnyx.ErrorDetail{
+		"errors": {{Code: code, Title: title, Detail: detail}},
+	})
+}
+
+func notFound(w http.ResponseWriter, kind, id string) {
+	writeError(w, http.StatusNotFound, "10005", "Resource not found", fmt.Sprintf("%s %s not found.", kind, id))
+}
+
+// invalid reports a missing or invalid field of the request.
+func invalid(w http.ResponseWriter, field, detail string) {
+	writeJSON(w, http.StatusUnprocessableEntity, map[string][]telnyx.ErrorDetail{
+		"errors": {{
+			Code:   "10015",
+			Title:  "Invalid value",
+			Detail: detail,
+			Source: &telnyx.ErrorSource{Pointer: "/" + field},
+		}},
+	})
+}
+
+// decode reads the JSON body of r into v, and replies with an error if it cannot.
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "10002", "Invalid JSON", err.Error())
+		return false
+	}
+	return true
+}
+
+// writeList writes a page of a v2 list, selected by page[number] and page[size].
+func writeList[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	q := r.URL.Query()
+	size, _ := strconv.Atoi(q.Get("page[size]"))
+	if size <= 0 {
+		size = 20
+	}
+	number, _ := strconv.Atoi(q.Get("page[number]"))
+	if number <= 0 {
+		number = 1
+	}
+	pages := (len(items) + size - 1) / size
+	if pages == 0 {
+		pages = 1
+	}
+
+	page := []T{}
+	if start := (number - 1) * size; start < len(items) {
+		end := start + size
+		if end > len(items) {
+			end = len(items)
+		}
+		page = items[start:end]
+	}
+	writeJSON(w, http.StatusOK, telnyx.ListResponse[T]{
+		Data: page,
+		Meta: telnyx.PageMeta{PageNumber: number, PageSize: size, TotalPages: pages, TotalResults: len(items)},
+	})
+}
+
+// writeAccountList writes a page of a list below /texml/Accounts, selected by Page and
+// PageSize, with the items under key.
+func writeAccountList[T any](w http.ResponseWriter, r *http.Request, key string, items []T) {
+	q := r.URL.Query()
+	size, _ := strconv.Atoi(q.Get("PageSize"))
+	if size <= 0 {
+		size = 50
+	}
+	number, _ := strconv.Atoi(q.Get("Page"))
+	if number < 0 {
+		number = 0
+	}
+
+	page := []T{}
+	if start := number * size; start < len(items) {
+		end := start + size
+		if end > len(items) {
+			end = len(items)
+		}
+		page = items[start:end]
+	}
+	resp := map[string]interface{}{
+		key:             page,
+		"page":          number,
+		"page_size":     size,
+		"uri":           r.URL.RequestURI(),
+		"next_page_uri": nil,
+	}
+	if (number+1)*size < len(items) {
+		q.Set("Page", strconv.Itoa(number+1))
+		q.Set("PageSize", strconv.Itoa(size))
+		resp["next_page_uri"] = r.URL.Path + "?" + q.Encode()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// find returns the first item matching match.
+func find[T any](items []*T, match func(*T) bool) *T {
+	for _, item := range items {
+		if match(item) {
+			return item
+		}
+	}
+	return nil
+}
+
+// remove returns items without item.
+func remove[T any](items []*T, item *T) []*T {
+	kept := items[:0]
+	for _, it := range items {
+		if it != item {
+			kept = append(kept, it)
+		}
+	}
+	return kept
+}
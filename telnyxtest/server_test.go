@@ -0,0 +1,148 @@
+package telnyxtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+	"github.com/andersryanc/telnyx-go/texml"
+)
+
+func newServer(t *testing.T) *Server {
+	t.Helper()
+	srv := NewServer()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// teXMLApp serves TeXML documents by path and records the requests it receives, like
+// the web application behind a TeXML application.
+type teXMLApp struct {
+	*httptest.Server
+	mu       sync.Mutex
+	docs     map[string]string
+	requests map[string][]url.Values
+}
+
+func newTeXMLApp(t *testing.T, docs map[string]string) *teXMLApp {
+	a := &teXMLApp{docs: docs, requests: map[string][]url.Values{}}
+	a.Server = httptest.NewServer(a)
+	t.Cleanup(a.Close)
+	return a
+}
+
+func (a *teXMLApp) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.requests[r.URL.Path] = append(a.requests[r.URL.Path], r.Form)
+	if doc, ok := a.docs[r.URL.Path]; ok {
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Response>` + doc + `</Response>`))
+	}
+}
+
+// received returns the requests made to path.
+func (a *teXMLApp) received(path string) []url.Values {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.requests[path]
+}
+
+// newApplication creates a TeXML application served by a, with its status callback at
+// /status, and assigns number to it.
+func newApplication(t *testing.T, srv *Server, a *teXMLApp, number string) (*texml.Client, *texml.Application) {
+	t.Helper()
+	client := texml.NewClient(srv.Client(), srv.AccountSid)
+	app, err := client.CreateApplication(testContext(t), &texml.ApplicationParams{
+		FriendlyName:   "ivr",
+		VoiceUrl:       a.URL + "/voice",
+		StatusCallback: a.URL + "/status",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if number != "" {
+		srv.AddPhoneNumber(number, app.ID)
+	}
+	return client, app
+}
+
+func TestServerErrors(t *testing.T) {
+	srv := newServer(t)
+	ctx := testContext(t)
+
+	bad, _ := telnyx.NewClientWithParams(telnyx.ClientParams{APIKey: "KEYwrong", BaseURL: srv.URL, MaxRetries: -1})
+	for _, tt := range []struct {
+		client *telnyx.Client
+		method string
+		path   string
+		status int
+	}{
+		{bad, http.MethodGet, "texml_applications", http.StatusUnauthorized},
+		{srv.Client(), http.MethodGet, "nothing/here", http.StatusNotFound},
+		{srv.Client(), http.MethodDelete, "messages", http.StatusMethodNotAllowed},
+		{srv.Client(), http.MethodGet, "texml_applications/missing", http.StatusNotFound},
+	} {
+		err := tt.client.Do(ctx, tt.method, tt.path, nil, nil)
+		var apiErr *telnyx.Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status || len(apiErr.Errors) != 1 {
+			t.Errorf("%s %s error = %v, want a %d API error", tt.method, tt.path, err, tt.status)
+		}
+	}
+
+	resp, err := http.Get(srv.URL[:len(srv.URL)-len("/v2/")] + "/other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("outside the API status = %d", resp.StatusCode)
+	}
+}
+
+func TestApplications(t *testing.T) {
+	srv := newServer(t)
+	ctx := testContext(t)
+	client := texml.NewClient(srv.Client(), srv.AccountSid)
+
+	if _, err := client.CreateApplication(ctx, &texml.ApplicationParams{FriendlyName: "no url"}); err == nil {
+		t.Error("an application without VoiceUrl was created")
+	}
+	app, err := client.CreateApplication(ctx, &texml.ApplicationParams{FriendlyName: "ivr", VoiceUrl: "https://example.com/voice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.CreateApplication(ctx, &texml.ApplicationParams{FriendlyName: "queue", VoiceUrl: "https://example.com/queue"})
+
+	if _, err := client.UpdateApplication(ctx, app.ID, &texml.ApplicationParams{VoiceUrl: "https://example.com/v2"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.GetApplication(ctx, app.ID)
+	if err != nil || got.VoiceUrl != "https://example.com/v2" || got.FriendlyName != "ivr" {
+		t.Errorf("GetApplication = %+v, %v", got, err)
+	}
+
+	apps, err := client.ListApplications(ctx, &texml.ListApplicationsParams{FriendlyName: "ivr"}).All()
+	if err != nil || len(apps) != 1 || apps[0].ID != app.ID {
+		t.Errorf("ListApplications(ivr) = %+v, %v", apps, err)
+	}
+	if _, err := client.DeleteApplication(ctx, app.ID); err != nil {
+		t.Fatal(err)
+	}
+	if apps, _ := client.ListApplications(ctx, nil).All(); len(apps) != 1 {
+		t.Errorf("%d applications after delete, want 1", len(apps))
+	}
+}
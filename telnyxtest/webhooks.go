@@ -0,0 +1,204 @@
+package telnyxtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+)
+
+// SendWebhook delivers a webhook of eventType with payload to webhookURL, signed with
+// the key pair of PublicKey, in the envelope of Call Control and messaging webhooks.
+// It fails unless the receiver replies with a 2xx status.
+func (s *Server) SendWebhook(ctx context.Context, webhookURL, eventType string, payload interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"data": map[string]interface{}{
+			"record_type": "event",
+			"id":          newID(),
+			"event_type":  eventType,
+			"occurred_at": now(),
+			"payload":     payload,
+		},
+		"meta": map[string]interface{}{
+			"attempt":      1,
+			"delivered_to": webhookURL,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("telnyxtest: encode %s webhook: %w", eventType, err)
+	}
+	resp, err := s.post(ctx, http.MethodPost, webhookURL, "application/json", body)
+	if err != nil {
+		return fmt.Errorf("telnyxtest: %s webhook: %w", eventType, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// post sends a signed request to a webhook or TeXML URL. A GET request carries body as
+// its query instead.
+func (s *Server) post(ctx context.Context, method, rawURL, contentType string, body []byte) (*http.Response, error) {
+	var r io.Reader
+	if method == http.MethodGet {
+		sep := "?"
+		if strings.Contains(rawURL, "?") {
+			sep = "&"
+		}
+		rawURL += sep + string(body)
+	} else {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, r)
+	if err != nil {
+		return nil, err
+	}
+	if method != http.MethodGet {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("User-Agent", "telnyxtest")
+	sig, ts := telnyx.SignWebhook(body, s.privateKey, now())
+	req.Header.Set(telnyx.SignatureHeader, sig)
+	req.Header.Set(telnyx.TimestampHeader, ts)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: %s: %s", method, rawURL, resp.Status, bytes.TrimSpace(data))
+	}
+	return resp, nil
+}
+
+// postForm sends form-encoded TeXML callback parameters to rawURL with method, GET or
+// POST, and returns the response body.
+func (s *Server) postForm(ctx context.Context, method, rawURL string, params url.Values) ([]byte, error) {
+	if method == "" {
+		method = http.MethodPost
+	}
+	resp, err := s.post(ctx, strings.ToUpper(method), rawURL, "application/x-www-form-urlencoded", []byte(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// webhookQueue delivers the webhooks caused by API requests in the background, in the
+// order they were queued, so that the receiver sees call.initiated before call.answered
+// even though it may send commands while handling the first.
+type webhookQueue struct {
+	s      *Server
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  []func(context.Context) error
+	closed bool
+	done   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newWebhookQueue(s *Server) *webhookQueue {
+	q := &webhookQueue{s: s, done: make(chan struct{})}
+	q.cond = sync.NewCond(&q.mu)
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	go q.run()
+	return q
+}
+
+// send queues a webhook for delivery.
+func (q *webhookQueue) send(webhookURL, eventType string, payload interface{}) {
+	if webhookURL == "" {
+		return
+	}
+	q.push(func(ctx context.Context) error {
+		return q.s.SendWebhook(ctx, webhookURL, eventType, payload)
+	})
+}
+
+// sendForm queues a TeXML status callback.
+func (q *webhookQueue) sendForm(method, callbackURL string, params url.Values) {
+	if callbackURL == "" {
+		return
+	}
+	q.push(func(ctx context.Context) error {
+		_, err := q.s.postForm(ctx, method, callbackURL, params)
+		if err != nil {
+			return fmt.Errorf("telnyxtest: status callback: %w", err)
+		}
+		return nil
+	})
+}
+
+func (q *webhookQueue) push(deliver func(context.Context) error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
+	q.queue = append(q.queue, deliver)
+	q.cond.Signal()
+}
+
+func (q *webhookQueue) run() {
+	defer close(q.done)
+	for {
+		q.mu.Lock()
+		for len(q.queue) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if len(q.queue) == 0 {
+			q.mu.Unlock()
+			return
+		}
+		deliver := q.queue[0]
+		q.queue = q.queue[1:]
+		q.mu.Unlock()
+
+		if err := deliver(q.ctx); err != nil && q.ctx.Err() == nil {
+			q.s.addErr(err)
+		}
+	}
+}
+
+// flush waits until the webhooks queued so far have been delivered.
+func (q *webhookQueue) flush(ctx context.Context) error {
+	done := make(chan struct{})
+	q.push(func(context.Context) error {
+		close(done)
+		return nil
+	})
+	select {
+	case <-done:
+		return nil
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *webhookQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.queue = nil
+	q.cond.Signal()
+	q.mu.Unlock()
+	q.cancel()
+	<-q.done
+}
+
+// Flush waits until the webhooks caused by the requests made so far have been
+// delivered.
+func (s *Server) Flush(ctx context.Context) error {
+	return s.webhooks.flush(ctx)
+}
@@ -0,0 +1,182 @@
+package telnyxtest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/andersryanc/telnyx-go/callcontrol"
+	"github.com/andersryanc/telnyx-go/messaging"
+	"github.com/andersryanc/telnyx-go/numbers"
+)
+
+func TestCallControl(t *testing.T) {
+	srv := newServer(t)
+	ctx := testContext(t)
+	client := callcontrol.NewClient(srv.Client())
+
+	events := make(chan string, 20)
+	digits := make(chan string, 1)
+	d := callcontrol.NewDispatcher(srv.PublicKey)
+	d.HandleDefault(func(ctx context.Context, e *callcontrol.Event) error {
+		events <- e.EventType
+		return nil
+	})
+	callcontrol.On(d, func(ctx context.Context, e *callcontrol.Event, p *callcontrol.CallInitiated) error {
+		events <- e.EventType
+		if err := client.Execute(ctx, p.CallControlID, callcontrol.Answer{}); err != nil {
+			return err
+		}
+		return client.Execute(ctx, p.CallControlID, callcontrol.GatherUsingSpeak{Payload: "Enter your PIN", Voice: "female"})
+	})
+	callcontrol.On(d, func(ctx context.Context, e *callcontrol.Event, p *callcontrol.CallGatherEnded) error {
+		events <- e.EventType
+		digits <- p.Digits
+		return client.Execute(ctx, p.CallControlID, callcontrol.Hangup{})
+	})
+	hook := httptest.NewServer(d)
+	defer hook.Close()
+
+	srv.SetCaller("+13125550199", Caller{Inputs: []string{"1234"}})
+	call, err := srv.IncomingCall(ctx, hook.URL, "+13125550199", "+13125550100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The hangup is requested while the gather.ended webhook is delivered, so wait for
+	// its event rather than flushing.
+	var got []string
+	for len(got) < 4 {
+		select {
+		case e := <-events:
+			got = append(got, e)
+		case <-ctx.Done():
+			t.Fatalf("events = %q, want 4", got)
+		}
+	}
+	if want := []string{"call.initiated", "call.answered", "call.gather.ended", "call.hangup"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+	if got := <-digits; got != "1234" {
+		t.Errorf("digits = %q", got)
+	}
+	var actions []string
+	for _, cmd := range srv.Commands(call.CallControlID) {
+		actions = append(actions, cmd.Action)
+	}
+	if want := []string{"answer", "gather_using_speak", "hangup"}; !reflect.DeepEqual(actions, want) {
+		t.Errorf("commands = %q, want %q", actions, want)
+	}
+	if info, err := client.GetCall(ctx, call.CallControlID); err != nil || info.IsAlive {
+		t.Errorf("GetCall = %+v, %v", info, err)
+	}
+	if err := client.Execute(ctx, call.CallControlID, callcontrol.Speak{Payload: "Too late"}); err == nil {
+		t.Error("a command was executed on a call that had ended")
+	}
+}
+
+func TestDialBusy(t *testing.T) {
+	srv := newServer(t)
+	ctx := testContext(t)
+	client := callcontrol.NewClient(srv.Client())
+
+	causes := make(chan string, 1)
+	d := callcontrol.NewDispatcher(srv.PublicKey)
+	callcontrol.On(d, func(ctx context.Context, e *callcontrol.Event, p *callcontrol.CallHangup) error {
+		causes <- p.HangupCause
+		return nil
+	})
+	hook := httptest.NewServer(d)
+	defer hook.Close()
+
+	srv.SetCaller("+13125550199", Caller{Status: "busy"})
+	call, err := client.Dial(ctx, &callcontrol.Dial{ConnectionID: "conn1", From: "+13125550100", To: "+13125550199", WebhookUrl: hook.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Flush(ctx)
+	if cause := <-causes; cause != "user_busy" {
+		t.Errorf("HangupCause = %q", cause)
+	}
+	if call.CallControlID == "" {
+		t.Error("Dial returned no call control ID")
+	}
+}
+
+func TestWebhookSignature(t *testing.T) {
+	srv := newServer(t)
+	ctx := testContext(t)
+
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+	hook := httptest.NewServer(callcontrol.NewDispatcher(other))
+	defer hook.Close()
+	if _, err := srv.IncomingCall(ctx, hook.URL, "+13125550199", "+13125550100"); err == nil {
+		t.Error("a webhook signed by the server was accepted with another key")
+	}
+}
+
+func TestMessagingAndNumbers(t *testing.T) {
+	srv := newServer(t)
+	ctx := testContext(t)
+	msgClient := messaging.NewClient(srv.Client())
+	numClient := numbers.NewClient(srv.Client())
+
+	received := make(chan *messaging.Message, 10)
+	statuses := make(chan string, 10)
+	d := messaging.NewDispatcher(srv.PublicKey)
+	messaging.On(d, func(ctx context.Context, e *messaging.Event, m *messaging.MessageReceived) error {
+		received <- &m.Message
+		return nil
+	})
+	messaging.On(d, func(ctx context.Context, e *messaging.Event, m *messaging.MessageFinalized) error {
+		statuses <- m.To[0].Status
+		return nil
+	})
+	hook := httptest.NewServer(d)
+	defer hook.Close()
+
+	profile, err := msgClient.CreateProfile(ctx, &messaging.ProfileParams{Name: "alerts", WebhookURL: hook.URL, WhitelistedDestinations: []string{"US"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	available, err := numClient.SearchNumbers(ctx, &numbers.SearchParams{CountryCode: "US", NationalDestinationCode: "773"})
+	if err != nil || len(available) == 0 {
+		t.Fatalf("SearchNumbers = %v, %v", available, err)
+	}
+	number := available[0].PhoneNumber
+	order, err := numClient.CreateOrder(ctx, &numbers.OrderParams{PhoneNumbers: []string{number}, MessagingProfileID: profile.ID})
+	if err != nil || order.Status != numbers.OrderStatusSuccess {
+		t.Fatalf("CreateOrder = %+v, %v", order, err)
+	}
+	if again, _ := numClient.SearchNumbers(ctx, &numbers.SearchParams{NationalDestinationCode: "773"}); again[0].PhoneNumber == number {
+		t.Error("an ordered number is still available")
+	}
+	if _, err := numClient.CreateOrder(ctx, &numbers.OrderParams{PhoneNumbers: []string{number}}); err == nil {
+		t.Error("a number was ordered twice")
+	}
+
+	if _, err := srv.ReceiveMessage(ctx, "+13125550199", number, "STOP"); err != nil {
+		t.Fatal(err)
+	}
+	if m := <-received; m.Text != "STOP" || m.From.PhoneNumber != "+13125550199" || m.MessagingProfileID != profile.ID {
+		t.Errorf("received message = %+v", m)
+	}
+
+	msg, err := msgClient.Send(ctx, &messaging.SendParams{From: number, To: "+13125550199", Text: "You are unsubscribed.", MessagingProfileID: profile.ID})
+	if err != nil || msg.Parts != 1 {
+		t.Fatalf("Send = %+v, %v", msg, err)
+	}
+	srv.Flush(ctx)
+	if status := <-statuses; status != "delivered" {
+		t.Errorf("finalized status = %q", status)
+	}
+	if got, err := msgClient.GetMessage(ctx, msg.ID); err != nil || got.Text != msg.Text {
+		t.Errorf("GetMessage = %+v, %v", got, err)
+	}
+
+	if _, err := srv.ReceiveMessage(ctx, "+13125550199", "+13125550100", "hi"); err == nil {
+		t.Error("a message to a number without a messaging profile was received")
+	}
+}
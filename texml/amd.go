@@ -0,0 +1,218 @@
+package texml
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MachineDetection is the answering machine detection of a call, a dialed <Number> or a
+// dialed <Sip>.
+type MachineDetection string
+
+const (
+	// MachineDetectionEnable reports the result as soon as a person or a machine is
+	// detected.
+	MachineDetectionEnable MachineDetection = "Enable"
+	// MachineDetectionDetectMessageEnd waits for the end of a machine greeting, after
+	// the beep, so that a message can be left.
+	MachineDetectionDetectMessageEnd MachineDetection = "DetectMessageEnd"
+	MachineDetectionDisable          MachineDetection = "Disable"
+)
+
+// DetectionMode selects the answering machine detection engine.
+type DetectionMode string
+
+const (
+	DetectionModeRegular DetectionMode = "Regular"
+	// DetectionModePremium uses the premium engine, which also tells residential from
+	// business answers and reports silence.
+	DetectionModePremium DetectionMode = "Premium"
+)
+
+// AnsweredBy is the result of answering machine detection.
+type AnsweredBy string
+
+const (
+	AnsweredByHuman             AnsweredBy = "human"
+	AnsweredByMachine           AnsweredBy = "machine"
+	AnsweredByMachineStart      AnsweredBy = "machine_start"
+	AnsweredByMachineEndBeep    AnsweredBy = "machine_end_beep"
+	AnsweredByMachineEndSilence AnsweredBy = "machine_end_silence"
+	AnsweredByMachineEndOther   AnsweredBy = "machine_end_other"
+	AnsweredByFax               AnsweredBy = "fax"
+	AnsweredByUnknown           AnsweredBy = "unknown"
+	AnsweredByNotSure           AnsweredBy = "not_sure"
+
+	// Results of DetectionModePremium.
+	AnsweredByHumanResidence AnsweredBy = "human_residence"
+	AnsweredByHumanBusiness  AnsweredBy = "human_business"
+	AnsweredBySilence        AnsweredBy = "silence"
+	AnsweredByFaxDetected    AnsweredBy = "fax_detected"
+)
+
+// IsHuman reports whether a person answered.
+func (a AnsweredBy) IsHuman() bool {
+	return a == AnsweredByHuman || strings.HasPrefix(string(a), "human_")
+}
+
+// IsMachine reports whether an answering machine answered.
+func (a AnsweredBy) IsMachine() bool {
+	return a == AnsweredByMachine || strings.HasPrefix(string(a), "machine_")
+}
+
+// IsFax reports whether a fax machine answered.
+func (a AnsweredBy) IsFax() bool {
+	return a == AnsweredByFax || a == AnsweredByFaxDetected
+}
+
+// AMDConfig is the answering machine detection of a call. Apply it to a call with
+// CallParams.SetAMD, or to a dialed party with VoiceNumber.WithAMD and VoiceSip.WithAMD.
+// Durations are sent in milliseconds; zero durations keep the Telnyx defaults.
+type AMDConfig struct {
+	MachineDetection MachineDetection
+	DetectionMode    DetectionMode
+	// Timeout bounds the detection, 3.5 seconds by default.
+	Timeout time.Duration
+
+	// The following settings tune the premium engine. Only CreateCall accepts them.
+	SpeechThreshold    time.Duration
+	SpeechEndThreshold time.Duration
+	SilenceTimeout     time.Duration
+
+	// Async runs the TeXML of the call while detection goes on, and posts the result to
+	// StatusCallback. Otherwise the TeXML is requested once detection ends, with the
+	// result in its AnsweredBy parameter. Only CreateCall accepts them.
+	Async                bool
+	StatusCallback       string
+	StatusCallbackMethod string
+}
+
+func millis(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return strconv.FormatInt(d.Milliseconds(), 10)
+}
+
+func millisPtr(d time.Duration) *int {
+	if d == 0 {
+		return nil
+	}
+	ms := int(d.Milliseconds())
+	return &ms
+}
+
+// SetAMD sets the answering machine detection of the call.
+func (p *CallParams) SetAMD(c AMDConfig) {
+	p.MachineDetection = string(c.MachineDetection)
+	p.DetectionMode = string(c.DetectionMode)
+	p.MachineDetectionTimeout = millisPtr(c.Timeout)
+	p.MachineDetectionSpeechThreshold = millisPtr(c.SpeechThreshold)
+	p.MachineDetectionSpeechEndThreshold = millisPtr(c.SpeechEndThreshold)
+	p.MachineDetectionSilenceTimeout = millisPtr(c.SilenceTimeout)
+	p.AsyncAmd = nil
+	if c.Async {
+		async := true
+		p.AsyncAmd = &async
+	}
+	p.AsyncAmdStatusCallback = c.StatusCallback
+	p.AsyncAmdStatusCallbackMethod = c.StatusCallbackMethod
+}
+
+// WithAMD returns m with the answering machine detection of c. The result is sent
+// with AnsweredBy to the Url of m, which is requested when the number answers.
+func (m VoiceNumber) WithAMD(c AMDConfig) VoiceNumber {
+	m.MachineDetection = string(c.MachineDetection)
+	m.DetectionMode = string(c.DetectionMode)
+	m.MachineDetectionTimeout = millis(c.Timeout)
+	return m
+}
+
+// WithAMD returns m with the answering machine detection of c, like
+// VoiceNumber.WithAMD.
+func (m VoiceSip) WithAMD(c AMDConfig) VoiceSip {
+	m.MachineDetection = string(c.MachineDetection)
+	m.DetectionMode = string(c.DetectionMode)
+	m.MachineDetectionTimeout = millis(c.Timeout)
+	return m
+}
+
+// AMDCallback is the result of answering machine detection, posted to the
+// StatusCallback of an AMDConfig. The requests for the TeXML of a call with
+// synchronous detection carry the same parameters.
+type AMDCallback struct {
+	CallbackCall
+	AnsweredBy AnsweredBy
+	// MachineDetectionDuration is how long the detection took.
+	MachineDetectionDuration time.Duration
+}
+
+// ParseAMDCallback reads the answering machine detection result of r.
+func ParseAMDCallback(r *http.Request) (*AMDCallback, error) {
+	form, err := parseCallbackForm(r, "AMD")
+	if err != nil {
+		return nil, err
+	}
+	return &AMDCallback{
+		CallbackCall:             parseCallbackCall(form),
+		AnsweredBy:               AnsweredBy(form.Get("AnsweredBy")),
+		MachineDetectionDuration: parseMillis(form.Get("MachineDetectionDuration")),
+	}, nil
+}
+
+// VoicemailDrop leaves a message on answering machines and runs other verbs when a
+// person answers. Place the call with the AMDConfig returned by AMD, which makes Telnyx
+// wait for the beep at the end of the greeting before it requests the TeXML of the
+// call, and serve that TeXML with the VoicemailDrop:
+//
+//	drop := texml.VoicemailDrop{Say: "Hi, this is Acme calling about your order."}
+//	params := &texml.CallParams{To: to, From: from, Url: "https://example.com/voicemail"}
+//	params.SetAMD(drop.AMD())
+//	http.Handle("/voicemail", drop)
+type VoicemailDrop struct {
+	// PlayUrl is the recorded message. Say is spoken instead when PlayUrl is empty.
+	PlayUrl  string
+	Say      string
+	Voice    string
+	Language string
+	// Human are the verbs run when a person answers or the detection is not sure. The
+	// call hangs up when there are none.
+	Human []Element
+}
+
+// AMD returns the detection that waits for the end of machine greetings.
+func (v VoicemailDrop) AMD() AMDConfig {
+	return AMDConfig{MachineDetection: MachineDetectionDetectMessageEnd}
+}
+
+// Verbs returns the verbs for a call answered by answeredBy: the message followed by
+// <Hangup> for a machine, <Hangup> for a fax, and Human otherwise.
+func (v VoicemailDrop) Verbs(answeredBy AnsweredBy) []Element {
+	switch {
+	case answeredBy.IsFax():
+		return []Element{VoiceHangup{}}
+	case !answeredBy.IsMachine():
+		if len(v.Human) == 0 {
+			return []Element{VoiceHangup{}}
+		}
+		return v.Human
+	}
+	var message Element = VoiceSay{Message: v.Say, Voice: v.Voice, Language: v.Language}
+	if v.PlayUrl != "" {
+		message = VoicePlay{Url: v.PlayUrl}
+	}
+	return []Element{message, VoiceHangup{}}
+}
+
+// ServeHTTP answers the TeXML request of a call with the Verbs for its AnsweredBy
+// parameter.
+func (v VoicemailDrop) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cb, err := ParseAMDCallback(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	WriteVoice(w, v.Verbs(cb.AnsweredBy))
+}
@@ -0,0 +1,223 @@
+package texml
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andersryanc/telnyx-go/internal/apitest"
+)
+
+func TestAnsweredBy(t *testing.T) {
+	for _, tt := range []struct {
+		a                      AnsweredBy
+		human, machine, faxing bool
+	}{
+		{AnsweredByHuman, true, false, false},
+		{AnsweredByHumanResidence, true, false, false},
+		{AnsweredByHumanBusiness, true, false, false},
+		{AnsweredByMachine, false, true, false},
+		{AnsweredByMachineStart, false, true, false},
+		{AnsweredByMachineEndBeep, false, true, false},
+		{AnsweredByMachineEndSilence, false, true, false},
+		{AnsweredByMachineEndOther, false, true, false},
+		{AnsweredByFax, false, false, true},
+		{AnsweredByFaxDetected, false, false, true},
+		{AnsweredByUnknown, false, false, false},
+		{AnsweredByNotSure, false, false, false},
+		{AnsweredBySilence, false, false, false},
+		{"", false, false, false},
+	} {
+		if tt.a.IsHuman() != tt.human || tt.a.IsMachine() != tt.machine || tt.a.IsFax() != tt.faxing {
+			t.Errorf("%q: IsHuman, IsMachine, IsFax = %v, %v, %v; want %v, %v, %v", tt.a,
+				tt.a.IsHuman(), tt.a.IsMachine(), tt.a.IsFax(), tt.human, tt.machine, tt.faxing)
+		}
+	}
+}
+
+func TestCreateCallWithAMD(t *testing.T) {
+	rec := apitest.NewRecorder(t, apitest.Reply(http.StatusOK, callJSON))
+	c := NewClient(rec.Client(), testAccountSid)
+
+	params := &CallParams{From: "+13125550100", To: "+13125550199", Url: "https://example.com/voice"}
+	params.SetAMD(AMDConfig{
+		MachineDetection:   MachineDetectionDetectMessageEnd,
+		DetectionMode:      DetectionModePremium,
+		Timeout:            5 * time.Second,
+		SpeechThreshold:    2500 * time.Millisecond,
+		SpeechEndThreshold: 1200 * time.Millisecond,
+		SilenceTimeout:     4 * time.Second,
+		Async:              true,
+		StatusCallback:     "https://example.com/amd",
+	})
+	if _, err := c.CreateCall(context.Background(), "app1", params); err != nil {
+		t.Fatal(err)
+	}
+	r := rec.Expect(http.MethodPost, "/texml/calls/app1")
+	for key, want := range map[string]interface{}{
+		"MachineDetection":                   "DetectMessageEnd",
+		"DetectionMode":                      "Premium",
+		"MachineDetectionTimeout":            float64(5000),
+		"MachineDetectionSpeechThreshold":    float64(2500),
+		"MachineDetectionSpeechEndThreshold": float64(1200),
+		"MachineDetectionSilenceTimeout":     float64(4000),
+		"AsyncAmd":                           true,
+		"AsyncAmdStatusCallback":             "https://example.com/amd",
+	} {
+		if r.Body[key] != want {
+			t.Errorf("%s = %v, want %v", key, r.Body[key], want)
+		}
+	}
+
+	// Setting a configuration without durations or async detection clears them.
+	params.SetAMD(AMDConfig{MachineDetection: MachineDetectionEnable})
+	if _, err := c.CreateCall(context.Background(), "app1", params); err != nil {
+		t.Fatal(err)
+	}
+	r = rec.Last()
+	if r.Body["MachineDetection"] != "Enable" {
+		t.Errorf("MachineDetection = %v, want Enable", r.Body["MachineDetection"])
+	}
+	for _, key := range []string{"DetectionMode", "MachineDetectionTimeout", "MachineDetectionSpeechThreshold", "AsyncAmd", "AsyncAmdStatusCallback"} {
+		if v, ok := r.Body[key]; ok {
+			t.Errorf("%s = %v, want it omitted", key, v)
+		}
+	}
+}
+
+func TestDialWithAMD(t *testing.T) {
+	config := AMDConfig{MachineDetection: MachineDetectionEnable, DetectionMode: DetectionModeRegular, Timeout: 3500 * time.Millisecond}
+	xml, err := Voice([]Element{VoiceDial{InnerElements: []Element{
+		VoiceNumber{PhoneNumber: "+13125550199", Url: "/answered"}.WithAMD(config),
+		VoiceSip{SipUrl: "sip:agent@example.com"}.WithAMD(config),
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dial := readResponse(t, xml).SelectElement("Dial")
+	for _, tag := range []string{"Number", "Sip"} {
+		e := dial.SelectElement(tag)
+		if e == nil {
+			t.Fatalf("<Dial> has no <%s> in %s", tag, xml)
+		}
+		for attr, want := range map[string]string{"machineDetection": "Enable", "detectionMode": "Regular", "machineDetectionTimeout": "3500"} {
+			if got := e.SelectAttrValue(attr, ""); got != want {
+				t.Errorf("<%s %s> = %q, want %q", tag, attr, got, want)
+			}
+		}
+	}
+	if got := dial.SelectElement("Number").SelectAttrValue("url", ""); got != "/answered" {
+		t.Errorf("<Number url> = %q, want /answered", got)
+	}
+
+	// Zero settings leave the attributes out.
+	n := VoiceNumber{PhoneNumber: "+13125550199"}.WithAMD(AMDConfig{MachineDetection: MachineDetectionEnable})
+	if n.DetectionMode != "" || n.MachineDetectionTimeout != "" {
+		t.Errorf("VoiceNumber = %+v, want no detection mode or timeout", n)
+	}
+}
+
+// amdRequest is a TeXML request of a call answered by answeredBy.
+func amdRequest(answeredBy string) *http.Request {
+	form := "AccountSid=AC123&CallSid=v3%3Aabc&From=%2B13125550100&To=%2B13125550199&Direction=outbound-api" +
+		"&CallStatus=in-progress&AnsweredBy=" + answeredBy + "&MachineDetectionDuration=2870"
+	r := httptest.NewRequest(http.MethodPost, "/voicemail", strings.NewReader(form))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestParseAMDCallback(t *testing.T) {
+	cb, err := ParseAMDCallback(amdRequest("machine_end_beep"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &AMDCallback{
+		CallbackCall: CallbackCall{
+			AccountSid: "AC123",
+			CallSid:    "v3:abc",
+			From:       "+13125550100",
+			To:         "+13125550199",
+			Direction:  "outbound-api",
+			CallStatus: CallStatusInProgress,
+		},
+		AnsweredBy:               AnsweredByMachineEndBeep,
+		MachineDetectionDuration: 2870 * time.Millisecond,
+	}
+	if !reflect.DeepEqual(cb, want) {
+		t.Errorf("ParseAMDCallback = %+v, want %+v", cb, want)
+	}
+
+	// The parameters of GET requests are in the query.
+	cb, err = ParseAMDCallback(httptest.NewRequest(http.MethodGet, "/voicemail?AnsweredBy=human", nil))
+	if err != nil || cb.AnsweredBy != AnsweredByHuman || cb.MachineDetectionDuration != 0 {
+		t.Errorf("ParseAMDCallback of a GET request = %+v, %v", cb, err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/voicemail", strings.NewReader("AnsweredBy=%zz"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if _, err := ParseAMDCallback(r); err == nil || !strings.HasPrefix(err.Error(), "texml: parse AMD callback") {
+		t.Errorf("ParseAMDCallback of a malformed form = %v", err)
+	}
+}
+
+func TestVoicemailDrop(t *testing.T) {
+	if got := (VoicemailDrop{}).AMD(); got != (AMDConfig{MachineDetection: MachineDetectionDetectMessageEnd}) {
+		t.Errorf("AMD = %+v, want DetectMessageEnd", got)
+	}
+
+	say := VoicemailDrop{Say: "Hi, this is Acme.", Voice: "alice", Language: "en-US"}
+	play := VoicemailDrop{PlayUrl: "https://example.com/message.mp3", Say: "unused"}
+	human := VoicemailDrop{Say: "Hi", Human: []Element{VoiceDial{Number: "+13125550123"}}}
+	hangup := []Element{VoiceHangup{}}
+	for _, tt := range []struct {
+		name       string
+		drop       VoicemailDrop
+		answeredBy AnsweredBy
+		want       []Element
+	}{
+		{"say", say, AnsweredByMachineEndBeep, []Element{VoiceSay{Message: "Hi, this is Acme.", Voice: "alice", Language: "en-US"}, VoiceHangup{}}},
+		{"play", play, AnsweredByMachineEndSilence, []Element{VoicePlay{Url: "https://example.com/message.mp3"}, VoiceHangup{}}},
+		{"machine", play, AnsweredByMachine, []Element{VoicePlay{Url: "https://example.com/message.mp3"}, VoiceHangup{}}},
+		{"fax", human, AnsweredByFax, hangup},
+		{"premium fax", human, AnsweredByFaxDetected, hangup},
+		{"human", human, AnsweredByHuman, human.Human},
+		{"not sure", human, AnsweredByNotSure, human.Human},
+		{"no human verbs", say, AnsweredByHumanBusiness, hangup},
+	} {
+		if got := tt.drop.Verbs(tt.answeredBy); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Verbs(%q) = %#v, want %#v", tt.name, tt.answeredBy, got, tt.want)
+		}
+	}
+}
+
+func TestVoicemailDropServeHTTP(t *testing.T) {
+	drop := VoicemailDrop{PlayUrl: "https://example.com/message.mp3"}
+
+	w := httptest.NewRecorder()
+	drop.ServeHTTP(w, amdRequest("machine_end_beep"))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/xml" {
+		t.Fatalf("response = %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	verbs := readResponse(t, w.Body.String()).ChildElements()
+	if len(verbs) != 2 || verbs[0].Tag != "Play" || verbs[0].Text() != "https://example.com/message.mp3" || verbs[1].Tag != "Hangup" {
+		t.Errorf("response = %s, want <Play> then <Hangup>", w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	drop.ServeHTTP(w, amdRequest("human"))
+	if verbs := readResponse(t, w.Body.String()).ChildElements(); len(verbs) != 1 || verbs[0].Tag != "Hangup" {
+		t.Errorf("response for a person = %s, want <Hangup>", w.Body)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/voicemail", strings.NewReader("AnsweredBy=%zz"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	drop.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status for a malformed request = %d, want 400", w.Code)
+	}
+}
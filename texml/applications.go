@@ -0,0 +1,132 @@
+package texml
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+)
+
+// Application is a TeXML application. Phone numbers and connections assigned to the
+// application fetch their TeXML from VoiceUrl.
+//
+// https://developers.telnyx.com/api/call-scripting/create-texml-application
+type Application struct {
+	ID                      string              `json:"id"`
+	RecordType              string              `json:"record_type"`
+	Active                  bool                `json:"active"`
+	FriendlyName            string              `json:"friendly_name"`
+	AnchorsiteOverride      string              `json:"anchorsite_override"`
+	DtmfType                string              `json:"dtmf_type"`
+	FirstCommandTimeout     bool                `json:"first_command_timeout"`
+	FirstCommandTimeoutSecs int                 `json:"first_command_timeout_secs"`
+	VoiceUrl                string              `json:"voice_url"`
+	VoiceFallbackUrl        string              `json:"voice_fallback_url"`
+	VoiceMethod             string              `json:"voice_method"`
+	StatusCallback          string              `json:"status_callback"`
+	StatusCallbackMethod    string              `json:"status_callback_method"`
+	Inbound                 ApplicationInbound  `json:"inbound"`
+	Outbound                ApplicationOutbound `json:"outbound"`
+	Tags                    []string            `json:"tags"`
+	CreatedAt               time.Time           `json:"created_at"`
+	UpdatedAt               time.Time           `json:"updated_at"`
+}
+
+// ApplicationInbound holds the settings for calls received by an application.
+type ApplicationInbound struct {
+	ChannelLimit                *int   `json:"channel_limit,omitempty"`
+	ShakenStirEnabled           *bool  `json:"shaken_stir_enabled,omitempty"`
+	SipSubdomain                string `json:"sip_subdomain,omitempty"`
+	SipSubdomainReceiveSettings string `json:"sip_subdomain_receive_settings,omitempty"`
+}
+
+// ApplicationOutbound holds the settings for calls placed by an application.
+type ApplicationOutbound struct {
+	ChannelLimit           *int   `json:"channel_limit,omitempty"`
+	OutboundVoiceProfileID string `json:"outbound_voice_profile_id,omitempty"`
+}
+
+// ApplicationParams are the fields sent when creating or updating an Application. Nil
+// and empty fields are left out of the request, so an update only changes the fields
+// that are set. FriendlyName and VoiceUrl are required on create.
+type ApplicationParams struct {
+	FriendlyName            string               `json:"friendly_name,omitempty"`
+	Active                  *bool                `json:"active,omitempty"`
+	AnchorsiteOverride      string               `json:"anchorsite_override,omitempty"`
+	DtmfType                string               `json:"dtmf_type,omitempty"`
+	FirstCommandTimeout     *bool                `json:"first_command_timeout,omitempty"`
+	FirstCommandTimeoutSecs *int                 `json:"first_command_timeout_secs,omitempty"`
+	VoiceUrl                string               `json:"voice_url,omitempty"`
+	VoiceFallbackUrl        string               `json:"voice_fallback_url,omitempty"`
+	VoiceMethod             string               `json:"voice_method,omitempty"`
+	StatusCallback          string               `json:"status_callback,omitempty"`
+	StatusCallbackMethod    string               `json:"status_callback_method,omitempty"`
+	Inbound                 *ApplicationInbound  `json:"inbound,omitempty"`
+	Outbound                *ApplicationOutbound `json:"outbound,omitempty"`
+	Tags                    []string             `json:"tags,omitempty"`
+}
+
+// ListApplicationsParams filters the applications returned by ListApplications.
+type ListApplicationsParams struct {
+	FriendlyName           string
+	OutboundVoiceProfileID string
+	// Sort is a field name, prefixed with "-" for descending order.
+	Sort     string
+	PageSize int
+}
+
+func (p *ListApplicationsParams) query() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := telnyx.PageQuery(p.PageSize)
+	if p.FriendlyName != "" {
+		q.Set("filter[friendly_name]", p.FriendlyName)
+	}
+	if p.OutboundVoiceProfileID != "" {
+		q.Set("filter[outbound_voice_profile_id]", p.OutboundVoiceProfileID)
+	}
+	if p.Sort != "" {
+		q.Set("sort", p.Sort)
+	}
+	return q
+}
+
+func (c *Client) CreateApplication(ctx context.Context, params *ApplicationParams) (*Application, error) {
+	var resp telnyx.DataResponse[Application]
+	if err := c.client.Do(ctx, http.MethodPost, "texml_applications", params, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+func (c *Client) GetApplication(ctx context.Context, id string) (*Application, error) {
+	var resp telnyx.DataResponse[Application]
+	if err := c.client.Do(ctx, http.MethodGet, "texml_applications/"+url.PathEscape(id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+func (c *Client) ListApplications(ctx context.Context, params *ListApplicationsParams) *telnyx.Iter[Application] {
+	return telnyx.List[Application](ctx, c.client, "texml_applications", params.query())
+}
+
+func (c *Client) UpdateApplication(ctx context.Context, id string, params *ApplicationParams) (*Application, error) {
+	var resp telnyx.DataResponse[Application]
+	if err := c.client.Do(ctx, http.MethodPatch, "texml_applications/"+url.PathEscape(id), params, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// DeleteApplication deletes an application and returns it as it was before deletion.
+func (c *Client) DeleteApplication(ctx context.Context, id string) (*Application, error) {
+	var resp telnyx.DataResponse[Application]
+	if err := c.client.Do(ctx, http.MethodDelete, "texml_applications/"+url.PathEscape(id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
@@ -0,0 +1,142 @@
+package texml
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+	"github.com/andersryanc/telnyx-go/internal/apitest"
+)
+
+const applicationJSON = `{"data":{"id":"1293384261075731499","record_type":"texml_application","active":true,
+	"friendly_name":"IVR","voice_url":"https://example.com/texml","voice_method":"post",
+	"inbound":{"channel_limit":10},"outbound":{"outbound_voice_profile_id":"1293384261075731500"},
+	"tags":["ivr"],"created_at":"2025-05-06T12:00:00Z","updated_at":"2025-05-06T12:00:00Z"}}`
+
+func TestCreateApplication(t *testing.T) {
+	rec := apitest.NewRecorder(t, apitest.Reply(http.StatusCreated, applicationJSON))
+	c := NewClient(rec.Client(), testAccountSid)
+
+	app, err := c.CreateApplication(context.Background(), &ApplicationParams{
+		FriendlyName: "IVR",
+		VoiceUrl:     "https://example.com/texml",
+		Active:       telnyx.Bool(true),
+		Inbound:      &ApplicationInbound{ChannelLimit: telnyx.Int(10)},
+		Tags:         []string{"ivr"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rec.Expect(http.MethodPost, "/texml_applications")
+	want := map[string]interface{}{
+		"friendly_name": "IVR",
+		"voice_url":     "https://example.com/texml",
+		"active":        true,
+		"inbound":       map[string]interface{}{"channel_limit": float64(10)},
+		"tags":          []interface{}{"ivr"},
+	}
+	if !reflect.DeepEqual(r.Body, want) {
+		t.Errorf("body = %v, want %v", r.Body, want)
+	}
+
+	if app.ID != "1293384261075731499" || app.FriendlyName != "IVR" || !app.Active {
+		t.Errorf("CreateApplication = %+v", app)
+	}
+	if app.Inbound.ChannelLimit == nil || *app.Inbound.ChannelLimit != 10 || app.Outbound.OutboundVoiceProfileID != "1293384261075731500" {
+		t.Errorf("Inbound = %+v, Outbound = %+v", app.Inbound, app.Outbound)
+	}
+	if app.CreatedAt.IsZero() {
+		t.Error("CreatedAt was not decoded")
+	}
+}
+
+func TestGetUpdateDeleteApplication(t *testing.T) {
+	rec := apitest.NewRecorder(t, apitest.Reply(http.StatusOK, applicationJSON))
+	c := NewClient(rec.Client(), testAccountSid)
+	ctx := context.Background()
+
+	if _, err := c.GetApplication(ctx, "1293384261075731499"); err != nil {
+		t.Fatal(err)
+	}
+	rec.Expect(http.MethodGet, "/texml_applications/1293384261075731499")
+
+	if _, err := c.UpdateApplication(ctx, "1293384261075731499", &ApplicationParams{StatusCallback: "https://example.com/status"}); err != nil {
+		t.Fatal(err)
+	}
+	r := rec.Expect(http.MethodPatch, "/texml_applications/1293384261075731499")
+	if want := map[string]interface{}{"status_callback": "https://example.com/status"}; !reflect.DeepEqual(r.Body, want) {
+		t.Errorf("update body = %v, want only the fields set: %v", r.Body, want)
+	}
+
+	app, err := c.DeleteApplication(ctx, "1293384261075731499")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Expect(http.MethodDelete, "/texml_applications/1293384261075731499")
+	if app.FriendlyName != "IVR" {
+		t.Errorf("DeleteApplication = %+v", app)
+	}
+}
+
+func TestListApplications(t *testing.T) {
+	rec := apitest.NewRecorder(t, func(r apitest.Request) (int, string) {
+		q := r.Query
+		if q.Get("page[number]") == "2" {
+			return http.StatusOK, `{"data":[{"id":"3"}],"meta":{"page_number":2,"page_size":2,"total_pages":2,"total_results":3}}`
+		}
+		return http.StatusOK, `{"data":[{"id":"1"},{"id":"2"}],"meta":{"page_number":1,"page_size":2,"total_pages":2,"total_results":3}}`
+	})
+	c := NewClient(rec.Client(), testAccountSid)
+
+	apps, err := c.ListApplications(context.Background(), &ListApplicationsParams{FriendlyName: "IVR", Sort: "-created_at", PageSize: 2}).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, app := range apps {
+		ids = append(ids, app.ID)
+	}
+	if want := []string{"1", "2", "3"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ids = %v, want %v", ids, want)
+	}
+	if len(rec.Requests()) != 2 {
+		t.Fatalf("%d requests, want 2", len(rec.Requests()))
+	}
+	q := rec.Requests()[1].Query
+	if q.Get("filter[friendly_name]") != "IVR" || q.Get("sort") != "-created_at" || q.Get("page[size]") != "2" {
+		t.Errorf("second page query = %s, want the filters kept", q.Encode())
+	}
+
+	if _, err := c.ListApplications(context.Background(), nil).All(); err != nil {
+		t.Fatal(err)
+	}
+	if r := rec.Requests()[2]; r.Path != "/texml_applications" || len(r.Query) != 0 {
+		t.Errorf("unfiltered list requested %s?%s", r.Path, r.Query.Encode())
+	}
+}
+
+func TestApplicationError(t *testing.T) {
+	rec := apitest.NewRecorder(t, func(apitest.Request) (int, string) {
+		return http.StatusUnprocessableEntity, `{"errors":[{"code":"10015","title":"Invalid value","detail":"voice_url must be a valid URL.","source":{"pointer":"/voice_url"}}]}`
+	})
+	c := NewClient(rec.Client(), testAccountSid)
+
+	_, err := c.CreateApplication(context.Background(), &ApplicationParams{FriendlyName: "IVR", VoiceUrl: "not a url"})
+	var apiErr *telnyx.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("CreateApplication error = %v, want a *telnyx.Error", err)
+	}
+	if apiErr.StatusCode != http.StatusUnprocessableEntity || apiErr.Code() != "10015" || apiErr.Errors[0].Source.Pointer != "/voice_url" {
+		t.Errorf("error = %+v", apiErr)
+	}
+
+	rec.Respond = func(apitest.Request) (int, string) {
+		return http.StatusNotFound, `{"errors":[{"code":"10005","title":"Resource not found"}]}`
+	}
+	if _, err := c.GetApplication(context.Background(), "missing"); !telnyx.IsNotFound(err) {
+		t.Errorf("GetApplication error = %v, want not found", err)
+	}
+}
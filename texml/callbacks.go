@@ -0,0 +1,238 @@
+package texml
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// CallbackCall holds the parameters Telnyx sends with every request about a call: the
+// requests for TeXML documents and the status and result callbacks.
+type CallbackCall struct {
+	AccountSid string
+	CallSid    string
+	From       string
+	To         string
+	// Direction is "inbound" or "outbound-api".
+	Direction  string
+	CallStatus CallStatus
+}
+
+func parseCallbackCall(form url.Values) CallbackCall {
+	return CallbackCall{
+		AccountSid: form.Get("AccountSid"),
+		CallSid:    form.Get("CallSid"),
+		From:       form.Get("From"),
+		To:         form.Get("To"),
+		Direction:  form.Get("Direction"),
+		CallStatus: CallStatus(form.Get("CallStatus")),
+	}
+}
+
+// parseCallbackForm returns the query and form parameters of a callback of kind.
+func parseCallbackForm(r *http.Request, kind string) (url.Values, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, fmt.Errorf("texml: parse %s callback: %w", kind, err)
+	}
+	return r.Form, nil
+}
+
+// parseSeconds returns the duration of a parameter counted in seconds, or zero.
+func parseSeconds(s string) time.Duration {
+	n, _ := strconv.Atoi(s)
+	return time.Duration(n) * time.Second
+}
+
+// parseMillis returns the duration of a parameter counted in milliseconds, or zero.
+func parseMillis(s string) time.Duration {
+	n, _ := strconv.Atoi(s)
+	return time.Duration(n) * time.Millisecond
+}
+
+// WriteVoice answers a request for a TeXML document with verbs, rendered with Voice.
+func WriteVoice(w http.ResponseWriter, verbs []Element) {
+	doc, err := Voice(verbs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	io.WriteString(w, doc)
+}
+
+// QueueResult is how a call left a queue, reported to the action of <Enqueue>.
+type QueueResult string
+
+const (
+	// QueueResultBridged is reported when an agent took the call with <Dial><Queue>.
+	QueueResultBridged               QueueResult = "bridged"
+	QueueResultBridgingInProcess     QueueResult = "bridging-in-process"
+	QueueResultRedirected            QueueResult = "redirected"
+	QueueResultRedirectedFromBridged QueueResult = "redirected-from-bridged"
+	// QueueResultHangup is reported when the caller hung up while waiting.
+	QueueResultHangup QueueResult = "hangup"
+	// QueueResultLeave is reported when the wait document made the caller <Leave>.
+	QueueResultLeave          QueueResult = "leave"
+	QueueResultQueueFull      QueueResult = "queue-full"
+	QueueResultError          QueueResult = "error"
+	QueueResultSystemError    QueueResult = "system-error"
+	QueueResultSystemShutdown QueueResult = "system-shutdown"
+)
+
+// EnqueueWaitCallback is the request for the waitUrl document of <Enqueue>, made when
+// the call enters the queue and each time the previous document ends.
+type EnqueueWaitCallback struct {
+	CallbackCall
+	QueueSid string
+	// QueuePosition is 1 for the next call to be taken.
+	QueuePosition    int
+	QueueTime        time.Duration
+	AvgQueueTime     time.Duration
+	CurrentQueueSize int
+	MaxQueueSize     int
+}
+
+// ParseEnqueueWaitCallback reads the parameters of a waitUrl request.
+func ParseEnqueueWaitCallback(r *http.Request) (*EnqueueWaitCallback, error) {
+	form, err := parseCallbackForm(r, "wait")
+	if err != nil {
+		return nil, err
+	}
+	position, _ := strconv.Atoi(form.Get("QueuePosition"))
+	size, _ := strconv.Atoi(form.Get("CurrentQueueSize"))
+	maxSize, _ := strconv.Atoi(form.Get("MaxQueueSize"))
+	return &EnqueueWaitCallback{
+		CallbackCall:     parseCallbackCall(form),
+		QueueSid:         form.Get("QueueSid"),
+		QueuePosition:    position,
+		QueueTime:        parseSeconds(form.Get("QueueTime")),
+		AvgQueueTime:     parseSeconds(form.Get("AvgQueueTime")),
+		CurrentQueueSize: size,
+		MaxQueueSize:     maxSize,
+	}, nil
+}
+
+// QueueResultCallback is the request for the action of <Enqueue>, made when the call
+// leaves the queue.
+type QueueResultCallback struct {
+	CallbackCall
+	QueueResult QueueResult
+	QueueSid    string
+	QueueTime   time.Duration
+}
+
+// ParseQueueResultCallback reads the parameters of an <Enqueue> action request.
+func ParseQueueResultCallback(r *http.Request) (*QueueResultCallback, error) {
+	form, err := parseCallbackForm(r, "queue result")
+	if err != nil {
+		return nil, err
+	}
+	return &QueueResultCallback{
+		CallbackCall: parseCallbackCall(form),
+		QueueResult:  QueueResult(form.Get("QueueResult")),
+		QueueSid:     form.Get("QueueSid"),
+		QueueTime:    parseSeconds(form.Get("QueueTime")),
+	}, nil
+}
+
+// DequeueCallback is the request for the url document of <Queue>, played to the caller
+// taken from the queue before it is connected to the agent.
+type DequeueCallback struct {
+	CallbackCall
+	QueueSid string
+	// QueueTime is how long the caller waited.
+	QueueTime time.Duration
+	// DequeueingCallSid is the call of the agent.
+	DequeueingCallSid string
+}
+
+// ParseDequeueCallback reads the parameters of a <Queue> url request.
+func ParseDequeueCallback(r *http.Request) (*DequeueCallback, error) {
+	form, err := parseCallbackForm(r, "dequeue")
+	if err != nil {
+		return nil, err
+	}
+	return &DequeueCallback{
+		CallbackCall:      parseCallbackCall(form),
+		QueueSid:          form.Get("QueueSid"),
+		QueueTime:         parseSeconds(form.Get("QueueTime")),
+		DequeueingCallSid: form.Get("DequeueingCallSid"),
+	}, nil
+}
+
+// ConferenceEvent is the StatusCallbackEvent of a conference status callback.
+type ConferenceEvent string
+
+const (
+	ConferenceEventStart             ConferenceEvent = "conference-start"
+	ConferenceEventEnd               ConferenceEvent = "conference-end"
+	ConferenceEventParticipantJoin   ConferenceEvent = "participant-join"
+	ConferenceEventParticipantLeave  ConferenceEvent = "participant-leave"
+	ConferenceEventParticipantMute   ConferenceEvent = "participant-mute"
+	ConferenceEventParticipantUnmute ConferenceEvent = "participant-unmute"
+	ConferenceEventParticipantHold   ConferenceEvent = "participant-hold"
+	ConferenceEventParticipantUnhold ConferenceEvent = "participant-unhold"
+	ConferenceEventSpeechStart       ConferenceEvent = "participant-speech-start"
+	ConferenceEventSpeechStop        ConferenceEvent = "participant-speech-stop"
+	ConferenceEventAnnouncementEnd   ConferenceEvent = "announcement-end"
+	ConferenceEventAnnouncementFail  ConferenceEvent = "announcement-fail"
+)
+
+// ConferenceStatusCallback is posted to the statusCallback of <Conference> for the
+// events named in its statusCallbackEvent attribute. The participant fields are set for
+// participant events only.
+type ConferenceStatusCallback struct {
+	AccountSid    string
+	ConferenceSid string
+	FriendlyName  string
+	Event         ConferenceEvent
+	// SequenceNumber orders the callbacks of a conference, which may arrive out of
+	// order.
+	SequenceNumber int
+	Timestamp      time.Time
+
+	CallSid                string
+	Muted                  bool
+	Hold                   bool
+	Coaching               bool
+	StartConferenceOnEnter bool
+	EndConferenceOnExit    bool
+
+	// ReasonConferenceEnded and CallSidEndingConference are set for
+	// ConferenceEventEnd.
+	ReasonConferenceEnded   string
+	CallSidEndingConference string
+}
+
+// ParseConferenceStatusCallback reads the parameters of a conference status callback.
+func ParseConferenceStatusCallback(r *http.Request) (*ConferenceStatusCallback, error) {
+	form, err := parseCallbackForm(r, "conference status")
+	if err != nil {
+		return nil, err
+	}
+	sequence, _ := strconv.Atoi(form.Get("SequenceNumber"))
+	timestamp, _ := time.Parse(time.RFC1123Z, form.Get("Timestamp"))
+	flag := func(key string) bool {
+		v, _ := strconv.ParseBool(form.Get(key))
+		return v
+	}
+	return &ConferenceStatusCallback{
+		AccountSid:              form.Get("AccountSid"),
+		ConferenceSid:           form.Get("ConferenceSid"),
+		FriendlyName:            form.Get("FriendlyName"),
+		Event:                   ConferenceEvent(form.Get("StatusCallbackEvent")),
+		SequenceNumber:          sequence,
+		Timestamp:               timestamp,
+		CallSid:                 form.Get("CallSid"),
+		Muted:                   flag("Muted"),
+		Hold:                    flag("Hold"),
+		Coaching:                flag("Coaching"),
+		StartConferenceOnEnter:  flag("StartConferenceOnEnter"),
+		EndConferenceOnExit:     flag("EndConferenceOnExit"),
+		ReasonConferenceEnded:   form.Get("ReasonConferenceEnded"),
+		CallSidEndingConference: form.Get("CallSidEndingConference"),
+	}, nil
+}
@@ -0,0 +1,271 @@
+// Package callcenter builds call-center queues on top of the TeXML <Enqueue> verb and
+// <Queue> noun: the TeXML that puts callers in line, a wait URL handler that announces
+// their position and estimated wait over hold music, the TeXML that connects agents,
+// and typed handlers for the requests Telnyx makes along the way.
+//
+//	support := &callcenter.Queue{
+//		Name:      "support",
+//		WaitUrl:   "https://example.com/queue/wait",
+//		ActionUrl: "https://example.com/queue/result",
+//		HoldMusic: []string{"https://example.com/music/1.mp3", "https://example.com/music/2.mp3"},
+//	}
+//	http.Handle("/queue/wait", support.WaitHandler())
+//	http.Handle("/queue/result", support.ResultHandler(func(cb *texml.QueueResultCallback) []texml.Element {
+//		if cb.QueueResult == texml.QueueResultLeave {
+//			return []texml.Element{texml.VoiceSay{Message: "Please leave a message."}, texml.VoiceRecord{}}
+//		}
+//		return nil
+//	}))
+//
+//	// Caller side:
+//	doc, err := texml.Voice([]texml.Element{support.Enqueue()})
+//	// Agent side:
+//	doc, err = texml.Voice([]texml.Element{support.Dial()})
+package callcenter
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/andersryanc/telnyx-go/texml"
+)
+
+// Queue is a call-center queue. Its handlers keep track of the callers waiting in it,
+// so a Queue must be used through a pointer and not copied after first use. It is safe
+// for concurrent use.
+type Queue struct {
+	// Name is the name of the TeXML queue.
+	Name string
+	// WaitUrl is the absolute URL where WaitHandler is served. Telnyx requests it when a
+	// caller enters the queue and again each time its document ends.
+	WaitUrl string
+	// ActionUrl is the absolute URL where ResultHandler is served. Without it, callers
+	// continue with the verbs that follow <Enqueue> when they leave the queue.
+	ActionUrl string
+	// WhisperUrl is the absolute URL where WhisperHandler is served, whose document is
+	// played to the caller taken by an agent before they are connected.
+	WhisperUrl string
+
+	// HoldMusic are the audio URLs played to waiting callers, one per wait document, in
+	// turn.
+	HoldMusic []string
+	Voice     string
+	Language  string
+	// AnnounceInterval is the least time between two announcements to a caller. Zero
+	// announces in every wait document; a negative interval never announces.
+	AnnounceInterval time.Duration
+	// Announce returns what is said to a caller at position, with the estimated wait,
+	// zero when unknown. It defaults to Announcement.
+	Announce func(position int, wait time.Duration) string
+	// MaxWait makes callers <Leave> the queue once they have waited that long, so that
+	// the ResultHandler can offer them another option. Zero waits forever.
+	MaxWait time.Duration
+
+	mu      sync.Mutex
+	callers map[string]*caller
+	// lastBridged is when an agent last took a caller, and interval the average time
+	// between two of them.
+	lastBridged time.Time
+	interval    time.Duration
+}
+
+// forgetAfter is how long a caller is kept after its last wait document was
+// requested, for the callers that leave without a result or whisper request.
+const forgetAfter = 30 * time.Minute
+
+// caller is the state of a waiting call.
+type caller struct {
+	documents     int
+	lastAnnounced time.Time
+	// entered is when the caller entered the queue, and lastSeen when its last wait
+	// document was requested.
+	entered  time.Time
+	lastSeen time.Time
+}
+
+// Enqueue returns the <Enqueue> verb that puts a caller in the queue.
+func (q *Queue) Enqueue() texml.VoiceEnqueue {
+	e := texml.VoiceEnqueue{Name: q.Name, WaitUrl: q.WaitUrl, Action: q.ActionUrl}
+	if q.WaitUrl != "" {
+		e.WaitUrlMethod = http.MethodPost
+	}
+	if q.ActionUrl != "" {
+		e.Method = http.MethodPost
+	}
+	return e
+}
+
+// Dial returns the <Dial><Queue> that connects an agent to the caller waiting the
+// longest.
+func (q *Queue) Dial() texml.VoiceDial {
+	noun := texml.VoiceQueue{Name: q.Name, Url: q.WhisperUrl}
+	if q.WhisperUrl != "" {
+		noun.Method = http.MethodPost
+	}
+	return texml.VoiceDial{InnerElements: []texml.Element{noun}}
+}
+
+// EstimatedWait returns how long the caller of cb may still wait: its position times
+// the average time between two callers taken by agents. Until agents have taken two
+// callers, it falls back to the average time in queue reported by Telnyx, or zero.
+func (q *Queue) EstimatedWait(cb *texml.EnqueueWaitCallback) time.Duration {
+	q.mu.Lock()
+	interval := q.interval
+	q.mu.Unlock()
+	if interval > 0 && cb.QueuePosition > 0 {
+		return time.Duration(cb.QueuePosition) * interval
+	}
+	return cb.AvgQueueTime
+}
+
+// Announcement is the default Queue.Announce, e.g. "You are number 3 in line. Your
+// estimated wait time is about 4 minutes."
+func Announcement(position int, wait time.Duration) string {
+	msg := fmt.Sprintf("You are number %d in line.", position)
+	if position == 1 {
+		msg = "You are next in line."
+	}
+	switch minutes := int((wait + time.Minute - 1) / time.Minute); {
+	case wait <= 0:
+	case minutes == 1:
+		msg += " Your estimated wait time is about one minute."
+	default:
+		msg += fmt.Sprintf(" Your estimated wait time is about %d minutes.", minutes)
+	}
+	return msg
+}
+
+// WaitVerbs returns the wait document for cb: an announcement when one is due, then
+// the next hold music track. A caller that has waited MaxWait gets <Leave>.
+func (q *Queue) WaitVerbs(cb *texml.EnqueueWaitCallback) []texml.Element {
+	if q.MaxWait > 0 && cb.QueueTime >= q.MaxWait {
+		q.forget(cb.CallSid)
+		return []texml.Element{texml.VoiceLeave{}}
+	}
+	wait := q.EstimatedWait(cb)
+
+	now := time.Now()
+	q.mu.Lock()
+	if q.callers == nil {
+		q.callers = map[string]*caller{}
+	}
+	for sid, c := range q.callers {
+		if now.Sub(c.lastSeen) >= forgetAfter {
+			delete(q.callers, sid)
+		}
+	}
+	c := q.callers[cb.CallSid]
+	if c == nil {
+		c = &caller{entered: now.Add(-cb.QueueTime)}
+		q.callers[cb.CallSid] = c
+	}
+	c.lastSeen = now
+	track := c.documents
+	c.documents++
+	announce := q.AnnounceInterval >= 0 && cb.QueuePosition > 0 &&
+		(c.lastAnnounced.IsZero() || now.Sub(c.lastAnnounced) >= q.AnnounceInterval)
+	if announce {
+		c.lastAnnounced = now
+	}
+	q.mu.Unlock()
+
+	var verbs []texml.Element
+	if announce {
+		text := q.Announce
+		if text == nil {
+			text = Announcement
+		}
+		verbs = append(verbs, texml.VoiceSay{Message: text(cb.QueuePosition, wait), Voice: q.Voice, Language: q.Language})
+	}
+	if len(q.HoldMusic) > 0 {
+		verbs = append(verbs, texml.VoicePlay{Url: q.HoldMusic[track%len(q.HoldMusic)]})
+	} else {
+		verbs = append(verbs, texml.VoicePause{Length: "10"})
+	}
+	return verbs
+}
+
+// WaitHandler serves the wait document of the queue, built by WaitVerbs.
+func (q *Queue) WaitHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cb, err := texml.ParseEnqueueWaitCallback(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		texml.WriteVoice(w, q.WaitVerbs(cb))
+	})
+}
+
+// ResultHandler serves the action of <Enqueue>, requested when a caller leaves the
+// queue, with the verbs returned by fn. fn may return nil to end the call but must not
+// be nil itself. Without a WhisperHandler, the wait estimates are updated from the
+// QueueTime of bridged callers.
+func (q *Queue) ResultHandler(fn func(cb *texml.QueueResultCallback) []texml.Element) http.Handler {
+	if fn == nil {
+		panic("callcenter: nil ResultHandler function")
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cb, err := texml.ParseQueueResultCallback(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// A bridged caller is still known when no whisper was requested for it. The
+		// result comes when the call ends, so it was taken QueueTime after it entered.
+		if c := q.forget(cb.CallSid); c != nil && cb.QueueResult == texml.QueueResultBridged {
+			q.bridged(c.entered.Add(cb.QueueTime))
+		}
+		texml.WriteVoice(w, fn(cb))
+	})
+}
+
+// WhisperHandler serves the document played to a caller taken by an agent, with the
+// verbs returned by fn, which must not be nil. It is requested as the caller leaves the
+// queue, and updates the wait estimates.
+func (q *Queue) WhisperHandler(fn func(cb *texml.DequeueCallback) []texml.Element) http.Handler {
+	if fn == nil {
+		panic("callcenter: nil WhisperHandler function")
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cb, err := texml.ParseDequeueCallback(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q.forget(cb.CallSid)
+		q.bridged(time.Now())
+		texml.WriteVoice(w, fn(cb))
+	})
+}
+
+// forget removes the caller callSid and returns it, or nil if it is not known.
+func (q *Queue) forget(callSid string) *caller {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	c := q.callers[callSid]
+	delete(q.callers, callSid)
+	return c
+}
+
+// bridged records that an agent took a caller at t, and updates the moving average of
+// the time between two callers. Callers reported out of order, taken before the last
+// one, are left out of the average.
+func (q *Queue) bridged(t time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if t.Before(q.lastBridged) {
+		return
+	}
+	if !q.lastBridged.IsZero() {
+		d := t.Sub(q.lastBridged)
+		if q.interval == 0 {
+			q.interval = d
+		} else {
+			q.interval = (3*q.interval + d) / 4
+		}
+	}
+	q.lastBridged = t
+}
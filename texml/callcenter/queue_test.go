@@ -0,0 +1,259 @@
+package callcenter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andersryanc/telnyx-go/texml"
+)
+
+// post serves a form request with params to h and returns the response.
+func post(h http.Handler, params url.Values) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(params.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// wait returns the wait callback of callSid at position after waited.
+func wait(callSid string, position int, waited time.Duration) *texml.EnqueueWaitCallback {
+	return &texml.EnqueueWaitCallback{
+		CallbackCall:  texml.CallbackCall{CallSid: callSid},
+		QueuePosition: position,
+		QueueTime:     waited,
+	}
+}
+
+func (q *Queue) known(callSid string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.callers[callSid] != nil
+}
+
+func TestEnqueueAndDial(t *testing.T) {
+	q := &Queue{Name: "support", WaitUrl: "https://example.com/wait", ActionUrl: "https://example.com/result", WhisperUrl: "https://example.com/whisper"}
+	want := texml.VoiceEnqueue{Name: "support", WaitUrl: "https://example.com/wait", WaitUrlMethod: "POST", Action: "https://example.com/result", Method: "POST"}
+	if e := q.Enqueue(); !reflect.DeepEqual(e, want) {
+		t.Errorf("Enqueue = %+v, want %+v", e, want)
+	}
+	dial := texml.VoiceDial{InnerElements: []texml.Element{texml.VoiceQueue{Name: "support", Url: "https://example.com/whisper", Method: "POST"}}}
+	if d := q.Dial(); !reflect.DeepEqual(d, dial) {
+		t.Errorf("Dial = %+v, want %+v", d, dial)
+	}
+
+	// Methods are only set with their URLs.
+	bare := &Queue{Name: "support"}
+	if e := bare.Enqueue(); !reflect.DeepEqual(e, texml.VoiceEnqueue{Name: "support"}) {
+		t.Errorf("Enqueue without URLs = %+v", e)
+	}
+	if d := bare.Dial(); !reflect.DeepEqual(d.InnerElements, []texml.Element{texml.VoiceQueue{Name: "support"}}) {
+		t.Errorf("Dial without a whisper URL = %+v", d)
+	}
+}
+
+func TestAnnouncement(t *testing.T) {
+	for _, tt := range []struct {
+		position int
+		wait     time.Duration
+		want     string
+	}{
+		{1, 0, "You are next in line."},
+		{3, 0, "You are number 3 in line."},
+		{2, 20 * time.Second, "You are number 2 in line. Your estimated wait time is about one minute."},
+		{4, 3*time.Minute + time.Second, "You are number 4 in line. Your estimated wait time is about 4 minutes."},
+	} {
+		if got := Announcement(tt.position, tt.wait); got != tt.want {
+			t.Errorf("Announcement(%d, %v) = %q, want %q", tt.position, tt.wait, got, tt.want)
+		}
+	}
+}
+
+func TestWaitVerbs(t *testing.T) {
+	q := &Queue{
+		Name:             "support",
+		HoldMusic:        []string{"one.mp3", "two.mp3"},
+		Voice:            "alice",
+		AnnounceInterval: time.Hour,
+		Announce:         func(position int, wait time.Duration) string { return "position " + strconv.Itoa(position) },
+		MaxWait:          10 * time.Minute,
+	}
+
+	verbs := q.WaitVerbs(wait("c1", 2, 0))
+	want := []texml.Element{texml.VoiceSay{Message: "position 2", Voice: "alice"}, texml.VoicePlay{Url: "one.mp3"}}
+	if !reflect.DeepEqual(verbs, want) {
+		t.Errorf("first wait document = %#v, want %#v", verbs, want)
+	}
+	// The next announcement is not due yet, and the music goes on with the next track.
+	for _, track := range []string{"two.mp3", "one.mp3"} {
+		if verbs := q.WaitVerbs(wait("c1", 1, time.Minute)); !reflect.DeepEqual(verbs, []texml.Element{texml.VoicePlay{Url: track}}) {
+			t.Errorf("wait document = %#v, want %s only", verbs, track)
+		}
+	}
+	// Every caller has its own announcements and tracks.
+	if verbs := q.WaitVerbs(wait("c2", 2, 0)); len(verbs) != 2 || !reflect.DeepEqual(verbs[1], texml.VoicePlay{Url: "one.mp3"}) {
+		t.Errorf("wait document of another caller = %#v", verbs)
+	}
+
+	if verbs := q.WaitVerbs(wait("c1", 1, 10*time.Minute)); !reflect.DeepEqual(verbs, []texml.Element{texml.VoiceLeave{}}) {
+		t.Errorf("wait document after MaxWait = %#v, want <Leave>", verbs)
+	}
+	if q.known("c1") {
+		t.Error("a caller that left is still tracked")
+	}
+
+	// Without hold music callers hear a pause, and a negative interval never announces.
+	q = &Queue{Name: "support", AnnounceInterval: -1}
+	if verbs := q.WaitVerbs(wait("c1", 1, 0)); !reflect.DeepEqual(verbs, []texml.Element{texml.VoicePause{Length: "10"}}) {
+		t.Errorf("wait document = %#v, want a pause", verbs)
+	}
+}
+
+func TestForgetStaleCallers(t *testing.T) {
+	q := &Queue{Name: "support"}
+	q.WaitVerbs(wait("gone", 1, 0))
+	q.WaitVerbs(wait("waiting", 2, 0))
+
+	// A caller that hung up without a result request stops asking for wait documents.
+	q.mu.Lock()
+	q.callers["gone"].lastSeen = time.Now().Add(-forgetAfter)
+	q.callers["waiting"].lastSeen = time.Now().Add(-forgetAfter + time.Minute)
+	q.mu.Unlock()
+	q.WaitVerbs(wait("new", 3, 0))
+	if q.known("gone") {
+		t.Error("a caller not seen for forgetAfter is still tracked")
+	}
+	if !q.known("waiting") || !q.known("new") {
+		t.Error("a waiting caller was forgotten")
+	}
+}
+
+func TestEstimatedWaitFromWhisper(t *testing.T) {
+	q := &Queue{Name: "support"}
+	whisper := q.WhisperHandler(func(cb *texml.DequeueCallback) []texml.Element {
+		return []texml.Element{texml.VoiceSay{Message: "Connecting you to " + cb.DequeueingCallSid}}
+	})
+
+	// Until agents have taken two callers, Telnyx's average is used.
+	if got := q.EstimatedWait(&texml.EnqueueWaitCallback{QueuePosition: 3, AvgQueueTime: 90 * time.Second}); got != 90*time.Second {
+		t.Errorf("EstimatedWait without history = %v, want the average time in queue", got)
+	}
+
+	q.WaitVerbs(wait("c1", 1, 0))
+	q.mu.Lock()
+	q.lastBridged = time.Now().Add(-2 * time.Minute)
+	q.mu.Unlock()
+	w := post(whisper, url.Values{"CallSid": {"c1"}, "DequeueingCallSid": {"agent1"}, "QueueTime": {"120"}})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Connecting you to agent1") {
+		t.Errorf("whisper response = %d %s", w.Code, w.Body)
+	}
+	if q.known("c1") {
+		t.Error("a caller taken by an agent is still tracked")
+	}
+	got := q.EstimatedWait(wait("c2", 3, 0))
+	if got < 6*time.Minute || got > 6*time.Minute+time.Second {
+		t.Errorf("EstimatedWait at position 3 = %v, want about 6m", got)
+	}
+
+	// The result of a whispered caller comes when its conversation ends, and is not
+	// counted again.
+	q.mu.Lock()
+	last := q.lastBridged
+	q.mu.Unlock()
+	result := q.ResultHandler(func(cb *texml.QueueResultCallback) []texml.Element { return nil })
+	post(result, url.Values{"CallSid": {"c1"}, "QueueResult": {"bridged"}, "QueueTime": {"120"}})
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.lastBridged.Equal(last) {
+		t.Errorf("the result of a whispered caller moved the last bridge from %v to %v", last, q.lastBridged)
+	}
+}
+
+func TestEstimatedWaitFromResult(t *testing.T) {
+	q := &Queue{Name: "support"}
+	var results []texml.QueueResult
+	result := q.ResultHandler(func(cb *texml.QueueResultCallback) []texml.Element {
+		results = append(results, cb.QueueResult)
+		if cb.QueueResult == texml.QueueResultLeave {
+			return []texml.Element{texml.VoiceRecord{}}
+		}
+		return nil
+	})
+
+	entered := time.Now().Add(-time.Hour)
+	for _, sid := range []string{"c1", "c2", "c3"} {
+		q.WaitVerbs(wait(sid, 1, 0))
+		q.mu.Lock()
+		q.callers[sid].entered = entered
+		q.mu.Unlock()
+	}
+
+	// The callers were taken 1 and 4 minutes after they entered, and their
+	// conversations ended much later.
+	post(result, url.Values{"CallSid": {"c1"}, "QueueResult": {"bridged"}, "QueueTime": {"60"}})
+	post(result, url.Values{"CallSid": {"c2"}, "QueueResult": {"bridged"}, "QueueTime": {"240"}})
+	if got := q.EstimatedWait(wait("c4", 2, 0)); got != 6*time.Minute {
+		t.Errorf("EstimatedWait at position 2 = %v, want 6m", got)
+	}
+	// A caller taken before the last one does not count.
+	post(result, url.Values{"CallSid": {"c3"}, "QueueResult": {"bridged"}, "QueueTime": {"120"}})
+	if got := q.EstimatedWait(wait("c4", 1, 0)); got != 3*time.Minute {
+		t.Errorf("EstimatedWait after an earlier bridge = %v, want 3m", got)
+	}
+
+	w := post(result, url.Values{"CallSid": {"c4"}, "QueueResult": {"leave"}, "QueueTime": {"600"}})
+	if verbs := w.Body.String(); !strings.Contains(verbs, "<Record") {
+		t.Errorf("leave response = %s, want <Record>", verbs)
+	}
+	if q.known("c4") {
+		t.Error("a caller that left is still tracked")
+	}
+	if want := []texml.QueueResult{"bridged", "bridged", "bridged", "leave"}; !reflect.DeepEqual(results, want) {
+		t.Errorf("results = %v, want %v", results, want)
+	}
+}
+
+func TestWaitHandler(t *testing.T) {
+	q := &Queue{Name: "support", HoldMusic: []string{"music.mp3"}}
+	w := post(q.WaitHandler(), url.Values{"CallSid": {"c1"}, "QueuePosition": {"1"}, "QueueTime": {"0"}})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "You are next in line.") || !strings.Contains(w.Body.String(), "music.mp3") {
+		t.Errorf("wait response = %d %s", w.Code, w.Body)
+	}
+
+	for name, h := range map[string]http.Handler{
+		"wait":    q.WaitHandler(),
+		"result":  q.ResultHandler(func(*texml.QueueResultCallback) []texml.Element { return nil }),
+		"whisper": q.WhisperHandler(func(*texml.DequeueCallback) []texml.Element { return nil }),
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("QueuePosition=%zz"))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s handler status for a malformed request = %d, want 400", name, w.Code)
+		}
+	}
+}
+
+func TestNilHandlerFunc(t *testing.T) {
+	q := &Queue{Name: "support"}
+	for name, register := range map[string]func(){
+		"ResultHandler":  func() { q.ResultHandler(nil) },
+		"WhisperHandler": func() { q.WhisperHandler(nil) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s accepted a nil function", name)
+				}
+			}()
+			register()
+		}()
+	}
+}
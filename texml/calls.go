@@ -0,0 +1,198 @@
+package texml
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// CallStatus is the state of a TeXML call.
+type CallStatus string
+
+const (
+	CallStatusQueued     CallStatus = "queued"
+	CallStatusRinging    CallStatus = "ringing"
+	CallStatusInProgress CallStatus = "in-progress"
+	CallStatusCompleted  CallStatus = "completed"
+	CallStatusBusy       CallStatus = "busy"
+	CallStatusFailed     CallStatus = "failed"
+	CallStatusNoAnswer   CallStatus = "no-answer"
+	CallStatusCanceled   CallStatus = "canceled"
+)
+
+// Ended reports whether the call is over and can no longer be updated.
+func (s CallStatus) Ended() bool {
+	switch s {
+	case CallStatusCompleted, CallStatusBusy, CallStatusFailed, CallStatusNoAnswer, CallStatusCanceled:
+		return true
+	}
+	return false
+}
+
+// Call is a call controlled by a TeXML application.
+//
+// https://developers.telnyx.com/api/call-scripting/update-texml-call
+type Call struct {
+	Sid           string     `json:"sid"`
+	CallSid       string     `json:"call_sid"`
+	AccountSid    string     `json:"account_sid"`
+	AnsweredBy    string     `json:"answered_by"`
+	CallerName    string     `json:"caller_name"`
+	DateCreated   string     `json:"date_created"`
+	DateUpdated   string     `json:"date_updated"`
+	Direction     string     `json:"direction"`
+	Duration      string     `json:"duration"`
+	StartTime     string     `json:"start_time"`
+	EndTime       string     `json:"end_time"`
+	From          string     `json:"from"`
+	FromFormatted string     `json:"from_formatted"`
+	To            string     `json:"to"`
+	ToFormatted   string     `json:"to_formatted"`
+	Price         string     `json:"price"`
+	PriceUnit     string     `json:"price_unit"`
+	Status        CallStatus `json:"status"`
+	Uri           string     `json:"uri"`
+}
+
+// CallParams are the fields of an outbound call started by CreateCall. Either Url or
+// Texml tells Telnyx what to do once the call is answered; Texml is rendered with Voice
+// and sent inline.
+//
+// https://developers.telnyx.com/api/call-scripting/initiate-texml-call
+type CallParams struct {
+	To                                 string    `json:"To"`
+	From                               string    `json:"From"`
+	CallerId                           string    `json:"CallerId,omitempty"`
+	Url                                string    `json:"Url,omitempty"`
+	UrlMethod                          string    `json:"UrlMethod,omitempty"`
+	FallbackUrl                        string    `json:"FallbackUrl,omitempty"`
+	Texml                              []Element `json:"-"`
+	StatusCallback                     string    `json:"StatusCallback,omitempty"`
+	StatusCallbackMethod               string    `json:"StatusCallbackMethod,omitempty"`
+	StatusCallbackEvent                string    `json:"StatusCallbackEvent,omitempty"`
+	MachineDetection                   string    `json:"MachineDetection,omitempty"`
+	DetectionMode                      string    `json:"DetectionMode,omitempty"`
+	MachineDetectionTimeout            *int      `json:"MachineDetectionTimeout,omitempty"`
+	MachineDetectionSpeechThreshold    *int      `json:"MachineDetectionSpeechThreshold,omitempty"`
+	MachineDetectionSpeechEndThreshold *int      `json:"MachineDetectionSpeechEndThreshold,omitempty"`
+	MachineDetectionSilenceTimeout     *int      `json:"MachineDetectionSilenceTimeout,omitempty"`
+	AsyncAmd                           *bool     `json:"AsyncAmd,omitempty"`
+	AsyncAmdStatusCallback             string    `json:"AsyncAmdStatusCallback,omitempty"`
+	AsyncAmdStatusCallbackMethod       string    `json:"AsyncAmdStatusCallbackMethod,omitempty"`
+	CancelPlaybackOnMachineDetection   *bool     `json:"CancelPlaybackOnMachineDetection,omitempty"`
+	CancelPlaybackOnDetectMessageEnd   *bool     `json:"CancelPlaybackOnDetectMessageEnd,omitempty"`
+	Record                             *bool     `json:"Record,omitempty"`
+	RecordingChannels                  string    `json:"RecordingChannels,omitempty"`
+	RecordingStatusCallback            string    `json:"RecordingStatusCallback,omitempty"`
+	RecordingStatusCallbackMethod      string    `json:"RecordingStatusCallbackMethod,omitempty"`
+	RecordingStatusCallbackEvent       string    `json:"RecordingStatusCallbackEvent,omitempty"`
+	RecordingTimeout                   *int      `json:"RecordingTimeout,omitempty"`
+	RecordingTrack                     string    `json:"RecordingTrack,omitempty"`
+	Trim                               string    `json:"Trim,omitempty"`
+	SipAuthUsername                    string    `json:"SipAuthUsername,omitempty"`
+	SipAuthPassword                    string    `json:"SipAuthPassword,omitempty"`
+}
+
+// UpdateCallParams change a call in progress. Setting Url or Texml replaces the TeXML
+// the call is executing; setting Status to CallStatusCompleted hangs it up, and to
+// CallStatusCanceled cancels a call that has not been answered yet.
+//
+// https://developers.telnyx.com/api/call-scripting/update-texml-call
+type UpdateCallParams struct {
+	Status               CallStatus `json:"Status,omitempty"`
+	Url                  string     `json:"Url,omitempty"`
+	Method               string     `json:"Method,omitempty"`
+	FallbackUrl          string     `json:"FallbackUrl,omitempty"`
+	FallbackMethod       string     `json:"FallbackMethod,omitempty"`
+	Texml                []Element  `json:"-"`
+	StatusCallback       string     `json:"StatusCallback,omitempty"`
+	StatusCallbackMethod string     `json:"StatusCallbackMethod,omitempty"`
+}
+
+func (p *CallParams) body() (interface{}, error) {
+	if p == nil {
+		// Do rejects the nil params.
+		return p, nil
+	}
+	type params CallParams
+	texml, err := inlineTexml(p.Texml)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		*params
+		Texml string `json:"Texml,omitempty"`
+	}{(*params)(p), texml}, nil
+}
+
+func (p *UpdateCallParams) body() (interface{}, error) {
+	if p == nil {
+		return p, nil
+	}
+	type params UpdateCallParams
+	texml, err := inlineTexml(p.Texml)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		*params
+		Texml string `json:"Texml,omitempty"`
+	}{(*params)(p), texml}, nil
+}
+
+func inlineTexml(verbs []Element) (string, error) {
+	if verbs == nil {
+		return "", nil
+	}
+	return Voice(verbs)
+}
+
+// CreateCall starts an outbound call from the TeXML application applicationID.
+func (c *Client) CreateCall(ctx context.Context, applicationID string, params *CallParams) (*Call, error) {
+	body, err := params.body()
+	if err != nil {
+		return nil, err
+	}
+	var call Call
+	if err := c.client.Do(ctx, http.MethodPost, "texml/calls/"+url.PathEscape(applicationID), body, &call); err != nil {
+		return nil, err
+	}
+	return &call, nil
+}
+
+func (c *Client) GetCall(ctx context.Context, callSid string) (*Call, error) {
+	var call Call
+	if err := c.client.Do(ctx, http.MethodGet, c.accountPath("Calls", callSid), nil, &call); err != nil {
+		return nil, err
+	}
+	return &call, nil
+}
+
+func (c *Client) UpdateCall(ctx context.Context, callSid string, params *UpdateCallParams) (*Call, error) {
+	body, err := params.body()
+	if err != nil {
+		return nil, err
+	}
+	var call Call
+	if err := c.client.Do(ctx, http.MethodPost, c.accountPath("Calls", callSid), body, &call); err != nil {
+		return nil, err
+	}
+	return &call, nil
+}
+
+// UpdateCallTeXML replaces the TeXML a call in progress is executing with verbs, for
+// example to take a caller out of hold music or to play a warning before a timeout.
+// The call continues with the first of verbs as soon as Telnyx receives the request.
+func (c *Client) UpdateCallTeXML(ctx context.Context, callSid string, verbs []Element) (*Call, error) {
+	return c.UpdateCall(ctx, callSid, &UpdateCallParams{Texml: verbs})
+}
+
+// RedirectCall makes a call in progress fetch and execute the TeXML at redirectUrl.
+func (c *Client) RedirectCall(ctx context.Context, callSid, redirectUrl, method string) (*Call, error) {
+	return c.UpdateCall(ctx, callSid, &UpdateCallParams{Url: redirectUrl, Method: method})
+}
+
+// HangupCall ends a call in progress.
+func (c *Client) HangupCall(ctx context.Context, callSid string) (*Call, error) {
+	return c.UpdateCall(ctx, callSid, &UpdateCallParams{Status: CallStatusCompleted})
+}
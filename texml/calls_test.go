@@ -0,0 +1,166 @@
+package texml
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+	"github.com/andersryanc/telnyx-go/internal/apitest"
+)
+
+const callJSON = `{"sid":"v3:abc","call_sid":"v3:abc","account_sid":"AC123","from":"+13125550100",
+	"to":"+13125550199","direction":"outbound-api","status":"queued","uri":"/v2/texml/Accounts/AC123/Calls/v3:abc"}`
+
+func TestCreateCall(t *testing.T) {
+	rec := apitest.NewRecorder(t, apitest.Reply(http.StatusOK, callJSON))
+	c := NewClient(rec.Client(), testAccountSid)
+
+	call, err := c.CreateCall(context.Background(), "app1", &CallParams{
+		From:                    "+13125550100",
+		To:                      "+13125550199",
+		StatusCallback:          "https://example.com/status",
+		MachineDetectionTimeout: telnyx.Int(5000),
+		Record:                  telnyx.Bool(true),
+		Texml:                   []Element{VoiceSay{Message: "Hello"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rec.Expect(http.MethodPost, "/texml/calls/app1")
+	for key, want := range map[string]interface{}{
+		"From":                    "+13125550100",
+		"To":                      "+13125550199",
+		"StatusCallback":          "https://example.com/status",
+		"MachineDetectionTimeout": float64(5000),
+		"Record":                  true,
+	} {
+		if r.Body[key] != want {
+			t.Errorf("%s = %v, want %v", key, r.Body[key], want)
+		}
+	}
+	if texml, _ := r.Body["Texml"].(string); !strings.Contains(texml, "<Response><Say>Hello</Say></Response>") {
+		t.Errorf("Texml = %q, want the rendered document", r.Body["Texml"])
+	}
+	if _, ok := r.Body["Url"]; ok {
+		t.Error("empty Url was sent")
+	}
+	if call.Sid != "v3:abc" || call.Status != CallStatusQueued || call.Direction != "outbound-api" {
+		t.Errorf("CreateCall = %+v", call)
+	}
+}
+
+func TestCreateCallWithUrl(t *testing.T) {
+	rec := apitest.NewRecorder(t, apitest.Reply(http.StatusOK, callJSON))
+	c := NewClient(rec.Client(), testAccountSid)
+
+	if _, err := c.CreateCall(context.Background(), "app1", &CallParams{From: "+13125550100", To: "+13125550199", Url: "https://example.com/texml"}); err != nil {
+		t.Fatal(err)
+	}
+	r := rec.Last()
+	if r.Body["Url"] != "https://example.com/texml" {
+		t.Errorf("Url = %v", r.Body["Url"])
+	}
+	if _, ok := r.Body["Texml"]; ok {
+		t.Error("Texml was sent without verbs")
+	}
+}
+
+func TestGetAndUpdateCall(t *testing.T) {
+	rec := apitest.NewRecorder(t, apitest.Reply(http.StatusOK, callJSON))
+	c := NewClient(rec.Client(), testAccountSid)
+	ctx := context.Background()
+
+	if _, err := c.GetCall(ctx, "v3:abc"); err != nil {
+		t.Fatal(err)
+	}
+	rec.Expect(http.MethodGet, "/texml/Accounts/AC123/Calls/v3:abc")
+
+	if _, err := c.RedirectCall(ctx, "v3:abc", "https://example.com/next", http.MethodGet); err != nil {
+		t.Fatal(err)
+	}
+	r := rec.Expect(http.MethodPost, "/texml/Accounts/AC123/Calls/v3:abc")
+	if r.Body["Url"] != "https://example.com/next" || r.Body["Method"] != http.MethodGet || len(r.Body) != 2 {
+		t.Errorf("redirect body = %v", r.Body)
+	}
+
+	if _, err := c.HangupCall(ctx, "v3:abc"); err != nil {
+		t.Fatal(err)
+	}
+	if r := rec.Last(); r.Body["Status"] != "completed" || len(r.Body) != 1 {
+		t.Errorf("hangup body = %v", r.Body)
+	}
+}
+
+func TestCallNilParams(t *testing.T) {
+	rec := apitest.NewRecorder(t, apitest.Reply(http.StatusOK, callJSON))
+	c := NewClient(rec.Client(), testAccountSid)
+
+	if _, err := c.CreateCall(context.Background(), "app1", nil); !errors.Is(err, telnyx.ErrNilParams) {
+		t.Errorf("CreateCall(nil) error = %v", err)
+	}
+	if _, err := c.UpdateCall(context.Background(), "v3:abc", nil); !errors.Is(err, telnyx.ErrNilParams) {
+		t.Errorf("UpdateCall(nil) error = %v", err)
+	}
+	if len(rec.Requests()) != 0 {
+		t.Errorf("%d requests were sent", len(rec.Requests()))
+	}
+}
+
+func TestCallError(t *testing.T) {
+	rec := apitest.NewRecorder(t, func(apitest.Request) (int, string) {
+		return http.StatusUnprocessableEntity, `{"errors":[{"code":"90018","title":"Call has already ended"}]}`
+	})
+	c := NewClient(rec.Client(), testAccountSid)
+
+	_, err := c.HangupCall(context.Background(), "v3:abc")
+	var apiErr *telnyx.Error
+	if !errors.As(err, &apiErr) || !apiErr.HasCode("90018") {
+		t.Errorf("HangupCall error = %v", err)
+	}
+}
+
+func TestUpdateCallTeXML(t *testing.T) {
+	rec := apitest.NewRecorder(t, func(apitest.Request) (int, string) {
+		return http.StatusOK, strings.Replace(callJSON, `"queued"`, `"in-progress"`, 1)
+	})
+	c := NewClient(rec.Client(), testAccountSid)
+
+	call, err := c.UpdateCallTeXML(context.Background(), "v3:abc", []Element{
+		VoiceSay{Message: "Your call will end in one minute."},
+		VoiceRedirect{Url: "https://example.com/hold"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rec.Expect(http.MethodPost, "/texml/Accounts/AC123/Calls/v3:abc")
+	if len(r.Body) != 1 {
+		t.Errorf("body = %v, want only Texml", r.Body)
+	}
+	doc := readResponse(t, r.Body["Texml"].(string))
+	if children := doc.ChildElements(); len(children) != 2 || children[0].Tag != "Say" || children[1].Tag != "Redirect" {
+		t.Errorf("Texml = %s", r.Body["Texml"])
+	}
+	if call.Status != CallStatusInProgress || call.Status.Ended() {
+		t.Errorf("Status = %q", call.Status)
+	}
+}
+
+func TestCallStatusEnded(t *testing.T) {
+	for status, ended := range map[CallStatus]bool{
+		CallStatusQueued:     false,
+		CallStatusRinging:    false,
+		CallStatusInProgress: false,
+		CallStatusCompleted:  true,
+		CallStatusBusy:       true,
+		CallStatusFailed:     true,
+		CallStatusNoAnswer:   true,
+		CallStatusCanceled:   true,
+	} {
+		if status.Ended() != ended {
+			t.Errorf("%s.Ended() = %v, want %v", status, !ended, ended)
+		}
+	}
+}
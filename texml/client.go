@@ -0,0 +1,29 @@
+package texml
+
+import (
+	"net/url"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+)
+
+// Client manages TeXML resources through the Telnyx REST API.
+type Client struct {
+	client     *telnyx.Client
+	accountSid string
+}
+
+// NewClient returns a Client that sends its requests through client. accountSid is the
+// account used in the /texml/Accounts paths of calls, conferences, queues and
+// recordings; it may be empty when only applications are managed.
+func NewClient(client *telnyx.Client, accountSid string) *Client {
+	return &Client{client: client, accountSid: accountSid}
+}
+
+// accountPath returns the path of a resource below /texml/Accounts/{accountSid}.
+func (c *Client) accountPath(elem ...string) string {
+	path := "texml/Accounts/" + url.PathEscape(c.accountSid)
+	for _, e := range elem {
+		path += "/" + url.PathEscape(e)
+	}
+	return path
+}
@@ -0,0 +1,50 @@
+package texml
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+	"github.com/andersryanc/telnyx-go/internal/apitest"
+)
+
+const testAccountSid = "AC123"
+
+// newTestClient returns a Client for a server running handler, with retries disabled.
+// Tests checking the requests sent use an apitest.Recorder instead.
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	client, err := telnyx.NewClientWithParams(telnyx.ClientParams{APIKey: "KEY123", BaseURL: srv.URL, MaxRetries: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClient(client, testAccountSid)
+}
+
+func TestNilParams(t *testing.T) {
+	rec := apitest.NewRecorder(t, apitest.Reply(http.StatusOK, "{}"))
+	c := NewClient(rec.Client(), testAccountSid)
+	ctx := context.Background()
+
+	for name, call := range map[string]func() error{
+		"CreateApplication": func() error { _, err := c.CreateApplication(ctx, nil); return err },
+		"UpdateApplication": func() error { _, err := c.UpdateApplication(ctx, "app1", nil); return err },
+		"UpdateConference":  func() error { _, err := c.UpdateConference(ctx, "cf1", nil); return err },
+		"CreateParticipant": func() error { _, err := c.CreateParticipant(ctx, "cf1", nil); return err },
+		"UpdateParticipant": func() error { _, err := c.UpdateParticipant(ctx, "cf1", "v3:abc", nil); return err },
+		"CreateQueue":       func() error { _, err := c.CreateQueue(ctx, nil); return err },
+		"UpdateQueue":       func() error { _, err := c.UpdateQueue(ctx, "qu1", nil); return err },
+	} {
+		if err := call(); !errors.Is(err, telnyx.ErrNilParams) {
+			t.Errorf("%s(nil) = %v, want telnyx.ErrNilParams", name, err)
+		}
+	}
+	if len(rec.Requests()) != 0 {
+		t.Errorf("requests with nil params were sent: %v", rec.Requests())
+	}
+}
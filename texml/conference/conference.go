@@ -0,0 +1,127 @@
+// Package conference orchestrates TeXML conferences: the <Dial><Conference> that puts
+// moderators, participants and listeners in a room, warm and cold transfers between
+// agents, supervisors that listen, coach or barge in, and a typed handler for the
+// conference status callbacks.
+//
+//	room := conference.Room{
+//		Name:           "support-1234",
+//		WaitUrl:        "https://example.com/music/hold.xml",
+//		StatusCallback: "https://example.com/conference/status",
+//	}
+//	http.Handle("/conference/status", room.StatusHandler(func(cb *texml.ConferenceStatusCallback) {
+//		log.Println(cb.FriendlyName, cb.Event, cb.CallSid)
+//	}))
+//
+//	// Caller side, waits for the agent:
+//	doc, err := texml.Voice([]texml.Element{room.Participant()})
+//	// Agent side, starts the conference:
+//	doc, err = texml.Voice([]texml.Element{room.Moderator()})
+//
+// Transfers and supervisors act on a conference in progress through the REST API:
+//
+//	ctl := conference.NewController(client, cb.ConferenceSid)
+//	transfer, err := ctl.WarmTransfer(ctx, callerSid, holdUrl, &texml.CreateParticipantParams{From: from, To: to})
+//	// ... once the agents have talked:
+//	err = transfer.Complete(ctx, agentSid)
+package conference
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/andersryanc/telnyx-go/texml"
+)
+
+// DefaultStatusCallbackEvent is the statusCallbackEvent of a Room without one.
+const DefaultStatusCallbackEvent = "start end join leave mute hold"
+
+// Room describes a conference and builds the TeXML that joins calls to it.
+type Room struct {
+	// Name is the name of the conference, unique within the account.
+	Name string
+	// WaitUrl is played to participants until the conference starts. Empty plays the
+	// default hold music.
+	WaitUrl    string
+	WaitMethod string
+	// Beep is "true", "false", "onEnter" or "onExit".
+	Beep            string
+	MaxParticipants int
+	// Record is "record-from-start" or "do-not-record".
+	Record                  string
+	RecordingStatusCallback string
+
+	StatusCallback string
+	// StatusCallbackEvent lists, space separated, the events sent to StatusCallback. It
+	// defaults to DefaultStatusCallbackEvent.
+	StatusCallbackEvent string
+}
+
+// JoinOptions configure how a call joins a Room.
+type JoinOptions struct {
+	Muted bool
+	// StartConferenceOnEnter starts the conference when the call joins. Calls that do
+	// not start it hear the WaitUrl until a call that does joins.
+	StartConferenceOnEnter bool
+	// EndConferenceOnExit ends the conference, for every participant, when the call
+	// leaves.
+	EndConferenceOnExit bool
+}
+
+// Join returns the <Dial><Conference> that joins a call to the room.
+func (r Room) Join(opts JoinOptions) texml.VoiceDial {
+	c := texml.VoiceConference{
+		Name:                    r.Name,
+		Muted:                   strconv.FormatBool(opts.Muted),
+		StartConferenceOnEnter:  strconv.FormatBool(opts.StartConferenceOnEnter),
+		EndConferenceOnExit:     strconv.FormatBool(opts.EndConferenceOnExit),
+		Beep:                    r.Beep,
+		Record:                  r.Record,
+		RecordingStatusCallback: r.RecordingStatusCallback,
+		WaitUrl:                 r.WaitUrl,
+		WaitMethod:              r.WaitMethod,
+	}
+	if r.MaxParticipants > 0 {
+		c.MaxParticipants = strconv.Itoa(r.MaxParticipants)
+	}
+	if r.StatusCallback != "" {
+		c.StatusCallback = r.StatusCallback
+		c.StatusCallbackMethod = http.MethodPost
+		c.StatusCallbackEvent = r.StatusCallbackEvent
+		if c.StatusCallbackEvent == "" {
+			c.StatusCallbackEvent = DefaultStatusCallbackEvent
+		}
+	}
+	return texml.VoiceDial{InnerElements: []texml.Element{c}}
+}
+
+// Moderator joins a call that starts the conference and ends it when it leaves.
+func (r Room) Moderator() texml.VoiceDial {
+	return r.Join(JoinOptions{StartConferenceOnEnter: true, EndConferenceOnExit: true})
+}
+
+// Participant joins a call that waits for a moderator, hearing the WaitUrl meanwhile.
+func (r Room) Participant() texml.VoiceDial {
+	return r.Join(JoinOptions{})
+}
+
+// Listener joins a muted call that waits for a moderator.
+func (r Room) Listener() texml.VoiceDial {
+	return r.Join(JoinOptions{Muted: true})
+}
+
+// StatusHandler serves the StatusCallback of the room, passing each callback to fn,
+// which must not be nil.
+func (r Room) StatusHandler(fn func(cb *texml.ConferenceStatusCallback)) http.Handler {
+	if fn == nil {
+		panic("conference: nil StatusHandler function")
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		cb, err := texml.ParseConferenceStatusCallback(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fn(cb)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
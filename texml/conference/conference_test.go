@@ -0,0 +1,87 @@
+package conference
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andersryanc/telnyx-go/texml"
+)
+
+func TestRoomJoin(t *testing.T) {
+	room := Room{
+		Name:            "support-1234",
+		WaitUrl:         "https://example.com/hold.xml",
+		Beep:            "onEnter",
+		MaxParticipants: 4,
+		StatusCallback:  "https://example.com/status",
+	}
+	for _, tt := range []struct {
+		name                  string
+		dial                  texml.VoiceDial
+		muted, start, endExit string
+	}{
+		{"moderator", room.Moderator(), "false", "true", "true"},
+		{"participant", room.Participant(), "false", "false", "false"},
+		{"listener", room.Listener(), "true", "false", "false"},
+	} {
+		want := texml.VoiceConference{
+			Name:                   "support-1234",
+			Muted:                  tt.muted,
+			StartConferenceOnEnter: tt.start,
+			EndConferenceOnExit:    tt.endExit,
+			Beep:                   "onEnter",
+			MaxParticipants:        "4",
+			WaitUrl:                "https://example.com/hold.xml",
+			StatusCallback:         "https://example.com/status",
+			StatusCallbackMethod:   "POST",
+			StatusCallbackEvent:    DefaultStatusCallbackEvent,
+		}
+		if !reflect.DeepEqual(tt.dial.InnerElements, []texml.Element{want}) {
+			t.Errorf("%s = %+v, want %+v", tt.name, tt.dial.InnerElements, want)
+		}
+	}
+
+	// Without a status callback, no events are requested.
+	c := Room{Name: "support-1234"}.Participant().InnerElements[0].(texml.VoiceConference)
+	if c.StatusCallbackMethod != "" || c.StatusCallbackEvent != "" || c.MaxParticipants != "" {
+		t.Errorf("conference without a status callback = %+v", c)
+	}
+}
+
+func TestStatusHandler(t *testing.T) {
+	var got *texml.ConferenceStatusCallback
+	h := Room{Name: "support-1234"}.StatusHandler(func(cb *texml.ConferenceStatusCallback) { got = cb })
+
+	r := httptest.NewRequest(http.MethodPost, "/status", strings.NewReader(
+		"ConferenceSid=cf1&FriendlyName=support-1234&StatusCallbackEvent=participant-join&CallSid=v3%3Aabc"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want 204", w.Code)
+	}
+	if got == nil || got.ConferenceSid != "cf1" || got.CallSid != "v3:abc" || got.Event != texml.ConferenceEventParticipantJoin {
+		t.Errorf("callback = %+v", got)
+	}
+
+	got = nil
+	r = httptest.NewRequest(http.MethodPost, "/status", strings.NewReader("ConferenceSid=%zz"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest || got != nil {
+		t.Errorf("malformed callback: status %d, callback %+v", w.Code, got)
+	}
+}
+
+func TestNilStatusHandlerFunc(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("StatusHandler accepted a nil function")
+		}
+	}()
+	Room{Name: "support-1234"}.StatusHandler(nil)
+}
@@ -0,0 +1,197 @@
+package conference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+	"github.com/andersryanc/telnyx-go/texml"
+)
+
+// Controller acts on a conference in progress.
+type Controller struct {
+	client        *texml.Client
+	conferenceSid string
+}
+
+// NewController returns a Controller for the conference conferenceSid, e.g. the
+// ConferenceSid of a ConferenceStatusCallback.
+func NewController(client *texml.Client, conferenceSid string) *Controller {
+	return &Controller{client: client, conferenceSid: conferenceSid}
+}
+
+// ConferenceSid returns the conference the controller acts on.
+func (c *Controller) ConferenceSid() string {
+	return c.conferenceSid
+}
+
+// ColdTransfer dials to into the conference and removes the call fromCallSid without
+// waiting for the new participant to answer. The conference keeps running even if
+// fromCallSid joined as a moderator.
+func (c *Controller) ColdTransfer(ctx context.Context, fromCallSid string, to *texml.CreateParticipantParams) (*texml.Participant, error) {
+	if to == nil {
+		return nil, fmt.Errorf("conference: cold transfer: %w", telnyx.ErrNilParams)
+	}
+	p, err := c.client.CreateParticipant(ctx, c.conferenceSid, transferParams(to))
+	if err != nil {
+		return nil, fmt.Errorf("conference: cold transfer: %w", err)
+	}
+	if err := c.leave(ctx, fromCallSid); err != nil {
+		return p, fmt.Errorf("conference: cold transfer: %w", err)
+	}
+	return p, nil
+}
+
+// leave removes the call callSid from the conference without ending it, even when the
+// call is a moderator.
+func (c *Controller) leave(ctx context.Context, callSid string) error {
+	params := &texml.UpdateParticipantParams{EndConferenceOnExit: telnyx.Bool(false)}
+	if _, err := c.client.UpdateParticipant(ctx, c.conferenceSid, callSid, params); err != nil {
+		return err
+	}
+	return c.client.KickParticipant(ctx, c.conferenceSid, callSid)
+}
+
+// WarmTransfer puts the call callerCallSid on hold, hearing holdUrl, and dials to into
+// the conference so the current agent can talk to it first. The transfer is then
+// completed or canceled through the returned WarmTransfer. If to cannot be dialed, the
+// caller is taken off hold again, and a failure to do so is joined to the error.
+func (c *Controller) WarmTransfer(ctx context.Context, callerCallSid, holdUrl string, to *texml.CreateParticipantParams) (*WarmTransfer, error) {
+	// Nil params are rejected before the caller is put on hold.
+	if to == nil {
+		return nil, fmt.Errorf("conference: warm transfer: %w", telnyx.ErrNilParams)
+	}
+	if _, err := c.client.HoldParticipant(ctx, c.conferenceSid, callerCallSid, holdUrl); err != nil {
+		return nil, fmt.Errorf("conference: warm transfer: %w", err)
+	}
+	p, err := c.client.CreateParticipant(ctx, c.conferenceSid, transferParams(to))
+	if err != nil {
+		if _, unholdErr := c.client.UnholdParticipant(ctx, c.conferenceSid, callerCallSid); unholdErr != nil {
+			err = errors.Join(err, fmt.Errorf("take caller off hold: %w", unholdErr))
+		}
+		return nil, fmt.Errorf("conference: warm transfer: %w", err)
+	}
+	return &WarmTransfer{c: c, CallerCallSid: callerCallSid, Target: p}, nil
+}
+
+// transferParams returns a copy of to, which must not be nil, that leaves the
+// conference running when the transfer target hangs up, unless set otherwise.
+func transferParams(to *texml.CreateParticipantParams) *texml.CreateParticipantParams {
+	params := *to
+	if params.StartConferenceOnEnter == nil {
+		params.StartConferenceOnEnter = telnyx.Bool(true)
+	}
+	if params.EndConferenceOnExit == nil {
+		params.EndConferenceOnExit = telnyx.Bool(false)
+	}
+	return &params
+}
+
+// WarmTransfer is a transfer started by Controller.WarmTransfer.
+type WarmTransfer struct {
+	c *Controller
+	// CallerCallSid is the call on hold.
+	CallerCallSid string
+	// Target is the participant the caller is transferred to.
+	Target *texml.Participant
+}
+
+// Complete takes the caller off hold and removes the call agentCallSid, leaving the
+// caller with the target. The conference keeps running even if agentCallSid joined as
+// a moderator.
+func (t *WarmTransfer) Complete(ctx context.Context, agentCallSid string) error {
+	if _, err := t.c.client.UnholdParticipant(ctx, t.c.conferenceSid, t.CallerCallSid); err != nil {
+		return fmt.Errorf("conference: complete transfer: %w", err)
+	}
+	if err := t.c.leave(ctx, agentCallSid); err != nil {
+		return fmt.Errorf("conference: complete transfer: %w", err)
+	}
+	return nil
+}
+
+// Cancel removes the target and takes the caller off hold, back with the current agent.
+func (t *WarmTransfer) Cancel(ctx context.Context) error {
+	if err := t.c.client.KickParticipant(ctx, t.c.conferenceSid, t.Target.CallSid); err != nil {
+		return fmt.Errorf("conference: cancel transfer: %w", err)
+	}
+	if _, err := t.c.client.UnholdParticipant(ctx, t.c.conferenceSid, t.CallerCallSid); err != nil {
+		return fmt.Errorf("conference: cancel transfer: %w", err)
+	}
+	return nil
+}
+
+// SupervisorMode is how a supervisor takes part in a conference.
+type SupervisorMode string
+
+const (
+	// SupervisorListen mutes the supervisor.
+	SupervisorListen SupervisorMode = "listen"
+	// SupervisorCoach lets the supervisor talk to the agent only.
+	SupervisorCoach SupervisorMode = "coach"
+	// SupervisorWhisper is another name for SupervisorCoach.
+	SupervisorWhisper = SupervisorCoach
+	// SupervisorBarge lets the supervisor talk to everyone.
+	SupervisorBarge SupervisorMode = "barge"
+)
+
+// AddSupervisor dials params into the conference as a supervisor of the call
+// agentCallSid, in mode. The supervisor neither starts nor ends the conference, unless
+// params say otherwise.
+func (c *Controller) AddSupervisor(ctx context.Context, mode SupervisorMode, agentCallSid string, params *texml.CreateParticipantParams) (*texml.Participant, error) {
+	if params == nil {
+		return nil, fmt.Errorf("conference: add supervisor: %w", telnyx.ErrNilParams)
+	}
+	muted, coaching, err := mode.flags()
+	if err != nil {
+		return nil, err
+	}
+	p := *params
+	p.Muted = telnyx.Bool(muted)
+	p.Coaching = telnyx.Bool(coaching)
+	p.CallSidToCoach = ""
+	if coaching {
+		p.CallSidToCoach = agentCallSid
+	}
+	if p.StartConferenceOnEnter == nil {
+		p.StartConferenceOnEnter = telnyx.Bool(false)
+	}
+	if p.EndConferenceOnExit == nil {
+		p.EndConferenceOnExit = telnyx.Bool(false)
+	}
+	participant, err := c.client.CreateParticipant(ctx, c.conferenceSid, &p)
+	if err != nil {
+		return nil, fmt.Errorf("conference: add supervisor: %w", err)
+	}
+	return participant, nil
+}
+
+// SetSupervisorMode switches the supervisor supervisorCallSid, already in the
+// conference, to mode. agentCallSid is the call coached in SupervisorCoach mode.
+func (c *Controller) SetSupervisorMode(ctx context.Context, supervisorCallSid string, mode SupervisorMode, agentCallSid string) (*texml.Participant, error) {
+	muted, coaching, err := mode.flags()
+	if err != nil {
+		return nil, err
+	}
+	params := &texml.UpdateParticipantParams{Muted: telnyx.Bool(muted), Coaching: telnyx.Bool(coaching)}
+	if coaching {
+		params.CallSidToCoach = agentCallSid
+	}
+	p, err := c.client.UpdateParticipant(ctx, c.conferenceSid, supervisorCallSid, params)
+	if err != nil {
+		return nil, fmt.Errorf("conference: set supervisor mode: %w", err)
+	}
+	return p, nil
+}
+
+func (m SupervisorMode) flags() (muted, coaching bool, err error) {
+	switch m {
+	case SupervisorListen:
+		return true, false, nil
+	case SupervisorCoach:
+		return false, true, nil
+	case SupervisorBarge:
+		return false, false, nil
+	}
+	return false, false, fmt.Errorf("conference: unknown supervisor mode %q", m)
+}
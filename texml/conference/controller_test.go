@@ -0,0 +1,253 @@
+package conference
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+	"github.com/andersryanc/telnyx-go/telnyxtest"
+	"github.com/andersryanc/telnyx-go/texml"
+)
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// waitFor polls cond until it holds, failing the test after a few seconds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+// conferenceTest is a conference of a fake Telnyx platform with a caller and an agent
+// in it.
+type conferenceTest struct {
+	srv           *telnyxtest.Server
+	client        *texml.Client
+	ctl           *Controller
+	caller, agent string
+}
+
+func newConferenceTest(t *testing.T) *conferenceTest {
+	t.Helper()
+	ctx := testContext(t)
+	srv := telnyxtest.NewServer()
+	t.Cleanup(srv.Close)
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		texml.WriteVoice(w, []texml.Element{Room{Name: "support"}.Moderator()})
+	}))
+	t.Cleanup(app.Close)
+
+	client := texml.NewClient(srv.Client(), srv.AccountSid)
+	application, err := client.CreateApplication(ctx, &texml.ApplicationParams{FriendlyName: "support", VoiceUrl: app.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.AddPhoneNumber("+13125550100", application.ID)
+
+	ct := &conferenceTest{srv: srv, client: client}
+	for _, from := range []string{"+13125550199", "+13125550150"} {
+		call, err := srv.Call(ctx, from, "+13125550100")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ct.caller == "" {
+			ct.caller = call.Sid
+		} else {
+			ct.agent = call.Sid
+		}
+	}
+	waitFor(t, "the caller and the agent to join", func() bool {
+		confs, _ := client.ListConferences(ctx, &texml.ListConferencesParams{Status: "in-progress"}).All()
+		if len(confs) != 1 {
+			return false
+		}
+		participants, _ := client.ListParticipants(ctx, confs[0].Sid).All()
+		ct.ctl = NewController(client, confs[0].Sid)
+		return len(participants) == 2
+	})
+	return ct
+}
+
+func (ct *conferenceTest) participant(t *testing.T, callSid string) *texml.Participant {
+	t.Helper()
+	p, err := ct.client.GetParticipant(testContext(t), ct.ctl.ConferenceSid(), callSid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// ended reports whether the call callSid has ended.
+func (ct *conferenceTest) ended(t *testing.T, callSid string) bool {
+	t.Helper()
+	call, err := ct.client.GetCall(testContext(t), callSid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return call.Status.Ended()
+}
+
+func TestWarmTransfer(t *testing.T) {
+	ct := newConferenceTest(t)
+	ctx := testContext(t)
+	to := &texml.CreateParticipantParams{From: "+13125550100", To: "+13125550111"}
+
+	transfer, err := ct.ctl.WarmTransfer(ctx, ct.caller, "https://example.com/hold", to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if to.StartConferenceOnEnter != nil || to.EndConferenceOnExit != nil {
+		t.Errorf("WarmTransfer changed the params: %+v", to)
+	}
+	if !ct.participant(t, ct.caller).Hold {
+		t.Error("the caller is not on hold while the agents talk")
+	}
+	if target := ct.participant(t, transfer.Target.CallSid); target.EndConferenceOnExit || target.Hold {
+		t.Errorf("target = %+v, want a participant that leaves the conference running", target)
+	}
+
+	// Canceling hangs up the target and takes the caller back.
+	if err := transfer.Cancel(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ct.participant(t, ct.caller).Hold {
+		t.Error("the caller is still on hold after the transfer was canceled")
+	}
+	waitFor(t, "the target to hang up", func() bool { return ct.ended(t, transfer.Target.CallSid) })
+
+	transfer, err = ct.ctl.WarmTransfer(ctx, ct.caller, "", &texml.CreateParticipantParams{From: "+13125550100", To: "+13125550112"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The agent is a moderator: completing the transfer must not end the conference.
+	if err := transfer.Complete(ctx, ct.agent); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the agent to hang up", func() bool { return ct.ended(t, ct.agent) })
+	if ct.participant(t, ct.caller).Hold || ct.ended(t, ct.caller) || ct.ended(t, transfer.Target.CallSid) {
+		t.Error("the caller and the target were not left together")
+	}
+}
+
+func TestColdTransfer(t *testing.T) {
+	ct := newConferenceTest(t)
+	ctx := testContext(t)
+
+	p, err := ct.ctl.ColdTransfer(ctx, ct.agent, &texml.CreateParticipantParams{From: "+13125550100", To: "+13125550111"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the agent to hang up", func() bool { return ct.ended(t, ct.agent) })
+	if ct.ended(t, ct.caller) || ct.ended(t, p.CallSid) {
+		t.Error("the conference ended with the agent that transferred the caller")
+	}
+
+	if _, err := ct.ctl.ColdTransfer(ctx, ct.caller, &texml.CreateParticipantParams{From: "+13125550100"}); err == nil {
+		t.Error("a transfer to no one succeeded")
+	}
+	if ct.ended(t, ct.caller) {
+		t.Error("the call was removed although the transfer failed")
+	}
+}
+
+func TestSupervisor(t *testing.T) {
+	ct := newConferenceTest(t)
+	ctx := testContext(t)
+
+	p, err := ct.ctl.AddSupervisor(ctx, SupervisorCoach, ct.agent, &texml.CreateParticipantParams{From: "+13125550100", To: "+13125550120"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Muted || !p.Coaching || p.CoachingCallSid != ct.agent || p.EndConferenceOnExit {
+		t.Errorf("coaching supervisor = %+v", p)
+	}
+
+	for _, tt := range []struct {
+		mode            SupervisorMode
+		muted, coaching bool
+	}{
+		{SupervisorListen, true, false},
+		{SupervisorBarge, false, false},
+		{SupervisorWhisper, false, true},
+	} {
+		p, err := ct.ctl.SetSupervisorMode(ctx, p.CallSid, tt.mode, ct.agent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Muted != tt.muted || p.Coaching != tt.coaching {
+			t.Errorf("%s: supervisor = %+v", tt.mode, p)
+		}
+	}
+
+	if _, err := ct.ctl.AddSupervisor(ctx, "spy", ct.agent, &texml.CreateParticipantParams{From: "+13125550100", To: "+13125550121"}); err == nil {
+		t.Error("AddSupervisor accepted an unknown mode")
+	}
+	if _, err := ct.ctl.SetSupervisorMode(ctx, p.CallSid, "spy", ct.agent); err == nil {
+		t.Error("SetSupervisorMode accepted an unknown mode")
+	}
+}
+
+func TestNilParams(t *testing.T) {
+	// Nil params are rejected before any request is made.
+	ctl := NewController(texml.NewClient(nil, "AC123"), "cf1")
+	ctx := context.Background()
+	if _, err := ctl.ColdTransfer(ctx, "v3:agent", nil); !errors.Is(err, telnyx.ErrNilParams) {
+		t.Errorf("ColdTransfer = %v, want telnyx.ErrNilParams", err)
+	}
+	if _, err := ctl.WarmTransfer(ctx, "v3:caller", "", nil); !errors.Is(err, telnyx.ErrNilParams) {
+		t.Errorf("WarmTransfer = %v, want telnyx.ErrNilParams", err)
+	}
+	if _, err := ctl.AddSupervisor(ctx, SupervisorListen, "v3:agent", nil); !errors.Is(err, telnyx.ErrNilParams) {
+		t.Errorf("AddSupervisor = %v, want telnyx.ErrNilParams", err)
+	}
+}
+
+func TestWarmTransferUnholdFails(t *testing.T) {
+	var unheld bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/Participants") {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"errors":[{"code":"10015","title":"Invalid value","detail":"To is not a valid number."}]}`))
+			return
+		}
+		var params texml.UpdateParticipantParams
+		json.NewDecoder(r.Body).Decode(&params)
+		if params.Hold != nil && !*params.Hold {
+			unheld = true
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[{"code":"10005","title":"Resource not found","detail":"The caller has hung up."}]}`))
+			return
+		}
+		w.Write([]byte(`{"call_sid":"v3:caller","conference_sid":"cf1","hold":true}`))
+	}))
+	t.Cleanup(srv.Close)
+	client, err := telnyx.NewClientWithParams(telnyx.ClientParams{APIKey: "KEY123", BaseURL: srv.URL, MaxRetries: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctl := NewController(texml.NewClient(client, "AC123"), "cf1")
+	_, err = ctl.WarmTransfer(context.Background(), "v3:caller", "", &texml.CreateParticipantParams{From: "+13125550100", To: "123"})
+	if !unheld {
+		t.Fatal("the caller was not taken off hold")
+	}
+	var apiErr *telnyx.Error
+	if err == nil || !errors.As(err, &apiErr) || !strings.Contains(err.Error(), "To is not a valid number") ||
+		!strings.Contains(err.Error(), "The caller has hung up") {
+		t.Errorf("WarmTransfer = %v, want the dial and the unhold errors", err)
+	}
+}
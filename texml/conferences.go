@@ -0,0 +1,237 @@
+package texml
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+)
+
+// Conference is a conference room created by the <Conference> noun.
+//
+// https://developers.telnyx.com/api/call-scripting/get-texml-conferences
+type Conference struct {
+	Sid                     string            `json:"sid"`
+	AccountSid              string            `json:"account_sid"`
+	FriendlyName            string            `json:"friendly_name"`
+	Status                  string            `json:"status"`
+	Region                  string            `json:"region"`
+	ReasonConferenceEnded   string            `json:"reason_conference_ended"`
+	CallSidEndingConference string            `json:"call_sid_ending_conference"`
+	DateCreated             string            `json:"date_created"`
+	DateUpdated             string            `json:"date_updated"`
+	SubresourceUris         map[string]string `json:"subresource_uris"`
+	Uri                     string            `json:"uri"`
+}
+
+// Participant is a call connected to a Conference.
+type Participant struct {
+	CallSid             string `json:"call_sid"`
+	AccountSid          string `json:"account_sid"`
+	ConferenceSid       string `json:"conference_sid"`
+	Status              string `json:"status"`
+	Muted               bool   `json:"muted"`
+	Hold                bool   `json:"hold"`
+	Coaching            bool   `json:"coaching"`
+	CoachingCallSid     string `json:"coaching_call_sid"`
+	EndConferenceOnExit bool   `json:"end_conference_on_exit"`
+	DateCreated         string `json:"date_created"`
+	DateUpdated         string `json:"date_updated"`
+	Uri                 string `json:"uri"`
+}
+
+// ListConferencesParams filters the conferences returned by ListConferences.
+type ListConferencesParams struct {
+	FriendlyName string
+	// Status is one of "init", "in-progress" or "completed".
+	Status string
+	// DateCreated and DateUpdated are dates in YYYY-MM-DD format.
+	DateCreated string
+	DateUpdated string
+	PageSize    int
+}
+
+func (p *ListConferencesParams) query() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := pageQuery(p.PageSize)
+	for k, v := range map[string]string{
+		"FriendlyName": p.FriendlyName,
+		"Status":       p.Status,
+		"DateCreated":  p.DateCreated,
+		"DateUpdated":  p.DateUpdated,
+	} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	return q
+}
+
+// UpdateConferenceParams change a conference in progress. Setting Status to
+// "completed" ends the conference; AnnounceUrl plays TeXML to every participant.
+type UpdateConferenceParams struct {
+	Status         string `json:"Status,omitempty"`
+	AnnounceUrl    string `json:"AnnounceUrl,omitempty"`
+	AnnounceMethod string `json:"AnnounceMethod,omitempty"`
+}
+
+// CreateParticipantParams dial a new call into a conference. Setting Coaching together
+// with CallSidToCoach lets the new participant whisper to one participant only.
+type CreateParticipantParams struct {
+	From                   string `json:"From"`
+	To                     string `json:"To"`
+	Beep                   string `json:"Beep,omitempty"`
+	Muted                  *bool  `json:"Muted,omitempty"`
+	Coaching               *bool  `json:"Coaching,omitempty"`
+	CallSidToCoach         string `json:"CallSidToCoach,omitempty"`
+	StartConferenceOnEnter *bool  `json:"StartConferenceOnEnter,omitempty"`
+	EndConferenceOnExit    *bool  `json:"EndConferenceOnExit,omitempty"`
+	EarlyMedia             *bool  `json:"EarlyMedia,omitempty"`
+	Timeout                *int   `json:"Timeout,omitempty"`
+	TimeLimit              *int   `json:"TimeLimit,omitempty"`
+	StatusCallback         string `json:"StatusCallback,omitempty"`
+	StatusCallbackMethod   string `json:"StatusCallbackMethod,omitempty"`
+	StatusCallbackEvent    string `json:"StatusCallbackEvent,omitempty"`
+	WaitUrl                string `json:"WaitUrl,omitempty"`
+	MachineDetection       string `json:"MachineDetection,omitempty"`
+	ConferenceRecord       string `json:"ConferenceRecord,omitempty"`
+}
+
+// UpdateParticipantParams change a participant. Nil fields are left unchanged.
+type UpdateParticipantParams struct {
+	Muted               *bool  `json:"Muted,omitempty"`
+	Hold                *bool  `json:"Hold,omitempty"`
+	HoldUrl             string `json:"HoldUrl,omitempty"`
+	HoldMethod          string `json:"HoldMethod,omitempty"`
+	AnnounceUrl         string `json:"AnnounceUrl,omitempty"`
+	AnnounceMethod      string `json:"AnnounceMethod,omitempty"`
+	WaitUrl             string `json:"WaitUrl,omitempty"`
+	BeepOnExit          *bool  `json:"BeepOnExit,omitempty"`
+	EndConferenceOnExit *bool  `json:"EndConferenceOnExit,omitempty"`
+	Coaching            *bool  `json:"Coaching,omitempty"`
+	CallSidToCoach      string `json:"CallSidToCoach,omitempty"`
+}
+
+// ConferenceRecordingParams configure StartConferenceRecording.
+type ConferenceRecordingParams struct {
+	PlayBeep *bool `json:"PlayBeep,omitempty"`
+	// RecordingChannels is "single" or "dual".
+	RecordingChannels             string `json:"RecordingChannels,omitempty"`
+	RecordingStatusCallback       string `json:"RecordingStatusCallback,omitempty"`
+	RecordingStatusCallbackMethod string `json:"RecordingStatusCallbackMethod,omitempty"`
+	// RecordingStatusCallbackEvent is a space-separated list of "in-progress",
+	// "completed" and "absent".
+	RecordingStatusCallbackEvent string `json:"RecordingStatusCallbackEvent,omitempty"`
+	// Trim is "trim-silence" or "do-not-trim".
+	Trim string `json:"Trim,omitempty"`
+}
+
+func (c *Client) ListConferences(ctx context.Context, params *ListConferencesParams) *telnyx.Iter[Conference] {
+	return listAccountResource[Conference](ctx, c, c.accountPath("Conferences"), "conferences", params.query())
+}
+
+func (c *Client) GetConference(ctx context.Context, conferenceSid string) (*Conference, error) {
+	var conference Conference
+	if err := c.client.Do(ctx, http.MethodGet, c.accountPath("Conferences", conferenceSid), nil, &conference); err != nil {
+		return nil, err
+	}
+	return &conference, nil
+}
+
+func (c *Client) UpdateConference(ctx context.Context, conferenceSid string, params *UpdateConferenceParams) (*Conference, error) {
+	var conference Conference
+	if err := c.client.Do(ctx, http.MethodPost, c.accountPath("Conferences", conferenceSid), params, &conference); err != nil {
+		return nil, err
+	}
+	return &conference, nil
+}
+
+// AnnounceConference plays the TeXML at announceUrl, typically a <Say> or <Play>, to
+// every participant of a conference.
+func (c *Client) AnnounceConference(ctx context.Context, conferenceSid, announceUrl, method string) (*Conference, error) {
+	return c.UpdateConference(ctx, conferenceSid, &UpdateConferenceParams{AnnounceUrl: announceUrl, AnnounceMethod: method})
+}
+
+// EndConference disconnects every participant and closes the conference.
+func (c *Client) EndConference(ctx context.Context, conferenceSid string) (*Conference, error) {
+	return c.UpdateConference(ctx, conferenceSid, &UpdateConferenceParams{Status: "completed"})
+}
+
+func (c *Client) ListParticipants(ctx context.Context, conferenceSid string) *telnyx.Iter[Participant] {
+	return listAccountResource[Participant](ctx, c, c.accountPath("Conferences", conferenceSid, "Participants"), "participants", nil)
+}
+
+// GetParticipant returns the participant identified by its call SID or participant
+// label.
+func (c *Client) GetParticipant(ctx context.Context, conferenceSid, callSid string) (*Participant, error) {
+	var participant Participant
+	if err := c.client.Do(ctx, http.MethodGet, c.accountPath("Conferences", conferenceSid, "Participants", callSid), nil, &participant); err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
+
+// CreateParticipant dials a new call into a conference.
+func (c *Client) CreateParticipant(ctx context.Context, conferenceSid string, params *CreateParticipantParams) (*Participant, error) {
+	var participant Participant
+	if err := c.client.Do(ctx, http.MethodPost, c.accountPath("Conferences", conferenceSid, "Participants"), params, &participant); err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
+
+func (c *Client) UpdateParticipant(ctx context.Context, conferenceSid, callSid string, params *UpdateParticipantParams) (*Participant, error) {
+	var participant Participant
+	if err := c.client.Do(ctx, http.MethodPost, c.accountPath("Conferences", conferenceSid, "Participants", callSid), params, &participant); err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
+
+func (c *Client) MuteParticipant(ctx context.Context, conferenceSid, callSid string) (*Participant, error) {
+	return c.UpdateParticipant(ctx, conferenceSid, callSid, &UpdateParticipantParams{Muted: telnyx.Bool(true)})
+}
+
+func (c *Client) UnmuteParticipant(ctx context.Context, conferenceSid, callSid string) (*Participant, error) {
+	return c.UpdateParticipant(ctx, conferenceSid, callSid, &UpdateParticipantParams{Muted: telnyx.Bool(false)})
+}
+
+// HoldParticipant puts a participant on hold, playing the TeXML at holdUrl to it. An
+// empty holdUrl plays the default hold music.
+func (c *Client) HoldParticipant(ctx context.Context, conferenceSid, callSid, holdUrl string) (*Participant, error) {
+	return c.UpdateParticipant(ctx, conferenceSid, callSid, &UpdateParticipantParams{Hold: telnyx.Bool(true), HoldUrl: holdUrl})
+}
+
+func (c *Client) UnholdParticipant(ctx context.Context, conferenceSid, callSid string) (*Participant, error) {
+	return c.UpdateParticipant(ctx, conferenceSid, callSid, &UpdateParticipantParams{Hold: telnyx.Bool(false)})
+}
+
+// KickParticipant removes a participant from a conference and hangs up its call.
+func (c *Client) KickParticipant(ctx context.Context, conferenceSid, callSid string) error {
+	return c.client.Do(ctx, http.MethodDelete, c.accountPath("Conferences", conferenceSid, "Participants", callSid), nil, nil)
+}
+
+// StartConferenceRecording starts recording the mixed audio of a conference. The
+// Recording returned is in progress; pass its Sid to StopConferenceRecording.
+func (c *Client) StartConferenceRecording(ctx context.Context, conferenceSid string, params *ConferenceRecordingParams) (*Recording, error) {
+	if params == nil {
+		params = &ConferenceRecordingParams{}
+	}
+	var recording Recording
+	if err := c.client.Do(ctx, http.MethodPost, c.accountPath("Conferences", conferenceSid, "Recordings"), params, &recording); err != nil {
+		return nil, err
+	}
+	return &recording, nil
+}
+
+func (c *Client) StopConferenceRecording(ctx context.Context, conferenceSid, recordingSid string) (*Recording, error) {
+	var recording Recording
+	body := map[string]string{"Status": "stopped"}
+	if err := c.client.Do(ctx, http.MethodPost, c.accountPath("Conferences", conferenceSid, "Recordings", recordingSid), body, &recording); err != nil {
+		return nil, err
+	}
+	return &recording, nil
+}
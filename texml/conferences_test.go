@@ -0,0 +1,152 @@
+package texml
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+	"github.com/andersryanc/telnyx-go/internal/apitest"
+)
+
+const (
+	conferenceJSON  = `{"sid":"cf1","account_sid":"AC123","friendly_name":"support","status":"in-progress"}`
+	participantJSON = `{"call_sid":"v3:abc","conference_sid":"cf1","status":"connected","muted":true}`
+	recordingJSON   = `{"sid":"rec1","conference_sid":"cf1","source":"StartConferenceRecordingAPI","status":"in-progress"}`
+)
+
+func TestConferences(t *testing.T) {
+	rec := apitest.NewRecorder(t, func(r apitest.Request) (int, string) {
+		if r.Method == http.MethodGet && r.Path == "/texml/Accounts/AC123/Conferences" {
+			return http.StatusOK, `{"conferences":[` + conferenceJSON + `],"page":0,"page_size":20}`
+		}
+		return http.StatusOK, conferenceJSON
+	})
+	c := NewClient(rec.Client(), testAccountSid)
+	ctx := context.Background()
+
+	confs, err := c.ListConferences(ctx, &ListConferencesParams{FriendlyName: "support", Status: "in-progress"}).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(confs) != 1 || confs[0].Sid != "cf1" {
+		t.Errorf("ListConferences = %+v", confs)
+	}
+	q := rec.Requests()[0].Query
+	if q.Get("FriendlyName") != "support" || q.Get("Status") != "in-progress" {
+		t.Errorf("query = %s", q.Encode())
+	}
+
+	if _, err := c.GetConference(ctx, "cf1"); err != nil {
+		t.Fatal(err)
+	}
+	rec.Expect(http.MethodGet, "/texml/Accounts/AC123/Conferences/cf1")
+
+	if _, err := c.AnnounceConference(ctx, "cf1", "https://example.com/announce", http.MethodGet); err != nil {
+		t.Fatal(err)
+	}
+	r := rec.Expect(http.MethodPost, "/texml/Accounts/AC123/Conferences/cf1")
+	if want := map[string]interface{}{"AnnounceUrl": "https://example.com/announce", "AnnounceMethod": "GET"}; !reflect.DeepEqual(r.Body, want) {
+		t.Errorf("announce body = %v, want %v", r.Body, want)
+	}
+
+	if _, err := c.EndConference(ctx, "cf1"); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{"Status": "completed"}; !reflect.DeepEqual(rec.Last().Body, want) {
+		t.Errorf("end body = %v, want %v", rec.Last().Body, want)
+	}
+}
+
+func TestParticipants(t *testing.T) {
+	rec := apitest.NewRecorder(t, func(r apitest.Request) (int, string) {
+		if r.Method == http.MethodDelete {
+			return http.StatusNoContent, ""
+		}
+		return http.StatusOK, participantJSON
+	})
+	c := NewClient(rec.Client(), testAccountSid)
+	ctx := context.Background()
+	const path = "/texml/Accounts/AC123/Conferences/cf1/Participants/v3:abc"
+
+	p, err := c.MuteParticipant(ctx, "cf1", "v3:abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := rec.Expect(http.MethodPost, path); !reflect.DeepEqual(r.Body, map[string]interface{}{"Muted": true}) {
+		t.Errorf("mute body = %v", r.Body)
+	}
+	if !p.Muted || p.ConferenceSid != "cf1" {
+		t.Errorf("MuteParticipant = %+v", p)
+	}
+
+	tests := []struct {
+		name string
+		call func() (*Participant, error)
+		want map[string]interface{}
+	}{
+		{"unmute", func() (*Participant, error) { return c.UnmuteParticipant(ctx, "cf1", "v3:abc") }, map[string]interface{}{"Muted": false}},
+		{"hold", func() (*Participant, error) {
+			return c.HoldParticipant(ctx, "cf1", "v3:abc", "https://example.com/hold")
+		},
+			map[string]interface{}{"Hold": true, "HoldUrl": "https://example.com/hold"}},
+		{"unhold", func() (*Participant, error) { return c.UnholdParticipant(ctx, "cf1", "v3:abc") }, map[string]interface{}{"Hold": false}},
+	}
+	for _, tt := range tests {
+		if _, err := tt.call(); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if r := rec.Expect(http.MethodPost, path); !reflect.DeepEqual(r.Body, tt.want) {
+			t.Errorf("%s body = %v, want %v", tt.name, r.Body, tt.want)
+		}
+	}
+
+	if _, err := c.CreateParticipant(ctx, "cf1", &CreateParticipantParams{
+		From: "+13125550100", To: "+13125550122", Coaching: telnyx.Bool(true), CallSidToCoach: "v3:abc",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	r := rec.Expect(http.MethodPost, "/texml/Accounts/AC123/Conferences/cf1/Participants")
+	if r.Body["Coaching"] != true || r.Body["CallSidToCoach"] != "v3:abc" || len(r.Body) != 4 {
+		t.Errorf("create body = %v", r.Body)
+	}
+
+	if err := c.KickParticipant(ctx, "cf1", "v3:abc"); err != nil {
+		t.Fatal(err)
+	}
+	rec.Expect(http.MethodDelete, path)
+}
+
+func TestConferenceRecording(t *testing.T) {
+	rec := apitest.NewRecorder(t, apitest.Reply(http.StatusOK, recordingJSON))
+	c := NewClient(rec.Client(), testAccountSid)
+	ctx := context.Background()
+
+	recording, err := c.StartConferenceRecording(ctx, "cf1", &ConferenceRecordingParams{PlayBeep: telnyx.Bool(true), RecordingChannels: "dual"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rec.Expect(http.MethodPost, "/texml/Accounts/AC123/Conferences/cf1/Recordings")
+	if want := map[string]interface{}{"PlayBeep": true, "RecordingChannels": "dual"}; !reflect.DeepEqual(r.Body, want) {
+		t.Errorf("start body = %v, want %v", r.Body, want)
+	}
+	if recording.Sid != "rec1" || recording.Status != "in-progress" {
+		t.Errorf("StartConferenceRecording = %+v", recording)
+	}
+
+	if _, err := c.StartConferenceRecording(ctx, "cf1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if r := rec.Last(); len(r.Body) != 0 {
+		t.Errorf("start body without params = %v", r.Body)
+	}
+
+	if _, err := c.StopConferenceRecording(ctx, "cf1", "rec1"); err != nil {
+		t.Fatal(err)
+	}
+	r = rec.Expect(http.MethodPost, "/texml/Accounts/AC123/Conferences/cf1/Recordings/rec1")
+	if want := map[string]interface{}{"Status": "stopped"}; !reflect.DeepEqual(r.Body, want) {
+		t.Errorf("stop body = %v, want %v", r.Body, want)
+	}
+}
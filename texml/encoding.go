@@ -0,0 +1,412 @@
+package texml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Keys reserved by the JSON and YAML representation of an Element. Every other key is
+// the lowerCamel name of one of the element's fields, e.g. "action" or "callerId".
+const (
+	verbKey       = "verb"
+	childrenKey   = "children"
+	attributesKey = "attributes"
+)
+
+// verbs maps the "verb" discriminator of an encoded Element to its Go type.
+var verbs = map[string]reflect.Type{
+	"Dial":        reflect.TypeOf(VoiceDial{}),
+	"Number":      reflect.TypeOf(VoiceNumber{}),
+	"Sip":         reflect.TypeOf(VoiceSip{}),
+	"Queue":       reflect.TypeOf(VoiceQueue{}),
+	"Conference":  reflect.TypeOf(VoiceConference{}),
+	"Enqueue":     reflect.TypeOf(VoiceEnqueue{}),
+	"Gather":      reflect.TypeOf(VoiceGather{}),
+	"Hangup":      reflect.TypeOf(VoiceHangup{}),
+	"Leave":       reflect.TypeOf(VoiceLeave{}),
+	"Pause":       reflect.TypeOf(VoicePause{}),
+	"Play":        reflect.TypeOf(VoicePlay{}),
+	"Record":      reflect.TypeOf(VoiceRecord{}),
+	"Redirect":    reflect.TypeOf(VoiceRedirect{}),
+	"Refer":       reflect.TypeOf(VoiceRefer{}),
+	"Reject":      reflect.TypeOf(VoiceReject{}),
+	"Say":         reflect.TypeOf(VoiceSay{}),
+	"Stop":        reflect.TypeOf(VoiceStop{}),
+	"Stream":      reflect.TypeOf(VoiceStream{}),
+	"Parameter":   reflect.TypeOf(VoiceParameter{}),
+	"Start":       reflect.TypeOf(VoiceStart{}),
+	"Suppression": reflect.TypeOf(VoiceSupression{}),
+}
+
+// nestedVerbs overrides verbs for nouns that share a tag name with another noun and are
+// told apart by their parent, such as <Sip> inside <Refer>.
+var nestedVerbs = map[string]map[string]reflect.Type{
+	"Refer": {"Sip": reflect.TypeOf(VoiceReferSip{})},
+}
+
+// encodable holds every type registered in verbs or nestedVerbs.
+var encodable = func() map[reflect.Type]bool {
+	types := map[reflect.Type]bool{}
+	for _, t := range verbs {
+		types[t] = true
+	}
+	for _, nested := range nestedVerbs {
+		for _, t := range nested {
+			types[t] = true
+		}
+	}
+	return types
+}()
+
+func lookupVerb(parent, verb string) reflect.Type {
+	if t, ok := nestedVerbs[parent][verb]; ok {
+		return t
+	}
+	return verbs[verb]
+}
+
+// Elements is a list of TeXML verbs that can be encoded to and decoded from JSON or
+// YAML. Each element is an object whose "verb" key holds the TeXML tag name, whose
+// "children" key holds its inner elements and whose "attributes" key holds its
+// OptionalAttributes. All other keys are the lowerCamel names of the element's fields.
+//
+//	[
+//	  {"verb": "Gather", "action": "/menu", "numDigits": "1", "children": [
+//	    {"verb": "Say", "message": "Press 1 for sales."}
+//	  ]},
+//	  {"verb": "Hangup"}
+//	]
+//
+// A decoded Elements value can be passed straight to Voice. The MarshalYAML and
+// UnmarshalYAML methods are those called by gopkg.in/yaml.v3 and gopkg.in/yaml.v2.
+type Elements []Element
+
+func (e Elements) MarshalJSON() ([]byte, error) {
+	encoded := make([]json.RawMessage, 0, len(e))
+	for _, element := range e {
+		data, err := marshalElementJSON(element)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, data)
+	}
+	return json.Marshal(encoded)
+}
+
+func (e *Elements) UnmarshalJSON(data []byte) error {
+	var raw []interface{}
+	if err := decodeJSON(data, &raw); err != nil {
+		return err
+	}
+	elements, err := decodeElements(raw, "")
+	if err != nil {
+		return err
+	}
+	*e = elements
+	return nil
+}
+
+func (e Elements) MarshalYAML() (interface{}, error) {
+	return encodeElementsYAML(e)
+}
+
+func (e *Elements) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw []interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	elements, err := decodeElements(raw, "")
+	if err != nil {
+		return err
+	}
+	*e = elements
+	return nil
+}
+
+// UnmarshalElement decodes a single JSON encoded element, using its "verb" key to pick
+// the concrete type.
+func UnmarshalElement(data []byte) (Element, error) {
+	var raw map[string]interface{}
+	if err := decodeJSON(data, &raw); err != nil {
+		return nil, err
+	}
+	return decodeElement(raw, "")
+}
+
+type encodedField struct {
+	key   string
+	value interface{}
+}
+
+// encodeFields lists the non-empty fields of a registered element in declaration order,
+// starting with the verb.
+func encodeFields(element Element) ([]encodedField, error) {
+	v := reflect.Indirect(reflect.ValueOf(element))
+	if !v.IsValid() || !encodable[v.Type()] {
+		return nil, fmt.Errorf("texml: cannot encode element of type %T", element)
+	}
+
+	fields := []encodedField{{verbKey, element.GetName()}}
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		switch name {
+		case "InnerElements":
+			if inner := v.Field(i).Interface().([]Element); len(inner) != 0 {
+				fields = append(fields, encodedField{childrenKey, Elements(inner)})
+			}
+		case "OptionalAttributes":
+			if attrs := v.Field(i).Interface().(map[string]string); len(attrs) != 0 {
+				fields = append(fields, encodedField{attributesKey, attrs})
+			}
+		default:
+			if s := v.Field(i).String(); s != "" {
+				fields = append(fields, encodedField{formatAttrKey(name), s})
+			}
+		}
+	}
+	return fields, nil
+}
+
+func marshalElementJSON(element Element) ([]byte, error) {
+	fields, err := encodeFields(element)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.key)
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func marshalElementYAML(element Element) (interface{}, error) {
+	fields, err := encodeFields(element)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		if children, ok := f.value.(Elements); ok {
+			if f.value, err = encodeElementsYAML(children); err != nil {
+				return nil, err
+			}
+		}
+		out[f.key] = f.value
+	}
+	return out, nil
+}
+
+func encodeElementsYAML(elements []Element) ([]interface{}, error) {
+	out := make([]interface{}, 0, len(elements))
+	for _, element := range elements {
+		encoded, err := marshalElementYAML(element)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, encoded)
+	}
+	return out, nil
+}
+
+func unmarshalElementJSON(data []byte, element Element) error {
+	var raw map[string]interface{}
+	if err := decodeJSON(data, &raw); err != nil {
+		return err
+	}
+	return decodeInto(raw, element)
+}
+
+func unmarshalElementYAML(unmarshal func(interface{}) error, element Element) error {
+	var raw interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	m, ok := toStringMap(raw)
+	if !ok {
+		return fmt.Errorf("texml: %s must be encoded as an object", element.GetName())
+	}
+	return decodeInto(m, element)
+}
+
+// decodeInto decodes raw into element, which must be a pointer to a registered type.
+func decodeInto(raw map[string]interface{}, element Element) error {
+	if verb, ok := raw[verbKey]; ok && verb != element.GetName() {
+		return fmt.Errorf("texml: cannot decode verb %v into %T", verb, element)
+	}
+	v := reflect.ValueOf(element).Elem()
+	v.Set(reflect.Zero(v.Type()))
+	return decodeFields(v, raw)
+}
+
+func decodeJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func decodeElements(raw []interface{}, parent string) ([]Element, error) {
+	elements := make([]Element, 0, len(raw))
+	for i, item := range raw {
+		m, ok := toStringMap(item)
+		if !ok {
+			return nil, fmt.Errorf("texml: element %d must be an object", i)
+		}
+		element, err := decodeElement(m, parent)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+	return elements, nil
+}
+
+func decodeElement(raw map[string]interface{}, parent string) (Element, error) {
+	verb, ok := raw[verbKey].(string)
+	if !ok {
+		return nil, fmt.Errorf("texml: element is missing the %q key", verbKey)
+	}
+	t := lookupVerb(parent, verb)
+	if t == nil {
+		return nil, fmt.Errorf("texml: unknown verb %q", verb)
+	}
+
+	v := reflect.New(t).Elem()
+	if err := decodeFields(v, raw); err != nil {
+		return nil, err
+	}
+	return v.Interface().(Element), nil
+}
+
+func decodeFields(v reflect.Value, raw map[string]interface{}) error {
+	verb := v.Addr().Interface().(Element).GetName()
+
+	keys := make([]string, 0, len(raw))
+	for k := range raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := raw[key]
+		switch key {
+		case verbKey:
+		case childrenKey:
+			if value == nil {
+				// A null list has no children, like an empty one.
+				continue
+			}
+			list, ok := value.([]interface{})
+			if !ok {
+				return fmt.Errorf("texml: %s.%s must be a list", verb, key)
+			}
+			if len(list) == 0 {
+				continue
+			}
+			field := v.FieldByName("InnerElements")
+			if !field.IsValid() {
+				return fmt.Errorf("texml: %s takes no children", verb)
+			}
+			children, err := decodeElements(list, verb)
+			if err != nil {
+				return err
+			}
+			field.Set(reflect.ValueOf(children))
+		case attributesKey:
+			m, ok := toStringMap(value)
+			if !ok {
+				return fmt.Errorf("texml: %s.%s must be an object", verb, key)
+			}
+			attrs := make(map[string]string, len(m))
+			for k, item := range m {
+				s, ok := scalarString(item)
+				if !ok {
+					return fmt.Errorf("texml: %s.%s.%s must be a scalar", verb, key, k)
+				}
+				attrs[k] = s
+			}
+			v.FieldByName("OptionalAttributes").Set(reflect.ValueOf(attrs))
+		default:
+			field := fieldByKey(v, key)
+			if !field.IsValid() {
+				return fmt.Errorf("texml: unknown %s field %q", verb, key)
+			}
+			s, ok := scalarString(value)
+			if !ok {
+				return fmt.Errorf("texml: %s.%s must be a scalar", verb, key)
+			}
+			field.SetString(s)
+		}
+	}
+	return nil
+}
+
+// fieldByKey returns the string field of v whose lowerCamel name is key.
+func fieldByKey(v reflect.Value, key string) reflect.Value {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.Type.Kind() == reflect.String && formatAttrKey(f.Name) == key {
+			return v.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
+
+// toStringMap accepts both the map[string]interface{} produced by encoding/json and
+// yaml.v3, and the map[interface{}]interface{} produced by yaml.v2.
+func toStringMap(value interface{}) (map[string]interface{}, bool) {
+	switch m := value.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			s, ok := k.(string)
+			if !ok {
+				return nil, false
+			}
+			out[s] = v
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// scalarString converts the scalar values an editor may store for an attribute, such as
+// numbers for timeouts or booleans for flags, to the string form used by the Go types.
+func scalarString(value interface{}) (string, bool) {
+	switch s := value.(type) {
+	case string:
+		return s, true
+	case json.Number:
+		return s.String(), true
+	case bool:
+		return strconv.FormatBool(s), true
+	case int:
+		return strconv.Itoa(s), true
+	case int64:
+		return strconv.FormatInt(s, 10), true
+	case uint64:
+		return strconv.FormatUint(s, 10), true
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64), true
+	}
+	return "", false
+}
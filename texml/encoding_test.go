@@ -0,0 +1,338 @@
+package texml
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sample returns an element of type t with every string field set, one optional
+// attribute and, when t takes them, one child.
+func sample(t reflect.Type) Element {
+	v := reflect.New(t).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		switch {
+		case f.Name == "InnerElements":
+			v.Field(i).Set(reflect.ValueOf([]Element{VoiceSay{Message: "hello"}}))
+		case f.Name == "OptionalAttributes":
+			v.Field(i).Set(reflect.ValueOf(map[string]string{"custom": "1"}))
+		case f.Type.Kind() == reflect.String:
+			v.Field(i).SetString(f.Name + " value")
+		}
+	}
+	return v.Interface().(Element)
+}
+
+// registered returns every element type that can be encoded, by verb, with the nested
+// types keyed as "Parent/Verb".
+func registered() map[string]reflect.Type {
+	types := map[string]reflect.Type{}
+	for verb, t := range verbs {
+		types[verb] = t
+	}
+	for parent, nested := range nestedVerbs {
+		for verb, t := range nested {
+			types[parent+"/"+verb] = t
+		}
+	}
+	return types
+}
+
+type yamlMarshaler interface {
+	MarshalYAML() (interface{}, error)
+}
+
+type yamlUnmarshaler interface {
+	UnmarshalYAML(unmarshal func(interface{}) error) error
+}
+
+// yamlNodes returns the generic values a YAML decoder reads back from the document
+// encoded by MarshalYAML: the map[string]interface{} of yaml.v3, then the
+// map[interface{}]interface{} of yaml.v2.
+func yamlNodes(t *testing.T, encoded interface{}) []interface{} {
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var node interface{}
+	if err := json.Unmarshal(data, &node); err != nil {
+		t.Fatal(err)
+	}
+	return []interface{}{node, yamlV2(node)}
+}
+
+// yamlUnmarshal returns the unmarshal function a YAML decoder passes to UnmarshalYAML
+// for node.
+func yamlUnmarshal(node interface{}) func(interface{}) error {
+	return func(out interface{}) error {
+		reflect.ValueOf(out).Elem().Set(reflect.ValueOf(node))
+		return nil
+	}
+}
+
+func yamlV2(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[interface{}]interface{}, len(v))
+		for k, item := range v {
+			out[k] = yamlV2(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = yamlV2(item)
+		}
+		return out
+	}
+	return value
+}
+
+func TestElementJSONRoundTrip(t *testing.T) {
+	for name, typ := range registered() {
+		t.Run(name, func(t *testing.T) {
+			want := sample(typ)
+			data, err := json.Marshal(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := reflect.New(typ)
+			if err := json.Unmarshal(data, got.Interface()); err != nil {
+				t.Fatalf("unmarshal %s: %v", data, err)
+			}
+			if !reflect.DeepEqual(got.Elem().Interface(), want) {
+				t.Errorf("round trip of %s = %#v, want %#v", data, got.Elem().Interface(), want)
+			}
+		})
+	}
+}
+
+func TestElementYAMLRoundTrip(t *testing.T) {
+	for name, typ := range registered() {
+		t.Run(name, func(t *testing.T) {
+			want := sample(typ)
+			encoded, err := want.(yamlMarshaler).MarshalYAML()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, node := range yamlNodes(t, encoded) {
+				got := reflect.New(typ)
+				if err := got.Interface().(yamlUnmarshaler).UnmarshalYAML(yamlUnmarshal(node)); err != nil {
+					t.Fatalf("unmarshal %v: %v", node, err)
+				}
+				if !reflect.DeepEqual(got.Elem().Interface(), want) {
+					t.Errorf("round trip of %v = %#v, want %#v", node, got.Elem().Interface(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeEveryVerb(t *testing.T) {
+	for verb, typ := range verbs {
+		t.Run(verb, func(t *testing.T) {
+			element, err := UnmarshalElement([]byte(`{"verb":"` + verb + `","attributes":{"a":"b"}}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reflect.TypeOf(element) != typ {
+				t.Errorf("decoded %s as %T, want %v", verb, element, typ)
+			}
+			if element.GetName() != verb {
+				t.Errorf("GetName() = %q, want %q", element.GetName(), verb)
+			}
+		})
+	}
+}
+
+func TestDecodeChildrenOfEveryVerb(t *testing.T) {
+	for name, typ := range registered() {
+		t.Run(name, func(t *testing.T) {
+			verb := name
+			wrap := func(s string) string { return s }
+			if parent, nested, ok := strings.Cut(name, "/"); ok {
+				verb = nested
+				wrap = func(s string) string { return `{"verb":"` + parent + `","children":[` + s + `]}` }
+			}
+			input := wrap(`{"verb":"` + verb + `","children":[{"verb":"Pause"}]}`)
+
+			_, takesChildren := typ.FieldByName("InnerElements")
+			_, err := UnmarshalElement([]byte(input))
+			switch {
+			case takesChildren && err != nil:
+				t.Errorf("UnmarshalElement(%s): %v", input, err)
+			case !takesChildren && (err == nil || !strings.Contains(err.Error(), verb+" takes no children")):
+				t.Errorf("UnmarshalElement(%s) error = %v, want %q", input, err, verb+" takes no children")
+			}
+
+			// An empty list is accepted whether or not the verb takes children.
+			if _, err := UnmarshalElement([]byte(wrap(`{"verb":"` + verb + `","children":[]}`))); err != nil {
+				t.Errorf("empty children: %v", err)
+			}
+		})
+	}
+}
+
+func TestElementsJSON(t *testing.T) {
+	want := Elements{
+		VoiceGather{Action: "/menu", NumDigits: "1", InnerElements: []Element{
+			VoiceSay{Message: "Press 1 for sales."},
+		}},
+		VoiceRefer{InnerElements: []Element{VoiceReferSip{SipUrl: "sip:agent@example.com"}}},
+		VoiceHangup{},
+	}
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const wantJSON = `[{"verb":"Gather","action":"/menu","numDigits":"1","children":[{"verb":"Say","message":"Press 1 for sales."}]},` +
+		`{"verb":"Refer","children":[{"verb":"Sip","sipUrl":"sip:agent@example.com"}]},{"verb":"Hangup"}]`
+	if string(data) != wantJSON {
+		t.Errorf("Marshal = %s, want %s", data, wantJSON)
+	}
+
+	var got Elements
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal = %#v, want %#v", got, want)
+	}
+}
+
+func TestElementsYAML(t *testing.T) {
+	want := Elements{
+		VoiceDial{Action: "/done", InnerElements: []Element{VoiceNumber{PhoneNumber: "+13125550100"}}},
+		VoiceRefer{InnerElements: []Element{VoiceReferSip{SipUrl: "sip:agent@example.com"}}},
+	}
+	encoded, err := want.MarshalYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range yamlNodes(t, encoded) {
+		var got Elements
+		if err := got.UnmarshalYAML(yamlUnmarshal(node)); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("UnmarshalYAML = %#v, want %#v", got, want)
+		}
+	}
+}
+
+func TestElementsYAMLv3(t *testing.T) {
+	// Every element survives a round trip through yaml.v3 itself. Nested nouns are
+	// only told apart inside their parent.
+	var want Elements
+	for name, typ := range registered() {
+		if parent, _, nested := strings.Cut(name, "/"); nested {
+			v := reflect.New(verbs[parent]).Elem()
+			v.FieldByName("InnerElements").Set(reflect.ValueOf([]Element{sample(typ)}))
+			want = append(want, v.Interface().(Element))
+			continue
+		}
+		want = append(want, sample(typ))
+	}
+	data, err := yaml.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Elements
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal %s: %v", data, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip of %s = %#v, want %#v", data, got, want)
+	}
+
+	// Hand-written documents have unquoted numbers and booleans.
+	doc := `
+- verb: Gather
+  action: /menu
+  numDigits: 1
+  attributes:
+    bargeIn: true
+  children:
+    - verb: Say
+      message: Press 1 for sales.
+- verb: Hangup
+`
+	want = Elements{
+		VoiceGather{Action: "/menu", NumDigits: "1", OptionalAttributes: map[string]string{"bargeIn": "true"},
+			InnerElements: []Element{VoiceSay{Message: "Press 1 for sales."}}},
+		VoiceHangup{},
+	}
+	got = nil
+	if err := yaml.Unmarshal([]byte(doc), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal = %#v, want %#v", got, want)
+	}
+}
+
+func TestDecodeScalars(t *testing.T) {
+	element, err := UnmarshalElement([]byte(`{"verb":"Gather","timeout":5,"numDigits":1.5,"attributes":{"bargeIn":true}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := VoiceGather{Timeout: "5", NumDigits: "1.5", OptionalAttributes: map[string]string{"bargeIn": "true"}}
+	if !reflect.DeepEqual(element, want) {
+		t.Errorf("UnmarshalElement = %#v, want %#v", element, want)
+	}
+}
+
+func TestDecodeNoChildren(t *testing.T) {
+	for _, input := range []string{
+		`{"verb":"Dial","number":"+13125550100","children":null}`,
+		`{"verb":"Dial","number":"+13125550100","children":[]}`,
+	} {
+		element, err := UnmarshalElement([]byte(input))
+		if err != nil {
+			t.Fatalf("UnmarshalElement(%s): %v", input, err)
+		}
+		if want := (VoiceDial{Number: "+13125550100"}); !reflect.DeepEqual(element, want) {
+			t.Errorf("UnmarshalElement(%s) = %#v, want %#v", input, element, want)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`{"message":"hi"}`, `missing the "verb" key`},
+		{`{"verb":"Shout"}`, `unknown verb "Shout"`},
+		{`{"verb":"Say","volume":"11"}`, `unknown Say field "volume"`},
+		{`{"verb":"Say","message":["hi"]}`, "Say.message must be a scalar"},
+		{`{"verb":"Dial","children":{}}`, "Dial.children must be a list"},
+		{`{"verb":"Dial","children":["+13125550100"]}`, "element 0 must be an object"},
+		{`{"verb":"Say","attributes":[]}`, "Say.attributes must be an object"},
+		{`{"verb":"Say","attributes":{"a":{}}}`, "Say.attributes.a must be a scalar"},
+	}
+	for _, tt := range tests {
+		_, err := UnmarshalElement([]byte(tt.input))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("UnmarshalElement(%s) error = %v, want %q", tt.input, err, tt.err)
+		}
+	}
+
+	var say VoiceSay
+	if err := json.Unmarshal([]byte(`{"verb":"Play","message":"hi"}`), &say); err == nil {
+		t.Error("decoding a Play into a VoiceSay succeeded")
+	}
+}
+
+func TestMarshalUnregistered(t *testing.T) {
+	type custom struct{ VoiceSay }
+	if _, err := json.Marshal(Elements{custom{}}); err == nil {
+		t.Error("encoding an unregistered element type succeeded")
+	}
+}
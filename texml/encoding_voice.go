@@ -0,0 +1,353 @@
+package texml
+
+func (m VoiceDial) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceDial) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceDial) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceDial) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceNumber) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceNumber) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceNumber) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceNumber) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceSip) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceSip) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceSip) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceSip) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceQueue) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceQueue) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceQueue) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceQueue) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceConference) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceConference) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceConference) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceConference) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceEnqueue) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceEnqueue) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceEnqueue) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceEnqueue) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceGather) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceGather) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceGather) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceGather) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceHangup) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceHangup) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceHangup) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceHangup) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceLeave) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceLeave) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceLeave) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceLeave) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoicePause) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoicePause) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoicePause) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoicePause) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoicePlay) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoicePlay) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoicePlay) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoicePlay) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceRecord) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceRecord) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceRecord) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceRecord) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceRedirect) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceRedirect) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceRedirect) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceRedirect) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceRefer) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceRefer) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceRefer) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceRefer) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceReferSip) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceReferSip) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceReferSip) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceReferSip) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceReject) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceReject) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceReject) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceReject) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceSay) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceSay) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceSay) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceSay) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceStop) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceStop) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceStop) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceStop) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceStream) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceStream) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceStream) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceStream) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceParameter) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceParameter) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceParameter) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceParameter) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceStart) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceStart) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceStart) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceStart) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
+
+func (m VoiceSupression) MarshalJSON() ([]byte, error) {
+	return marshalElementJSON(m)
+}
+
+func (m *VoiceSupression) UnmarshalJSON(data []byte) error {
+	return unmarshalElementJSON(data, m)
+}
+
+func (m VoiceSupression) MarshalYAML() (interface{}, error) {
+	return marshalElementYAML(m)
+}
+
+func (m *VoiceSupression) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalElementYAML(unmarshal, m)
+}
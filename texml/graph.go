@@ -0,0 +1,281 @@
+package texml
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+// FlowEndpoint is one URL of a TeXML application. The document served at URL is either
+// given directly as Verbs or produced by calling Handler with a POST request, the way
+// Telnyx fetches TeXML by default.
+type FlowEndpoint struct {
+	URL     string
+	Verbs   []Element
+	Handler http.Handler
+}
+
+// FlowNode is a URL in a FlowGraph. Nodes that were linked to but not part of the
+// endpoints passed to BuildFlowGraph are External and have no Verbs.
+type FlowNode struct {
+	URL      string
+	Verbs    []string
+	External bool
+}
+
+// FlowEdge links the document served at From to the document Telnyx fetches from To.
+// Label names the verb and attribute that hold the link, e.g. "Gather action".
+type FlowEdge struct {
+	From  string
+	To    string
+	Label string
+}
+
+// FlowGraph is the call flow formed by a set of TeXML endpoints.
+type FlowGraph struct {
+	Nodes []FlowNode
+	Edges []FlowEdge
+}
+
+// flowLinks lists, per verb, the attributes whose value is a URL that continues the
+// call flow. Status and recording callbacks are left out since they do not return
+// TeXML that is executed on the call.
+var flowLinks = map[string][]string{
+	"Dial":       {"action"},
+	"Number":     {"url"},
+	"Sip":        {"url"},
+	"Queue":      {"url"},
+	"Conference": {"waitUrl"},
+	"Enqueue":    {"action", "waitUrl"},
+	"Gather":     {"action", "invalidDigitsAction"},
+	"Record":     {"action"},
+	"Refer":      {"action"},
+}
+
+// flowElement is the common form of an Element tree and a parsed TeXML document.
+type flowElement struct {
+	name     string
+	text     string
+	attrs    map[string]string
+	children []flowElement
+}
+
+// BuildFlowGraph follows the action, wait and redirect URLs of every endpoint and
+// returns the resulting graph. Relative URLs are resolved against the URL of the
+// endpoint that contains them.
+func BuildFlowGraph(endpoints []FlowEndpoint) (*FlowGraph, error) {
+	g := &FlowGraph{}
+	index := map[string]int{}
+	seenEdges := map[FlowEdge]bool{}
+
+	addNode := func(node FlowNode) {
+		if i, ok := index[node.URL]; ok {
+			if !node.External {
+				g.Nodes[i] = node
+			}
+			return
+		}
+		index[node.URL] = len(g.Nodes)
+		g.Nodes = append(g.Nodes, node)
+	}
+
+	type fetched struct {
+		base     *url.URL
+		elements []flowElement
+	}
+	documents := make([]fetched, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		base, err := url.Parse(endpoint.URL)
+		if err != nil {
+			return nil, fmt.Errorf("texml: endpoint %q: %w", endpoint.URL, err)
+		}
+		elements, err := endpoint.elements()
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, fetched{base, elements})
+
+		node := FlowNode{URL: base.String()}
+		for _, el := range elements {
+			node.Verbs = append(node.Verbs, el.name)
+		}
+		addNode(node)
+	}
+
+	for _, doc := range documents {
+		for _, edge := range flowEdges(doc.base, doc.elements) {
+			if seenEdges[edge] {
+				continue
+			}
+			seenEdges[edge] = true
+			addNode(FlowNode{URL: edge.To, External: true})
+			g.Edges = append(g.Edges, edge)
+		}
+	}
+	return g, nil
+}
+
+func (e FlowEndpoint) elements() ([]flowElement, error) {
+	if e.Handler == nil {
+		return flowElements(e.Verbs), nil
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.URL, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("texml: endpoint %q: %w", e.URL, err)
+	}
+	rec := &flowRecorder{header: http.Header{}}
+	e.Handler.ServeHTTP(rec, req)
+	if rec.code != 0 && rec.code != http.StatusOK {
+		return nil, fmt.Errorf("texml: endpoint %q returned status %d", e.URL, rec.code)
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(rec.body.Bytes()); err != nil {
+		return nil, fmt.Errorf("texml: endpoint %q: %w", e.URL, err)
+	}
+	if doc.Root() == nil {
+		return nil, fmt.Errorf("texml: endpoint %q returned an empty document", e.URL)
+	}
+	return parsedFlowElements(doc.Root()), nil
+}
+
+// flowRecorder is the http.ResponseWriter the Handler of a FlowEndpoint writes to.
+type flowRecorder struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (r *flowRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *flowRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+}
+
+func (r *flowRecorder) Write(p []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(p)
+}
+
+func flowElements(elements []Element) []flowElement {
+	out := make([]flowElement, 0, len(elements))
+	for _, element := range elements {
+		el := flowElement{name: element.GetName(), text: element.GetText(), attrs: map[string]string{}}
+		optAttr, paramAttr := element.GetAttr()
+		for _, attr := range []map[string]string{paramAttr, optAttr} {
+			for k, v := range attr {
+				if v != "" {
+					el.attrs[formatAttrKey(k)] = v
+				}
+			}
+		}
+		el.children = flowElements(element.GetInnerElements())
+		out = append(out, el)
+	}
+	return out
+}
+
+func parsedFlowElements(parent *etree.Element) []flowElement {
+	var out []flowElement
+	for _, child := range parent.ChildElements() {
+		el := flowElement{name: child.Tag, text: strings.TrimSpace(child.Text()), attrs: map[string]string{}}
+		for _, attr := range child.Attr {
+			el.attrs[attr.Key] = attr.Value
+		}
+		el.children = parsedFlowElements(child)
+		out = append(out, el)
+	}
+	return out
+}
+
+func flowEdges(base *url.URL, elements []flowElement) []FlowEdge {
+	var edges []FlowEdge
+	add := func(ref, label string) {
+		target, err := base.Parse(ref)
+		if err != nil {
+			return
+		}
+		edges = append(edges, FlowEdge{From: base.String(), To: target.String(), Label: label})
+	}
+
+	for _, el := range elements {
+		if el.name == "Redirect" && el.text != "" {
+			add(el.text, "Redirect")
+		}
+		for _, attr := range flowLinks[el.name] {
+			if ref := el.attrs[attr]; ref != "" {
+				add(ref, el.name+" "+attr)
+			}
+		}
+		edges = append(edges, flowEdges(base, el.children)...)
+	}
+	return edges
+}
+
+// DOT renders the graph in the Graphviz DOT language. External nodes are dashed.
+func (g *FlowGraph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph texml {\n")
+	sb.WriteString("\trankdir=TB;\n")
+	sb.WriteString("\tnode [shape=box];\n")
+	for _, node := range g.Nodes {
+		style := ""
+		if node.External {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&sb, "\t%s [label=%s%s];\n", dotQuote(node.URL), dotQuote(node.label("\n")), style)
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&sb, "\t%s -> %s [label=%s];\n", dotQuote(edge.From), dotQuote(edge.To), dotQuote(edge.Label))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart. External nodes are drawn with
+// rounded corners.
+func (g *FlowGraph) Mermaid() string {
+	ids := make(map[string]string, len(g.Nodes))
+	var sb strings.Builder
+	sb.WriteString("flowchart TD\n")
+	for i, node := range g.Nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[node.URL] = id
+		shape := "[\"%s\"]"
+		if node.External {
+			shape = "(\"%s\")"
+		}
+		fmt.Fprintf(&sb, "    %s"+shape+"\n", id, mermaidEscape(node.label("<br/>")))
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&sb, "    %s -->|\"%s\"| %s\n", ids[edge.From], mermaidEscape(edge.Label), ids[edge.To])
+	}
+	return sb.String()
+}
+
+func (n FlowNode) label(sep string) string {
+	if len(n.Verbs) == 0 {
+		return n.URL
+	}
+	return n.URL + sep + strings.Join(n.Verbs, ", ")
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
@@ -0,0 +1,108 @@
+package texml
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildFlowGraph(t *testing.T) {
+	menu := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("handler requested with %s", r.Method)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm: %v", err)
+		}
+		xml, _ := Voice([]Element{
+			VoiceDial{Action: "/dial-done", InnerElements: []Element{VoiceNumber{PhoneNumber: "+13125550100"}}},
+		})
+		w.Write([]byte(xml))
+	})
+
+	g, err := BuildFlowGraph([]FlowEndpoint{
+		{URL: "https://example.com/ivr/start", Verbs: []Element{
+			VoiceGather{Action: "menu", InnerElements: []Element{VoiceSay{Message: "Press 1"}}},
+			VoiceRedirect{Url: "/ivr/start"},
+		}},
+		{URL: "https://example.com/ivr/menu", Handler: menu},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantNodes := []FlowNode{
+		{URL: "https://example.com/ivr/start", Verbs: []string{"Gather", "Redirect"}},
+		{URL: "https://example.com/ivr/menu", Verbs: []string{"Dial"}},
+		{URL: "https://example.com/dial-done", External: true},
+	}
+	if !reflect.DeepEqual(g.Nodes, wantNodes) {
+		t.Errorf("Nodes = %+v, want %+v", g.Nodes, wantNodes)
+	}
+	wantEdges := []FlowEdge{
+		{From: "https://example.com/ivr/start", To: "https://example.com/ivr/menu", Label: "Gather action"},
+		{From: "https://example.com/ivr/start", To: "https://example.com/ivr/start", Label: "Redirect"},
+		{From: "https://example.com/ivr/menu", To: "https://example.com/dial-done", Label: "Dial action"},
+	}
+	if !reflect.DeepEqual(g.Edges, wantEdges) {
+		t.Errorf("Edges = %+v, want %+v", g.Edges, wantEdges)
+	}
+
+	dot := g.DOT()
+	for _, want := range []string{
+		`"https://example.com/ivr/start" -> "https://example.com/ivr/menu" [label="Gather action"];`,
+		`"https://example.com/dial-done" [label="https://example.com/dial-done", style=dashed];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT() does not contain %s:\n%s", want, dot)
+		}
+	}
+	mermaid := g.Mermaid()
+	for _, want := range []string{
+		`n0["https://example.com/ivr/start<br/>Gather, Redirect"]`,
+		`n2("https://example.com/dial-done")`,
+		`n1 -->|"Dial action"| n2`,
+	} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Mermaid() does not contain %s:\n%s", want, mermaid)
+		}
+	}
+}
+
+func TestBuildFlowGraphHandlerErrors(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<Response><Hangup/></Response>"))
+	})
+	tests := []struct {
+		name     string
+		endpoint FlowEndpoint
+		err      string
+	}{
+		{"status", FlowEndpoint{URL: "/fail", Handler: http.NotFoundHandler()}, "returned status 404"},
+		{"empty", FlowEndpoint{URL: "/empty", Handler: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})}, "empty document"},
+		{"unparsable URL", FlowEndpoint{URL: "http://[::1", Handler: ok}, "texml: endpoint"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BuildFlowGraph([]FlowEndpoint{tt.endpoint})
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("BuildFlowGraph error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestBuildFlowGraphHandlerURLWithSpace(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<Response><Redirect>next step</Redirect></Response>`))
+	})
+	g, err := BuildFlowGraph([]FlowEndpoint{{URL: "/flows/main menu", Handler: handler}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FlowEdge{{From: "/flows/main%20menu", To: "/flows/next%20step", Label: "Redirect"}}
+	if !reflect.DeepEqual(g.Edges, want) {
+		t.Errorf("Edges = %+v, want %+v", g.Edges, want)
+	}
+}
@@ -0,0 +1,66 @@
+package texml
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+)
+
+// listAccountResource iterates over a list endpoint below /texml/Accounts. These
+// endpoints follow the TwiML REST conventions: the items are held under key and the
+// next page is given by next_page_uri, whose query is reused on path.
+func listAccountResource[T any](ctx context.Context, c *Client, path, key string, query url.Values) *telnyx.Iter[T] {
+	return telnyx.NewIter(ctx, func(ctx context.Context, cursor string) ([]T, string, error) {
+		q := query
+		if cursor != "" {
+			var err error
+			if q, err = url.ParseQuery(cursor); err != nil {
+				return nil, "", err
+			}
+		}
+
+		p := path
+		if len(q) != 0 {
+			p += "?" + q.Encode()
+		}
+		var resp map[string]json.RawMessage
+		if err := c.client.Do(ctx, http.MethodGet, p, nil, &resp); err != nil {
+			return nil, "", err
+		}
+
+		var items []T
+		if raw, ok := resp[key]; ok {
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return nil, "", fmt.Errorf("texml: decode %s: %w", key, err)
+			}
+		}
+		var next string
+		if raw, ok := resp["next_page_uri"]; ok {
+			var nextURI *string
+			if err := json.Unmarshal(raw, &nextURI); err == nil && nextURI != nil && *nextURI != "" {
+				u, err := url.Parse(*nextURI)
+				if err != nil {
+					return nil, "", fmt.Errorf("texml: invalid next_page_uri: %w", err)
+				}
+				next = u.RawQuery
+			}
+		}
+		if len(items) == 0 {
+			next = ""
+		}
+		return items, next, nil
+	})
+}
+
+// pageQuery returns the query of the first page of a list below /texml/Accounts.
+func pageQuery(pageSize int) url.Values {
+	q := url.Values{}
+	if pageSize > 0 {
+		q.Set("PageSize", fmt.Sprint(pageSize))
+	}
+	return q
+}
@@ -0,0 +1,106 @@
+package texml
+
+import (
+	"context"
+	"net/http"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+)
+
+// Queue is a call queue that <Enqueue> puts callers in and <Dial><Queue> connects
+// agents to.
+type Queue struct {
+	Sid          string `json:"sid"`
+	AccountSid   string `json:"account_sid"`
+	FriendlyName string `json:"friendly_name"`
+	CurrentSize  int    `json:"current_size"`
+	MaxSize      int    `json:"max_size"`
+	// AverageWaitTime is the average time, in seconds, the calls currently in the queue
+	// have been waiting.
+	AverageWaitTime int    `json:"average_wait_time"`
+	DateCreated     string `json:"date_created"`
+	DateUpdated     string `json:"date_updated"`
+	Uri             string `json:"uri"`
+}
+
+// QueueMember is a call waiting in a Queue.
+type QueueMember struct {
+	CallSid      string `json:"call_sid"`
+	QueueSid     string `json:"queue_sid"`
+	Position     int    `json:"position"`
+	WaitTime     int    `json:"wait_time"`
+	DateEnqueued string `json:"date_enqueued"`
+	Uri          string `json:"uri"`
+}
+
+// QueueParams are the fields sent when creating or updating a Queue.
+type QueueParams struct {
+	FriendlyName string `json:"FriendlyName,omitempty"`
+	MaxSize      *int   `json:"MaxSize,omitempty"`
+}
+
+// FrontOfQueue can be passed as the call SID of GetQueueMember and DequeueMember to
+// select the caller that has been waiting the longest.
+const FrontOfQueue = "Front"
+
+func (c *Client) CreateQueue(ctx context.Context, params *QueueParams) (*Queue, error) {
+	var queue Queue
+	if err := c.client.Do(ctx, http.MethodPost, c.accountPath("Queues"), params, &queue); err != nil {
+		return nil, err
+	}
+	return &queue, nil
+}
+
+// GetQueue returns a queue, including its current size and average wait time.
+func (c *Client) GetQueue(ctx context.Context, queueSid string) (*Queue, error) {
+	var queue Queue
+	if err := c.client.Do(ctx, http.MethodGet, c.accountPath("Queues", queueSid), nil, &queue); err != nil {
+		return nil, err
+	}
+	return &queue, nil
+}
+
+func (c *Client) ListQueues(ctx context.Context, pageSize int) *telnyx.Iter[Queue] {
+	return listAccountResource[Queue](ctx, c, c.accountPath("Queues"), "queues", pageQuery(pageSize))
+}
+
+func (c *Client) UpdateQueue(ctx context.Context, queueSid string, params *QueueParams) (*Queue, error) {
+	var queue Queue
+	if err := c.client.Do(ctx, http.MethodPost, c.accountPath("Queues", queueSid), params, &queue); err != nil {
+		return nil, err
+	}
+	return &queue, nil
+}
+
+// DeleteQueue deletes an empty queue.
+func (c *Client) DeleteQueue(ctx context.Context, queueSid string) error {
+	return c.client.Do(ctx, http.MethodDelete, c.accountPath("Queues", queueSid), nil, nil)
+}
+
+// ListQueueMembers lists the calls waiting in a queue, in queue order.
+func (c *Client) ListQueueMembers(ctx context.Context, queueSid string, pageSize int) *telnyx.Iter[QueueMember] {
+	return listAccountResource[QueueMember](ctx, c, c.accountPath("Queues", queueSid, "Members"), "queue_members", pageQuery(pageSize))
+}
+
+func (c *Client) GetQueueMember(ctx context.Context, queueSid, callSid string) (*QueueMember, error) {
+	var member QueueMember
+	if err := c.client.Do(ctx, http.MethodGet, c.accountPath("Queues", queueSid, "Members", callSid), nil, &member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// DequeueMember takes a caller out of a queue and makes it fetch and execute the TeXML
+// at dequeueUrl.
+func (c *Client) DequeueMember(ctx context.Context, queueSid, callSid, dequeueUrl, method string) (*QueueMember, error) {
+	params := struct {
+		Url    string `json:"Url"`
+		Method string `json:"Method,omitempty"`
+	}{dequeueUrl, method}
+
+	var member QueueMember
+	if err := c.client.Do(ctx, http.MethodPost, c.accountPath("Queues", queueSid, "Members", callSid), params, &member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
@@ -0,0 +1,120 @@
+package texml
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+	"github.com/andersryanc/telnyx-go/internal/apitest"
+)
+
+const queueJSON = `{"sid":"q1","account_sid":"AC123","friendly_name":"support","current_size":2,"max_size":100,"average_wait_time":45}`
+
+func TestQueues(t *testing.T) {
+	rec := apitest.NewRecorder(t, func(r apitest.Request) (int, string) {
+		if r.Method == http.MethodDelete {
+			return http.StatusNoContent, ""
+		}
+		return http.StatusOK, queueJSON
+	})
+	c := NewClient(rec.Client(), testAccountSid)
+	ctx := context.Background()
+
+	if _, err := c.CreateQueue(ctx, &QueueParams{FriendlyName: "support", MaxSize: telnyx.Int(100)}); err != nil {
+		t.Fatal(err)
+	}
+	r := rec.Expect(http.MethodPost, "/texml/Accounts/AC123/Queues")
+	if want := map[string]interface{}{"FriendlyName": "support", "MaxSize": float64(100)}; !reflect.DeepEqual(r.Body, want) {
+		t.Errorf("create body = %v, want %v", r.Body, want)
+	}
+
+	q, err := c.GetQueue(ctx, "q1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Expect(http.MethodGet, "/texml/Accounts/AC123/Queues/q1")
+	if q.CurrentSize != 2 || q.AverageWaitTime != 45 {
+		t.Errorf("GetQueue = %+v", q)
+	}
+
+	if _, err := c.UpdateQueue(ctx, "q1", &QueueParams{MaxSize: telnyx.Int(10)}); err != nil {
+		t.Fatal(err)
+	}
+	if r := rec.Expect(http.MethodPost, "/texml/Accounts/AC123/Queues/q1"); !reflect.DeepEqual(r.Body, map[string]interface{}{"MaxSize": float64(10)}) {
+		t.Errorf("update body = %v", r.Body)
+	}
+
+	if err := c.DeleteQueue(ctx, "q1"); err != nil {
+		t.Fatal(err)
+	}
+	rec.Expect(http.MethodDelete, "/texml/Accounts/AC123/Queues/q1")
+}
+
+func TestListQueues(t *testing.T) {
+	rec := apitest.NewRecorder(t, func(r apitest.Request) (int, string) {
+		q := r.Query
+		if q.Get("Page") == "1" {
+			return http.StatusOK, `{"queues":[{"sid":"q3"}],"next_page_uri":null}`
+		}
+		return http.StatusOK, `{"queues":[{"sid":"q1"},{"sid":"q2"}],
+			"next_page_uri":"/v2/texml/Accounts/AC123/Queues?Page=1&PageSize=2&PageToken=t1"}`
+	})
+	c := NewClient(rec.Client(), testAccountSid)
+
+	queues, err := c.ListQueues(context.Background(), 2).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sids []string
+	for _, q := range queues {
+		sids = append(sids, q.Sid)
+	}
+	if want := []string{"q1", "q2", "q3"}; !reflect.DeepEqual(sids, want) {
+		t.Errorf("sids = %v, want %v", sids, want)
+	}
+	if q := rec.Requests()[0].Query.Encode(); q != "PageSize=2" {
+		t.Errorf("first page query = %s", q)
+	}
+	second := rec.Requests()[1]
+	if q := second.Query; second.Path != "/texml/Accounts/AC123/Queues" || q.Get("PageToken") != "t1" {
+		t.Errorf("second page = %s?%s, want the next_page_uri query", second.Path, second.Query.Encode())
+	}
+}
+
+func TestQueueMembers(t *testing.T) {
+	rec := apitest.NewRecorder(t, func(r apitest.Request) (int, string) {
+		if r.Path == "/texml/Accounts/AC123/Queues/q1/Members" {
+			return http.StatusOK, `{"queue_members":[{"call_sid":"v3:a","position":1,"wait_time":60},{"call_sid":"v3:b","position":2,"wait_time":30}]}`
+		}
+		return http.StatusOK, `{"call_sid":"v3:a","queue_sid":"q1","position":1,"wait_time":60}`
+	})
+	c := NewClient(rec.Client(), testAccountSid)
+	ctx := context.Background()
+
+	members, err := c.ListQueueMembers(ctx, "q1", 0).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 2 || members[0].CallSid != "v3:a" || members[1].Position != 2 {
+		t.Errorf("ListQueueMembers = %+v", members)
+	}
+
+	if _, err := c.GetQueueMember(ctx, "q1", FrontOfQueue); err != nil {
+		t.Fatal(err)
+	}
+	rec.Expect(http.MethodGet, "/texml/Accounts/AC123/Queues/q1/Members/Front")
+
+	m, err := c.DequeueMember(ctx, "q1", "v3:a", "https://example.com/agent", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rec.Expect(http.MethodPost, "/texml/Accounts/AC123/Queues/q1/Members/v3:a")
+	if want := map[string]interface{}{"Url": "https://example.com/agent"}; !reflect.DeepEqual(r.Body, want) {
+		t.Errorf("dequeue body = %v, want %v", r.Body, want)
+	}
+	if m.WaitTime != 60 {
+		t.Errorf("DequeueMember = %+v", m)
+	}
+}
@@ -0,0 +1,130 @@
+package texml
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+)
+
+// Recording is the audio recorded by <Record>, <Dial record> or <Conference record>.
+// MediaUrl expires a few minutes after it was returned, so download the recording with
+// DownloadRecording rather than storing the URL.
+//
+// https://developers.telnyx.com/api/call-scripting/get-texml-call-recordings
+type Recording struct {
+	Sid             string            `json:"sid"`
+	AccountSid      string            `json:"account_sid"`
+	CallSid         string            `json:"call_sid"`
+	ConferenceSid   string            `json:"conference_sid"`
+	Channels        int               `json:"channels"`
+	Duration        string            `json:"duration"`
+	Source          string            `json:"source"`
+	Status          string            `json:"status"`
+	ErrorCode       string            `json:"error_code"`
+	MediaUrl        string            `json:"media_url"`
+	StartTime       string            `json:"start_time"`
+	DateCreated     string            `json:"date_created"`
+	DateUpdated     string            `json:"date_updated"`
+	SubresourceUris map[string]string `json:"subresource_uris"`
+	Uri             string            `json:"uri"`
+}
+
+// Transcription is the text of a Recording.
+type Transcription struct {
+	Sid               string `json:"sid"`
+	AccountSid        string `json:"account_sid"`
+	CallSid           string `json:"call_sid"`
+	RecordingSid      string `json:"recording_sid"`
+	Duration          string `json:"duration"`
+	Status            string `json:"status"`
+	TranscriptionText string `json:"transcription_text"`
+	DateCreated       string `json:"date_created"`
+	DateUpdated       string `json:"date_updated"`
+	Uri               string `json:"uri"`
+}
+
+// ListRecordingsParams filters the recordings returned by ListRecordings. Zero fields
+// are not filtered on.
+type ListRecordingsParams struct {
+	CallSid       string
+	ConferenceSid string
+	// CreatedAfter and CreatedBefore bound the creation time of the recordings.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	PageSize      int
+}
+
+func (p *ListRecordingsParams) query() url.Values {
+	if p == nil {
+		return nil
+	}
+	q := pageQuery(p.PageSize)
+	if p.CallSid != "" {
+		q.Set("CallSid", p.CallSid)
+	}
+	if p.ConferenceSid != "" {
+		q.Set("ConferenceSid", p.ConferenceSid)
+	}
+	if !p.CreatedAfter.IsZero() {
+		q.Set("DateCreated>", p.CreatedAfter.UTC().Format(time.RFC3339))
+	}
+	if !p.CreatedBefore.IsZero() {
+		q.Set("DateCreated<", p.CreatedBefore.UTC().Format(time.RFC3339))
+	}
+	return q
+}
+
+func (c *Client) ListRecordings(ctx context.Context, params *ListRecordingsParams) *telnyx.Iter[Recording] {
+	return listAccountResource[Recording](ctx, c, c.accountPath("Recordings.json"), "recordings", params.query())
+}
+
+func (c *Client) GetRecording(ctx context.Context, recordingSid string) (*Recording, error) {
+	var recording Recording
+	if err := c.client.Do(ctx, http.MethodGet, c.accountPath("Recordings", recordingSid+".json"), nil, &recording); err != nil {
+		return nil, err
+	}
+	return &recording, nil
+}
+
+func (c *Client) DeleteRecording(ctx context.Context, recordingSid string) error {
+	return c.client.Do(ctx, http.MethodDelete, c.accountPath("Recordings", recordingSid+".json"), nil, nil)
+}
+
+// DownloadRecording streams the audio of a recording. It fetches the recording first so
+// that the media URL is fresh. The caller must close the returned reader.
+func (c *Client) DownloadRecording(ctx context.Context, recordingSid string) (io.ReadCloser, error) {
+	recording, err := c.GetRecording(ctx, recordingSid)
+	if err != nil {
+		return nil, err
+	}
+	if recording.MediaUrl == "" {
+		return nil, fmt.Errorf("texml: recording %s has no media URL", recordingSid)
+	}
+	return c.client.Download(ctx, recording.MediaUrl)
+}
+
+// ListTranscriptions lists the transcriptions of every recording of the account.
+func (c *Client) ListTranscriptions(ctx context.Context, pageSize int) *telnyx.Iter[Transcription] {
+	return listAccountResource[Transcription](ctx, c, c.accountPath("Transcriptions.json"), "transcriptions", pageQuery(pageSize))
+}
+
+func (c *Client) ListRecordingTranscriptions(ctx context.Context, recordingSid string) *telnyx.Iter[Transcription] {
+	return listAccountResource[Transcription](ctx, c, c.accountPath("Recordings", recordingSid, "Transcriptions.json"), "transcriptions", nil)
+}
+
+func (c *Client) GetTranscription(ctx context.Context, transcriptionSid string) (*Transcription, error) {
+	var transcription Transcription
+	if err := c.client.Do(ctx, http.MethodGet, c.accountPath("Transcriptions", transcriptionSid+".json"), nil, &transcription); err != nil {
+		return nil, err
+	}
+	return &transcription, nil
+}
+
+func (c *Client) DeleteTranscription(ctx context.Context, transcriptionSid string) error {
+	return c.client.Do(ctx, http.MethodDelete, c.accountPath("Transcriptions", transcriptionSid+".json"), nil, nil)
+}
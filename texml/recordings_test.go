@@ -0,0 +1,135 @@
+package texml
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	telnyx "github.com/andersryanc/telnyx-go"
+	"github.com/andersryanc/telnyx-go/internal/apitest"
+)
+
+func TestListRecordings(t *testing.T) {
+	rec := apitest.NewRecorder(t, func(apitest.Request) (int, string) {
+		return http.StatusOK, `{"recordings":[{"sid":"rec1","call_sid":"v3:abc","channels":2,"duration":"12"}]}`
+	})
+	c := NewClient(rec.Client(), testAccountSid)
+
+	after := time.Date(2025, 5, 6, 8, 0, 0, 0, time.FixedZone("CDT", -5*3600))
+	recordings, err := c.ListRecordings(context.Background(), &ListRecordingsParams{
+		CallSid:       "v3:abc",
+		ConferenceSid: "cf1",
+		CreatedAfter:  after,
+		CreatedBefore: after.Add(24 * time.Hour),
+	}).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recordings) != 1 || recordings[0].Channels != 2 || recordings[0].Duration != "12" {
+		t.Errorf("ListRecordings = %+v", recordings)
+	}
+	r := rec.Expect(http.MethodGet, "/texml/Accounts/AC123/Recordings.json")
+	q := r.Query
+	want := url.Values{
+		"CallSid":       {"v3:abc"},
+		"ConferenceSid": {"cf1"},
+		"DateCreated>":  {"2025-05-06T13:00:00Z"},
+		"DateCreated<":  {"2025-05-07T13:00:00Z"},
+	}
+	if q.Encode() != want.Encode() {
+		t.Errorf("query = %s, want %s", q.Encode(), want.Encode())
+	}
+}
+
+func TestRecordingCRUD(t *testing.T) {
+	rec := apitest.NewRecorder(t, func(r apitest.Request) (int, string) {
+		switch {
+		case r.Method == http.MethodDelete:
+			return http.StatusNoContent, ""
+		case strings.HasSuffix(r.Path, "Transcriptions.json"):
+			return http.StatusOK, `{"transcriptions":[{"sid":"tr1","recording_sid":"rec1","transcription_text":"hello"}]}`
+		case strings.HasPrefix(r.Path, "/texml/Accounts/AC123/Transcriptions/"):
+			return http.StatusOK, `{"sid":"tr1","recording_sid":"rec1","status":"completed"}`
+		}
+		return http.StatusOK, `{"sid":"rec1","status":"completed"}`
+	})
+	c := NewClient(rec.Client(), testAccountSid)
+	ctx := context.Background()
+
+	if _, err := c.GetRecording(ctx, "rec1"); err != nil {
+		t.Fatal(err)
+	}
+	rec.Expect(http.MethodGet, "/texml/Accounts/AC123/Recordings/rec1.json")
+
+	transcriptions, err := c.ListRecordingTranscriptions(ctx, "rec1").All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Expect(http.MethodGet, "/texml/Accounts/AC123/Recordings/rec1/Transcriptions.json")
+	if len(transcriptions) != 1 || transcriptions[0].TranscriptionText != "hello" {
+		t.Errorf("ListRecordingTranscriptions = %+v", transcriptions)
+	}
+
+	if _, err := c.ListTranscriptions(ctx, 50).All(); err != nil {
+		t.Fatal(err)
+	}
+	if r := rec.Expect(http.MethodGet, "/texml/Accounts/AC123/Transcriptions.json"); r.Query.Encode() != "PageSize=50" {
+		t.Errorf("query = %s", r.Query.Encode())
+	}
+
+	if _, err := c.GetTranscription(ctx, "tr1"); err != nil {
+		t.Fatal(err)
+	}
+	rec.Expect(http.MethodGet, "/texml/Accounts/AC123/Transcriptions/tr1.json")
+
+	if err := c.DeleteTranscription(ctx, "tr1"); err != nil {
+		t.Fatal(err)
+	}
+	rec.Expect(http.MethodDelete, "/texml/Accounts/AC123/Transcriptions/tr1.json")
+
+	if err := c.DeleteRecording(ctx, "rec1"); err != nil {
+		t.Fatal(err)
+	}
+	rec.Expect(http.MethodDelete, "/texml/Accounts/AC123/Recordings/rec1.json")
+}
+
+func TestDownloadRecording(t *testing.T) {
+	var fetches int
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/texml/Accounts/AC123/Recordings/rec1.json":
+			fetches++
+			io.WriteString(w, `{"sid":"rec1","media_url":"http://`+r.Host+`/media/rec1.wav?token=`+strconv.Itoa(fetches)+`"}`)
+		case "/texml/Accounts/AC123/Recordings/empty.json":
+			io.WriteString(w, `{"sid":"empty"}`)
+		case "/media/rec1.wav":
+			io.WriteString(w, "RIFF"+r.URL.Query().Get("token"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	for _, want := range []string{"RIFF1", "RIFF2"} {
+		body, err := c.DownloadRecording(context.Background(), "rec1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(body)
+		body.Close()
+		if string(data) != want {
+			t.Errorf("DownloadRecording = %q, want %q from a fresh media URL", data, want)
+		}
+	}
+
+	if _, err := c.DownloadRecording(context.Background(), "empty"); err == nil || !strings.Contains(err.Error(), "no media URL") {
+		t.Errorf("DownloadRecording without a media URL error = %v", err)
+	}
+	if _, err := c.DownloadRecording(context.Background(), "missing"); !telnyx.IsNotFound(err) {
+		t.Errorf("DownloadRecording(missing) error = %v, want not found", err)
+	}
+}
@@ -0,0 +1,68 @@
+// Package audio converts the audio of media streams between the codecs selected by the
+// Codec and BidirectionalCodec attributes of <Stream> and 16-bit linear PCM, resamples
+// it and writes it to WAV files.
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Encodings of the media format of a stream.
+const (
+	PCMU = "PCMU"
+	PCMA = "PCMA"
+	G722 = "G722"
+	OPUS = "OPUS"
+	L16  = "L16"
+)
+
+// Codec converts between an encoding and 16-bit linear PCM. Codecs such as G722 keep
+// state between calls, so a stream needs one Codec per track and direction.
+type Codec interface {
+	// Decode returns the PCM samples of an encoded payload.
+	Decode(payload []byte) ([]int16, error)
+	// Encode returns the payload encoding samples.
+	Encode(samples []int16) ([]byte, error)
+	// SampleRate is the rate, in Hz, of the PCM samples.
+	SampleRate() int
+}
+
+// ErrUnsupportedCodec is returned by NewCodec for encodings without a Codec.
+var ErrUnsupportedCodec = errors.New("audio: unsupported codec")
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]func(sampleRate int) (Codec, error){
+		PCMU: func(int) (Codec, error) { return g711{encode: linearToULaw, decode: &ulawTable}, nil },
+		PCMA: func(int) (Codec, error) { return g711{encode: linearToALaw, decode: &alawTable}, nil },
+		G722: func(int) (Codec, error) { return newG722(), nil },
+		OPUS: newOpus,
+		L16:  newL16,
+	}
+)
+
+// RegisterCodec makes NewCodec return the codecs built by fn for encoding, replacing
+// any built-in codec. The built-in OPUS codec only decodes payloads in the CELT mode,
+// so streams in the SILK or hybrid modes need one registered, for example a wrapper
+// around a libopus binding, as do encodings such as AMR-WB.
+func RegisterCodec(encoding string, fn func(sampleRate int) (Codec, error)) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[strings.ToUpper(encoding)] = fn
+}
+
+// NewCodec returns a Codec for encoding, as found in the media format of a stream.
+// sampleRate is only used by encodings that support several rates, such as L16 and
+// OPUS.
+func NewCodec(encoding string, sampleRate int) (Codec, error) {
+	codecsMu.RLock()
+	fn, ok := codecs[strings.ToUpper(encoding)]
+	codecsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedCodec, encoding)
+	}
+	return fn(sampleRate)
+}
@@ -0,0 +1,201 @@
+package audio
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// tone returns n samples of a sine of freq Hz at rate Hz and amplitude amp.
+func tone(freq float64, rate, n int, amp float64) []int16 {
+	samples := make([]int16, n)
+	for i := range samples {
+		samples[i] = int16(amp * math.Sin(2*math.Pi*freq*float64(i)/float64(rate)))
+	}
+	return samples
+}
+
+// snr returns the signal to noise ratio, in dB, of got against want, with got delayed
+// by up to maxDelay samples.
+func snr(want, got []int16, maxDelay int) float64 {
+	best := math.Inf(-1)
+	for d := 0; d <= maxDelay; d++ {
+		var signal, noise float64
+		for i := 0; i+d < len(got) && i < len(want); i++ {
+			diff := float64(got[i+d]) - float64(want[i])
+			signal += float64(want[i]) * float64(want[i])
+			noise += diff * diff
+		}
+		if r := 10 * math.Log10(signal/noise); r > best {
+			best = r
+		}
+	}
+	return best
+}
+
+func TestG711(t *testing.T) {
+	pcmu, _ := NewCodec(PCMU, 0)
+	pcma, _ := NewCodec("pcma", 0)
+
+	// Silence encodes to 0xFF in µ-law and 0xD5 in A-law.
+	if p, _ := pcmu.Encode([]int16{0}); p[0] != 0xff {
+		t.Errorf("PCMU silence = %#x", p[0])
+	}
+	if p, _ := pcma.Encode([]int16{0}); p[0] != 0xd5 {
+		t.Errorf("PCMA silence = %#x", p[0])
+	}
+	for _, tt := range []struct {
+		codec Codec
+		in    byte
+		want  int16
+	}{
+		{pcmu, 0x00, -32124}, {pcmu, 0x80, 32124}, {pcmu, 0xff, 0},
+		{pcma, 0x55, -8}, {pcma, 0xd5, 8}, {pcma, 0xaa, 32256},
+	} {
+		if got, _ := tt.codec.Decode([]byte{tt.in}); got[0] != tt.want {
+			t.Errorf("Decode(%#x) = %d, want %d", tt.in, got[0], tt.want)
+		}
+	}
+
+	// Every code decodes to a value that encodes back to it, except for the negative
+	// zero of µ-law.
+	for name, codec := range map[string]Codec{PCMU: pcmu, PCMA: pcma} {
+		for b := 0; b < 256; b++ {
+			samples, _ := codec.Decode([]byte{byte(b)})
+			p, _ := codec.Encode(samples)
+			if p[0] != byte(b) && !(name == PCMU && b == 0x7f) {
+				t.Errorf("%s: %#x decodes to %d, which encodes to %#x", name, b, samples[0], p[0])
+			}
+		}
+		if codec.SampleRate() != 8000 {
+			t.Errorf("SampleRate() = %d", codec.SampleRate())
+		}
+	}
+
+	in := tone(1000, 8000, 800, 10000)
+	for name, codec := range map[string]Codec{PCMU: pcmu, PCMA: pcma} {
+		p, _ := codec.Encode(in)
+		out, _ := codec.Decode(p)
+		if r := snr(in, out, 0); r < 30 {
+			t.Errorf("%s round trip SNR = %.1f dB, want at least 30", name, r)
+		}
+	}
+}
+
+func TestG722(t *testing.T) {
+	enc, _ := NewCodec(G722, 0)
+	dec, _ := NewCodec(G722, 0)
+	if enc.SampleRate() != 16000 {
+		t.Errorf("SampleRate() = %d, want 16000", enc.SampleRate())
+	}
+
+	in := tone(1000, 16000, 3200, 8000)
+	var out []int16
+	// Encode and decode in 20 ms chunks; the codec state carries over.
+	for i := 0; i < len(in); i += 320 {
+		p, err := enc.Encode(in[i : i+320])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(p) != 160 {
+			t.Fatalf("20 ms encoded to %d bytes, want 160", len(p))
+		}
+		samples, err := dec.Decode(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, samples...)
+	}
+	if len(out) != len(in) {
+		t.Fatalf("%d samples decoded, want %d", len(out), len(in))
+	}
+	if r := snr(in[400:], out[400:], 64); r < 15 {
+		t.Errorf("G722 round trip SNR = %.1f dB, want at least 15", r)
+	}
+}
+
+func TestL16(t *testing.T) {
+	codec, err := NewCodec(L16, 16000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := codec.Encode([]int16{1, -2, math.MaxInt16})
+	if want := []byte{0x00, 0x01, 0xff, 0xfe, 0x7f, 0xff}; !reflect.DeepEqual(p, want) {
+		t.Errorf("Encode = %x, want big endian %x", p, want)
+	}
+	if samples, _ := codec.Decode(p); !reflect.DeepEqual(samples, []int16{1, -2, math.MaxInt16}) {
+		t.Errorf("Decode = %v", samples)
+	}
+	if _, err := codec.Decode([]byte{1, 2, 3}); err == nil {
+		t.Error("an odd-length payload was decoded")
+	}
+	if codec.SampleRate() != 16000 {
+		t.Errorf("SampleRate() = %d", codec.SampleRate())
+	}
+	if _, err := NewCodec(L16, 0); err == nil {
+		t.Error("L16 without a sample rate was accepted")
+	}
+}
+
+type fakeCodec struct{ rate int }
+
+func (c fakeCodec) Decode(payload []byte) ([]int16, error) { return nil, nil }
+func (c fakeCodec) Encode(samples []int16) ([]byte, error) { return nil, nil }
+func (c fakeCodec) SampleRate() int                        { return c.rate }
+
+func TestRegisterCodec(t *testing.T) {
+	if _, err := NewCodec("AMR-WB", 16000); !errors.Is(err, ErrUnsupportedCodec) {
+		t.Fatalf("NewCodec(AMR-WB) error = %v, want ErrUnsupportedCodec", err)
+	}
+	RegisterCodec("amr-wb", func(rate int) (Codec, error) { return fakeCodec{rate}, nil })
+	defer func() {
+		codecsMu.Lock()
+		delete(codecs, "AMR-WB")
+		codecsMu.Unlock()
+	}()
+	codec, err := NewCodec("AMR-WB", 16000)
+	if err != nil || codec.SampleRate() != 16000 {
+		t.Errorf("NewCodec(AMR-WB) = %v, %v", codec, err)
+	}
+}
+
+func TestOpus(t *testing.T) {
+	codec, err := NewCodec(OPUS, 16000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codec.SampleRate() != 16000 {
+		t.Errorf("SampleRate() = %d", codec.SampleRate())
+	}
+	in := tone(1000, 16000, 16000, 8000)
+	var out []int16
+	for i := 0; i < len(in); i += 320 {
+		payload, err := codec.Encode(in[i : i+320])
+		if err != nil {
+			t.Fatal(err)
+		}
+		samples, err := codec.Decode(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, samples...)
+	}
+	if r := snr(in[1600:], out[1600:], 160); r < 15 {
+		t.Errorf("SNR = %.1f dB", r)
+	}
+
+	// A SILK-only wideband packet.
+	if _, err := codec.Decode([]byte{0x48, 0x00}); !errors.Is(err, ErrUnsupportedCodec) {
+		t.Errorf("Decode(SILK) error = %v, want ErrUnsupportedCodec", err)
+	}
+	if _, err := codec.Encode(make([]int16, 30)); err == nil {
+		t.Error("30 samples were encoded")
+	}
+	if _, err := NewCodec(OPUS, 44100); err == nil {
+		t.Error("OPUS at 44.1 kHz was accepted")
+	}
+	if codec, err := NewCodec(OPUS, 0); err != nil || codec.SampleRate() != 48000 {
+		t.Errorf("NewCodec(OPUS, 0) = %v, %v", codec, err)
+	}
+}
@@ -0,0 +1,175 @@
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+var (
+	dtmfRows   = [4]float64{697, 770, 852, 941}
+	dtmfCols   = [4]float64{1209, 1336, 1477, 1633}
+	dtmfDigits = [4][4]string{
+		{"1", "2", "3", "A"},
+		{"4", "5", "6", "B"},
+		{"7", "8", "9", "C"},
+		{"*", "0", "#", "D"},
+	}
+)
+
+const (
+	// dtmfMinLevel is the lowest level, in dBFS, of a block holding a digit.
+	dtmfMinLevel = -40
+	// dtmfPurity is the share of the energy of a block that the two tones of a digit
+	// must hold, which rejects speech and music.
+	dtmfPurity = 0.7
+	// dtmfTwist is the largest power ratio allowed between the two tones, 8 dB.
+	dtmfTwist = 6.3
+	// dtmfRelativePeak is how many times stronger than the other frequencies of its
+	// group each tone must be, 6 dB.
+	dtmfRelativePeak = 4
+	// dtmfStableBlocks is how many consecutive blocks must agree before a digit, or the
+	// end of one, is reported.
+	dtmfStableBlocks = 2
+)
+
+// DTMFTone is a digit detected by a DTMFDetector.
+type DTMFTone struct {
+	// Digit is one of 0-9, *, # and A-D.
+	Digit string
+	// Offset is the start of the tone from the start of the audio given to the detector.
+	Offset time.Duration
+}
+
+// DTMFDetector finds the DTMF digits in audio with the Goertzel algorithm. The audio is
+// analyzed in blocks of about 25 ms that overlap by half, and a digit is reported once
+// it has been heard in two consecutive blocks, which catches tones as short as the
+// 40 ms allowed by ITU-T Q.24. It is reported again only after a pause.
+type DTMFDetector struct {
+	sampleRate int
+	blockSize  int
+	// step is the distance between the starts of consecutive blocks.
+	step      int
+	rowCoeffs [4]float64
+	colCoeffs [4]float64
+
+	block []int16
+	// pos is the number of samples processed so far.
+	pos int64
+
+	candidate      string
+	candidateStart int64
+	count          int
+	reported       string
+}
+
+// NewDTMFDetector returns a DTMFDetector for audio at sampleRate.
+func NewDTMFDetector(sampleRate int) *DTMFDetector {
+	d := &DTMFDetector{
+		sampleRate: sampleRate,
+		// 205 samples at 8 kHz resolve the DTMF frequencies in the fewest samples.
+		blockSize: 205 * sampleRate / 8000,
+	}
+	d.step = d.blockSize / 2
+	for i := range dtmfRows {
+		d.rowCoeffs[i] = 2 * math.Cos(2*math.Pi*dtmfRows[i]/float64(sampleRate))
+		d.colCoeffs[i] = 2 * math.Cos(2*math.Pi*dtmfCols[i]/float64(sampleRate))
+	}
+	return d
+}
+
+// SampleRate returns the sample rate of the audio the detector analyzes.
+func (d *DTMFDetector) SampleRate() int {
+	return d.sampleRate
+}
+
+// Process analyzes the next samples of the audio and returns the digits they complete.
+func (d *DTMFDetector) Process(samples []int16) []DTMFTone {
+	var tones []DTMFTone
+	for len(samples) > 0 {
+		n := d.blockSize - len(d.block)
+		if n > len(samples) {
+			n = len(samples)
+		}
+		d.block = append(d.block, samples[:n]...)
+		samples = samples[n:]
+		if len(d.block) < d.blockSize {
+			break
+		}
+
+		digit := d.classify(d.block)
+		if digit != d.candidate {
+			d.candidate, d.candidateStart, d.count = digit, d.pos, 0
+		}
+		d.count++
+		if d.count == dtmfStableBlocks && d.candidate != d.reported {
+			d.reported = d.candidate
+			if digit != "" {
+				offset := time.Duration(d.candidateStart) * time.Second / time.Duration(d.sampleRate)
+				tones = append(tones, DTMFTone{Digit: digit, Offset: offset})
+			}
+		}
+		d.pos += int64(d.step)
+		d.block = append(d.block[:0], d.block[d.step:]...)
+	}
+	return tones
+}
+
+// classify returns the digit a block holds, or "".
+func (d *DTMFDetector) classify(block []int16) string {
+	if level(block) < dtmfMinLevel {
+		return ""
+	}
+	energy := 0.0
+	for _, s := range block {
+		energy += float64(s) * float64(s)
+	}
+
+	var rows, cols [4]float64
+	for i := range rows {
+		rows[i] = goertzel(block, d.rowCoeffs[i])
+		cols[i] = goertzel(block, d.colCoeffs[i])
+	}
+	row, rowOK := peak(rows)
+	col, colOK := peak(cols)
+	if !rowOK || !colOK {
+		return ""
+	}
+
+	r, c := rows[row], cols[col]
+	if r > dtmfTwist*c || c > dtmfTwist*r {
+		return ""
+	}
+	// A sine of amplitude A has a Goertzel power of (A*N/2)^2 and an energy of
+	// N*A^2/2, so 2*P/(N*E) is the share of the energy held by the tone.
+	if 2*(r+c)/(float64(len(block))*energy) < dtmfPurity {
+		return ""
+	}
+	return dtmfDigits[row][col]
+}
+
+// peak returns the index of the strongest of powers, and whether it stands out from the
+// others.
+func peak(powers [4]float64) (int, bool) {
+	best := 0
+	for i, p := range powers {
+		if p > powers[best] {
+			best = i
+		}
+	}
+	for i, p := range powers {
+		if i != best && p*dtmfRelativePeak > powers[best] {
+			return best, false
+		}
+	}
+	return best, true
+}
+
+// goertzel returns the power of samples at the frequency of coeff, 2cos(2πf/fs).
+func goertzel(samples []int16, coeff float64) float64 {
+	var s1, s2 float64
+	for _, x := range samples {
+		s0 := float64(x) + coeff*s1 - s2
+		s2, s1 = s1, s0
+	}
+	return s1*s1 + s2*s2 - coeff*s1*s2
+}
@@ -0,0 +1,148 @@
+package audio
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+const allDigits = "123A456B789C*0#D"
+
+// dtmf returns ms milliseconds of the tone pair row and col Hz at rate, each tone at
+// level dBFS plus its own offset.
+func dtmf(row, col float64, rate, ms int, rowDB, colDB float64) []int16 {
+	rowAmp := 32768 * math.Pow(10, rowDB/20)
+	colAmp := 32768 * math.Pow(10, colDB/20)
+	samples := make([]int16, rate*ms/1000)
+	for i := range samples {
+		x := float64(i) / float64(rate)
+		samples[i] = int16(rowAmp*math.Sin(2*math.Pi*row*x) + colAmp*math.Sin(2*math.Pi*col*x))
+	}
+	return samples
+}
+
+// digit returns ms milliseconds of the tone of d at rate, with both tones at -10 dBFS.
+func digit(d byte, rate, ms int) []int16 {
+	i := strings.IndexByte(allDigits, d)
+	return dtmf(dtmfRows[i/4], dtmfCols[i%4], rate, ms, -10, -10)
+}
+
+func silence(rate, ms int) []int16 {
+	return make([]int16, rate*ms/1000)
+}
+
+// detect runs a detector over samples in 20 ms chunks and returns the digits found.
+func detect(rate int, samples []int16) []DTMFTone {
+	d := NewDTMFDetector(rate)
+	var tones []DTMFTone
+	chunk := rate / 50
+	for i := 0; i < len(samples); i += chunk {
+		end := i + chunk
+		if end > len(samples) {
+			end = len(samples)
+		}
+		tones = append(tones, d.Process(samples[i:end])...)
+	}
+	return tones
+}
+
+func digits(tones []DTMFTone) string {
+	var b strings.Builder
+	for _, t := range tones {
+		b.WriteString(t.Digit)
+	}
+	return b.String()
+}
+
+func TestDTMFAllDigits(t *testing.T) {
+	for _, rate := range []int{8000, 16000} {
+		var samples []int16
+		for i := 0; i < len(allDigits); i++ {
+			samples = append(samples, concat(digit(allDigits[i], rate, 100), silence(rate, 50))...)
+		}
+		tones := detect(rate, samples)
+		if got := digits(tones); got != allDigits {
+			t.Errorf("%d Hz: digits = %q, want %q", rate, got, allDigits)
+			continue
+		}
+		for i, tone := range tones {
+			// Offsets are accurate to a block step, about 13 ms.
+			want := time.Duration(i) * 150 * time.Millisecond
+			if tone.Offset < want-13*time.Millisecond || tone.Offset > want+13*time.Millisecond {
+				t.Errorf("%d Hz: %s at %v, want %v", rate, tone.Digit, tone.Offset, want)
+			}
+		}
+	}
+}
+
+func TestDTMFShortTones(t *testing.T) {
+	// 40 ms tones and pauses, the shortest ITU-T Q.24 allows, at every alignment with
+	// the blocks.
+	for lead := 0; lead < 205; lead += 7 {
+		samples := make([]int16, lead)
+		for i := 0; i < len(allDigits); i++ {
+			samples = append(samples, concat(digit(allDigits[i], 8000, 40), silence(8000, 40))...)
+		}
+		if got := digits(detect(8000, samples)); got != allDigits {
+			t.Errorf("lead of %d samples: digits = %q, want %q", lead, got, allDigits)
+		}
+	}
+}
+
+func TestDTMFRepeatsAfterPause(t *testing.T) {
+	// A held digit is reported once, and again after a pause.
+	samples := concat(digit('5', 8000, 1000), silence(8000, 40), digit('5', 8000, 60))
+	if got := digits(detect(8000, samples)); got != "55" {
+		t.Errorf("digits = %q, want 55", got)
+	}
+}
+
+func TestDTMFTwist(t *testing.T) {
+	for _, tt := range []struct {
+		rowDB, colDB float64
+		want         string
+	}{
+		{-10, -14, "5"},
+		{-14, -10, "5"},
+		{-10, -20, ""},
+		{-20, -10, ""},
+	} {
+		samples := dtmf(770, 1336, 8000, 100, tt.rowDB, tt.colDB)
+		if got := digits(detect(8000, samples)); got != tt.want {
+			t.Errorf("row at %v dB, column at %v dB: digits = %q, want %q", tt.rowDB, tt.colDB, got, tt.want)
+		}
+	}
+}
+
+func TestDTMFRejects(t *testing.T) {
+	var speech []int16
+	for _, f := range []float64{180, 360, 540, 720, 900, 1080, 1260} {
+		speech = append(speech, tone(f, 8000, 800, 3000)...)
+	}
+	for name, samples := range map[string][]int16{
+		// Halfway between the rows and columns of 5.
+		"off frequency": dtmf(811, 1406, 8000, 200, -10, -10),
+		// 697 and 1209 Hz shifted by 5%.
+		"shifted":     dtmf(732, 1269, 8000, 200, -10, -10),
+		"single tone": tone(770, 8000, 1600, 10000),
+		"too quiet":   dtmf(770, 1336, 8000, 200, -50, -50),
+		"noise":       noise(5*8000, 10000, 1),
+		"harmonics":   speech,
+		"two rows":    dtmf(770, 941, 8000, 200, -10, -10),
+	} {
+		if tones := detect(8000, samples); len(tones) != 0 {
+			t.Errorf("%s: digits %q detected", name, digits(tones))
+		}
+	}
+}
+
+func TestDTMFInNoise(t *testing.T) {
+	samples := concat(silence(8000, 100), digit('#', 8000, 60), silence(8000, 100))
+	for i, n := range noise(len(samples), 300, 2) {
+		samples[i] += n
+	}
+	if got := digits(detect(8000, samples)); got != "#" {
+		t.Errorf("digits = %q, want #", got)
+	}
+}
@@ -0,0 +1,119 @@
+package audio
+
+// g711 is the PCMU or PCMA codec. Both are stateless, with one byte per sample at
+// 8 kHz.
+type g711 struct {
+	encode func(int16) byte
+	decode *[256]int16
+}
+
+func (c g711) Decode(payload []byte) ([]int16, error) {
+	samples := make([]int16, len(payload))
+	for i, b := range payload {
+		samples[i] = c.decode[b]
+	}
+	return samples, nil
+}
+
+func (c g711) Encode(samples []int16) ([]byte, error) {
+	payload := make([]byte, len(samples))
+	for i, s := range samples {
+		payload[i] = c.encode(s)
+	}
+	return payload, nil
+}
+
+func (c g711) SampleRate() int {
+	return 8000
+}
+
+var ulawTable, alawTable [256]int16
+
+func init() {
+	for i := range ulawTable {
+		ulawTable[i] = ulawToLinear(byte(i))
+		alawTable[i] = alawToLinear(byte(i))
+	}
+}
+
+const (
+	ulawBias = 0x84
+	ulawClip = 32635
+)
+
+func linearToULaw(sample int16) byte {
+	s := int(sample)
+	sign := 0
+	if s < 0 {
+		s = -s
+		sign = 0x80
+	}
+	if s > ulawClip {
+		s = ulawClip
+	}
+	s += ulawBias
+
+	exponent := 7
+	for mask := 0x4000; s&mask == 0 && exponent > 0; mask >>= 1 {
+		exponent--
+	}
+	mantissa := (s >> (exponent + 3)) & 0x0f
+	return ^byte(sign | exponent<<4 | mantissa)
+}
+
+func ulawToLinear(u byte) int16 {
+	u = ^u
+	exponent := int(u>>4) & 0x07
+	mantissa := int(u & 0x0f)
+	s := ((mantissa << 3) + ulawBias) << exponent
+	s -= ulawBias
+	if u&0x80 != 0 {
+		return int16(-s)
+	}
+	return int16(s)
+}
+
+// alawSegmentEnd holds the largest 13-bit magnitude of each A-law segment.
+var alawSegmentEnd = [8]int{0x1f, 0x3f, 0x7f, 0xff, 0x1ff, 0x3ff, 0x7ff, 0xfff}
+
+func linearToALaw(sample int16) byte {
+	s := int(sample) >> 3
+	mask := 0xd5
+	if s < 0 {
+		mask = 0x55
+		s = -s - 1
+	}
+
+	segment := 0
+	for segment < 8 && s > alawSegmentEnd[segment] {
+		segment++
+	}
+	if segment == 8 {
+		return byte(0x7f ^ mask)
+	}
+	a := segment << 4
+	if segment < 2 {
+		a |= (s >> 1) & 0x0f
+	} else {
+		a |= (s >> segment) & 0x0f
+	}
+	return byte(a ^ mask)
+}
+
+func alawToLinear(a byte) int16 {
+	a ^= 0x55
+	s := int(a&0x0f) << 4
+	switch segment := int(a&0x70) >> 4; segment {
+	case 0:
+		s += 8
+	case 1:
+		s += 0x108
+	default:
+		s += 0x108
+		s <<= segment - 1
+	}
+	if a&0x80 != 0 {
+		return int16(s)
+	}
+	return int16(-s)
+}
@@ -0,0 +1,296 @@
+package audio
+
+// G.722 at 64 kbit/s: a QMF splits 16 kHz audio into a low and a high band, coded with
+// 6 and 2 bits of sub-band ADPCM. This follows the block structure of the ITU-T G.722
+// reference implementation.
+
+var (
+	g722Q6   = [32]int{0, 35, 72, 110, 150, 190, 233, 276, 323, 370, 422, 473, 530, 587, 650, 714, 786, 858, 940, 1023, 1121, 1219, 1339, 1458, 1612, 1765, 1980, 2195, 2557, 2919, 0, 0}
+	g722ILN  = [32]int{0, 63, 62, 31, 30, 29, 28, 27, 26, 25, 24, 23, 22, 21, 20, 19, 18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 0}
+	g722ILP  = [32]int{0, 61, 60, 59, 58, 57, 56, 55, 54, 53, 52, 51, 50, 49, 48, 47, 46, 45, 44, 43, 42, 41, 40, 39, 38, 37, 36, 35, 34, 33, 32, 0}
+	g722WL   = [8]int{-60, -30, 58, 172, 334, 538, 1198, 3042}
+	g722RL42 = [16]int{0, 7, 6, 5, 4, 3, 2, 1, 7, 6, 5, 4, 3, 2, 1, 0}
+	g722ILB  = [32]int{2048, 2093, 2139, 2186, 2233, 2282, 2332, 2383, 2435, 2489, 2543, 2599, 2656, 2714, 2774, 2834, 2896, 2960, 3025, 3091, 3158, 3228, 3298, 3371, 3444, 3520, 3597, 3676, 3756, 3838, 3922, 4008}
+	g722QM4  = [16]int{0, -20456, -12896, -8968, -6288, -4240, -2584, -1200, 20456, 12896, 8968, 6288, 4240, 2584, 1200, 0}
+	g722QM6  = [64]int{
+		-136, -136, -136, -136, -24808, -21904, -19008, -16704,
+		-14984, -13512, -12280, -11192, -10232, -9360, -8576, -7856,
+		-7192, -6576, -6000, -5456, -4944, -4464, -4008, -3576,
+		-3168, -2776, -2400, -2032, -1688, -1360, -1040, -728,
+		24808, 21904, 19008, 16704, 14984, 13512, 12280, 11192,
+		10232, 9360, 8576, 7856, 7192, 6576, 6000, 5456,
+		4944, 4464, 4008, 3576, 3168, 2776, 2400, 2032,
+		1688, 1360, 1040, 728, 432, 136, -432, -136,
+	}
+	g722QM2 = [4]int{-7408, -1616, 7408, 1616}
+	g722QMF = [12]int{3, -11, 12, 32, -210, 951, 3876, -805, 362, -156, 53, -11}
+	g722IHN = [3]int{0, 1, 0}
+	g722IHP = [3]int{0, 3, 2}
+	g722WH  = [3]int{0, -214, 798}
+	g722RH2 = [4]int{2, 1, 2, 1}
+)
+
+// g722Band is the ADPCM state of one sub-band.
+type g722Band struct {
+	s, sp, sz int
+	r, a, ap  [3]int
+	p         [3]int
+	d, b, bp  [7]int
+	sg        [7]int
+	nb, det   int
+}
+
+// g722State is the state of one direction: the QMF delay line and both sub-bands.
+type g722State struct {
+	x    [24]int
+	band [2]g722Band
+}
+
+func newG722State() g722State {
+	var s g722State
+	s.band[0].det = 32
+	s.band[1].det = 8
+	return s
+}
+
+type g722Codec struct {
+	enc, dec g722State
+	// odd holds the last sample of an odd-length Encode input until the next call.
+	odd    int16
+	hasOdd bool
+}
+
+func newG722() *g722Codec {
+	return &g722Codec{enc: newG722State(), dec: newG722State()}
+}
+
+func (c *g722Codec) SampleRate() int {
+	return 16000
+}
+
+func saturate(v int) int {
+	if v > 32767 {
+		return 32767
+	}
+	if v < -32768 {
+		return -32768
+	}
+	return v
+}
+
+// scale returns the quantizer scale factor of a band for its log scale factor nb.
+func scale(nb, shift int) int {
+	wd1 := (nb >> 6) & 31
+	wd2 := shift - (nb >> 11)
+	var wd3 int
+	if wd2 < 0 {
+		wd3 = g722ILB[wd1] << -wd2
+	} else {
+		wd3 = g722ILB[wd1] >> wd2
+	}
+	return wd3 << 2
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// block4 updates the predictor of a band with the quantized difference signal d.
+func (s *g722Band) block4(d int) {
+	// RECONS and PARREC
+	s.d[0] = d
+	s.r[0] = saturate(s.s + d)
+	s.p[0] = saturate(s.sz + d)
+
+	// UPPOL2
+	for i := 0; i < 3; i++ {
+		s.sg[i] = s.p[i] >> 15
+	}
+	wd1 := saturate(s.a[1] << 2)
+	wd2 := wd1
+	if s.sg[0] == s.sg[1] {
+		wd2 = -wd1
+	}
+	if wd2 > 32767 {
+		wd2 = 32767
+	}
+	wd3 := -128
+	if s.sg[0] == s.sg[2] {
+		wd3 = 128
+	}
+	wd3 += wd2 >> 7
+	wd3 += (s.a[2] * 32512) >> 15
+	s.ap[2] = clamp(wd3, -12288, 12288)
+
+	// UPPOL1
+	s.sg[0] = s.p[0] >> 15
+	s.sg[1] = s.p[1] >> 15
+	wd1 = -192
+	if s.sg[0] == s.sg[1] {
+		wd1 = 192
+	}
+	wd2 = (s.a[1] * 32640) >> 15
+	s.ap[1] = saturate(wd1 + wd2)
+	wd3 = saturate(15360 - s.ap[2])
+	s.ap[1] = clamp(s.ap[1], -wd3, wd3)
+
+	// UPZERO
+	wd1 = 128
+	if d == 0 {
+		wd1 = 0
+	}
+	s.sg[0] = d >> 15
+	for i := 1; i < 7; i++ {
+		s.sg[i] = s.d[i] >> 15
+		wd2 = -wd1
+		if s.sg[i] == s.sg[0] {
+			wd2 = wd1
+		}
+		wd3 = (s.b[i] * 32640) >> 15
+		s.bp[i] = saturate(wd2 + wd3)
+	}
+
+	// DELAYA
+	for i := 6; i > 0; i-- {
+		s.d[i] = s.d[i-1]
+		s.b[i] = s.bp[i]
+	}
+	for i := 2; i > 0; i-- {
+		s.r[i] = s.r[i-1]
+		s.p[i] = s.p[i-1]
+		s.a[i] = s.ap[i]
+	}
+
+	// FILTEP
+	wd1 = saturate(s.r[1] + s.r[1])
+	wd1 = (s.a[1] * wd1) >> 15
+	wd2 = saturate(s.r[2] + s.r[2])
+	wd2 = (s.a[2] * wd2) >> 15
+	s.sp = saturate(wd1 + wd2)
+
+	// FILTEZ
+	s.sz = 0
+	for i := 6; i > 0; i-- {
+		wd1 = saturate(s.d[i] + s.d[i])
+		s.sz += (s.b[i] * wd1) >> 15
+	}
+	s.sz = saturate(s.sz)
+
+	// PREDIC
+	s.s = saturate(s.sp + s.sz)
+}
+
+func (c *g722Codec) Encode(samples []int16) ([]byte, error) {
+	if c.hasOdd {
+		samples = append([]int16{c.odd}, samples...)
+		c.hasOdd = false
+	}
+	if len(samples)%2 != 0 {
+		c.odd, c.hasOdd = samples[len(samples)-1], true
+		samples = samples[:len(samples)-1]
+	}
+
+	s := &c.enc
+	low, high := &s.band[0], &s.band[1]
+	out := make([]byte, 0, len(samples)/2)
+	for j := 0; j < len(samples); j += 2 {
+		// Transmit QMF
+		copy(s.x[:22], s.x[2:])
+		s.x[22] = int(samples[j])
+		s.x[23] = int(samples[j+1])
+		sumEven, sumOdd := 0, 0
+		for i := 0; i < 12; i++ {
+			sumOdd += s.x[2*i] * g722QMF[i]
+			sumEven += s.x[2*i+1] * g722QMF[11-i]
+		}
+		xlow := (sumEven + sumOdd) >> 14
+		xhigh := (sumEven - sumOdd) >> 14
+
+		// Low band: SUBTRA, QUANTL, INVQAL, LOGSCL, SCALEL
+		el := saturate(xlow - low.s)
+		wd := el
+		if el < 0 {
+			wd = -(el + 1)
+		}
+		i := 1
+		for ; i < 30; i++ {
+			if wd < (g722Q6[i]*low.det)>>12 {
+				break
+			}
+		}
+		ilow := g722ILP[i]
+		if el < 0 {
+			ilow = g722ILN[i]
+		}
+		ril := ilow >> 2
+		dlow := (low.det * g722QM4[ril]) >> 15
+		low.nb = clamp((low.nb*127)>>7+g722WL[g722RL42[ril]], 0, 18432)
+		low.det = scale(low.nb, 8)
+		low.block4(dlow)
+
+		// High band: SUBTRA, QUANTH, INVQAH, LOGSCH, SCALEH
+		eh := saturate(xhigh - high.s)
+		wd = eh
+		if eh < 0 {
+			wd = -(eh + 1)
+		}
+		mih := 1
+		if wd >= (564*high.det)>>12 {
+			mih = 2
+		}
+		ihigh := g722IHP[mih]
+		if eh < 0 {
+			ihigh = g722IHN[mih]
+		}
+		dhigh := (high.det * g722QM2[ihigh]) >> 15
+		high.nb = clamp((high.nb*127)>>7+g722WH[g722RH2[ihigh]], 0, 22528)
+		high.det = scale(high.nb, 10)
+		high.block4(dhigh)
+
+		out = append(out, byte(ihigh<<6|ilow))
+	}
+	return out, nil
+}
+
+func (c *g722Codec) Decode(payload []byte) ([]int16, error) {
+	s := &c.dec
+	low, high := &s.band[0], &s.band[1]
+	out := make([]int16, 0, 2*len(payload))
+	for _, code := range payload {
+		ilow := int(code & 0x3f)
+		ihigh := int(code>>6) & 0x03
+
+		// Low band: INVQBL, RECONS, LIMIT, INVQAL, LOGSCL, SCALEL
+		rlow := clamp(low.s+(low.det*g722QM6[ilow])>>15, -16384, 16383)
+		ril := ilow >> 2
+		dlow := (low.det * g722QM4[ril]) >> 15
+		low.nb = clamp((low.nb*127)>>7+g722WL[g722RL42[ril]], 0, 18432)
+		low.det = scale(low.nb, 8)
+		low.block4(dlow)
+
+		// High band: INVQAH, RECONS, LIMIT, LOGSCH, SCALEH
+		dhigh := (high.det * g722QM2[ihigh]) >> 15
+		rhigh := clamp(dhigh+high.s, -16384, 16383)
+		high.nb = clamp((high.nb*127)>>7+g722WH[g722RH2[ihigh]], 0, 22528)
+		high.det = scale(high.nb, 10)
+		high.block4(dhigh)
+
+		// Receive QMF
+		copy(s.x[:22], s.x[2:])
+		s.x[22] = rlow + rhigh
+		s.x[23] = rlow - rhigh
+		out1, out2 := 0, 0
+		for i := 0; i < 12; i++ {
+			out2 += s.x[2*i] * g722QMF[i]
+			out1 += s.x[2*i+1] * g722QMF[11-i]
+		}
+		out = append(out, int16(saturate(out1>>11)), int16(saturate(out2>>11)))
+	}
+	return out, nil
+}
@@ -0,0 +1,806 @@
+package opus
+
+import "math"
+
+// Quantization of the band shapes, from celt/bands.c: bands are split recursively,
+// coding the angle theta between the halves, until the pulses fit a PVQ codebook.
+
+type bandCtx struct {
+	coder
+	encode        bool
+	resynth       bool
+	i             int
+	intensity     int
+	spread        int
+	tfChange      int
+	remainingBits int
+	// bandE holds the band amplitudes of both channels, for intensity stereo.
+	bandE           []float32
+	seed            uint32
+	avoidSplitNoise bool
+}
+
+type splitCtx struct {
+	inv    bool
+	imid   int
+	iside  int
+	delta  int
+	itheta int
+	qalloc int
+}
+
+// fracMul16 is FRAC_MUL16 of libopus: a Q15 product of 16-bit values, rounded.
+func fracMul16(a, b int) int {
+	return (16384 + int(int16(a))*int(int16(b))) >> 15
+}
+
+func bitexactCos(x int) int {
+	tmp := (4096 + x*x) >> 13
+	x2 := tmp
+	x2 = (32767 - x2) + fracMul16(x2, -7651+fracMul16(x2, 8277+fracMul16(-626, x2)))
+	return 1 + x2
+}
+
+func bitexactLog2tan(isin, icos int) int {
+	lc := ilog(uint32(icos))
+	ls := ilog(uint32(isin))
+	icos <<= uint(15 - lc)
+	isin <<= uint(15 - ls)
+	return (ls-lc)*(1<<11) + fracMul16(isin, fracMul16(isin, -2597)+7932) - fracMul16(icos, fracMul16(icos, -2597)+7932)
+}
+
+var exp2Table8 = [8]int{16384, 17866, 19483, 21247, 23170, 25267, 27554, 30048}
+
+// sdiv is a division rounding towards zero, as in C.
+func sdiv(a, b int) int {
+	return a / b
+}
+
+func computeQn(n, b, offset, pulseCap int, stereo bool) int {
+	n2 := 2*n - 1
+	if stereo && n == 2 {
+		n2--
+	}
+	qb := sdiv(b+n2*offset, n2)
+	if v := b - pulseCap - 4<<bitRes; v < qb {
+		qb = v
+	}
+	if qb > 8<<bitRes {
+		qb = 8 << bitRes
+	}
+	if qb < 1<<bitRes>>1 {
+		return 1
+	}
+	qn := exp2Table8[qb&7] >> uint(14-qb>>bitRes)
+	return (qn + 1) >> 1 << 1
+}
+
+func intensityStereo(x, y []float32, bandE []float32, band int) {
+	left := float64(bandE[band])
+	right := float64(bandE[band+nbEBands])
+	norm := 1e-15 + math.Sqrt(1e-15+left*left+right*right)
+	a1 := float32(left / norm)
+	a2 := float32(right / norm)
+	for j := range x {
+		x[j] = a1*x[j] + a2*y[j]
+	}
+}
+
+func stereoSplit(x, y []float32) {
+	for j := range x {
+		l := 0.70710678 * x[j]
+		r := 0.70710678 * y[j]
+		x[j] = l + r
+		y[j] = r - l
+	}
+}
+
+func stereoMerge(x, y []float32, mid float32) {
+	var xp, side float32
+	for j := range x {
+		xp += y[j] * x[j]
+		side += y[j] * y[j]
+	}
+	xp *= mid
+	el := mid*mid + side - 2*xp
+	er := mid*mid + side + 2*xp
+	if er < 6e-4 || el < 6e-4 {
+		copy(y, x)
+		return
+	}
+	lgain := float32(1 / math.Sqrt(float64(el)))
+	rgain := float32(1 / math.Sqrt(float64(er)))
+	for j := range x {
+		l := mid * x[j]
+		r := y[j]
+		x[j] = lgain * (l - r)
+		y[j] = rgain * (l + r)
+	}
+}
+
+func isqrt32(v uint32) int {
+	return int(math.Sqrt(float64(v)))
+}
+
+func (ctx *bandCtx) computeTheta(sctx *splitCtx, x, y []float32, n int, b *int, B, b0, lm int, stereo bool, fill *int) {
+	pulseCap := logN[ctx.i] + lm*(1<<bitRes)
+	offset := pulseCap>>1 - qthetaOffset
+	if stereo && n == 2 {
+		offset = pulseCap>>1 - qthetaOffsetTwoPhase
+	}
+	qn := computeQn(n, *b, offset, pulseCap, stereo)
+	if stereo && ctx.i >= ctx.intensity {
+		qn = 1
+	}
+	itheta := 0
+	if ctx.encode {
+		itheta = stereoItheta(x, y, stereo)
+	}
+	tell := ctx.tellFrac()
+	inv := false
+	if qn != 1 {
+		if ctx.encode {
+			itheta = (itheta*qn + 8192) >> 14
+			if !stereo && ctx.avoidSplitNoise && itheta > 0 && itheta < qn {
+				unquantized := itheta * 16384 / qn
+				imid := bitexactCos(unquantized)
+				iside := bitexactCos(16384 - unquantized)
+				delta := fracMul16((n-1)<<7, bitexactLog2tan(iside, imid))
+				if delta > *b {
+					itheta = qn
+				} else if delta < -*b {
+					itheta = 0
+				}
+			}
+		}
+		switch {
+		case stereo && n > 2:
+			// A step distribution, three times as likely below a half turn.
+			const p0 = 3
+			x0 := qn / 2
+			ft := uint32(p0*(x0+1) + x0)
+			xv := itheta
+			if !ctx.encode {
+				fs := int(ctx.dec.decode(ft))
+				if fs < (x0+1)*p0 {
+					xv = fs / p0
+				} else {
+					xv = x0 + 1 + (fs - (x0+1)*p0)
+				}
+			}
+			var fl, fh int
+			if xv <= x0 {
+				fl, fh = p0*xv, p0*(xv+1)
+			} else {
+				fl, fh = (xv-1-x0)+(x0+1)*p0, (xv-x0)+(x0+1)*p0
+			}
+			if ctx.encode {
+				ctx.enc.encode(uint32(fl), uint32(fh), ft)
+			} else {
+				ctx.dec.update(uint32(fl), uint32(fh), ft)
+				itheta = xv
+			}
+		case b0 > 1 || stereo:
+			itheta = ctx.uint(itheta, qn+1)
+		default:
+			// A triangular distribution.
+			ft := ((qn >> 1) + 1) * ((qn >> 1) + 1)
+			if ctx.encode {
+				fs := qn + 1 - itheta
+				fl := ft - ((qn+1-itheta)*(qn+2-itheta))>>1
+				if itheta <= qn>>1 {
+					fs = itheta + 1
+					fl = itheta * (itheta + 1) >> 1
+				}
+				ctx.enc.encode(uint32(fl), uint32(fl+fs), uint32(ft))
+			} else {
+				fm := int(ctx.dec.decode(uint32(ft)))
+				var fs, fl int
+				if fm < ((qn>>1)*((qn>>1)+1))>>1 {
+					itheta = (isqrt32(8*uint32(fm)+1) - 1) >> 1
+					fs = itheta + 1
+					fl = itheta * (itheta + 1) >> 1
+				} else {
+					itheta = (2*(qn+1) - isqrt32(8*uint32(ft-fm-1)+1)) >> 1
+					fs = qn + 1 - itheta
+					fl = ft - ((qn+1-itheta)*(qn+2-itheta))>>1
+				}
+				ctx.dec.update(uint32(fl), uint32(fl+fs), uint32(ft))
+			}
+		}
+		itheta = itheta * 16384 / qn
+		if ctx.encode && stereo {
+			if itheta == 0 {
+				intensityStereo(x, y, ctx.bandE, ctx.i)
+			} else {
+				stereoSplit(x, y)
+			}
+		}
+	} else if stereo {
+		if ctx.encode {
+			inv = itheta > 8192
+			if inv {
+				for j := range y {
+					y[j] = -y[j]
+				}
+			}
+			intensityStereo(x, y, ctx.bandE, ctx.i)
+		}
+		if *b > 2<<bitRes && ctx.remainingBits > 2<<bitRes {
+			v := 0
+			if inv {
+				v = 1
+			}
+			inv = ctx.bitLogp(v, 2) == 1
+		} else {
+			inv = false
+		}
+		itheta = 0
+	}
+	qalloc := ctx.tellFrac() - tell
+	*b -= qalloc
+
+	var imid, iside, delta int
+	switch itheta {
+	case 0:
+		imid, iside = 32767, 0
+		*fill &= 1<<uint(B) - 1
+		delta = -16384
+	case 16384:
+		imid, iside = 0, 32767
+		*fill &= (1<<uint(B) - 1) << uint(B)
+		delta = 16384
+	default:
+		imid = bitexactCos(itheta)
+		iside = bitexactCos(16384 - itheta)
+		delta = fracMul16((n-1)<<7, bitexactLog2tan(iside, imid))
+	}
+	*sctx = splitCtx{inv: inv, imid: imid, iside: iside, delta: delta, itheta: itheta, qalloc: qalloc}
+}
+
+func (ctx *bandCtx) quantBandN1(x, y []float32, lowbandOut []float32) uint {
+	for c, v := range [][]float32{x, y} {
+		if c == 1 && y == nil {
+			break
+		}
+		sign := 0
+		if ctx.remainingBits >= 1<<bitRes {
+			if ctx.encode && v[0] < 0 {
+				sign = 1
+			}
+			sign = ctx.bit(sign)
+			ctx.remainingBits -= 1 << bitRes
+		}
+		if ctx.resynth {
+			v[0] = 1
+			if sign != 0 {
+				v[0] = -1
+			}
+		}
+	}
+	if lowbandOut != nil {
+		lowbandOut[0] = x[0]
+	}
+	return 1
+}
+
+func (ctx *bandCtx) quantPartition(x []float32, n, b, B int, lowband []float32, lm int, gain float32, fill int) uint {
+	b0 := B
+	var cm uint
+	c := cache.bits[cache.index[(lm+1)*nbEBands+ctx.i]:]
+	if lm != -1 && b > int(c[c[0]])+12 && n > 2 {
+		n >>= 1
+		y := x[n : 2*n]
+		lm--
+		if B == 1 {
+			fill = fill&1 | fill<<1
+		}
+		B = (B + 1) >> 1
+
+		var sctx splitCtx
+		ctx.computeTheta(&sctx, x[:n], y, n, &b, B, b0, lm, false, &fill)
+		mid := float32(sctx.imid) / 32768
+		side := float32(sctx.iside) / 32768
+		delta, itheta := sctx.delta, sctx.itheta
+
+		// Give more bits to low-energy MDCTs than they would otherwise deserve.
+		if b0 > 1 && itheta&0x3fff != 0 {
+			if itheta > 8192 {
+				delta -= delta >> uint(4-lm)
+			} else if delta = delta + n<<bitRes>>uint(5-lm); delta > 0 {
+				delta = 0
+			}
+		}
+		mbits := (b - delta) / 2
+		if mbits > b {
+			mbits = b
+		}
+		if mbits < 0 {
+			mbits = 0
+		}
+		sbits := b - mbits
+		ctx.remainingBits -= sctx.qalloc
+
+		var nextLowband2 []float32
+		if lowband != nil {
+			nextLowband2 = lowband[n:]
+		}
+		rebalance := ctx.remainingBits
+		if mbits >= sbits {
+			cm = ctx.quantPartition(x, n, mbits, B, lowband, lm, gain*mid, fill)
+			rebalance = mbits - (rebalance - ctx.remainingBits)
+			if rebalance > 3<<bitRes && itheta != 0 {
+				sbits += rebalance - 3<<bitRes
+			}
+			cm |= ctx.quantPartition(y, n, sbits, B, nextLowband2, lm, gain*side, fill>>uint(B)) << uint(b0>>1)
+		} else {
+			cm = ctx.quantPartition(y, n, sbits, B, nextLowband2, lm, gain*side, fill>>uint(B)) << uint(b0>>1)
+			rebalance = sbits - (rebalance - ctx.remainingBits)
+			if rebalance > 3<<bitRes && itheta != 16384 {
+				mbits += rebalance - 3<<bitRes
+			}
+			cm |= ctx.quantPartition(x, n, mbits, B, lowband, lm, gain*mid, fill)
+		}
+		return cm
+	}
+
+	// The basic case: no split.
+	x = x[:n]
+	q := bits2pulses(ctx.i, lm, b)
+	currBits := pulses2bits(ctx.i, lm, q)
+	ctx.remainingBits -= currBits
+	// Never bust the budget.
+	for ctx.remainingBits < 0 && q > 0 {
+		ctx.remainingBits += currBits
+		q--
+		currBits = pulses2bits(ctx.i, lm, q)
+		ctx.remainingBits -= currBits
+	}
+	if q != 0 {
+		k := getPulses(q)
+		if ctx.encode {
+			return algQuant(x, k, ctx.spread, B, ctx.enc, gain, ctx.resynth)
+		}
+		return algUnquant(x, k, ctx.spread, B, ctx.dec, gain)
+	}
+	// Without pulses, fill the band anyway.
+	if ctx.resynth {
+		cmMask := uint(1)<<uint(B) - 1
+		fill &= int(cmMask)
+		if fill == 0 {
+			for j := range x {
+				x[j] = 0
+			}
+			return 0
+		}
+		if lowband == nil {
+			// Noise.
+			for j := range x {
+				ctx.seed = lcgRand(ctx.seed)
+				x[j] = float32(int32(ctx.seed) >> 20)
+			}
+			cm = cmMask
+		} else {
+			// Folded spectrum, with noise about 48 dB below it.
+			for j := range x {
+				ctx.seed = lcgRand(ctx.seed)
+				tmp := float32(1.0 / 256)
+				if ctx.seed&0x8000 == 0 {
+					tmp = -tmp
+				}
+				x[j] = lowband[j] + tmp
+			}
+			cm = uint(fill)
+		}
+		renormaliseVector(x, gain)
+	}
+	return cm
+}
+
+func haar1(x []float32, n0, stride int) {
+	n0 >>= 1
+	for i := 0; i < stride; i++ {
+		for j := 0; j < n0; j++ {
+			tmp1 := 0.70710678 * x[stride*2*j+i]
+			tmp2 := 0.70710678 * x[stride*(2*j+1)+i]
+			x[stride*2*j+i] = tmp1 + tmp2
+			x[stride*(2*j+1)+i] = tmp1 - tmp2
+		}
+	}
+}
+
+var orderyTable = [30]int{
+	1, 0,
+	3, 0, 2, 1,
+	7, 0, 4, 3, 6, 1, 5, 2,
+	15, 0, 8, 7, 12, 3, 11, 4, 14, 1, 9, 6, 13, 2, 10, 5,
+}
+
+func deinterleaveHadamard(x []float32, n0, stride int, hadamard bool) {
+	n := n0 * stride
+	tmp := make([]float32, n)
+	if hadamard {
+		ordery := orderyTable[stride-2:]
+		for i := 0; i < stride; i++ {
+			for j := 0; j < n0; j++ {
+				tmp[ordery[i]*n0+j] = x[j*stride+i]
+			}
+		}
+	} else {
+		for i := 0; i < stride; i++ {
+			for j := 0; j < n0; j++ {
+				tmp[i*n0+j] = x[j*stride+i]
+			}
+		}
+	}
+	copy(x, tmp)
+}
+
+func interleaveHadamard(x []float32, n0, stride int, hadamard bool) {
+	n := n0 * stride
+	tmp := make([]float32, n)
+	if hadamard {
+		ordery := orderyTable[stride-2:]
+		for i := 0; i < stride; i++ {
+			for j := 0; j < n0; j++ {
+				tmp[j*stride+i] = x[ordery[i]*n0+j]
+			}
+		}
+	} else {
+		for i := 0; i < stride; i++ {
+			for j := 0; j < n0; j++ {
+				tmp[j*stride+i] = x[i*n0+j]
+			}
+		}
+	}
+	copy(x, tmp)
+}
+
+var (
+	bitInterleaveTable   = [16]int{0, 1, 1, 1, 2, 3, 3, 3, 2, 3, 3, 3, 2, 3, 3, 3}
+	bitDeinterleaveTable = [16]uint{
+		0x00, 0x03, 0x0C, 0x0F, 0x30, 0x33, 0x3C, 0x3F,
+		0xC0, 0xC3, 0xCC, 0xCF, 0xF0, 0xF3, 0xFC, 0xFF,
+	}
+)
+
+// quantBand codes a mono band, or the mid or side of a stereo band, changing its
+// time-frequency resolution as tf_change asks.
+func (ctx *bandCtx) quantBand(x []float32, n, b, B int, lowband []float32, lm int, lowbandOut []float32, gain float32, lowbandScratch []float32, fill int) uint {
+	n0 := n
+	nB := n / B
+	b0 := B
+	timeDivide := 0
+	recombine := 0
+	longBlocks := b0 == 1
+	tfChange := ctx.tfChange
+	x = x[:n]
+
+	if n == 1 {
+		return ctx.quantBandN1(x, nil, lowbandOut)
+	}
+	if tfChange > 0 {
+		recombine = tfChange
+	}
+	if lowbandScratch != nil && lowband != nil && (recombine != 0 || (nB&1 == 0 && tfChange < 0) || b0 > 1) {
+		copy(lowbandScratch[:n], lowband[:n])
+		lowband = lowbandScratch
+	}
+	for k := 0; k < recombine; k++ {
+		if ctx.encode {
+			haar1(x, n>>uint(k), 1<<uint(k))
+		}
+		if lowband != nil {
+			haar1(lowband, n>>uint(k), 1<<uint(k))
+		}
+		fill = bitInterleaveTable[fill&0xF] | bitInterleaveTable[fill>>4]<<2
+	}
+	B >>= uint(recombine)
+	nB <<= uint(recombine)
+
+	// Increase the time resolution.
+	for nB&1 == 0 && tfChange < 0 {
+		if ctx.encode {
+			haar1(x, nB, B)
+		}
+		if lowband != nil {
+			haar1(lowband, nB, B)
+		}
+		fill |= fill << uint(B)
+		B <<= 1
+		nB >>= 1
+		timeDivide++
+		tfChange++
+	}
+	b0 = B
+	nB0 := nB
+
+	// Reorganize the samples in time order instead of frequency order.
+	if b0 > 1 {
+		if ctx.encode {
+			deinterleaveHadamard(x, nB>>uint(recombine), b0<<uint(recombine), longBlocks)
+		}
+		if lowband != nil {
+			deinterleaveHadamard(lowband, nB>>uint(recombine), b0<<uint(recombine), longBlocks)
+		}
+	}
+
+	cm := ctx.quantPartition(x, n, b, B, lowband, lm, gain, fill)
+
+	if ctx.resynth {
+		if b0 > 1 {
+			interleaveHadamard(x, nB>>uint(recombine), b0<<uint(recombine), longBlocks)
+		}
+		nB = nB0
+		B = b0
+		for k := 0; k < timeDivide; k++ {
+			B >>= 1
+			nB <<= 1
+			cm |= cm >> uint(B)
+			haar1(x, nB, B)
+		}
+		for k := 0; k < recombine; k++ {
+			cm = bitDeinterleaveTable[cm]
+			haar1(x, n0>>uint(k), 1<<uint(k))
+		}
+		B <<= uint(recombine)
+
+		// Scale the output for later folding.
+		if lowbandOut != nil {
+			s := float32(math.Sqrt(float64(n0)))
+			for j := 0; j < n0; j++ {
+				lowbandOut[j] = s * x[j]
+			}
+		}
+		cm &= 1<<uint(B) - 1
+	}
+	return cm
+}
+
+func (ctx *bandCtx) quantBandStereo(x, y []float32, n, b, B int, lowband []float32, lm int, lowbandOut, lowbandScratch []float32, fill int) uint {
+	x, y = x[:n], y[:n]
+	if n == 1 {
+		return ctx.quantBandN1(x, y, lowbandOut)
+	}
+	origFill := fill
+
+	var sctx splitCtx
+	ctx.computeTheta(&sctx, x, y, n, &b, B, B, lm, true, &fill)
+	mid := float32(sctx.imid) / 32768
+	side := float32(sctx.iside) / 32768
+	delta, itheta := sctx.delta, sctx.itheta
+	var cm uint
+
+	if n == 2 {
+		// The side of an N=2 band is orthogonal to the mid, so a sign codes it.
+		mbits := b
+		sbits := 0
+		if itheta != 0 && itheta != 16384 {
+			sbits = 1 << bitRes
+		}
+		mbits -= sbits
+		c := itheta > 8192
+		ctx.remainingBits -= sctx.qalloc + sbits
+		x2, y2 := x, y
+		if c {
+			x2, y2 = y, x
+		}
+		sign := 0
+		if sbits != 0 {
+			if ctx.encode && x2[0]*y2[1]-x2[1]*y2[0] < 0 {
+				sign = 1
+			}
+			sign = ctx.bit(sign)
+		}
+		s := float32(1 - 2*sign)
+		cm = ctx.quantBand(x2, n, mbits, B, lowband, lm, lowbandOut, 1, lowbandScratch, origFill)
+		y2[0] = -s * x2[1]
+		y2[1] = s * x2[0]
+		if ctx.resynth {
+			x[0] *= mid
+			x[1] *= mid
+			y[0] *= side
+			y[1] *= side
+			tmp := x[0]
+			x[0] = tmp - y[0]
+			y[0] = tmp + y[0]
+			tmp = x[1]
+			x[1] = tmp - y[1]
+			y[1] = tmp + y[1]
+		}
+	} else {
+		mbits := (b - delta) / 2
+		if mbits > b {
+			mbits = b
+		}
+		if mbits < 0 {
+			mbits = 0
+		}
+		sbits := b - mbits
+		ctx.remainingBits -= sctx.qalloc
+		rebalance := ctx.remainingBits
+		if mbits >= sbits {
+			cm = ctx.quantBand(x, n, mbits, B, lowband, lm, lowbandOut, 1, lowbandScratch, fill)
+			rebalance = mbits - (rebalance - ctx.remainingBits)
+			if rebalance > 3<<bitRes && itheta != 0 {
+				sbits += rebalance - 3<<bitRes
+			}
+			cm |= ctx.quantBand(y, n, sbits, B, nil, lm, nil, side, nil, fill>>uint(B))
+		} else {
+			cm = ctx.quantBand(y, n, sbits, B, nil, lm, nil, side, nil, fill>>uint(B))
+			rebalance = sbits - (rebalance - ctx.remainingBits)
+			if rebalance > 3<<bitRes && itheta != 16384 {
+				mbits += rebalance - 3<<bitRes
+			}
+			cm |= ctx.quantBand(x, n, mbits, B, lowband, lm, lowbandOut, 1, lowbandScratch, fill)
+		}
+	}
+	if ctx.resynth {
+		if n != 2 {
+			stereoMerge(x, y, mid)
+		}
+		if sctx.inv {
+			for j := range y {
+				y[j] = -y[j]
+			}
+		}
+	}
+	return cm
+}
+
+// quantAllBands codes the shapes of the bands start to end of x, and of y in stereo.
+// It returns the collapse masks of the bands.
+func quantAllBands(ctx *bandCtx, start, end int, x, y []float32, pulses []int, shortBlocks bool, spread int, dualStereo bool, intensity int, tfRes []int, totalBits, balance, lm, codedBands int) []uint8 {
+	m := 1 << uint(lm)
+	B := 1
+	if shortBlocks {
+		B = m
+	}
+	channels := 1
+	if y != nil {
+		channels = 2
+	}
+	collapseMasks := make([]uint8, channels*nbEBands)
+	normOffset := m * eBands[start]
+	normLen := m*eBands[nbEBands-1] - normOffset
+	norm := make([]float32, 2*normLen)
+	norm2 := norm[normLen:]
+	lowbandScratch := make([]float32, m*(eBands[nbEBands]-eBands[nbEBands-1]))
+
+	ctx.intensity = intensity
+	ctx.spread = spread
+	ctx.avoidSplitNoise = B > 1
+	lowbandOffset := 0
+	updateLowband := true
+	for i := start; i < end; i++ {
+		ctx.i = i
+		last := i == end-1
+		xi := x[m*eBands[i]:]
+		var yi []float32
+		if y != nil {
+			yi = y[m*eBands[i]:]
+		}
+		n := m*eBands[i+1] - m*eBands[i]
+		tell := ctx.tellFrac()
+
+		// The bits wanted for this band.
+		if i != start {
+			balance -= tell
+		}
+		remainingBits := totalBits - tell - 1
+		ctx.remainingBits = remainingBits
+		b := 0
+		if i <= codedBands-1 {
+			d := codedBands - i
+			if d > 3 {
+				d = 3
+			}
+			currBalance := sdiv(balance, d)
+			b = pulses[i] + currBalance
+			if remainingBits+1 < b {
+				b = remainingBits + 1
+			}
+			if b > 16383 {
+				b = 16383
+			}
+			if b < 0 {
+				b = 0
+			}
+		}
+
+		if ctx.resynth && (m*eBands[i]-n >= m*eBands[start] || i == start+1) && (updateLowband || lowbandOffset == 0) {
+			lowbandOffset = i
+		}
+		if i == start+1 {
+			// Duplicate enough of the first band to fold the second, in hybrid mode.
+			n1 := m * (eBands[start+1] - eBands[start])
+			n2 := m * (eBands[start+2] - eBands[start+1])
+			if n2 > n1 {
+				copy(norm[n1:n2], norm[2*n1-n2:n1])
+				if dualStereo {
+					copy(norm2[n1:n2], norm2[2*n1-n2:n1])
+				}
+			}
+		}
+
+		ctx.tfChange = tfRes[i]
+		scratch := lowbandScratch
+		if last {
+			scratch = nil
+		}
+
+		// A conservative estimate of the collapse masks of the bands folded from.
+		effectiveLowband := -1
+		var xcm, ycm uint
+		if lowbandOffset != 0 && (spread != spreadAggressive || B > 1 || ctx.tfChange < 0) {
+			effectiveLowband = m*eBands[lowbandOffset] - normOffset - n
+			if effectiveLowband < 0 {
+				effectiveLowband = 0
+			}
+			foldStart := lowbandOffset
+			for {
+				foldStart--
+				if m*eBands[foldStart] <= effectiveLowband+normOffset {
+					break
+				}
+			}
+			foldEnd := lowbandOffset - 1
+			for {
+				foldEnd++
+				if !(foldEnd < i && m*eBands[foldEnd] < effectiveLowband+normOffset+n) {
+					break
+				}
+			}
+			for f := foldStart; f < foldEnd; f++ {
+				xcm |= uint(collapseMasks[f*channels])
+				ycm |= uint(collapseMasks[f*channels+channels-1])
+			}
+			if foldStart >= foldEnd {
+				xcm |= uint(collapseMasks[foldStart*channels])
+				ycm |= uint(collapseMasks[foldStart*channels+channels-1])
+			}
+		} else {
+			xcm = 1<<uint(B) - 1
+			ycm = xcm
+		}
+
+		if dualStereo && i == intensity {
+			// Switch off dual stereo to do intensity.
+			dualStereo = false
+			if ctx.resynth {
+				for j := 0; j < m*eBands[i]-normOffset; j++ {
+					norm[j] = 0.5 * (norm[j] + norm2[j])
+				}
+			}
+		}
+		var lb, lb2 []float32
+		if effectiveLowband != -1 {
+			lb = norm[effectiveLowband:]
+			lb2 = norm2[effectiveLowband:]
+		}
+		var out, out2 []float32
+		if !last {
+			out = norm[m*eBands[i]-normOffset:]
+			out2 = norm2[m*eBands[i]-normOffset:]
+		}
+		if dualStereo {
+			xcm = ctx.quantBand(xi, n, b/2, B, lb, lm, out, 1, scratch, int(xcm))
+			ycm = ctx.quantBand(yi, n, b/2, B, lb2, lm, out2, 1, scratch, int(ycm))
+		} else {
+			if yi != nil {
+				xcm = ctx.quantBandStereo(xi, yi, n, b, B, lb, lm, out, scratch, int(xcm|ycm))
+			} else {
+				xcm = ctx.quantBand(xi, n, b, B, lb, lm, out, 1, scratch, int(xcm|ycm))
+			}
+			ycm = xcm
+		}
+		collapseMasks[i*channels] = uint8(xcm)
+		collapseMasks[i*channels+channels-1] = uint8(ycm)
+		balance += pulses[i] + tell
+
+		// Update the folding position only as long as we have 1 bit/sample depth.
+		updateLowband = b > n<<bitRes
+		ctx.avoidSplitNoise = false
+	}
+	return collapseMasks
+}
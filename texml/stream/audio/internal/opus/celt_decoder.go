@@ -0,0 +1,481 @@
+package opus
+
+import (
+	"errors"
+	"math"
+)
+
+var errCorrupt = errors.New("opus: corrupt packet")
+
+// celtDecoder decodes CELT frames to mono, downmixing stereo streams as libopus does
+// when asked for one channel.
+type celtDecoder struct {
+	downsample int
+	start, end int
+	rng        uint32
+	lossCount  int
+
+	postfilterPeriod, postfilterPeriodOld    int
+	postfilterGain, postfilterGainOld        float32
+	postfilterTapset, postfilterTapsetOld    int
+	preemphMem                               float32
+	decodeMem                                []float32
+	oldBandE, oldLogE, oldLogE2, backgroundE [2 * nbEBands]float32
+}
+
+func newCELTDecoder(downsample int) *celtDecoder {
+	d := &celtDecoder{downsample: downsample}
+	d.reset()
+	return d
+}
+
+func (d *celtDecoder) reset() {
+	*d = celtDecoder{downsample: d.downsample, end: nbEBands, decodeMem: make([]float32, decodeBufferSize+overlap)}
+	for i := range d.oldLogE {
+		d.oldLogE[i] = -28
+		d.oldLogE2[i] = -28
+	}
+}
+
+func tfDecode(start, end int, isTransient bool, tfRes []int, lm int, dec *rangeDecoder) {
+	budget := dec.storage * 8
+	tell := dec.tell()
+	logp := 4
+	if isTransient {
+		logp = 2
+	}
+	tfSelectRsv := 0
+	if lm > 0 && tell+logp+1 <= budget {
+		tfSelectRsv = 1
+	}
+	budget -= tfSelectRsv
+	curr, tfChanged := 0, 0
+	for i := start; i < end; i++ {
+		if tell+logp <= budget {
+			if dec.bitLogp(uint(logp)) {
+				curr ^= 1
+			}
+			tell = dec.tell()
+			tfChanged |= curr
+		}
+		tfRes[i] = curr
+		logp = 5
+		if isTransient {
+			logp = 4
+		}
+	}
+	tfSelect := 0
+	t := 4 * b2i(isTransient)
+	if tfSelectRsv != 0 && tfSelectTable[lm][t+tfChanged] != tfSelectTable[lm][t+2+tfChanged] {
+		if dec.bitLogp(1) {
+			tfSelect = 1
+		}
+	}
+	for i := start; i < end; i++ {
+		tfRes[i] = tfSelectTable[lm][t+2*tfSelect+tfRes[i]]
+	}
+}
+
+// combFilter applies the pitch pre- or post-filter to x[start:start+n] in place,
+// moving from the old parameters to the new ones over the overlap. x holds at least
+// 1024+2 samples of history before start.
+func combFilter(x []float32, start, n, t0, t1 int, g0, g1 float32, tapset0, tapset1 int, inverse bool) {
+	if g0 == 0 && g1 == 0 {
+		return
+	}
+	t0 = imax(t0, combMinPeriod)
+	t1 = imax(t1, combMinPeriod)
+	sign := float32(1)
+	if inverse {
+		sign = -1
+	}
+	g00, g01, g02 := sign*g0*combGains[tapset0][0], sign*g0*combGains[tapset0][1], sign*g0*combGains[tapset0][2]
+	g10, g11, g12 := sign*g1*combGains[tapset1][0], sign*g1*combGains[tapset1][1], sign*g1*combGains[tapset1][2]
+	ov := overlap
+	if g0 == g1 && t0 == t1 && tapset0 == tapset1 {
+		ov = 0
+	}
+	// The decoder filters in place, so the delayed samples are already filtered; the
+	// encoder reads them from a copy of the input.
+	src := x
+	if inverse {
+		src = append([]float32(nil), x[:start+n]...)
+	}
+	i := 0
+	for ; i < ov && i < n; i++ {
+		p := start + i
+		f := window[i] * window[i]
+		x[p] = src[p] +
+			(1-f)*g00*src[p-t0] +
+			(1-f)*g01*(src[p-t0+1]+src[p-t0-1]) +
+			(1-f)*g02*(src[p-t0+2]+src[p-t0-2]) +
+			f*g10*src[p-t1] +
+			f*g11*(src[p-t1+1]+src[p-t1-1]) +
+			f*g12*(src[p-t1+2]+src[p-t1-2])
+	}
+	if g1 == 0 {
+		return
+	}
+	for ; i < n; i++ {
+		p := start + i
+		x[p] = src[p] + g10*src[p-t1] + g11*(src[p-t1+1]+src[p-t1-1]) + g12*(src[p-t1+2]+src[p-t1-2])
+	}
+}
+
+// denormaliseBands scales the unit-norm bands of x by their energies into freq.
+func denormaliseBands(x, freq []float32, bandLogE []float32, start, end, m, downsample int, silence bool) {
+	n := m * shortMdct
+	bound := m * eBands[end]
+	if downsample != 1 {
+		bound = imin(bound, n/downsample)
+	}
+	if silence {
+		bound, start, end = 0, 0, 0
+	}
+	for i := range freq[:m*eBands[start]] {
+		freq[i] = 0
+	}
+	for i := start; i < end; i++ {
+		lg := float64(bandLogE[i] + eMeans[i])
+		g := float32(math.Exp2(math.Min(32, lg)))
+		for j := m * eBands[i]; j < m*eBands[i+1]; j++ {
+			freq[j] = x[j] * g
+		}
+	}
+	for i := bound; i < n; i++ {
+		freq[i] = 0
+	}
+}
+
+// synthesis runs the inverse MDCTs of the bands into out, which holds the tail of the
+// previous frame in its first overlap/2 samples.
+func (d *celtDecoder) synthesis(x []float32, out []float32, start, end, channels int, isTransient bool, lm int, silence bool) {
+	m := 1 << uint(lm)
+	n := m * shortMdct
+	freq := make([]float32, n)
+	b, nb, shift := 1, n, lm
+	if isTransient {
+		b, nb, shift = m, shortMdct, 0
+	}
+	denormaliseBands(x, freq, d.oldBandE[:], start, end, m, d.downsample, silence)
+	if channels == 2 {
+		freq2 := make([]float32, n)
+		denormaliseBands(x[n:], freq2, d.oldBandE[nbEBands:], start, end, m, d.downsample, silence)
+		for i := range freq {
+			freq[i] = 0.5*freq[i] + 0.5*freq2[i]
+		}
+	}
+	for i := 0; i < b; i++ {
+		mdctLookup.backward(freq[i:], out[nb*i:], shift, b)
+	}
+	for i := range out[:n] {
+		if out[i] > 536870911 {
+			out[i] = 536870911
+		} else if out[i] < -536870911 {
+			out[i] = -536870911
+		}
+	}
+}
+
+// decodeLost conceals a lost frame with noise at the last band energies.
+func (d *celtDecoder) decodeLost(n, lm int) {
+	m := 1 << uint(lm)
+	decay := float32(1.5)
+	if d.lossCount > 0 {
+		decay = 0.5
+	}
+	for i := d.start; i < d.end; i++ {
+		e := d.oldBandE[i] - decay
+		if e < d.backgroundE[i] {
+			e = d.backgroundE[i]
+		}
+		d.oldBandE[i] = e
+	}
+	x := make([]float32, n)
+	seed := d.rng
+	for i := d.start; i < d.end; i++ {
+		band := x[m*eBands[i] : m*eBands[i+1]]
+		for j := range band {
+			seed = lcgRand(seed)
+			band[j] = float32(int32(seed) >> 20)
+		}
+		renormaliseVector(band, 1)
+	}
+	d.rng = seed
+	copy(d.decodeMem, d.decodeMem[n:decodeBufferSize+overlap/2])
+	outSyn := decodeBufferSize - n
+	d.synthesis(x, d.decodeMem[outSyn:], d.start, d.end, 1, false, lm, false)
+	combFilter(d.decodeMem, outSyn, n, d.postfilterPeriodOld, d.postfilterPeriod, d.postfilterGainOld, d.postfilterGain, d.postfilterTapsetOld, d.postfilterTapset, false)
+	d.postfilterPeriodOld, d.postfilterGainOld, d.postfilterTapsetOld = d.postfilterPeriod, d.postfilterGain, d.postfilterTapset
+	d.lossCount++
+}
+
+// decode decodes a frame of frameSize samples at 48 kHz from the range decoder dec,
+// whose buffer holds the len bytes of the frame, and returns it at the output rate.
+// A nil dec conceals a lost frame.
+func (d *celtDecoder) decode(dec *rangeDecoder, length, frameSize, channels int) ([]float32, error) {
+	lm := 0
+	for ; lm <= maxLM; lm++ {
+		if shortMdct<<uint(lm) == frameSize {
+			break
+		}
+	}
+	if lm > maxLM {
+		return nil, errCorrupt
+	}
+	m := 1 << uint(lm)
+	n := m * shortMdct
+	outSyn := decodeBufferSize - n
+	start, end := d.start, d.end
+
+	if dec == nil || length <= 1 {
+		d.decodeLost(n, lm)
+		return d.deemphasis(d.decodeMem[outSyn : outSyn+n]), nil
+	}
+
+	if channels == 1 {
+		for i := 0; i < nbEBands; i++ {
+			d.oldBandE[i] = max32(d.oldBandE[i], d.oldBandE[nbEBands+i])
+		}
+	}
+	totalBits := length * 8
+	tell := dec.tell()
+	silence := false
+	if tell >= totalBits {
+		silence = true
+	} else if tell == 1 {
+		silence = dec.bitLogp(15)
+	}
+	if silence {
+		// Pretend all the remaining bits were read.
+		tell = length * 8
+		dec.nbitsTot += tell - dec.tell()
+	}
+
+	postfilterGain := float32(0)
+	postfilterPitch, postfilterTapset := 0, 0
+	if start == 0 && tell+16 <= totalBits {
+		if dec.bitLogp(1) {
+			octave := int(dec.uint(6))
+			postfilterPitch = (16 << uint(octave)) + int(dec.bits(uint(4+octave))) - 1
+			qg := int(dec.bits(3))
+			if dec.tell()+2 <= totalBits {
+				postfilterTapset = dec.icdf(tapsetICDF, 2)
+			}
+			postfilterGain = 0.09375 * float32(qg+1)
+		}
+		tell = dec.tell()
+	}
+
+	isTransient := false
+	if lm > 0 && tell+3 <= totalBits {
+		isTransient = dec.bitLogp(3)
+		tell = dec.tell()
+	}
+	intra := false
+	if tell+3 <= totalBits {
+		intra = dec.bitLogp(3)
+	}
+	unquantCoarseEnergy(start, end, d.oldBandE[:], intra, dec, channels, lm)
+
+	tfRes := make([]int, nbEBands)
+	tfDecode(start, end, isTransient, tfRes, lm, dec)
+
+	tell = dec.tell()
+	spread := spreadNormal
+	if tell+4 <= totalBits {
+		spread = dec.icdf(spreadICDF, 5)
+	}
+
+	caps := initCaps(lm, channels)
+	var offsets [nbEBands]int
+	dynallocLogp := 6
+	totalBits <<= bitRes
+	tell = dec.tellFrac()
+	for i := start; i < end; i++ {
+		width := channels * (eBands[i+1] - eBands[i]) << uint(lm)
+		// quanta is 6 bits, but no more than 1 bit/sample and no less than 1/8.
+		quanta := imin(width<<bitRes, imax(6<<bitRes, width))
+		loopLogp := dynallocLogp
+		boost := 0
+		for tell+loopLogp<<bitRes < totalBits && boost < caps[i] {
+			flag := dec.bitLogp(uint(loopLogp))
+			tell = dec.tellFrac()
+			if !flag {
+				break
+			}
+			boost += quanta
+			totalBits -= quanta
+			loopLogp = 1
+		}
+		offsets[i] = boost
+		if boost > 0 {
+			dynallocLogp = imax(2, dynallocLogp-1)
+		}
+	}
+
+	allocTrim := 5
+	if tell+6<<bitRes <= totalBits {
+		allocTrim = dec.icdf(trimICDF, 7)
+	}
+	bits := length*8<<bitRes - dec.tellFrac() - 1
+	antiCollapseRsv := 0
+	if isTransient && lm >= 2 && bits >= (lm+2)<<bitRes {
+		antiCollapseRsv = 1 << bitRes
+	}
+	bits -= antiCollapseRsv
+
+	var a allocation
+	computeAllocation(&a, start, end, &offsets, &caps, allocTrim, bits, channels, lm, coder{dec: dec}, 0, 0)
+	unquantFineEnergy(start, end, d.oldBandE[:], &a.fineQuant, dec, channels)
+
+	copy(d.decodeMem, d.decodeMem[n:decodeBufferSize+overlap/2])
+
+	x := make([]float32, channels*n)
+	var y []float32
+	if channels == 2 {
+		y = x[n:]
+	}
+	ctx := &bandCtx{coder: coder{dec: dec}, resynth: true, seed: d.rng}
+	collapseMasks := quantAllBands(ctx, start, end, x[:n], y, a.pulses[:], isTransient, spread, a.dualStereo, a.intensity, tfRes, length*(8<<bitRes)-antiCollapseRsv, a.balance, lm, a.codedBands)
+	d.rng = ctx.seed
+
+	antiCollapseOn := false
+	if antiCollapseRsv > 0 {
+		antiCollapseOn = dec.bits(1) == 1
+	}
+	unquantEnergyFinalise(start, end, d.oldBandE[:], &a, length*8-dec.tell(), dec, channels)
+	if antiCollapseOn {
+		antiCollapse(x, collapseMasks, lm, channels, n, start, end, d.oldBandE[:], d.oldLogE[:], d.oldLogE2[:], a.pulses[:], d.rng)
+	}
+	if silence {
+		for i := range d.oldBandE {
+			d.oldBandE[i] = -28
+		}
+	}
+
+	d.synthesis(x, d.decodeMem[outSyn:], start, end, channels, isTransient, lm, silence)
+
+	d.postfilterPeriod = imax(d.postfilterPeriod, combMinPeriod)
+	d.postfilterPeriodOld = imax(d.postfilterPeriodOld, combMinPeriod)
+	combFilter(d.decodeMem, outSyn, shortMdct, d.postfilterPeriodOld, d.postfilterPeriod, d.postfilterGainOld, d.postfilterGain, d.postfilterTapsetOld, d.postfilterTapset, false)
+	if lm != 0 {
+		combFilter(d.decodeMem, outSyn+shortMdct, n-shortMdct, d.postfilterPeriod, postfilterPitch, d.postfilterGain, postfilterGain, d.postfilterTapset, postfilterTapset, false)
+	}
+	d.postfilterPeriodOld, d.postfilterGainOld, d.postfilterTapsetOld = d.postfilterPeriod, d.postfilterGain, d.postfilterTapset
+	d.postfilterPeriod, d.postfilterGain, d.postfilterTapset = postfilterPitch, postfilterGain, postfilterTapset
+	if lm != 0 {
+		d.postfilterPeriodOld, d.postfilterGainOld, d.postfilterTapsetOld = d.postfilterPeriod, d.postfilterGain, d.postfilterTapset
+	}
+
+	if channels == 1 {
+		copy(d.oldBandE[nbEBands:], d.oldBandE[:nbEBands])
+	}
+	if !isTransient {
+		d.oldLogE2 = d.oldLogE
+		d.oldLogE = d.oldBandE
+		maxIncrease := float32(m) * 0.001
+		if d.lossCount >= 10 {
+			maxIncrease = 1
+		}
+		for i := range d.backgroundE {
+			d.backgroundE[i] = min32(d.backgroundE[i]+maxIncrease, d.oldBandE[i])
+		}
+	} else {
+		for i := range d.oldLogE {
+			d.oldLogE[i] = min32(d.oldLogE[i], d.oldBandE[i])
+		}
+	}
+	for c := 0; c < 2; c++ {
+		for i := 0; i < nbEBands; i++ {
+			if i < start || i >= end {
+				d.oldBandE[c*nbEBands+i] = 0
+				d.oldLogE[c*nbEBands+i] = -28
+				d.oldLogE2[c*nbEBands+i] = -28
+			}
+		}
+	}
+	d.rng = dec.rng
+	d.lossCount = 0
+	pcm := d.deemphasis(d.decodeMem[outSyn : outSyn+n])
+	if dec.tell() > 8*length {
+		return pcm, errCorrupt
+	}
+	return pcm, nil
+}
+
+// deemphasis undoes the pre-emphasis of the encoder and downsamples to the output
+// rate.
+func (d *celtDecoder) deemphasis(x []float32) []float32 {
+	out := make([]float32, len(x)/d.downsample)
+	m := d.preemphMem
+	for j, v := range x {
+		tmp := v + 1e-30 + m
+		m = preemph * tmp
+		if j%d.downsample == 0 {
+			out[j/d.downsample] = tmp
+		}
+	}
+	d.preemphMem = m
+	return out
+}
+
+func antiCollapse(x []float32, collapseMasks []uint8, lm, channels, size, start, end int, logE, prev1LogE, prev2LogE []float32, pulses []int, seed uint32) {
+	for i := start; i < end; i++ {
+		n0 := eBands[i+1] - eBands[i]
+		// The depth in 1/8 bits.
+		depth := (1 + pulses[i]) / n0 >> uint(lm)
+		thresh := 0.5 * math.Exp2(-0.125*float64(depth))
+		sqrt1 := 1 / math.Sqrt(float64(n0<<uint(lm)))
+		for c := 0; c < channels; c++ {
+			prev1 := prev1LogE[c*nbEBands+i]
+			prev2 := prev2LogE[c*nbEBands+i]
+			if channels == 1 {
+				prev1 = max32(prev1, prev1LogE[nbEBands+i])
+				prev2 = max32(prev2, prev2LogE[nbEBands+i])
+			}
+			ediff := float64(logE[c*nbEBands+i] - min32(prev1, prev2))
+			if ediff < 0 {
+				ediff = 0
+			}
+			// Short blocks don't have the same energy as long ones.
+			r := 2 * math.Exp2(-ediff)
+			if lm == 3 {
+				r *= 1.41421356
+			}
+			r = math.Min(thresh, r) * sqrt1
+			band := x[c*size+eBands[i]<<uint(lm) : c*size+eBands[i+1]<<uint(lm)]
+			renormalize := false
+			for k := 0; k < 1<<uint(lm); k++ {
+				if collapseMasks[i*channels+c]&(1<<uint(k)) == 0 {
+					for j := 0; j < n0; j++ {
+						seed = lcgRand(seed)
+						if seed&0x8000 != 0 {
+							band[j<<uint(lm)+k] = float32(r)
+						} else {
+							band[j<<uint(lm)+k] = float32(-r)
+						}
+					}
+					renormalize = true
+				}
+			}
+			if renormalize {
+				renormaliseVector(band, 1)
+			}
+		}
+	}
+}
+
+func max32(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,165 @@
+package opus
+
+import "math"
+
+// celtEncoder encodes mono CELT frames at a constant bitrate. It makes the simple
+// choices of a low complexity libopus encoder: long blocks, no pitch pre-filter, no
+// dynamic allocation and the default tilt and spreading.
+type celtEncoder struct {
+	end            int
+	rng            uint32
+	preemphMem     float32
+	inMem          [overlap]float32
+	oldBandE       [nbEBands]float32
+	intra          bool
+	lastCodedBands int
+}
+
+func newCELTEncoder(end int) *celtEncoder {
+	return &celtEncoder{end: end, intra: true}
+}
+
+func tfEncode(start, end int, isTransient bool, tfRes []int, lm, tfSelect int, enc *rangeEncoder) {
+	budget := enc.storage * 8
+	tell := enc.tell()
+	logp := 4
+	if isTransient {
+		logp = 2
+	}
+	tfSelectRsv := lm > 0 && tell+logp+1 <= budget
+	if tfSelectRsv {
+		budget--
+	}
+	curr, tfChanged := 0, 0
+	for i := start; i < end; i++ {
+		if tell+logp <= budget {
+			enc.bitLogp(tfRes[i]^curr != 0, uint(logp))
+			tell = enc.tell()
+			curr = tfRes[i]
+			tfChanged |= curr
+		} else {
+			tfRes[i] = curr
+		}
+		logp = 5
+		if isTransient {
+			logp = 4
+		}
+	}
+	t := 4 * b2i(isTransient)
+	if tfSelectRsv && tfSelectTable[lm][t+tfChanged] != tfSelectTable[lm][t+2+tfChanged] {
+		enc.bitLogp(tfSelect != 0, 1)
+	} else {
+		tfSelect = 0
+	}
+	for i := start; i < end; i++ {
+		tfRes[i] = tfSelectTable[lm][t+2*tfSelect+tfRes[i]]
+	}
+}
+
+// encode codes the frameSize samples of pcm, at 48 kHz, into a frame of nbBytes.
+func (e *celtEncoder) encode(pcm []float32, nbBytes int) []byte {
+	n := len(pcm)
+	lm := 0
+	for shortMdct<<uint(lm) != n {
+		lm++
+	}
+	m := 1 << uint(lm)
+	start, end := 0, e.end
+
+	buf := make([]byte, nbBytes)
+	enc := &rangeEncoder{}
+	enc.init(buf)
+	totalBits := nbBytes * 8
+
+	in := make([]float32, n+overlap)
+	copy(in, e.inMem[:])
+	mem := e.preemphMem
+	for i, v := range pcm {
+		in[overlap+i] = v - mem
+		mem = preemph * v
+	}
+	e.preemphMem = mem
+	copy(e.inMem[:], in[n:])
+
+	// Never silent: a zero frame codes as very low energies.
+	enc.bitLogp(false, 15)
+	if enc.tell()+16 <= totalBits {
+		// No pitch post-filter.
+		enc.bitLogp(false, 1)
+	}
+	if lm > 0 && enc.tell()+3 <= totalBits {
+		// Long blocks only.
+		enc.bitLogp(false, 3)
+	}
+
+	freq := make([]float32, n)
+	mdctLookup.forward(in, freq, lm, 1)
+
+	var bandE, bandLogE [nbEBands]float32
+	x := make([]float32, n)
+	for i := 0; i < nbEBands; i++ {
+		band := freq[m*eBands[i] : m*eBands[i+1]]
+		sum := float32(1e-27)
+		for _, v := range band {
+			sum += v * v
+		}
+		bandE[i] = float32(math.Sqrt(float64(sum)))
+		g := 1 / (1e-27 + bandE[i])
+		for j, v := range band {
+			x[m*eBands[i]+j] = v * g
+		}
+		if i < end {
+			bandLogE[i] = float32(math.Log2(float64(bandE[i]))) - eMeans[i]
+		} else {
+			bandLogE[i] = -14
+		}
+	}
+
+	var errs [nbEBands]float32
+	quantCoarseEnergy(start, end, bandLogE[:], e.oldBandE[:], e.intra, enc, 1, lm, nbBytes, errs[:])
+	e.intra = false
+
+	tfRes := make([]int, nbEBands)
+	tfEncode(start, end, false, tfRes, lm, 0, enc)
+
+	if enc.tell()+4 <= totalBits {
+		enc.icdf(spreadNormal, spreadICDF, 5)
+	}
+
+	caps := initCaps(lm, 1)
+	var offsets [nbEBands]int
+	totalBits <<= bitRes
+	tell := enc.tellFrac()
+	dynallocLogp := 6
+	for i := start; i < end; i++ {
+		if tell+dynallocLogp<<bitRes < totalBits && caps[i] > 0 {
+			enc.bitLogp(false, uint(dynallocLogp))
+			tell = enc.tellFrac()
+		}
+	}
+	allocTrim := 5
+	if tell+6<<bitRes <= totalBits {
+		enc.icdf(allocTrim, trimICDF, 7)
+	}
+
+	bits := nbBytes*8<<bitRes - enc.tellFrac() - 1
+	var a allocation
+	computeAllocation(&a, start, end, &offsets, &caps, allocTrim, bits, 1, lm, coder{enc: enc}, e.lastCodedBands, end-1)
+	if e.lastCodedBands != 0 {
+		e.lastCodedBands = imin(e.lastCodedBands+1, imax(e.lastCodedBands-1, a.codedBands))
+	} else {
+		e.lastCodedBands = a.codedBands
+	}
+	quantFineEnergy(start, end, e.oldBandE[:], errs[:], &a.fineQuant, enc, 1)
+
+	ctx := &bandCtx{coder: coder{enc: enc}, encode: true, bandE: bandE[:], seed: e.rng}
+	quantAllBands(ctx, start, end, x, nil, a.pulses[:], false, spreadNormal, a.dualStereo, a.intensity, tfRes, nbBytes*(8<<bitRes), a.balance, lm, a.codedBands)
+
+	quantEnergyFinalise(start, end, e.oldBandE[:], errs[:], &a, nbBytes*8-enc.tell(), enc, 1)
+	for i := end; i < nbEBands; i++ {
+		e.oldBandE[i] = 0
+	}
+	enc.done()
+	e.rng = enc.rng
+	return buf
+}
@@ -0,0 +1,115 @@
+package opus
+
+// Tables of the 48 kHz CELT mode, from RFC 6716 and celt/static_modes_float.h.
+
+const (
+	nbEBands     = 21
+	effEBands    = 21
+	shortMdct    = 120
+	overlap      = 120
+	maxLM        = 3
+	bitRes       = 3
+	maxFineBits  = 8
+	fineOffset   = 21
+	qthetaOffset = 4
+	// qthetaOffsetTwoPhase is the offset for stereo N=2 bands.
+	qthetaOffsetTwoPhase = 16
+	maxPseudo            = 40
+	logMaxPseudo         = 6
+	allocSteps           = 6
+	combMinPeriod        = 15
+	decodeBufferSize     = 2048
+	preemph              = 0.8500061035
+)
+
+const (
+	spreadNone = iota
+	spreadLight
+	spreadNormal
+	spreadAggressive
+)
+
+var eBands = [nbEBands + 1]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 10, 12, 14, 16, 20, 24, 28, 34, 40, 48, 60, 78, 100}
+
+// logN is log2 of the band widths in 1/8 bits.
+var logN = [nbEBands]int{0, 0, 0, 0, 0, 0, 0, 0, 8, 8, 8, 8, 16, 16, 16, 21, 21, 24, 29, 34, 36}
+
+const nbAllocVectors = 11
+
+var bandAllocation = [nbAllocVectors * nbEBands]uint8{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	90, 80, 75, 69, 63, 56, 49, 40, 34, 29, 20, 18, 10, 0, 0, 0, 0, 0, 0, 0, 0,
+	110, 100, 90, 84, 78, 71, 65, 58, 51, 45, 39, 32, 26, 20, 12, 0, 0, 0, 0, 0, 0,
+	118, 110, 103, 93, 86, 80, 75, 70, 65, 59, 53, 47, 40, 31, 23, 15, 4, 0, 0, 0, 0,
+	126, 119, 112, 104, 95, 89, 83, 78, 72, 66, 60, 54, 47, 39, 32, 25, 17, 12, 1, 0, 0,
+	134, 127, 120, 114, 103, 97, 91, 85, 78, 72, 66, 60, 54, 47, 41, 35, 29, 23, 16, 10, 1,
+	144, 137, 130, 124, 113, 107, 101, 95, 88, 82, 76, 70, 64, 57, 51, 45, 39, 33, 26, 15, 1,
+	152, 145, 138, 132, 123, 117, 111, 105, 98, 92, 86, 80, 74, 67, 61, 55, 49, 43, 36, 20, 1,
+	162, 155, 148, 142, 133, 127, 121, 115, 108, 102, 96, 90, 84, 77, 71, 65, 59, 53, 46, 30, 1,
+	172, 165, 158, 152, 143, 137, 131, 125, 118, 112, 106, 100, 94, 87, 81, 75, 69, 63, 56, 45, 20,
+	200, 200, 200, 200, 200, 200, 200, 200, 198, 193, 188, 183, 178, 173, 168, 163, 158, 153, 148, 129, 104,
+}
+
+// eMeans are the mean band energies, in log2 units, removed before quantization.
+var eMeans = [25]float32{
+	6.437500, 6.250000, 5.750000, 5.312500, 5.062500,
+	4.812500, 4.500000, 4.375000, 4.875000, 4.687500,
+	4.562500, 4.437500, 4.875000, 4.625000, 4.312500,
+	4.500000, 4.375000, 4.625000, 4.750000, 4.437500,
+	3.750000, 3.750000, 3.750000, 3.750000, 3.750000,
+}
+
+// eProbModel holds the Laplace parameters of the coarse energy, by LM, inter/intra
+// and band.
+var eProbModel = [4][2][42]uint8{
+	{
+		{72, 127, 65, 129, 66, 128, 65, 128, 64, 128, 62, 128, 64, 128, 64, 128, 92, 78, 92, 79, 92, 78, 90, 79, 116, 41, 115, 40, 114, 40, 132, 26, 132, 26, 145, 17, 161, 12, 176, 10, 177, 11},
+		{24, 179, 48, 138, 54, 135, 54, 132, 53, 134, 56, 133, 55, 132, 55, 132, 61, 114, 70, 96, 74, 88, 75, 88, 87, 74, 89, 66, 91, 67, 100, 59, 108, 50, 120, 40, 122, 37, 97, 43, 78, 50},
+	},
+	{
+		{83, 78, 84, 81, 88, 75, 86, 74, 87, 71, 90, 73, 93, 74, 93, 74, 109, 40, 114, 36, 117, 34, 117, 34, 143, 17, 145, 18, 146, 19, 162, 12, 165, 10, 178, 7, 189, 6, 190, 8, 177, 9},
+		{23, 178, 54, 115, 63, 102, 66, 98, 69, 99, 74, 89, 71, 91, 73, 91, 78, 89, 86, 80, 92, 66, 93, 64, 102, 59, 103, 60, 104, 60, 117, 52, 123, 44, 138, 35, 133, 31, 97, 38, 77, 45},
+	},
+	{
+		{61, 90, 93, 60, 105, 42, 107, 41, 110, 45, 116, 38, 113, 38, 112, 38, 124, 26, 132, 27, 136, 19, 140, 20, 155, 14, 159, 16, 158, 18, 170, 13, 177, 10, 187, 8, 192, 6, 175, 9, 159, 10},
+		{21, 178, 59, 110, 71, 86, 75, 85, 84, 83, 91, 66, 88, 73, 87, 72, 92, 75, 98, 72, 105, 58, 107, 54, 115, 52, 114, 55, 112, 56, 129, 51, 132, 40, 150, 33, 140, 29, 98, 35, 77, 42},
+	},
+	{
+		{42, 121, 96, 66, 108, 43, 111, 40, 117, 44, 123, 32, 120, 36, 119, 33, 127, 33, 134, 34, 139, 21, 147, 23, 152, 20, 158, 25, 154, 26, 166, 21, 173, 16, 184, 13, 184, 10, 150, 13, 139, 15},
+		{22, 178, 63, 114, 74, 82, 84, 83, 92, 82, 103, 62, 96, 72, 96, 67, 101, 73, 107, 72, 113, 55, 118, 52, 125, 52, 118, 52, 117, 55, 135, 49, 137, 39, 157, 32, 145, 29, 97, 33, 77, 40},
+	},
+}
+
+var (
+	predCoef  = [4]float32{29440 / 32768., 26112 / 32768., 21248 / 32768., 16384 / 32768.}
+	betaCoef  = [4]float32{30147 / 32768., 22282 / 32768., 12124 / 32768., 6554 / 32768.}
+	betaIntra = float32(4915 / 32768.)
+)
+
+var (
+	smallEnergyICDF = []uint8{2, 1, 0}
+	trimICDF        = []uint8{126, 124, 119, 109, 87, 41, 19, 9, 4, 2, 0}
+	spreadICDF      = []uint8{25, 23, 2, 0}
+	tapsetICDF      = []uint8{2, 1, 0}
+)
+
+var tfSelectTable = [4][8]int{
+	{0, -1, 0, -1, 0, -1, 0, -1},
+	{0, -1, 0, -2, 1, 0, 1, -1},
+	{0, -2, 0, -3, 2, 0, 1, -1},
+	{0, -2, 0, -3, 3, 0, 1, -1},
+}
+
+var log2FracTable = [24]int{
+	0,
+	8, 13,
+	16, 19, 21, 23,
+	24, 26, 27, 28, 29, 30, 31, 32,
+	32, 33, 34, 34, 35, 36, 36, 37, 37,
+}
+
+var combGains = [3][3]float32{
+	{0.3066406250, 0.2170410156, 0.1296386719},
+	{0.4638671875, 0.2680664062, 0},
+	{0.7998046875, 0.1000976562, 0},
+}
@@ -0,0 +1,78 @@
+package opus
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func tone(freq float64, n int) []float32 {
+	x := make([]float32, n)
+	for i := range x {
+		x[i] = float32(8000 * math.Sin(2*math.Pi*freq*float64(i)/48000))
+	}
+	return x
+}
+
+// snr returns the signal to noise ratio of got against want, in dB, at the best delay
+// up to maxDelay samples.
+func snr(want, got []float32, maxDelay int) float64 {
+	best := math.Inf(-1)
+	for d := 0; d <= maxDelay; d++ {
+		var sig, noise float64
+		for i := 2000; i+d < len(got) && i < len(want); i++ {
+			e := float64(got[i+d] - want[i])
+			sig += float64(want[i]) * float64(want[i])
+			noise += e * e
+		}
+		if s := 10 * math.Log10(sig/noise); s > best {
+			best = s
+		}
+	}
+	return best
+}
+
+func TestCELTRoundTrip(t *testing.T) {
+	for _, lm := range []int{0, 1, 2, 3} {
+		n := shortMdct << uint(lm)
+		x := tone(440, 48000)
+		for i := range x {
+			x[i] += 2000 * float32(math.Sin(2*math.Pi*1234*float64(i)/48000))
+		}
+		enc := newCELTEncoder(nbEBands)
+		dec := newCELTDecoder(1)
+		var out []float32
+		for f := 0; f+n <= len(x); f += n {
+			// 64 kbit/s.
+			nbBytes := 160 * n / 960
+			pkt := enc.encode(x[f:f+n], nbBytes)
+			rd := &rangeDecoder{}
+			rd.init(pkt)
+			pcm, err := dec.decode(rd, len(pkt), n, 1)
+			if err != nil {
+				t.Fatalf("LM %d: decode: %v", lm, err)
+			}
+			if rd.rng != enc.rng {
+				t.Fatalf("LM %d: frame %d: decoder range %08x, encoder %08x", lm, f/n, rd.rng, enc.rng)
+			}
+			out = append(out, pcm...)
+		}
+		if s := snr(x, out, 400); s < 15 {
+			t.Errorf("LM %d: SNR %.1f dB", lm, s)
+		} else {
+			t.Logf("LM %d: SNR %.1f dB", lm, s)
+		}
+	}
+}
+
+func TestCELTDecodeGarbage(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	dec := newCELTDecoder(1)
+	for i := 0; i < 2000; i++ {
+		pkt := make([]byte, 1+r.Intn(200))
+		r.Read(pkt)
+		rd := &rangeDecoder{}
+		rd.init(pkt)
+		dec.decode(rd, len(pkt), shortMdct<<uint(r.Intn(4)), 1+r.Intn(2))
+	}
+}
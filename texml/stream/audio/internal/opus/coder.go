@@ -0,0 +1,70 @@
+package opus
+
+// coder is either side of the range coder, for the parts of the bitstream that the
+// encoder and decoder code symmetrically. Its methods return the value coded.
+type coder struct {
+	enc *rangeEncoder
+	dec *rangeDecoder
+}
+
+func (c coder) tell() int {
+	if c.enc != nil {
+		return c.enc.tell()
+	}
+	return c.dec.tell()
+}
+
+func (c coder) tellFrac() int {
+	if c.enc != nil {
+		return c.enc.tellFrac()
+	}
+	return c.dec.tellFrac()
+}
+
+// bit codes one raw bit.
+func (c coder) bit(v int) int {
+	return int(c.bits(uint32(v), 1))
+}
+
+func (c coder) bits(v uint32, n uint) uint32 {
+	if c.enc != nil {
+		c.enc.bits(v, n)
+		return v
+	}
+	return c.dec.bits(n)
+}
+
+func (c coder) bitLogp(v int, logp uint) int {
+	if c.enc != nil {
+		c.enc.bitLogp(v != 0, logp)
+		return v
+	}
+	if c.dec.bitLogp(logp) {
+		return 1
+	}
+	return 0
+}
+
+func (c coder) uint(v, ft int) int {
+	if c.enc != nil {
+		c.enc.uint(uint32(v), uint32(ft))
+		return v
+	}
+	return int(c.dec.uint(uint32(ft)))
+}
+
+func (c coder) icdf(v int, icdf []uint8, ftb uint) int {
+	if c.enc != nil {
+		c.enc.icdf(v, icdf, ftb)
+		return v
+	}
+	return c.dec.icdf(icdf, ftb)
+}
+
+// storage returns the size of the buffer, in bytes.
+func (c coder) storage() int {
+	if c.enc != nil {
+		return c.enc.storage
+	}
+	return c.dec.storage
+}
@@ -0,0 +1,198 @@
+package opus
+
+// Enumeration of the pulse vectors of the pyramid vector quantizer, from celt/cwrs.c.
+// pvqU(n, k) is the number of vectors of n dimensions with k-1 pulses whose first
+// coordinate is positive, and pvqV(n, k) = pvqU(n, k) + pvqU(n, k+1) the number of
+// vectors with k pulses.
+
+const pvqMax = 178
+
+// pvqTable holds pvqU, which is symmetric. Entries that do not fit in 32 bits are
+// never used.
+var pvqTable = func() *[pvqMax][pvqMax]uint32 {
+	var u [pvqMax][pvqMax]uint32
+	u[0][0] = 1
+	for n := 1; n < pvqMax; n++ {
+		u[n][1], u[1][n] = 1, 1
+	}
+	for n := 2; n < pvqMax; n++ {
+		for k := 2; k < pvqMax; k++ {
+			u[n][k] = u[n-1][k] + u[n][k-1] + u[n-1][k-1]
+		}
+	}
+	return &u
+}()
+
+func pvqU(n, k int) uint32 {
+	return pvqTable[n][k]
+}
+
+func pvqV(n, k int) uint32 {
+	return pvqU(n, k) + pvqU(n, k+1)
+}
+
+var (
+	fitsMaxN = [15]int{32767, 32767, 32767, 1476, 283, 109, 60, 40, 29, 24, 20, 18, 16, 14, 13}
+	fitsMaxK = [15]int{32767, 32767, 32767, 32767, 1172, 238, 95, 53, 36, 27, 22, 18, 16, 15, 13}
+)
+
+// fitsIn32 tells whether pvqV(n, k) fits in 32 bits.
+func fitsIn32(n, k int) bool {
+	if n >= 14 {
+		if k >= 14 {
+			return false
+		}
+		return n <= fitsMaxN[k]
+	}
+	return k <= fitsMaxK[n]
+}
+
+// log2Frac returns log2(val) with frac fractional bits, rounded up.
+func log2Frac(val uint32, frac int) int {
+	l := ilog(val)
+	if val&(val-1) == 0 {
+		return (l - 1) << uint(frac)
+	}
+	if l > 16 {
+		val = (val-1)>>uint(l-16) + 1
+	} else {
+		val <<= uint(16 - l)
+	}
+	l = (l - 1) << uint(frac)
+	for {
+		b := int(val >> 16)
+		l += b << uint(frac)
+		val = (val + uint32(b)) >> uint(b)
+		val = (val*val + 0x7FFF) >> 15
+		if frac <= 0 {
+			break
+		}
+		frac--
+	}
+	if val > 0x8000 {
+		l++
+	}
+	return l
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// icwrs returns the index of the pulse vector y.
+func icwrs(y []int) uint32 {
+	n := len(y)
+	j := n - 1
+	var i uint32
+	if y[j] < 0 {
+		i = 1
+	}
+	k := abs(y[j])
+	for {
+		j--
+		i += pvqU(n-j, k)
+		k += abs(y[j])
+		if y[j] < 0 {
+			i += pvqU(n-j, k+1)
+		}
+		if j <= 0 {
+			break
+		}
+	}
+	return i
+}
+
+func encodePulses(y []int, k int, enc *rangeEncoder) {
+	enc.uint(icwrs(y), pvqV(len(y), k))
+}
+
+// cwrsi sets y to the pulse vector of index i, and returns its squared norm.
+func cwrsi(n, k int, i uint32, y []int) float32 {
+	var yy float32
+	for n > 2 {
+		var p uint32
+		var s int
+		if k >= n {
+			row := &pvqTable[n]
+			p = row[k+1]
+			if i >= p {
+				s = -1
+				i -= p
+			}
+			k0 := k
+			q := row[n]
+			if q > i {
+				k = n
+				for {
+					k--
+					p = pvqTable[k][n]
+					if p <= i {
+						break
+					}
+				}
+			} else {
+				for p = row[k]; p > i; p = row[k] {
+					k--
+				}
+			}
+			i -= p
+			val := (k0 - k + s) ^ s
+			y[0] = val
+			y = y[1:]
+			yy += float32(val * val)
+		} else {
+			p = pvqTable[k][n]
+			q := pvqTable[k+1][n]
+			if p <= i && i < q {
+				i -= p
+				y[0] = 0
+				y = y[1:]
+			} else {
+				if i >= q {
+					s = -1
+					i -= q
+				}
+				k0 := k
+				for {
+					k--
+					p = pvqTable[k][n]
+					if p <= i {
+						break
+					}
+				}
+				i -= p
+				val := (k0 - k + s) ^ s
+				y[0] = val
+				y = y[1:]
+				yy += float32(val * val)
+			}
+		}
+		n--
+	}
+	p := uint32(2*k + 1)
+	s := 0
+	if i >= p {
+		s = -1
+		i -= p
+	}
+	k0 := k
+	k = int(i+1) >> 1
+	if k != 0 {
+		i -= uint32(2*k - 1)
+	}
+	val := (k0 - k + s) ^ s
+	y[0] = val
+	yy += float32(val * val)
+	s = -int(i)
+	val = (k + s) ^ s
+	y[1] = val
+	yy += float32(val * val)
+	return yy
+}
+
+func decodePulses(y []int, k int, dec *rangeDecoder) float32 {
+	return cwrsi(len(y), k, dec.uint(pvqV(len(y), k)), y)
+}
@@ -0,0 +1,186 @@
+package opus
+
+import "math"
+
+// Quantization of the band energies, from celt/quant_bands.c. Energies are in log2
+// units with the means removed: a coarse part predicted from the previous frame and
+// the previous band, and fine bits refining it.
+
+func unquantCoarseEnergy(start, end int, oldE []float32, intra bool, dec *rangeDecoder, channels, lm int) {
+	prob := eProbModel[lm][b2i(intra)][:]
+	var prev [2]float32
+	coef, beta := predCoef[lm], betaCoef[lm]
+	if intra {
+		coef, beta = 0, betaIntra
+	}
+	budget := dec.storage * 8
+	for i := start; i < end; i++ {
+		for c := 0; c < channels; c++ {
+			var qi int
+			tell := dec.tell()
+			switch {
+			case budget-tell >= 15:
+				pi := 2 * imin(i, 20)
+				qi = dec.laplace(int(prob[pi])<<7, int(prob[pi+1])<<6)
+			case budget-tell >= 2:
+				qi = dec.icdf(smallEnergyICDF, 2)
+				qi = (qi >> 1) ^ -(qi & 1)
+			case budget-tell >= 1:
+				if dec.bitLogp(1) {
+					qi = -1
+				}
+			default:
+				qi = -1
+			}
+			q := float32(qi)
+			e := &oldE[i+c*nbEBands]
+			if *e < -9 {
+				*e = -9
+			}
+			*e = coef**e + prev[c] + q
+			prev[c] = prev[c] + q - beta*q
+		}
+	}
+}
+
+func unquantFineEnergy(start, end int, oldE []float32, fineQuant *[nbEBands]int, dec *rangeDecoder, channels int) {
+	for i := start; i < end; i++ {
+		if fineQuant[i] <= 0 {
+			continue
+		}
+		for c := 0; c < channels; c++ {
+			q2 := dec.bits(uint(fineQuant[i]))
+			offset := (float32(q2)+0.5)*float32(int(1)<<uint(14-fineQuant[i]))/16384 - 0.5
+			oldE[i+c*nbEBands] += offset
+		}
+	}
+}
+
+func unquantEnergyFinalise(start, end int, oldE []float32, a *allocation, bitsLeft int, dec *rangeDecoder, channels int) {
+	for prio := 0; prio < 2; prio++ {
+		for i := start; i < end && bitsLeft >= channels; i++ {
+			if a.fineQuant[i] >= maxFineBits || a.finePriority[i] != prio {
+				continue
+			}
+			for c := 0; c < channels; c++ {
+				q2 := dec.bits(1)
+				offset := (float32(q2) - 0.5) * float32(int(1)<<uint(14-a.fineQuant[i]-1)) / 16384
+				oldE[i+c*nbEBands] += offset
+				bitsLeft--
+			}
+		}
+	}
+}
+
+// quantCoarseEnergy codes the coarse energies of bandLogE, updating oldE to the
+// quantized values and storing the quantization error in errs.
+func quantCoarseEnergy(start, end int, bandLogE, oldE []float32, intra bool, enc *rangeEncoder, channels, lm, nbAvailableBytes int, errs []float32) {
+	budget := enc.storage * 8
+	if enc.tell()+3 <= budget {
+		enc.bitLogp(intra, 3)
+	} else {
+		// The decoder assumes inter prediction without the flag.
+		intra = false
+	}
+	prob := eProbModel[lm][b2i(intra)][:]
+	var prev [2]float32
+	coef, beta := predCoef[lm], betaCoef[lm]
+	if intra {
+		coef, beta = 0, betaIntra
+	}
+	maxDecay := float32(16)
+	if d := float32(nbAvailableBytes) / 8; d < maxDecay {
+		maxDecay = d
+	}
+	for i := start; i < end; i++ {
+		for c := 0; c < channels; c++ {
+			x := bandLogE[i+c*nbEBands]
+			oldEi := oldE[i+c*nbEBands]
+			if oldEi < -9 {
+				oldEi = -9
+			}
+			f := x - coef*oldEi - prev[c]
+			qi := int(math.Floor(float64(0.5 + f)))
+			decayBound := oldE[i+c*nbEBands] - maxDecay
+			if decayBound < -28 {
+				decayBound = -28
+			}
+			// Keep the energy from going down too quickly.
+			if qi < 0 && x < decayBound {
+				qi += int(decayBound - x)
+				if qi > 0 {
+					qi = 0
+				}
+			}
+			tell := enc.tell()
+			bitsLeft := budget - tell - 3*channels*(end-i)
+			if i != start && bitsLeft < 30 {
+				if bitsLeft < 24 {
+					qi = imin(1, qi)
+				}
+				if bitsLeft < 16 {
+					qi = imax(-1, qi)
+				}
+			}
+			switch {
+			case budget-tell >= 15:
+				pi := 2 * imin(i, 20)
+				enc.laplace(&qi, int(prob[pi])<<7, int(prob[pi+1])<<6)
+			case budget-tell >= 2:
+				qi = imax(-1, imin(qi, 1))
+				s := 2 * qi
+				if qi < 0 {
+					s = -s - 1
+				}
+				enc.icdf(s, smallEnergyICDF, 2)
+			case budget-tell >= 1:
+				qi = imin(0, qi)
+				enc.bitLogp(qi != 0, 1)
+			default:
+				qi = -1
+			}
+			errs[i+c*nbEBands] = f - float32(qi)
+			q := float32(qi)
+			oldE[i+c*nbEBands] = coef*oldEi + prev[c] + q
+			prev[c] = prev[c] + q - beta*q
+		}
+	}
+}
+
+func quantFineEnergy(start, end int, oldE, errs []float32, fineQuant *[nbEBands]int, enc *rangeEncoder, channels int) {
+	for i := start; i < end; i++ {
+		if fineQuant[i] <= 0 {
+			continue
+		}
+		frac := 1 << uint(fineQuant[i])
+		for c := 0; c < channels; c++ {
+			q2 := int(math.Floor(float64((errs[i+c*nbEBands] + 0.5) * float32(frac))))
+			q2 = imax(0, imin(q2, frac-1))
+			enc.bits(uint32(q2), uint(fineQuant[i]))
+			offset := (float32(q2)+0.5)*float32(int(1)<<uint(14-fineQuant[i]))/16384 - 0.5
+			oldE[i+c*nbEBands] += offset
+			errs[i+c*nbEBands] -= offset
+		}
+	}
+}
+
+func quantEnergyFinalise(start, end int, oldE, errs []float32, a *allocation, bitsLeft int, enc *rangeEncoder, channels int) {
+	for prio := 0; prio < 2; prio++ {
+		for i := start; i < end && bitsLeft >= channels; i++ {
+			if a.fineQuant[i] >= maxFineBits || a.finePriority[i] != prio {
+				continue
+			}
+			for c := 0; c < channels; c++ {
+				q2 := 1
+				if errs[i+c*nbEBands] < 0 {
+					q2 = 0
+				}
+				enc.bits(uint32(q2), 1)
+				offset := (float32(q2) - 0.5) * float32(int(1)<<uint(14-a.fineQuant[i]-1)) / 16384
+				oldE[i+c*nbEBands] += offset
+				errs[i+c*nbEBands] -= offset
+				bitsLeft--
+			}
+		}
+	}
+}
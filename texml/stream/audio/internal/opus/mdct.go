@@ -0,0 +1,206 @@
+package opus
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// The MDCT of celt/mdct.c, built on an N/4 point complex FFT. The scaling and the
+// twiddles are those of libopus, so band energies match those of other encoders.
+
+// fft is an unscaled forward complex FFT of a size whose factors are 2, 3 and 5.
+type fft struct {
+	n       int
+	factors []int
+	tw      []complex128
+}
+
+func newFFT(n int) *fft {
+	f := &fft{n: n, tw: make([]complex128, n)}
+	for i := range f.tw {
+		f.tw[i] = cmplx.Exp(complex(0, -2*math.Pi*float64(i)/float64(n)))
+	}
+	for m := n; m > 1; {
+		for _, p := range []int{4, 2, 3, 5} {
+			if m%p == 0 {
+				f.factors = append(f.factors, p)
+				m /= p
+				break
+			}
+		}
+	}
+	return f
+}
+
+func (f *fft) transform(x []complex128) {
+	out := make([]complex128, f.n)
+	f.recurse(out, x, 1, f.factors)
+	copy(x, out)
+}
+
+// recurse computes in out the DFT of the len(out) samples of in taken every stride.
+func (f *fft) recurse(out, in []complex128, stride int, factors []int) {
+	n := len(out)
+	if n == 1 {
+		out[0] = in[0]
+		return
+	}
+	p := factors[0]
+	m := n / p
+	for q := 0; q < p; q++ {
+		f.recurse(out[q*m:(q+1)*m], in[q*stride:], stride*p, factors[1:])
+	}
+	var tmp [5]complex128
+	twStep := f.n / n
+	for k := 0; k < m; k++ {
+		for q := 0; q < p; q++ {
+			tmp[q] = out[q*m+k] * f.tw[q*k*twStep]
+		}
+		for r := 0; r < p; r++ {
+			var sum complex128
+			for q := 0; q < p; q++ {
+				sum += tmp[q] * f.tw[(q*r*m*twStep)%f.n]
+			}
+			out[r*m+k] = sum
+		}
+	}
+}
+
+// mdct holds the twiddles of the MDCT of N = 240<<lm samples for every lm.
+type mdct struct {
+	trig [maxLM + 1][]float32
+	ffts [maxLM + 1]*fft
+}
+
+var mdctLookup = newMDCT()
+
+func newMDCT() *mdct {
+	m := &mdct{}
+	for lm := 0; lm <= maxLM; lm++ {
+		n := 2 * shortMdct << uint(lm)
+		trig := make([]float32, n/2)
+		for i := range trig {
+			trig[i] = float32(math.Cos(2 * math.Pi * (float64(i) + 0.125) / float64(n)))
+		}
+		m.trig[lm] = trig
+		m.ffts[lm] = newFFT(n / 4)
+	}
+	return m
+}
+
+// backward computes the inverse MDCT of the n2 = 120<<lm coefficients of in, read
+// every stride, into out[overlap/2 : overlap/2+n2], and applies the TDAC window to
+// out[:overlap], whose first half holds the tail of the previous block.
+func (m *mdct) backward(in []float32, out []float32, lm, stride int) {
+	trig := m.trig[lm]
+	n := 2 * shortMdct << uint(lm)
+	n2 := n >> 1
+	n4 := n >> 2
+	z := make([]complex128, n4)
+	for i := 0; i < n4; i++ {
+		x1 := float64(in[2*i*stride])
+		x2 := float64(in[(n2-1-2*i)*stride])
+		t0, t1 := float64(trig[i]), float64(trig[n4+i])
+		yr := x2*t0 + x1*t1
+		yi := x1*t0 - x2*t1
+		// Real and imaginary parts are swapped to use a forward FFT.
+		z[i] = complex(yi, yr)
+	}
+	m.ffts[lm].transform(z)
+
+	yp := out[overlap/2 : overlap/2+n2]
+	for i := 0; i < n4; i++ {
+		yp[2*i] = float32(real(z[i]))
+		yp[2*i+1] = float32(imag(z[i]))
+	}
+	// Post-rotate from both ends at once, in place.
+	p0, p1 := 0, n2-2
+	for i := 0; i < (n4+1)>>1; i++ {
+		re, im := float64(yp[p0+1]), float64(yp[p0])
+		t0, t1 := float64(trig[i]), float64(trig[n4+i])
+		yr := re*t0 + im*t1
+		yi := re*t1 - im*t0
+		re, im = float64(yp[p1+1]), float64(yp[p1])
+		yp[p0] = float32(yr)
+		yp[p1+1] = float32(yi)
+		t0, t1 = float64(trig[n4-i-1]), float64(trig[n2-i-1])
+		yr = re*t0 + im*t1
+		yi = re*t1 - im*t0
+		yp[p1] = float32(yr)
+		yp[p0+1] = float32(yi)
+		p0 += 2
+		p1 -= 2
+	}
+
+	// Mirror on both sides for TDAC.
+	for i := 0; i < overlap/2; i++ {
+		x1 := out[overlap-1-i]
+		x2 := out[i]
+		w1, w2 := window[i], window[overlap-1-i]
+		out[i] = w2*x2 - w1*x1
+		out[overlap-1-i] = w1*x2 + w2*x1
+	}
+}
+
+// forward computes the MDCT of the n2+overlap samples of in into the n2 = 120<<lm
+// coefficients of out, written every stride.
+func (m *mdct) forward(in []float32, out []float32, lm, stride int) {
+	trig := m.trig[lm]
+	n := 2 * shortMdct << uint(lm)
+	n2 := n >> 1
+	n4 := n >> 2
+	f := make([]float32, n2)
+
+	// Window, shuffle and fold.
+	xp1 := overlap / 2
+	xp2 := n2 - 1 + overlap/2
+	wp1 := overlap / 2
+	wp2 := overlap/2 - 1
+	y := 0
+	i := 0
+	for ; i < (overlap+3)>>2; i++ {
+		f[y] = window[wp2]*in[xp1+n2] + window[wp1]*in[xp2]
+		f[y+1] = window[wp1]*in[xp1] - window[wp2]*in[xp2-n2]
+		y += 2
+		xp1 += 2
+		xp2 -= 2
+		wp1 += 2
+		wp2 -= 2
+	}
+	wp1 = 0
+	wp2 = overlap - 1
+	for ; i < n4-(overlap+3)>>2; i++ {
+		f[y] = in[xp2]
+		f[y+1] = in[xp1]
+		y += 2
+		xp1 += 2
+		xp2 -= 2
+	}
+	for ; i < n4; i++ {
+		f[y] = -window[wp1]*in[xp1-n2] + window[wp2]*in[xp2]
+		f[y+1] = window[wp2]*in[xp1] + window[wp1]*in[xp2+n2]
+		y += 2
+		xp1 += 2
+		xp2 -= 2
+		wp1 += 2
+		wp2 -= 2
+	}
+
+	// Pre-rotate.
+	scale := 1 / float64(n4)
+	z := make([]complex128, n4)
+	for i := 0; i < n4; i++ {
+		t0, t1 := float64(trig[i]), float64(trig[n4+i])
+		re, im := float64(f[2*i]), float64(f[2*i+1])
+		z[i] = complex((re*t0-im*t1)*scale, (im*t0+re*t1)*scale)
+	}
+	m.ffts[lm].transform(z)
+
+	// Post-rotate.
+	for i := 0; i < n4; i++ {
+		t0, t1 := float64(trig[i]), float64(trig[n4+i])
+		fr, fi := real(z[i]), imag(z[i])
+		out[2*i*stride] = float32(fi*t1 - fr*t0)
+		out[(n2-1-2*i)*stride] = float32(fr*t1 + fi*t0)
+	}
+}
@@ -0,0 +1,53 @@
+package opus
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+func TestFFT(t *testing.T) {
+	for _, n := range []int{60, 120, 240, 480} {
+		f := newFFT(n)
+		x := make([]complex128, n)
+		for i := range x {
+			x[i] = complex(rand.Float64(), rand.Float64())
+		}
+		want := make([]complex128, n)
+		for k := range want {
+			for i, v := range x {
+				want[k] += v * cmplx.Exp(complex(0, -2*math.Pi*float64(i*k)/float64(n)))
+			}
+		}
+		f.transform(x)
+		for k := range x {
+			if cmplx.Abs(x[k]-want[k]) > 1e-9 {
+				t.Fatalf("FFT(%d)[%d] = %v, want %v", n, k, x[k], want[k])
+			}
+		}
+	}
+}
+
+func TestMDCTReconstruction(t *testing.T) {
+	for lm := 0; lm <= maxLM; lm++ {
+		n2 := shortMdct << uint(lm)
+		frames := 6
+		x := make([]float32, overlap+frames*n2)
+		for i := range x {
+			x[i] = float32(rand.Float64()*2 - 1)
+		}
+		out := make([]float32, frames*n2+overlap)
+		coeffs := make([]float32, n2)
+		for f := 0; f < frames; f++ {
+			mdctLookup.forward(x[f*n2:f*n2+n2+overlap], coeffs, lm, 1)
+			mdctLookup.backward(coeffs, out[f*n2:], lm, 1)
+		}
+		// The output lags the input by the overlap.
+		for i := overlap; i < (frames-1)*n2; i++ {
+			if d := math.Abs(float64(out[i] - x[i])); d > 1e-4 {
+				t.Fatalf("LM %d: sample %d = %v, want %v", lm, i, out[i], x[i])
+			}
+		}
+	}
+}
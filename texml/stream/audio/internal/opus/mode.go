@@ -0,0 +1,208 @@
+package opus
+
+import "math"
+
+// The parts of the CELT mode computed at start-up, as celt/modes.c and celt/rate.c do
+// when creating a custom mode: the MDCT window and the pulse cache, which tells how
+// many bits each number of pulses costs in each band.
+
+// getPulses returns the number of pulses of the pseudo-pulse index i.
+func getPulses(i int) int {
+	if i < 8 {
+		return i
+	}
+	return (8 + i&7) << uint(i>>3-1)
+}
+
+type pulseCache struct {
+	index []int
+	bits  []uint8
+	caps  []uint8
+}
+
+var (
+	cache  = computePulseCache()
+	window = computeWindow()
+)
+
+func computeWindow() [overlap]float32 {
+	var w [overlap]float32
+	for i := range w {
+		s := math.Sin(0.5 * math.Pi * (float64(i) + 0.5) / overlap)
+		w[i] = float32(math.Sin(0.5 * math.Pi * s * s))
+	}
+	return w
+}
+
+func computePulseCache() *pulseCache {
+	const lm = maxLM
+	c := &pulseCache{index: make([]int, nbEBands*(lm+2))}
+	var entryN, entryK, entryI []int
+	curr := 0
+	for i := 0; i <= lm+1; i++ {
+		for j := 0; j < nbEBands; j++ {
+			n := (eBands[j+1] - eBands[j]) << uint(i) >> 1
+			c.index[i*nbEBands+j] = -1
+			// Share the entry of a band of the same size.
+		search:
+			for k := 0; k <= i; k++ {
+				for m := 0; m < nbEBands && (k != i || m < j); m++ {
+					if n == (eBands[m+1]-eBands[m])<<uint(k)>>1 {
+						c.index[i*nbEBands+j] = c.index[k*nbEBands+m]
+						break search
+					}
+				}
+			}
+			if c.index[i*nbEBands+j] == -1 && n != 0 {
+				k := 0
+				for fitsIn32(n, getPulses(k+1)) && k < maxPseudo {
+					k++
+				}
+				entryN = append(entryN, n)
+				entryK = append(entryK, k)
+				entryI = append(entryI, curr)
+				c.index[i*nbEBands+j] = curr
+				curr += k + 1
+			}
+		}
+	}
+	c.bits = make([]uint8, curr)
+	for i := range entryN {
+		ptr := c.bits[entryI[i]:]
+		n, maxK := entryN[i], entryK[i]
+		for j := 1; j <= maxK; j++ {
+			ptr[j] = uint8(requiredBits(n, getPulses(j)) - 1)
+		}
+		ptr[0] = uint8(maxK)
+	}
+
+	// The maximum rate of each band at which we reliably use as many bits as asked.
+	c.caps = make([]uint8, 0, (lm+1)*2*nbEBands)
+	for i := 0; i <= lm; i++ {
+		for ch := 1; ch <= 2; ch++ {
+			for j := 0; j < nbEBands; j++ {
+				n0 := eBands[j+1] - eBands[j]
+				var maxBits int
+				if n0<<uint(i) == 1 {
+					maxBits = ch * (1 + maxFineBits) << bitRes
+				} else {
+					lm0 := 0
+					if n0 > 2 {
+						n0 >>= 1
+						lm0--
+					} else if n0 <= 1 {
+						lm0 = i
+						if lm0 > 1 {
+							lm0 = 1
+						}
+						n0 <<= uint(lm0)
+					}
+					pcache := c.bits[c.index[(lm0+1)*nbEBands+j]:]
+					maxBits = int(pcache[pcache[0]]) + 1
+					n := n0
+					for k := 0; k < i-lm0; k++ {
+						maxBits <<= 1
+						offset := (logN[j]+(lm0+k)<<bitRes)>>1 - qthetaOffset
+						num := 459 * ((2*n-1)*offset + maxBits)
+						den := (2*n-1)<<9 - 459
+						qb := (num + den>>1) / den
+						if qb > 57 {
+							qb = 57
+						}
+						maxBits += qb
+						n <<= 1
+					}
+					if ch == 2 {
+						maxBits <<= 1
+						offset := (logN[j]+i<<bitRes)>>1 - qthetaOffset
+						p, qmax := 487, 61
+						ndof := 2*n - 1
+						if n == 2 {
+							offset = (logN[j]+i<<bitRes)>>1 - qthetaOffsetTwoPhase
+							p, qmax = 512, 64
+							ndof--
+						}
+						num := p * (maxBits + ndof*offset)
+						den := ndof<<9 - p
+						qb := (num + den>>1) / den
+						if qb > qmax {
+							qb = qmax
+						}
+						maxBits += qb
+					}
+					ndof := ch * n
+					if ch == 2 && n > 2 {
+						ndof++
+					}
+					offset := (logN[j]+i<<bitRes)>>1 - fineOffset
+					if n == 2 {
+						offset += 1 << bitRes >> 2
+					}
+					num := maxBits + ndof*offset
+					den := (ndof - 1) << bitRes
+					qb := (num + den>>1) / den
+					if qb > maxFineBits {
+						qb = maxFineBits
+					}
+					maxBits += ch * qb << bitRes
+				}
+				maxBits = 4*maxBits/(ch*((eBands[j+1]-eBands[j])<<uint(i))) - 64
+				if maxBits > 255 {
+					maxBits = 255
+				}
+				c.caps = append(c.caps, uint8(maxBits))
+			}
+		}
+	}
+	return c
+}
+
+// requiredBits returns the cost, in 1/8 bits, of coding k pulses in n dimensions.
+func requiredBits(n, k int) int {
+	if n == 1 {
+		return 1 << bitRes
+	}
+	return log2Frac(pvqV(n, k), bitRes)
+}
+
+func bits2pulses(band, lm, b int) int {
+	lm++
+	c := cache.bits[cache.index[lm*nbEBands+band]:]
+	lo, hi := 0, int(c[0])
+	b--
+	for i := 0; i < logMaxPseudo; i++ {
+		mid := (lo + hi + 1) >> 1
+		if int(c[mid]) >= b {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	low := -1
+	if lo != 0 {
+		low = int(c[lo])
+	}
+	if b-low <= int(c[hi])-b {
+		return lo
+	}
+	return hi
+}
+
+func pulses2bits(band, lm, pulses int) int {
+	if pulses == 0 {
+		return 0
+	}
+	lm++
+	c := cache.bits[cache.index[lm*nbEBands+band]:]
+	return int(c[pulses]) + 1
+}
+
+// initCaps returns the maximum allocation of each band, in 1/8 bits.
+func initCaps(lm, channels int) [nbEBands]int {
+	var caps [nbEBands]int
+	for i := range caps {
+		n := (eBands[i+1] - eBands[i]) << uint(lm)
+		caps[i] = (int(cache.caps[nbEBands*(2*lm+channels-1)+i]) + 64) * channels * n >> 2
+	}
+	return caps
+}
@@ -0,0 +1,61 @@
+package opus
+
+import "testing"
+
+// cacheCaps50 is the cache_caps50 table of the static 48 kHz mode of libopus.
+var cacheCaps50 = [168]uint8{
+	224, 224, 224, 224, 224, 224, 224, 224, 160, 160,
+	160, 160, 185, 185, 185, 178, 178, 168, 134, 61,
+	37, 224, 224, 224, 224, 224, 224, 224, 224, 240,
+	240, 240, 240, 207, 207, 207, 198, 198, 183, 144,
+	66, 40, 160, 160, 160, 160, 160, 160, 160, 160,
+	185, 185, 185, 185, 193, 193, 193, 183, 183, 172,
+	138, 64, 38, 240, 240, 240, 240, 240, 240, 240,
+	240, 207, 207, 207, 207, 204, 204, 204, 193, 193,
+	180, 143, 66, 40, 185, 185, 185, 185, 185, 185,
+	185, 185, 193, 193, 193, 193, 193, 193, 193, 183,
+	183, 172, 138, 65, 39, 207, 207, 207, 207, 207,
+	207, 207, 207, 204, 204, 204, 204, 201, 201, 201,
+	188, 188, 176, 141, 66, 40, 193, 193, 193, 193,
+	193, 193, 193, 193, 193, 193, 193, 193, 194, 194,
+	194, 184, 184, 173, 139, 65, 39, 204, 204, 204,
+	204, 204, 204, 204, 204, 201, 201, 201, 201, 198,
+	198, 198, 187, 187, 175, 140, 66, 40,
+}
+
+func TestPulseCache(t *testing.T) {
+	if len(cache.caps) != len(cacheCaps50) {
+		t.Fatalf("%d caps, want %d", len(cache.caps), len(cacheCaps50))
+	}
+	for i, c := range cache.caps {
+		if c != cacheCaps50[i] {
+			t.Errorf("caps[%d] = %d, want %d", i, c, cacheCaps50[i])
+		}
+	}
+	// The static mode has 392 cached bit counts.
+	if len(cache.bits) != 392 {
+		t.Errorf("%d cached bit counts, want 392", len(cache.bits))
+	}
+}
+
+func TestCWRS(t *testing.T) {
+	for n := 2; n < 12; n++ {
+		for k := 1; k < 6; k++ {
+			v := pvqV(n, k)
+			y := make([]int, n)
+			for i := uint32(0); i < v; i++ {
+				cwrsi(n, k, i, y)
+				sum := 0
+				for _, c := range y {
+					sum += abs(c)
+				}
+				if sum != k {
+					t.Fatalf("vector %d of V(%d, %d) = %v has %d pulses", i, n, k, y, sum)
+				}
+				if got := icwrs(y); got != i {
+					t.Fatalf("icwrs(%v) = %d, want %d", y, got, i)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Package opus is a pure-Go Opus codec for mono speech, after RFC 6716 and libopus.
+//
+// The decoder handles the CELT only packets of the Opus modes, mixing stereo streams
+// down to mono; packets in the SILK and hybrid modes return ErrUnsupportedMode. The
+// encoder produces CELT only packets at a constant bitrate.
+package opus
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrUnsupportedMode is returned when decoding a packet coded in the SILK or hybrid
+// modes.
+var ErrUnsupportedMode = errors.New("opus: SILK and hybrid packets are not supported")
+
+// Rates are the sample rates supported, in Hz.
+var Rates = []int{8000, 12000, 16000, 24000, 48000}
+
+func checkRate(rate int) error {
+	for _, r := range Rates {
+		if r == rate {
+			return nil
+		}
+	}
+	return fmt.Errorf("opus: unsupported sample rate %d", rate)
+}
+
+// Decoder decodes Opus packets to mono 16-bit PCM.
+type Decoder struct {
+	rate      int
+	celt      *celtDecoder
+	frameSize int
+}
+
+// NewDecoder returns a decoder with output at rate Hz.
+func NewDecoder(rate int) (*Decoder, error) {
+	if err := checkRate(rate); err != nil {
+		return nil, err
+	}
+	return &Decoder{rate: rate, celt: newCELTDecoder(48000 / rate), frameSize: 960}, nil
+}
+
+// Decode decodes a packet. An empty packet conceals a lost one of the same duration
+// as the last.
+func (d *Decoder) Decode(packet []byte) ([]int16, error) {
+	if len(packet) == 0 {
+		pcm, _ := d.celt.decode(nil, 0, d.frameSize, 1)
+		return toInt16(pcm), nil
+	}
+	t, frames, err := parsePacket(packet)
+	if err != nil {
+		return nil, err
+	}
+	if !t.celtOnly {
+		return nil, ErrUnsupportedMode
+	}
+	channels := 1
+	if t.stereo {
+		channels = 2
+	}
+	d.celt.end = endBand[t.bandwidth]
+	d.frameSize = t.frameSize
+	var out []int16
+	for _, f := range frames {
+		var pcm []float32
+		if len(f) <= 1 {
+			pcm, err = d.celt.decode(nil, 0, t.frameSize, channels)
+		} else {
+			rd := &rangeDecoder{}
+			rd.init(f)
+			pcm, err = d.celt.decode(rd, len(f), t.frameSize, channels)
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, toInt16(pcm)...)
+	}
+	return out, nil
+}
+
+func toInt16(pcm []float32) []int16 {
+	out := make([]int16, len(pcm))
+	for i, v := range pcm {
+		out[i] = int16(math.Max(-32768, math.Min(32767, math.Floor(float64(v)+0.5))))
+	}
+	return out
+}
+
+// Encoder encodes mono 16-bit PCM to CELT only Opus packets.
+type Encoder struct {
+	upsample  int
+	bandwidth bandwidth
+	// bitrate is in bits per second.
+	bitrate int
+	celt    *celtEncoder
+}
+
+// NewEncoder returns an encoder of input at rate Hz, coding the full bandwidth of
+// the input.
+func NewEncoder(rate int) (*Encoder, error) {
+	if err := checkRate(rate); err != nil {
+		return nil, err
+	}
+	bw, bitrate := fullband, 64000
+	switch rate {
+	case 8000:
+		bw, bitrate = narrowband, 32000
+	case 12000, 16000:
+		bw, bitrate = wideband, 32000
+	case 24000:
+		bw, bitrate = superwideband, 48000
+	}
+	return &Encoder{upsample: 48000 / rate, bandwidth: bw, bitrate: bitrate, celt: newCELTEncoder(endBand[bw])}, nil
+}
+
+// Encode encodes pcm into one packet. Its duration must be a multiple of 2.5, 5, 10
+// or 20 ms, and at most 120 ms.
+func (e *Encoder) Encode(pcm []int16) ([]byte, error) {
+	n := len(pcm) * e.upsample
+	lm := maxLM
+	for ; lm >= 0; lm-- {
+		if size := shortMdct << uint(lm); n > 0 && n%size == 0 && n/size <= 48 {
+			break
+		}
+	}
+	if lm < 0 || n > 5760 {
+		return nil, fmt.Errorf("opus: cannot encode %d samples in a packet", len(pcm))
+	}
+	size := shortMdct << uint(lm)
+	count := n / size
+	nbBytes := imin(maxFrameBytes, e.bitrate*size/48000/8)
+
+	// Upsample by inserting zeros; the bands above the input bandwidth are not coded.
+	x := make([]float32, n)
+	for i, v := range pcm {
+		x[i*e.upsample] = float32(v) * float32(e.upsample)
+	}
+	packet := []byte{celtTOC(e.bandwidth, lm, 0)}
+	if count > 1 {
+		packet[0] |= 3
+		packet = append(packet, byte(count))
+	}
+	for f := 0; f < count; f++ {
+		packet = append(packet, e.celt.encode(x[f*size:(f+1)*size], nbBytes)...)
+	}
+	return packet, nil
+}
@@ -0,0 +1,123 @@
+package opus
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	for _, rate := range Rates {
+		enc, err := NewEncoder(rate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec, err := NewDecoder(rate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		in := make([]float32, rate)
+		var out []float32
+		for f := 0; f+rate/50 <= len(in); f += rate / 50 {
+			frame := make([]int16, rate/50)
+			for i := range frame {
+				v := 8000 * math.Sin(2*math.Pi*700*float64(f+i)/float64(rate))
+				frame[i] = int16(v)
+				in[f+i] = float32(frame[i])
+			}
+			packet, err := enc.Encode(frame)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pcm, err := dec.Decode(packet)
+			if err != nil {
+				t.Fatalf("%d Hz: Decode: %v", rate, err)
+			}
+			if len(pcm) != len(frame) {
+				t.Fatalf("%d Hz: decoded %d samples, want %d", rate, len(pcm), len(frame))
+			}
+			for _, v := range pcm {
+				out = append(out, float32(v))
+			}
+		}
+		if s := snr(in, out, rate/100); s < 15 {
+			t.Errorf("%d Hz: SNR %.1f dB", rate, s)
+		}
+	}
+}
+
+func TestEncodeFrames(t *testing.T) {
+	enc, _ := NewEncoder(16000)
+	dec, _ := NewDecoder(16000)
+	for _, n := range []int{40, 80, 160, 320, 640, 960, 1920} {
+		packet, err := enc.Encode(make([]int16, n))
+		if err != nil {
+			t.Fatalf("Encode(%d samples): %v", n, err)
+		}
+		pcm, err := dec.Decode(packet)
+		if err != nil || len(pcm) != n {
+			t.Fatalf("Decode of %d samples = %d samples, %v", n, len(pcm), err)
+		}
+	}
+	for _, n := range []int{0, 30, 1930} {
+		if _, err := enc.Encode(make([]int16, n)); err == nil {
+			t.Errorf("Encode(%d samples) succeeded", n)
+		}
+	}
+}
+
+func TestParsePacket(t *testing.T) {
+	cases := []struct {
+		packet  []byte
+		lengths []int
+	}{
+		{[]byte{0xf8, 1, 2, 3}, []int{3}},
+		{[]byte{0xf9, 1, 2, 3, 4}, []int{2, 2}},
+		{[]byte{0xfa, 1, 9, 9, 9}, []int{1, 2}},
+		{[]byte{0xfb, 0x03, 1, 2, 3}, []int{1, 1, 1}},
+		{[]byte{0xfb, 0x83, 1, 2, 7, 7, 7, 7}, []int{1, 2, 1}},
+		{[]byte{0xfb, 0x42, 2, 1, 2, 0, 0}, []int{1, 1}},
+	}
+	for _, c := range cases {
+		_, frames, err := parsePacket(c.packet)
+		if err != nil {
+			t.Errorf("parsePacket(% x): %v", c.packet, err)
+			continue
+		}
+		if len(frames) != len(c.lengths) {
+			t.Errorf("parsePacket(% x) = %d frames, want %d", c.packet, len(frames), len(c.lengths))
+			continue
+		}
+		for i, f := range frames {
+			if len(f) != c.lengths[i] {
+				t.Errorf("parsePacket(% x) frame %d = %d bytes, want %d", c.packet, i, len(f), c.lengths[i])
+			}
+		}
+	}
+	for _, p := range [][]byte{{}, {0xf9, 1, 2, 3}, {0xfa, 5, 1}, {0xfb}, {0xfb, 0x00}, {0xfb, 0x02, 1, 2, 3}, {0xfb, 0x41, 9}} {
+		if _, _, err := parsePacket(p); err == nil {
+			t.Errorf("parsePacket(% x) succeeded", p)
+		}
+	}
+}
+
+func TestDecodeSILK(t *testing.T) {
+	dec, _ := NewDecoder(8000)
+	if _, err := dec.Decode([]byte{0x08, 1, 2, 3}); !errors.Is(err, ErrUnsupportedMode) {
+		t.Errorf("Decode of a SILK packet: %v, want ErrUnsupportedMode", err)
+	}
+}
+
+func TestDecodeGarbage(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, rate := range Rates {
+		dec, _ := NewDecoder(rate)
+		for i := 0; i < 500; i++ {
+			p := make([]byte, 1+r.Intn(300))
+			r.Read(p)
+			p[0] |= 0x80
+			dec.Decode(p)
+		}
+	}
+}
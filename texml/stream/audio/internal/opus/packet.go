@@ -0,0 +1,167 @@
+package opus
+
+import "errors"
+
+// Packets and their table-of-contents byte, from RFC 6716 section 3.
+
+const maxFrameBytes = 1275
+
+var errPacket = errors.New("opus: malformed packet")
+
+// bandwidth is the audio bandwidth signalled in a TOC byte.
+type bandwidth int
+
+const (
+	narrowband bandwidth = iota
+	mediumband
+	wideband
+	superwideband
+	fullband
+)
+
+// endBand is the last CELT band coded, plus one, at a bandwidth.
+var endBand = [...]int{narrowband: 13, mediumband: 17, wideband: 17, superwideband: 19, fullband: 21}
+
+// toc is a decoded TOC byte.
+type toc struct {
+	celtOnly  bool
+	bandwidth bandwidth
+	// frameSize is the duration of a frame, in samples at 48 kHz.
+	frameSize int
+	stereo    bool
+}
+
+func parseTOC(b byte) toc {
+	config := int(b >> 3)
+	t := toc{stereo: b&4 != 0}
+	switch {
+	case config < 12:
+		// SILK only: 10, 20, 40 and 60 ms frames.
+		t.bandwidth = bandwidth(config >> 2)
+		t.frameSize = [4]int{480, 960, 1920, 2880}[config&3]
+	case config < 16:
+		// Hybrid: 10 and 20 ms frames.
+		t.bandwidth = superwideband + bandwidth(config>>1&1)
+		t.frameSize = 480 << uint(config&1)
+	default:
+		t.celtOnly = true
+		t.bandwidth = bandwidth((config - 16) >> 2)
+		if t.bandwidth != narrowband {
+			t.bandwidth++
+		}
+		t.frameSize = shortMdct << uint(config&3)
+	}
+	return t
+}
+
+// celtTOC returns the TOC byte of a mono CELT only packet.
+func celtTOC(bw bandwidth, lm, code int) byte {
+	i := int(bw)
+	if bw != narrowband {
+		i--
+	}
+	return byte((16+4*i+lm)<<3 | code)
+}
+
+// parsePacket splits a packet into its frames.
+func parsePacket(p []byte) (toc, [][]byte, error) {
+	if len(p) < 1 {
+		return toc{}, nil, errPacket
+	}
+	t := parseTOC(p[0])
+	data := p[1:]
+	var frames [][]byte
+	switch p[0] & 3 {
+	case 0:
+		frames = [][]byte{data}
+	case 1:
+		if len(data)%2 != 0 {
+			return t, nil, errPacket
+		}
+		frames = [][]byte{data[:len(data)/2], data[len(data)/2:]}
+	case 2:
+		n, size := frameLength(data)
+		if size < 0 || n > len(data)-size {
+			return t, nil, errPacket
+		}
+		data = data[size:]
+		frames = [][]byte{data[:n], data[n:]}
+	case 3:
+		if len(data) < 1 {
+			return t, nil, errPacket
+		}
+		vbr, padded, count := data[0]&0x80 != 0, data[0]&0x40 != 0, int(data[0]&0x3f)
+		data = data[1:]
+		if count == 0 || count*t.frameSize > 5760 {
+			return t, nil, errPacket
+		}
+		if padded {
+			pad := 0
+			for {
+				if len(data) < 1 {
+					return t, nil, errPacket
+				}
+				v := int(data[0])
+				data = data[1:]
+				if v == 255 {
+					pad += 254
+					continue
+				}
+				pad += v
+				break
+			}
+			if pad > len(data) {
+				return t, nil, errPacket
+			}
+			data = data[:len(data)-pad]
+		}
+		if vbr {
+			lengths := make([]int, count-1)
+			for i := range lengths {
+				n, size := frameLength(data)
+				if size < 0 {
+					return t, nil, errPacket
+				}
+				lengths[i] = n
+				data = data[size:]
+			}
+			for _, n := range lengths {
+				if n > len(data) {
+					return t, nil, errPacket
+				}
+				frames = append(frames, data[:n])
+				data = data[n:]
+			}
+			frames = append(frames, data)
+		} else {
+			if len(data)%count != 0 {
+				return t, nil, errPacket
+			}
+			n := len(data) / count
+			for i := 0; i < count; i++ {
+				frames = append(frames, data[i*n:(i+1)*n])
+			}
+		}
+	}
+	for _, f := range frames {
+		if len(f) > maxFrameBytes {
+			return t, nil, errPacket
+		}
+	}
+	return t, frames, nil
+}
+
+// frameLength decodes a frame length, returning it and the bytes it took, or -1 bytes
+// if data is too short.
+func frameLength(data []byte) (int, int) {
+	switch {
+	case len(data) < 1:
+		return 0, -1
+	case data[0] < 252:
+		return int(data[0]), 1
+	case len(data) < 2:
+		return 0, -1
+	default:
+		return int(data[0]) + 4*int(data[1]), 2
+	}
+}
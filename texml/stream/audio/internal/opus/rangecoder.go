@@ -0,0 +1,502 @@
+package opus
+
+import "math/bits"
+
+// The range coder of RFC 6716, section 4.1 (decoder) and 5.1 (encoder). Symbols are
+// coded from the front of the buffer and raw bits from its end.
+
+const (
+	codeBits   = 32
+	symBits    = 8
+	symMax     = 1<<symBits - 1
+	codeShift  = codeBits - symBits - 1
+	codeTop    = 1 << (codeBits - 1)
+	codeBot    = codeTop >> symBits
+	codeExtra  = (codeBits-2)%symBits + 1
+	uintBits   = 8
+	windowSize = 32
+)
+
+// ilog returns the number of bits needed to represent v.
+func ilog(v uint32) int {
+	return bits.Len32(v)
+}
+
+type rangeDecoder struct {
+	buf       []byte
+	storage   int
+	offs      int
+	endOffs   int
+	endWindow uint32
+	nendBits  int
+	nbitsTot  int
+	rng       uint32
+	val       uint32
+	ext       uint32
+	rem       int
+	err       bool
+}
+
+func (d *rangeDecoder) init(buf []byte) {
+	*d = rangeDecoder{buf: buf, storage: len(buf)}
+	d.nbitsTot = codeBits + 1 - ((codeBits-codeExtra)/symBits)*symBits
+	d.rng = 1 << codeExtra
+	d.rem = d.readByte()
+	d.val = d.rng - 1 - uint32(d.rem>>(symBits-codeExtra))
+	d.normalize()
+}
+
+func (d *rangeDecoder) readByte() int {
+	if d.offs < d.storage {
+		d.offs++
+		return int(d.buf[d.offs-1])
+	}
+	return 0
+}
+
+func (d *rangeDecoder) readByteFromEnd() int {
+	if d.endOffs < d.storage {
+		d.endOffs++
+		return int(d.buf[d.storage-d.endOffs])
+	}
+	return 0
+}
+
+func (d *rangeDecoder) normalize() {
+	for d.rng <= codeBot {
+		d.nbitsTot += symBits
+		d.rng <<= symBits
+		sym := d.rem
+		d.rem = d.readByte()
+		sym = (sym<<symBits | d.rem) >> (symBits - codeExtra)
+		d.val = ((d.val << symBits) + uint32(symMax&^sym)) & (codeTop - 1)
+	}
+}
+
+// decode returns the cumulative frequency of the next symbol, out of ft. It must be
+// followed by update.
+func (d *rangeDecoder) decode(ft uint32) uint32 {
+	d.ext = d.rng / ft
+	s := d.val / d.ext
+	if s+1 < ft {
+		return ft - (s + 1)
+	}
+	return 0
+}
+
+// decodeBin is decode with ft = 1<<bits.
+func (d *rangeDecoder) decodeBin(nbits uint) uint32 {
+	d.ext = d.rng >> nbits
+	s := d.val / d.ext
+	ft := uint32(1) << nbits
+	if s+1 < ft {
+		return ft - (s + 1)
+	}
+	return 0
+}
+
+func (d *rangeDecoder) update(fl, fh, ft uint32) {
+	s := d.ext * (ft - fh)
+	d.val -= s
+	if fl > 0 {
+		d.rng = d.ext * (fh - fl)
+	} else {
+		d.rng -= s
+	}
+	d.normalize()
+}
+
+// bitLogp decodes a bit whose probability of being one is 1/(1<<logp).
+func (d *rangeDecoder) bitLogp(logp uint) bool {
+	r := d.rng
+	v := d.val
+	s := r >> logp
+	ret := v < s
+	if !ret {
+		d.val = v - s
+		d.rng = r - s
+	} else {
+		d.rng = s
+	}
+	d.normalize()
+	return ret
+}
+
+// icdf decodes a symbol with the inverse cumulative distribution icdf, scaled by
+// 1<<ftb.
+func (d *rangeDecoder) icdf(icdf []uint8, ftb uint) int {
+	s := d.rng
+	v := d.val
+	r := s >> ftb
+	ret := -1
+	var t uint32
+	for {
+		t = s
+		ret++
+		s = r * uint32(icdf[ret])
+		if v >= s {
+			break
+		}
+	}
+	d.val = v - s
+	d.rng = t - s
+	d.normalize()
+	return ret
+}
+
+// uint decodes an integer in [0, ft).
+func (d *rangeDecoder) uint(ft uint32) uint32 {
+	ft--
+	ftb := ilog(ft)
+	if ftb > uintBits {
+		ftb -= uintBits
+		ft1 := ft>>uint(ftb) + 1
+		s := d.decode(ft1)
+		d.update(s, s+1, ft1)
+		t := s<<uint(ftb) | d.bits(uint(ftb))
+		if t <= ft {
+			return t
+		}
+		d.err = true
+		return ft
+	}
+	ft++
+	s := d.decode(ft)
+	d.update(s, s+1, ft)
+	return s
+}
+
+// bits reads nbits raw bits from the end of the buffer.
+func (d *rangeDecoder) bits(nbits uint) uint32 {
+	w := d.endWindow
+	available := d.nendBits
+	if available < int(nbits) {
+		for {
+			w |= uint32(d.readByteFromEnd()) << uint(available)
+			available += symBits
+			if available > windowSize-symBits {
+				break
+			}
+		}
+	}
+	ret := w & (1<<nbits - 1)
+	w >>= nbits
+	available -= int(nbits)
+	d.endWindow = w
+	d.nendBits = available
+	d.nbitsTot += int(nbits)
+	return ret
+}
+
+// tell returns the number of bits used so far, rounded up.
+func (d *rangeDecoder) tell() int {
+	return d.nbitsTot - ilog(d.rng)
+}
+
+var tellCorrection = [8]uint32{35733, 38967, 42495, 46340, 50535, 55109, 60097, 65535}
+
+// tellFrac returns the number of bits used so far in 1/8 bits, rounded up.
+func tellFrac(nbitsTot int, rng uint32) int {
+	nbits := nbitsTot << 3
+	l := ilog(rng)
+	r := rng >> uint(l-16)
+	b := int(r>>12) - 8
+	if r > tellCorrection[b] {
+		b++
+	}
+	l = l<<3 + b
+	return nbits - l
+}
+
+func (d *rangeDecoder) tellFrac() int {
+	return tellFrac(d.nbitsTot, d.rng)
+}
+
+type rangeEncoder struct {
+	buf       []byte
+	storage   int
+	offs      int
+	endOffs   int
+	endWindow uint32
+	nendBits  int
+	nbitsTot  int
+	rng       uint32
+	val       uint32
+	ext       int
+	rem       int
+	err       bool
+}
+
+func (e *rangeEncoder) init(buf []byte) {
+	*e = rangeEncoder{buf: buf, storage: len(buf), nbitsTot: codeBits + 1, rng: codeTop, rem: -1}
+}
+
+func (e *rangeEncoder) writeByte(v int) {
+	if e.offs+e.endOffs >= e.storage {
+		e.err = true
+		return
+	}
+	e.buf[e.offs] = byte(v)
+	e.offs++
+}
+
+func (e *rangeEncoder) writeByteAtEnd(v int) {
+	if e.offs+e.endOffs >= e.storage {
+		e.err = true
+		return
+	}
+	e.endOffs++
+	e.buf[e.storage-e.endOffs] = byte(v)
+}
+
+// carryOut outputs a symbol, buffering runs of 0xFF until the carry is known.
+func (e *rangeEncoder) carryOut(c int) {
+	if c != symMax {
+		carry := c >> symBits
+		if e.rem >= 0 {
+			e.writeByte(e.rem + carry)
+		}
+		if e.ext > 0 {
+			sym := (symMax + carry) & symMax
+			for ; e.ext > 0; e.ext-- {
+				e.writeByte(sym)
+			}
+		}
+		e.rem = c & symMax
+	} else {
+		e.ext++
+	}
+}
+
+func (e *rangeEncoder) normalize() {
+	for e.rng <= codeBot {
+		e.carryOut(int(e.val >> codeShift))
+		e.val = (e.val << symBits) & (codeTop - 1)
+		e.rng <<= symBits
+		e.nbitsTot += symBits
+	}
+}
+
+func (e *rangeEncoder) encode(fl, fh, ft uint32) {
+	r := e.rng / ft
+	if fl > 0 {
+		e.val += e.rng - r*(ft-fl)
+		e.rng = r * (fh - fl)
+	} else {
+		e.rng -= r * (ft - fh)
+	}
+	e.normalize()
+}
+
+func (e *rangeEncoder) encodeBin(fl, fh uint32, nbits uint) {
+	r := e.rng >> nbits
+	if fl > 0 {
+		e.val += e.rng - r*((1<<nbits)-fl)
+		e.rng = r * (fh - fl)
+	} else {
+		e.rng -= r * ((1 << nbits) - fh)
+	}
+	e.normalize()
+}
+
+func (e *rangeEncoder) bitLogp(val bool, logp uint) {
+	r := e.rng
+	l := e.val
+	s := r >> logp
+	r -= s
+	if val {
+		e.val = l + r
+		e.rng = s
+	} else {
+		e.rng = r
+	}
+	e.normalize()
+}
+
+func (e *rangeEncoder) icdf(s int, icdf []uint8, ftb uint) {
+	r := e.rng >> ftb
+	if s > 0 {
+		e.val += e.rng - r*uint32(icdf[s-1])
+		e.rng = r * uint32(icdf[s-1]-icdf[s])
+	} else {
+		e.rng -= r * uint32(icdf[s])
+	}
+	e.normalize()
+}
+
+func (e *rangeEncoder) uint(fl, ft uint32) {
+	ft--
+	ftb := ilog(ft)
+	if ftb > uintBits {
+		ftb -= uintBits
+		ft1 := ft>>uint(ftb) + 1
+		e.encode(fl>>uint(ftb), fl>>uint(ftb)+1, ft1)
+		e.bits(fl&(1<<uint(ftb)-1), uint(ftb))
+	} else {
+		e.encode(fl, fl+1, ft+1)
+	}
+}
+
+func (e *rangeEncoder) bits(fl uint32, nbits uint) {
+	w := e.endWindow
+	used := e.nendBits
+	if used+int(nbits) > windowSize {
+		for {
+			e.writeByteAtEnd(int(w & symMax))
+			w >>= symBits
+			used -= symBits
+			if used < symBits {
+				break
+			}
+		}
+	}
+	w |= fl << uint(used)
+	used += int(nbits)
+	e.endWindow = w
+	e.nendBits = used
+	e.nbitsTot += int(nbits)
+}
+
+func (e *rangeEncoder) tell() int {
+	return e.nbitsTot - ilog(e.rng)
+}
+
+func (e *rangeEncoder) tellFrac() int {
+	return tellFrac(e.nbitsTot, e.rng)
+}
+
+// shrink moves the raw bits to the end of a buffer of size bytes.
+func (e *rangeEncoder) shrink(size int) {
+	copy(e.buf[size-e.endOffs:size], e.buf[e.storage-e.endOffs:e.storage])
+	e.storage = size
+	e.buf = e.buf[:size]
+}
+
+// done flushes the encoder. The buffer holds the packet afterwards, with zeros between
+// the range coded data and the raw bits.
+func (e *rangeEncoder) done() {
+	l := codeBits - ilog(e.rng)
+	msk := uint32(codeTop-1) >> uint(l)
+	end := (e.val + msk) &^ msk
+	if end|msk >= e.val+e.rng {
+		l++
+		msk >>= 1
+		end = (e.val + msk) &^ msk
+	}
+	for l > 0 {
+		e.carryOut(int(end >> codeShift))
+		end = (end << symBits) & (codeTop - 1)
+		l -= symBits
+	}
+	if e.rem >= 0 || e.ext > 0 {
+		e.carryOut(0)
+	}
+	w := e.endWindow
+	used := e.nendBits
+	for used >= symBits {
+		e.writeByteAtEnd(int(w & symMax))
+		w >>= symBits
+		used -= symBits
+	}
+	if !e.err {
+		for i := e.offs; i < e.storage-e.endOffs; i++ {
+			e.buf[i] = 0
+		}
+		if used > 0 {
+			if e.endOffs >= e.storage {
+				e.err = true
+			} else {
+				l = -l
+				if e.offs+e.endOffs >= e.storage && l < used {
+					w &= 1<<uint(l) - 1
+					e.err = true
+				}
+				e.buf[e.storage-e.endOffs-1] |= byte(w)
+			}
+		}
+	}
+}
+
+// Laplace coding of the coarse band energies, from celt/laplace.c.
+
+const (
+	laplaceLogMinP = 0
+	laplaceMinP    = 1 << laplaceLogMinP
+	laplaceNMin    = 16
+)
+
+func laplaceFreq1(fs0, decay int) int {
+	ft := 32768 - laplaceMinP*(2*laplaceNMin) - fs0
+	return ft * (16384 - decay) >> 15
+}
+
+func (e *rangeEncoder) laplace(value *int, fs, decay int) {
+	val := *value
+	fl := 0
+	if val != 0 {
+		s := 0
+		if val < 0 {
+			s = -1
+		}
+		val = (val + s) ^ s
+		fl = fs
+		fs = laplaceFreq1(fs, decay)
+		i := 1
+		for ; fs > 0 && i < val; i++ {
+			fs *= 2
+			fl += fs + 2*laplaceMinP
+			fs = fs * decay >> 15
+		}
+		if fs == 0 {
+			ndiMax := (32768 - fl + laplaceMinP - 1) >> laplaceLogMinP
+			ndiMax = (ndiMax - s) >> 1
+			di := val - i
+			if di > ndiMax-1 {
+				di = ndiMax - 1
+			}
+			fl += (2*di + 1 + s) * laplaceMinP
+			fs = laplaceMinP
+			if 32768-fl < fs {
+				fs = 32768 - fl
+			}
+			*value = (i + di + s) ^ s
+		} else {
+			fs += laplaceMinP
+			fl += fs &^ s
+		}
+	}
+	e.encodeBin(uint32(fl), uint32(fl+fs), 15)
+}
+
+func (d *rangeDecoder) laplace(fs, decay int) int {
+	val := 0
+	fm := int(d.decodeBin(15))
+	fl := 0
+	if fm >= fs {
+		val++
+		fl = fs
+		fs = laplaceFreq1(fs, decay) + laplaceMinP
+		for fs > laplaceMinP && fm >= fl+2*fs {
+			fs *= 2
+			fl += fs
+			fs = (fs-2*laplaceMinP)*decay>>15 + laplaceMinP
+			val++
+		}
+		if fs <= laplaceMinP {
+			di := (fm - fl) >> (laplaceLogMinP + 1)
+			val += di
+			fl += 2 * di * laplaceMinP
+		}
+		if fm < fl+fs {
+			val = -val
+		} else {
+			fl += fs
+		}
+	}
+	fh := fl + fs
+	if fh > 32768 {
+		fh = 32768
+	}
+	d.update(uint32(fl), uint32(fh), 32768)
+	return val
+}
@@ -0,0 +1,91 @@
+package opus
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRangeCoderRoundTrip(t *testing.T) {
+	icdf := []uint8{200, 120, 40, 0}
+	rnd := rand.New(rand.NewSource(1))
+	type symbol struct {
+		kind, v, ft int
+	}
+	var syms []symbol
+	for i := 0; i < 2000; i++ {
+		switch k := rnd.Intn(6); k {
+		case 0:
+			ft := 2 + rnd.Intn(1000)
+			syms = append(syms, symbol{k, rnd.Intn(ft), ft})
+		case 1:
+			syms = append(syms, symbol{k, rnd.Intn(2), 1 + rnd.Intn(15)})
+		case 2:
+			syms = append(syms, symbol{k, rnd.Intn(4), 0})
+		case 3:
+			n := 1 + rnd.Intn(25)
+			syms = append(syms, symbol{k, rnd.Intn(1 << n), n})
+		case 4:
+			syms = append(syms, symbol{k, rnd.Intn(41) - 20, 0})
+		case 5:
+			ft := 2 + rnd.Intn(1<<20)
+			syms = append(syms, symbol{k, rnd.Intn(ft), ft})
+		}
+	}
+
+	buf := make([]byte, 10000)
+	var enc rangeEncoder
+	enc.init(buf)
+	for i, s := range syms {
+		switch s.kind {
+		case 0:
+			enc.encode(uint32(s.v), uint32(s.v+1), uint32(s.ft))
+		case 1:
+			enc.bitLogp(s.v == 1, uint(s.ft))
+		case 2:
+			enc.icdf(s.v, icdf, 8)
+		case 3:
+			enc.bits(uint32(s.v), uint(s.ft))
+		case 4:
+			v := s.v
+			enc.laplace(&v, 6000, 9000)
+			syms[i].v = v
+		case 5:
+			enc.uint(uint32(s.v), uint32(s.ft))
+		}
+	}
+	tell := enc.tell()
+	enc.done()
+	if enc.err {
+		t.Fatal("encoder error")
+	}
+
+	var dec rangeDecoder
+	dec.init(buf)
+	for i, s := range syms {
+		var got int
+		switch s.kind {
+		case 0:
+			v := dec.decode(uint32(s.ft))
+			dec.update(v, v+1, uint32(s.ft))
+			got = int(v)
+		case 1:
+			if dec.bitLogp(uint(s.ft)) {
+				got = 1
+			}
+		case 2:
+			got = dec.icdf(icdf, 8)
+		case 3:
+			got = int(dec.bits(uint(s.ft)))
+		case 4:
+			got = dec.laplace(6000, 9000)
+		case 5:
+			got = int(dec.uint(uint32(s.ft)))
+		}
+		if got != s.v {
+			t.Fatalf("symbol %d of kind %d = %d, want %d", i, s.kind, got, s.v)
+		}
+	}
+	if dec.tell() != tell {
+		t.Errorf("decoder tell = %d, encoder tell = %d", dec.tell(), tell)
+	}
+}
@@ -0,0 +1,332 @@
+package opus
+
+// Bit allocation between the bands, from celt/rate.c. The encoder and decoder run it
+// identically, except for the band skipping decisions, which the encoder makes and
+// codes.
+
+type allocation struct {
+	codedBands   int
+	intensity    int
+	dualStereo   bool
+	balance      int
+	pulses       [nbEBands]int
+	fineQuant    [nbEBands]int
+	finePriority [nbEBands]int
+}
+
+func interpBits2Pulses(a *allocation, start, end, skipStart int, bits1, bits2, thresh, caps *[nbEBands]int, total, skipRsv, intensityRsv, dualStereoRsv, channels, lm int, ec coder, prev, signalBandwidth int) {
+	allocFloor := channels << bitRes
+	stereo := 0
+	if channels > 1 {
+		stereo = 1
+	}
+	logM := lm << bitRes
+	lo, hi := 0, 1<<allocSteps
+	for i := 0; i < allocSteps; i++ {
+		mid := (lo + hi) >> 1
+		psum := 0
+		done := false
+		for j := end - 1; j >= start; j-- {
+			tmp := bits1[j] + mid*bits2[j]>>allocSteps
+			if tmp >= thresh[j] || done {
+				done = true
+				psum += imin(tmp, caps[j])
+			} else if tmp >= allocFloor {
+				psum += allocFloor
+			}
+		}
+		if psum > total {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	bits := &a.pulses
+	ebits := &a.fineQuant
+	psum := 0
+	done := false
+	for j := end - 1; j >= start; j-- {
+		tmp := bits1[j] + lo*bits2[j]>>allocSteps
+		if tmp < thresh[j] && !done {
+			if tmp >= allocFloor {
+				tmp = allocFloor
+			} else {
+				tmp = 0
+			}
+		} else {
+			done = true
+		}
+		tmp = imin(tmp, caps[j])
+		bits[j] = tmp
+		psum += tmp
+	}
+
+	// Decide which bands to skip, working backwards from the end.
+	codedBands := end
+	for ; ; codedBands-- {
+		j := codedBands - 1
+		// Never skip the first band, nor a band boosted by dynalloc.
+		if j <= skipStart {
+			total += skipRsv
+			break
+		}
+		// The left-over bits this band would get, including those of skipped bands.
+		left := total - psum
+		percoeff := left / (eBands[codedBands] - eBands[start])
+		left -= (eBands[codedBands] - eBands[start]) * percoeff
+		rem := imax(left-(eBands[j]-eBands[start]), 0)
+		bandWidth := eBands[codedBands] - eBands[j]
+		bandBits := bits[j] + percoeff*bandWidth + rem
+		// Only code a skip decision above the threshold for this band; otherwise it
+		// is force-skipped.
+		if bandBits >= imax(thresh[j], allocFloor+1<<bitRes) {
+			skip := 0
+			if ec.enc != nil {
+				depthThreshold := 0
+				if codedBands > 17 {
+					depthThreshold = 9
+					if j < prev {
+						depthThreshold = 7
+					}
+				}
+				if codedBands <= start+2 || (bandBits > (depthThreshold*bandWidth<<uint(lm)<<bitRes)>>4 && j <= signalBandwidth) {
+					skip = 1
+				}
+			}
+			if ec.bitLogp(skip, 1) == 1 {
+				break
+			}
+			// A bit was used to skip this band.
+			psum += 1 << bitRes
+			bandBits -= 1 << bitRes
+		}
+		// Reclaim the bits originally allocated to this band.
+		psum -= bits[j] + intensityRsv
+		if intensityRsv > 0 {
+			intensityRsv = log2FracTable[j-start]
+		}
+		psum += intensityRsv
+		if bandBits >= allocFloor {
+			// Enough for a fine energy bit per channel.
+			psum += allocFloor
+			bits[j] = allocFloor
+		} else {
+			bits[j] = 0
+		}
+	}
+
+	// Code the intensity and dual stereo parameters.
+	if intensityRsv > 0 {
+		if ec.enc != nil {
+			a.intensity = imin(a.intensity, codedBands)
+		}
+		a.intensity = start + ec.uint(a.intensity-start, codedBands+1-start)
+	} else {
+		a.intensity = 0
+	}
+	if a.intensity <= start {
+		total += dualStereoRsv
+		dualStereoRsv = 0
+	}
+	if dualStereoRsv > 0 {
+		v := 0
+		if a.dualStereo {
+			v = 1
+		}
+		a.dualStereo = ec.bitLogp(v, 1) == 1
+	} else {
+		a.dualStereo = false
+	}
+
+	// Allocate the remaining bits.
+	left := total - psum
+	percoeff := left / (eBands[codedBands] - eBands[start])
+	left -= (eBands[codedBands] - eBands[start]) * percoeff
+	for j := start; j < codedBands; j++ {
+		bits[j] += percoeff * (eBands[j+1] - eBands[j])
+	}
+	for j := start; j < codedBands; j++ {
+		tmp := imin(left, eBands[j+1]-eBands[j])
+		bits[j] += tmp
+		left -= tmp
+	}
+
+	balance := 0
+	j := start
+	for ; j < codedBands; j++ {
+		n0 := eBands[j+1] - eBands[j]
+		n := n0 << uint(lm)
+		bit := bits[j] + balance
+		var excess int
+		if n > 1 {
+			excess = imax(bit-caps[j], 0)
+			bits[j] = bit - excess
+
+			// Compensate for the extra degree of freedom in stereo.
+			den := channels * n
+			if channels == 2 && n > 2 && !a.dualStereo && j < a.intensity {
+				den++
+			}
+			nclogn := den * (logN[j] + logM)
+			// Offset the fine bits by log2(N)/2 + FINE_OFFSET compared to their
+			// "fair share" of total/N.
+			offset := nclogn>>1 - den*fineOffset
+			if n == 2 {
+				offset += den << bitRes >> 2
+			}
+			// Changing the offset for allocating the second and third fine bit.
+			if bits[j]+offset < den*2<<bitRes {
+				offset += nclogn >> 2
+			} else if bits[j]+offset < den*3<<bitRes {
+				offset += nclogn >> 3
+			}
+			ebits[j] = imax(0, bits[j]+offset+den<<(bitRes-1))
+			ebits[j] = ebits[j] / den >> bitRes
+			// Make sure not to bust.
+			if channels*ebits[j] > bits[j]>>bitRes {
+				ebits[j] = bits[j] >> uint(stereo) >> bitRes
+			}
+			ebits[j] = imin(ebits[j], maxFineBits)
+			// Rounded down or capped bands are candidates for the final fine pass.
+			a.finePriority[j] = b2i(ebits[j]*(den<<bitRes) >= bits[j]+offset)
+			// The rest of the bits go to PVQ.
+			bits[j] -= channels * ebits[j] << bitRes
+		} else {
+			// For N=1, all bits go to fine energy except for a sign bit.
+			excess = imax(0, bit-channels<<bitRes)
+			bits[j] = bit - excess
+			ebits[j] = 0
+			a.finePriority[j] = 1
+		}
+		// Fine energy can't use the re-balancing of quantAllBands, so do it here.
+		if excess > 0 {
+			extraFine := imin(excess>>uint(stereo+bitRes), maxFineBits-ebits[j])
+			ebits[j] += extraFine
+			extraBits := extraFine * channels << bitRes
+			a.finePriority[j] = b2i(extraBits >= excess-balance)
+			excess -= extraBits
+		}
+		balance = excess
+	}
+	a.balance = balance
+
+	// The skipped bands use all their bits for fine energy.
+	for ; j < end; j++ {
+		ebits[j] = bits[j] >> uint(stereo) >> bitRes
+		bits[j] = 0
+		a.finePriority[j] = b2i(ebits[j] < 1)
+	}
+	a.codedBands = codedBands
+}
+
+// computeAllocation splits total bits, in 1/8 bits, between the bands. offsets are the
+// dynalloc boosts and trim the allocation tilt.
+func computeAllocation(a *allocation, start, end int, offsets, caps *[nbEBands]int, trim, total, channels, lm int, ec coder, prev, signalBandwidth int) {
+	total = imax(total, 0)
+	skipStart := start
+	// Reserve a bit to signal the end of manually skipped bands.
+	skipRsv := 0
+	if total >= 1<<bitRes {
+		skipRsv = 1 << bitRes
+	}
+	total -= skipRsv
+	// Reserve bits for the intensity and dual stereo parameters.
+	intensityRsv, dualStereoRsv := 0, 0
+	if channels == 2 {
+		intensityRsv = log2FracTable[end-start]
+		if intensityRsv > total {
+			intensityRsv = 0
+		} else {
+			total -= intensityRsv
+			if total >= 1<<bitRes {
+				dualStereoRsv = 1 << bitRes
+			}
+			total -= dualStereoRsv
+		}
+	}
+	var bits1, bits2, thresh, trimOffset [nbEBands]int
+	for j := start; j < end; j++ {
+		n := eBands[j+1] - eBands[j]
+		// Below this threshold, no PVQ bits are allocated.
+		thresh[j] = imax(channels<<bitRes, (3*n<<uint(lm)<<bitRes)>>4)
+		// The tilt of the allocation curve.
+		trimOffset[j] = channels * n * (trim - 5 - lm) * (end - j - 1) * (1 << uint(lm+bitRes)) >> 6
+		// Single-coefficient bands benefit more from a coarse value per coefficient.
+		if n<<uint(lm) == 1 {
+			trimOffset[j] -= channels << bitRes
+		}
+	}
+	lo, hi := 1, nbAllocVectors-1
+	for lo <= hi {
+		done := false
+		psum := 0
+		mid := (lo + hi) >> 1
+		for j := end - 1; j >= start; j-- {
+			n := eBands[j+1] - eBands[j]
+			bitsj := channels * n * int(bandAllocation[mid*nbEBands+j]) << uint(lm) >> 2
+			if bitsj > 0 {
+				bitsj = imax(0, bitsj+trimOffset[j])
+			}
+			bitsj += offsets[j]
+			if bitsj >= thresh[j] || done {
+				done = true
+				psum += imin(bitsj, caps[j])
+			} else if bitsj >= channels<<bitRes {
+				psum += channels << bitRes
+			}
+		}
+		if psum > total {
+			hi = mid - 1
+		} else {
+			lo = mid + 1
+		}
+	}
+	hi = lo
+	lo--
+	for j := start; j < end; j++ {
+		n := eBands[j+1] - eBands[j]
+		bits1j := channels * n * int(bandAllocation[lo*nbEBands+j]) << uint(lm) >> 2
+		bits2j := caps[j]
+		if hi < nbAllocVectors {
+			bits2j = channels * n * int(bandAllocation[hi*nbEBands+j]) << uint(lm) >> 2
+		}
+		if bits1j > 0 {
+			bits1j = imax(0, bits1j+trimOffset[j])
+		}
+		if bits2j > 0 {
+			bits2j = imax(0, bits2j+trimOffset[j])
+		}
+		if lo > 0 {
+			bits1j += offsets[j]
+		}
+		bits2j += offsets[j]
+		if offsets[j] > 0 {
+			skipStart = j
+		}
+		bits2j = imax(0, bits2j-bits1j)
+		bits1[j] = bits1j
+		bits2[j] = bits2j
+	}
+	interpBits2Pulses(a, start, end, skipStart, &bits1, &bits2, &thresh, caps, total, skipRsv, intensityRsv, dualStereoRsv, channels, lm, ec, prev, signalBandwidth)
+}
+
+func imin(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func imax(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
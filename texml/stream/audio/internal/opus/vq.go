@@ -0,0 +1,206 @@
+package opus
+
+import "math"
+
+// Pyramid vector quantization of the normalized bands, from celt/vq.c.
+
+func lcgRand(seed uint32) uint32 {
+	return 1664525*seed + 1013904223
+}
+
+func expRotation1(x []float32, length, stride int, c, s float32) {
+	ms := -s
+	for i := 0; i < length-stride; i++ {
+		x1, x2 := x[i], x[i+stride]
+		x[i+stride] = c*x2 + s*x1
+		x[i] = c*x1 + ms*x2
+	}
+	for i := length - 2*stride - 1; i >= 0; i-- {
+		x1, x2 := x[i], x[i+stride]
+		x[i+stride] = c*x2 + s*x1
+		x[i] = c*x1 + ms*x2
+	}
+}
+
+var spreadFactor = [3]int{15, 10, 5}
+
+// expRotation spreads the pulses of x, or undoes it when dir is negative.
+func expRotation(x []float32, length, dir, stride, k, spread int) {
+	if 2*k >= length || spread == spreadNone {
+		return
+	}
+	factor := spreadFactor[spread-1]
+	gain := float64(length) / float64(length+factor*k)
+	theta := 0.5 * gain * gain
+	c := float32(math.Cos(0.5 * math.Pi * theta))
+	s := float32(math.Cos(0.5 * math.Pi * (1 - theta)))
+
+	stride2 := 0
+	if length >= 8*stride {
+		stride2 = 1
+		// Approximately sqrt(length/stride), with rounding.
+		for (stride2*stride2+stride2)*stride+stride>>2 < length {
+			stride2++
+		}
+	}
+	length /= stride
+	for i := 0; i < stride; i++ {
+		xi := x[i*length : (i+1)*length]
+		if dir < 0 {
+			if stride2 != 0 {
+				expRotation1(xi, length, stride2, s, c)
+			}
+			expRotation1(xi, length, 1, c, s)
+		} else {
+			expRotation1(xi, length, 1, c, -s)
+			if stride2 != 0 {
+				expRotation1(xi, length, stride2, s, -c)
+			}
+		}
+	}
+}
+
+func normaliseResidual(iy []int, x []float32, ryy, gain float32) {
+	g := gain / float32(math.Sqrt(float64(ryy)))
+	for i := range iy {
+		x[i] = g * float32(iy[i])
+	}
+}
+
+func extractCollapseMask(iy []int, b int) uint {
+	if b <= 1 {
+		return 1
+	}
+	n0 := len(iy) / b
+	var mask uint
+	for i := 0; i < b; i++ {
+		for _, v := range iy[i*n0 : (i+1)*n0] {
+			if v != 0 {
+				mask |= 1 << uint(i)
+				break
+			}
+		}
+	}
+	return mask
+}
+
+// pvqSearch returns the vector of k pulses closest in direction to x, and its squared
+// norm.
+func pvqSearch(x []float32, iy []int, k int) float32 {
+	n := len(x)
+	y := make([]float32, n)
+	signx := make([]bool, n)
+	ax := make([]float32, n)
+	for j, v := range x {
+		signx[j] = v < 0
+		ax[j] = float32(math.Abs(float64(v)))
+		iy[j] = 0
+	}
+	var xy, yy float32
+	pulsesLeft := k
+
+	// Pre-search by projecting on the pyramid.
+	if k > n>>1 {
+		var sum float32
+		for _, v := range ax {
+			sum += v
+		}
+		if !(sum > 1e-15 && sum < 64) {
+			ax[0] = 1
+			for j := 1; j < n; j++ {
+				ax[j] = 0
+			}
+			sum = 1
+		}
+		rcp := (float32(k) + 0.8) / sum
+		for j := range ax {
+			iy[j] = int(math.Floor(float64(rcp * ax[j])))
+			y[j] = float32(iy[j])
+			yy += y[j] * y[j]
+			xy += ax[j] * y[j]
+			y[j] *= 2
+			pulsesLeft -= iy[j]
+		}
+	}
+	if pulsesLeft > n+3 {
+		tmp := float32(pulsesLeft)
+		yy += tmp*tmp + tmp*y[0]
+		iy[0] += pulsesLeft
+		pulsesLeft = 0
+	}
+	for i := 0; i < pulsesLeft; i++ {
+		yy++
+		bestID := 0
+		rxy := xy + ax[0]
+		bestNum := rxy * rxy
+		bestDen := yy + y[0]
+		for j := 1; j < n; j++ {
+			rxy := xy + ax[j]
+			ryy := yy + y[j]
+			rxy *= rxy
+			if bestDen*rxy > ryy*bestNum {
+				bestDen, bestNum, bestID = ryy, rxy, j
+			}
+		}
+		xy += ax[bestID]
+		yy += y[bestID]
+		y[bestID] += 2
+		iy[bestID]++
+	}
+	for j := range iy {
+		if signx[j] {
+			iy[j] = -iy[j]
+		}
+	}
+	return yy
+}
+
+func algQuant(x []float32, k, spread, b int, enc *rangeEncoder, gain float32, resynth bool) uint {
+	n := len(x)
+	iy := make([]int, n)
+	expRotation(x, n, 1, b, k, spread)
+	yy := pvqSearch(x, iy, k)
+	encodePulses(iy, k, enc)
+	if resynth {
+		normaliseResidual(iy, x, yy, gain)
+		expRotation(x, n, -1, b, k, spread)
+	}
+	return extractCollapseMask(iy, b)
+}
+
+func algUnquant(x []float32, k, spread, b int, dec *rangeDecoder, gain float32) uint {
+	n := len(x)
+	iy := make([]int, n)
+	ryy := decodePulses(iy, k, dec)
+	normaliseResidual(iy, x, ryy, gain)
+	expRotation(x, n, -1, b, k, spread)
+	return extractCollapseMask(iy, b)
+}
+
+func renormaliseVector(x []float32, gain float32) {
+	e := float32(1e-15)
+	for _, v := range x {
+		e += v * v
+	}
+	g := gain / float32(math.Sqrt(float64(e)))
+	for i := range x {
+		x[i] *= g
+	}
+}
+
+// stereoItheta returns the angle, in Q14 of a quarter turn, between the mid and side
+// of x and y, or between x and y themselves.
+func stereoItheta(x, y []float32, stereo bool) int {
+	emid, eside := 1e-15, 1e-15
+	for i := range x {
+		if stereo {
+			m, s := float64(x[i]+y[i]), float64(x[i]-y[i])
+			emid += m * m
+			eside += s * s
+		} else {
+			emid += float64(x[i]) * float64(x[i])
+			eside += float64(y[i]) * float64(y[i])
+		}
+	}
+	return int(math.Floor(0.5 + 16384*0.63662*math.Atan2(math.Sqrt(eside), math.Sqrt(emid))))
+}
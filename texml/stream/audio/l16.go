@@ -0,0 +1,41 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// l16 is 16-bit linear PCM in network byte order, as defined for RTP by RFC 3551.
+type l16 struct {
+	sampleRate int
+}
+
+func newL16(sampleRate int) (Codec, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("audio: L16 needs a sample rate")
+	}
+	return l16{sampleRate}, nil
+}
+
+func (c l16) Decode(payload []byte) ([]int16, error) {
+	if len(payload)%2 != 0 {
+		return nil, fmt.Errorf("audio: L16 payload of odd length %d", len(payload))
+	}
+	samples := make([]int16, len(payload)/2)
+	for i := range samples {
+		samples[i] = int16(binary.BigEndian.Uint16(payload[2*i:]))
+	}
+	return samples, nil
+}
+
+func (c l16) Encode(samples []int16) ([]byte, error) {
+	payload := make([]byte, 2*len(samples))
+	for i, s := range samples {
+		binary.BigEndian.PutUint16(payload[2*i:], uint16(s))
+	}
+	return payload, nil
+}
+
+func (c l16) SampleRate() int {
+	return c.sampleRate
+}
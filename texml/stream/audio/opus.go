@@ -0,0 +1,53 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/andersryanc/telnyx-go/texml/stream/audio/internal/opus"
+)
+
+// opusCodec is Opus (RFC 6716) in mono. Payloads in the CELT mode decode at any of the
+// Opus rates; those in the SILK and hybrid modes, which libopus uses for most
+// wideband speech, are refused with an error wrapping ErrUnsupportedCodec. Encoding
+// produces CELT packets, so the duration of samples must be a multiple of 2.5 ms.
+type opusCodec struct {
+	dec        *opus.Decoder
+	enc        *opus.Encoder
+	sampleRate int
+}
+
+func newOpus(sampleRate int) (Codec, error) {
+	if sampleRate == 0 {
+		sampleRate = 48000
+	}
+	dec, err := opus.NewDecoder(sampleRate)
+	if err != nil {
+		return nil, fmt.Errorf("audio: OPUS at %d Hz: %w", sampleRate, err)
+	}
+	enc, _ := opus.NewEncoder(sampleRate)
+	return &opusCodec{dec: dec, enc: enc, sampleRate: sampleRate}, nil
+}
+
+func (c *opusCodec) Decode(payload []byte) ([]int16, error) {
+	samples, err := c.dec.Decode(payload)
+	if errors.Is(err, opus.ErrUnsupportedMode) {
+		return nil, fmt.Errorf("%w: OPUS payload in the SILK or hybrid mode", ErrUnsupportedCodec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("audio: %w", err)
+	}
+	return samples, nil
+}
+
+func (c *opusCodec) Encode(samples []int16) ([]byte, error) {
+	payload, err := c.enc.Encode(samples)
+	if err != nil {
+		return nil, fmt.Errorf("audio: %w", err)
+	}
+	return payload, nil
+}
+
+func (c *opusCodec) SampleRate() int {
+	return c.sampleRate
+}
@@ -0,0 +1,120 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+)
+
+// Resampler converts PCM between two sample rates whose ratio is an integer, such as
+// 8 kHz and 16 kHz. It keeps the filter history between calls, so a stream is
+// resampled one chunk at a time without clicks at chunk boundaries.
+type Resampler struct {
+	from, to int
+	factor   int
+	up       bool
+	taps     []float64
+	history  []float64
+	// phase is the position, in input samples, of the next output sample when
+	// downsampling.
+	phase int
+}
+
+// NewResampler returns a Resampler from one sample rate to the other. One of the rates
+// must be an integer multiple of the other.
+func NewResampler(from, to int) (*Resampler, error) {
+	if from <= 0 || to <= 0 {
+		return nil, fmt.Errorf("audio: invalid sample rates %d and %d", from, to)
+	}
+	r := &Resampler{from: from, to: to, factor: 1}
+	switch {
+	case from == to:
+		return r, nil
+	case to%from == 0:
+		r.factor, r.up = to/from, true
+	case from%to == 0:
+		r.factor = from / to
+	default:
+		return nil, fmt.Errorf("audio: cannot resample from %d Hz to %d Hz", from, to)
+	}
+	r.taps = lowPass(r.factor)
+	r.history = make([]float64, len(r.taps)-1)
+	return r, nil
+}
+
+// lowPass returns a Blackman windowed-sinc filter that keeps the band below the
+// Nyquist frequency of the lower rate, at the higher rate.
+func lowPass(factor int) []float64 {
+	n := 16*factor + 1
+	cutoff := 0.5 / float64(factor)
+	taps := make([]float64, n)
+	m := float64(n - 1)
+	sum := 0.0
+	for i := range taps {
+		x := float64(i) - m/2
+		sinc := 2 * cutoff
+		if x != 0 {
+			sinc = math.Sin(2*math.Pi*cutoff*x) / (math.Pi * x)
+		}
+		window := 0.42 - 0.5*math.Cos(2*math.Pi*float64(i)/m) + 0.08*math.Cos(4*math.Pi*float64(i)/m)
+		taps[i] = sinc * window
+		sum += taps[i]
+	}
+	for i := range taps {
+		taps[i] /= sum
+	}
+	return taps
+}
+
+// Resample returns samples converted to the target rate.
+func (r *Resampler) Resample(samples []int16) []int16 {
+	if r.factor == 1 {
+		return append([]int16(nil), samples...)
+	}
+
+	// The filter runs at the higher rate. Upsampling stuffs factor-1 zeros after each
+	// input sample and scales the result back up by factor.
+	var in []float64
+	gain := 1.0
+	if r.up {
+		in = make([]float64, len(samples)*r.factor)
+		for i, s := range samples {
+			in[i*r.factor] = float64(s)
+		}
+		gain = float64(r.factor)
+	} else {
+		in = make([]float64, len(samples))
+		for i, s := range samples {
+			in[i] = float64(s)
+		}
+	}
+
+	buf := append(r.history, in...)
+	var out []int16
+	for i := 0; i < len(in); i++ {
+		if !r.up {
+			if r.phase != 0 {
+				r.phase--
+				continue
+			}
+			r.phase = r.factor - 1
+		}
+		acc := 0.0
+		for k, t := range r.taps {
+			acc += t * buf[i+len(r.taps)-1-k]
+		}
+		out = append(out, clip16(acc*gain))
+	}
+	r.history = append(r.history[:0], buf[len(buf)-len(r.history):]...)
+	return out
+}
+
+func clip16(v float64) int16 {
+	v = math.Round(v)
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if v < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(v)
+}
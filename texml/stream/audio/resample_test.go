@@ -0,0 +1,88 @@
+package audio
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func rms(samples []int16) float64 {
+	sum := 0.0
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+func TestResampler(t *testing.T) {
+	for _, tt := range []struct{ from, to int }{{8000, 16000}, {16000, 8000}, {8000, 48000}, {48000, 8000}} {
+		r, err := NewResampler(tt.from, tt.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		in := tone(1000, tt.from, tt.from/5, 8000)
+		out := r.Resample(in)
+		if want := len(in) * tt.to / tt.from; len(out) != want {
+			t.Errorf("%d to %d: %d samples, want %d", tt.from, tt.to, len(out), want)
+			continue
+		}
+		// The tone keeps its frequency and level once past the filter delay.
+		want := tone(1000, tt.to, len(out), 8000)
+		skip := len(out) / 4
+		if r := snr(want[:len(out)-skip], out[skip:], skip); r < 25 {
+			t.Errorf("%d to %d: SNR = %.1f dB, want at least 25", tt.from, tt.to, r)
+		}
+	}
+}
+
+func TestResamplerAntiAliasing(t *testing.T) {
+	r, _ := NewResampler(16000, 8000)
+	// 6 kHz is above the 4 kHz Nyquist frequency of the target rate and would alias to
+	// 2 kHz without the filter.
+	out := r.Resample(tone(6000, 16000, 3200, 8000))
+	if level := rms(out[100:]); level > 8000/math.Sqrt2/100 {
+		t.Errorf("6 kHz tone downsampled to 8 kHz has rms %.0f, want it attenuated by 40 dB", level)
+	}
+}
+
+func TestResamplerChunked(t *testing.T) {
+	in := tone(440, 8000, 1600, 8000)
+	for _, tt := range []struct{ from, to int }{{8000, 16000}, {16000, 8000}} {
+		whole, _ := NewResampler(tt.from, tt.to)
+		chunked, _ := NewResampler(tt.from, tt.to)
+		want := whole.Resample(in)
+		var got []int16
+		// 20 ms chunks, then odd sizes that split the downsampling phase.
+		for i, n := 0, 160; i < len(in); i, n = i+n, n%7+3 {
+			end := i + n
+			if end > len(in) {
+				end = len(in)
+			}
+			got = append(got, chunked.Resample(in[i:end])...)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%d to %d: resampling in chunks differs from resampling at once", tt.from, tt.to)
+		}
+	}
+}
+
+func TestResamplerSameRate(t *testing.T) {
+	r, err := NewResampler(8000, 8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := []int16{1, 2, 3}
+	out := r.Resample(in)
+	out[0] = 9
+	if !reflect.DeepEqual(in, []int16{1, 2, 3}) {
+		t.Error("Resample returned the input slice")
+	}
+}
+
+func TestNewResamplerErrors(t *testing.T) {
+	for _, tt := range []struct{ from, to int }{{0, 8000}, {8000, -1}, {8000, 12000}, {44100, 16000}} {
+		if _, err := NewResampler(tt.from, tt.to); err == nil {
+			t.Errorf("NewResampler(%d, %d) succeeded", tt.from, tt.to)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+// vadFrame is the length of the frames a VAD classifies.
+const vadFrame = 20 * time.Millisecond
+
+// VoiceActivity is a change of state detected by a VAD.
+type VoiceActivity struct {
+	// Speaking is true when speech starts and false when it ends.
+	Speaking bool
+	// Offset is the position of the change from the start of the audio given to the
+	// VAD.
+	Offset time.Duration
+}
+
+// VAD is an energy based voice activity detector. It classifies 20 ms frames as
+// speech when they are louder than both MinLevel and the tracked noise floor by
+// Threshold, and reports speech once it has lasted StartDuration and its end once
+// silence has lasted EndDuration. The fields can be tuned before the first call to
+// Process.
+type VAD struct {
+	// Threshold is how far, in dB, speech must be above the noise floor.
+	Threshold float64
+	// MinLevel is the lowest level, in dBFS, taken as speech.
+	MinLevel float64
+	// StartDuration is how long speech must last to be reported, which ignores clicks
+	// and short noises.
+	StartDuration time.Duration
+	// EndDuration is how long silence must last to end speech, which bridges the pauses
+	// between words.
+	EndDuration time.Duration
+
+	frameSize int
+	frame     []int16
+	noise     float64
+	speaking  bool
+	// run counts the consecutive frames that disagree with speaking.
+	run      int
+	runStart int64
+	// pos is the number of samples processed so far.
+	pos        int64
+	sampleRate int
+}
+
+// NewVAD returns a VAD for audio at sampleRate, with defaults suited to telephone
+// speech.
+func NewVAD(sampleRate int) *VAD {
+	return &VAD{
+		Threshold:     10,
+		MinLevel:      -45,
+		StartDuration: 60 * time.Millisecond,
+		EndDuration:   600 * time.Millisecond,
+		frameSize:     sampleRate * int(vadFrame/time.Millisecond) / 1000,
+		noise:         -70,
+		sampleRate:    sampleRate,
+	}
+}
+
+// SampleRate returns the sample rate of the audio the VAD analyzes.
+func (v *VAD) SampleRate() int {
+	return v.sampleRate
+}
+
+// Speaking reports whether speech is in progress.
+func (v *VAD) Speaking() bool {
+	return v.speaking
+}
+
+// Process analyzes the next samples of the audio and returns the changes of state they
+// complete.
+func (v *VAD) Process(samples []int16) []VoiceActivity {
+	var changes []VoiceActivity
+	for len(samples) > 0 {
+		n := v.frameSize - len(v.frame)
+		if n > len(samples) {
+			n = len(samples)
+		}
+		v.frame = append(v.frame, samples[:n]...)
+		samples = samples[n:]
+		if len(v.frame) < v.frameSize {
+			break
+		}
+		if change, ok := v.classify(level(v.frame)); ok {
+			changes = append(changes, change)
+		}
+		v.pos += int64(len(v.frame))
+		v.frame = v.frame[:0]
+	}
+	return changes
+}
+
+func (v *VAD) classify(db float64) (VoiceActivity, bool) {
+	speech := db > v.MinLevel && db > v.noise+v.Threshold
+	if speech {
+		// Follow a rising background slowly, about 1 dB per second.
+		v.noise += 0.02
+	} else {
+		v.noise = 0.95*v.noise + 0.05*db
+		if db < v.noise {
+			v.noise = db
+		}
+	}
+
+	if speech == v.speaking {
+		v.run = 0
+		return VoiceActivity{}, false
+	}
+	if v.run == 0 {
+		v.runStart = v.pos
+	}
+	v.run++
+
+	needed := v.StartDuration
+	if v.speaking {
+		needed = v.EndDuration
+	}
+	if time.Duration(v.run)*vadFrame < needed {
+		return VoiceActivity{}, false
+	}
+	v.speaking = speech
+	v.run = 0
+	offset := time.Duration(v.runStart) * time.Second / time.Duration(v.sampleRate)
+	return VoiceActivity{Speaking: speech, Offset: offset}, true
+}
+
+// level returns the RMS level of samples in dBFS.
+func level(samples []int16) float64 {
+	sum := 0.0
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	rms := math.Sqrt(sum / float64(len(samples)))
+	if rms < 1 {
+		rms = 1
+	}
+	return 20 * math.Log10(rms/32768)
+}
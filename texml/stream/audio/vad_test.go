@@ -0,0 +1,85 @@
+package audio
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// noise returns n samples of white noise of amplitude amp.
+func noise(n int, amp float64, seed int64) []int16 {
+	rnd := rand.New(rand.NewSource(seed))
+	samples := make([]int16, n)
+	for i := range samples {
+		samples[i] = int16(amp * (2*rnd.Float64() - 1))
+	}
+	return samples
+}
+
+func concat(parts ...[]int16) []int16 {
+	var samples []int16
+	for _, p := range parts {
+		samples = append(samples, p...)
+	}
+	return samples
+}
+
+func TestVAD(t *testing.T) {
+	v := NewVAD(8000)
+	audio := concat(
+		noise(8000, 30, 1),
+		tone(300, 8000, 8000, 3000),
+		noise(8000, 30, 2),
+	)
+
+	var changes []VoiceActivity
+	// Feed the audio in chunks that do not line up with the 20 ms frames.
+	for i := 0; i < len(audio); i += 130 {
+		end := i + 130
+		if end > len(audio) {
+			end = len(audio)
+		}
+		changes = append(changes, v.Process(audio[i:end])...)
+	}
+	want := []VoiceActivity{{true, time.Second}, {false, 2 * time.Second}}
+	if len(changes) != len(want) || changes[0] != want[0] || changes[1] != want[1] {
+		t.Fatalf("changes = %+v, want %+v", changes, want)
+	}
+	if v.Speaking() {
+		t.Error("Speaking() after the speech ended")
+	}
+}
+
+func TestVADIgnoresShortSounds(t *testing.T) {
+	v := NewVAD(16000)
+	if v.SampleRate() != 16000 {
+		t.Errorf("SampleRate() = %d", v.SampleRate())
+	}
+	// A 40 ms click is shorter than StartDuration.
+	if changes := v.Process(concat(noise(16000, 30, 1), tone(300, 16000, 640, 10000), noise(16000, 30, 2))); len(changes) != 0 {
+		t.Errorf("changes = %+v, want none", changes)
+	}
+}
+
+func TestVADBridgesPauses(t *testing.T) {
+	v := NewVAD(8000)
+	speech := tone(300, 8000, 4000, 3000)
+	// A 300 ms pause between words is shorter than EndDuration.
+	changes := v.Process(concat(noise(4000, 30, 1), speech, noise(2400, 30, 2), speech))
+	if len(changes) != 1 || !changes[0].Speaking || !v.Speaking() {
+		t.Errorf("changes = %+v, want a single start", changes)
+	}
+}
+
+func TestVADNoiseFloor(t *testing.T) {
+	v := NewVAD(8000)
+	// A hum louder than MinLevel but within Threshold of the background noise is not
+	// speech.
+	changes := v.Process(concat(noise(8000, 200, 1), tone(300, 8000, 8000, 300)))
+	if len(changes) != 0 {
+		t.Errorf("changes = %+v, want none", changes)
+	}
+	if changes := v.Process(tone(300, 8000, 8000, 3000)); len(changes) != 1 || !changes[0].Speaking {
+		t.Errorf("changes = %+v, want speech above the noise", changes)
+	}
+}
@@ -0,0 +1,82 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const wavHeaderSize = 44
+
+// WAVWriter writes 16-bit PCM to a WAV file. When the underlying writer is an
+// io.WriteSeeker, such as an *os.File, Close fills in the sizes in the header;
+// otherwise they are left at their maximum, which players read as "until the end of
+// the file".
+type WAVWriter struct {
+	w          io.Writer
+	sampleRate int
+	channels   int
+	size       int64
+	err        error
+}
+
+// NewWAVWriter writes a WAV header for sampleRate and channels to w and returns a
+// WAVWriter for the samples that follow.
+func NewWAVWriter(w io.Writer, sampleRate, channels int) (*WAVWriter, error) {
+	ww := &WAVWriter{w: w, sampleRate: sampleRate, channels: channels}
+	if _, err := w.Write(ww.header(0xffffffff - wavHeaderSize + 8)); err != nil {
+		return nil, err
+	}
+	return ww, nil
+}
+
+func (w *WAVWriter) header(dataSize int64) []byte {
+	blockAlign := 2 * w.channels
+	h := make([]byte, wavHeaderSize)
+	copy(h[0:], "RIFF")
+	binary.LittleEndian.PutUint32(h[4:], uint32(dataSize+wavHeaderSize-8))
+	copy(h[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(h[16:], 16)
+	binary.LittleEndian.PutUint16(h[20:], 1) // PCM
+	binary.LittleEndian.PutUint16(h[22:], uint16(w.channels))
+	binary.LittleEndian.PutUint32(h[24:], uint32(w.sampleRate))
+	binary.LittleEndian.PutUint32(h[28:], uint32(w.sampleRate*blockAlign))
+	binary.LittleEndian.PutUint16(h[32:], uint16(blockAlign))
+	binary.LittleEndian.PutUint16(h[34:], 16)
+	copy(h[36:], "data")
+	binary.LittleEndian.PutUint32(h[40:], uint32(dataSize))
+	return h
+}
+
+// WriteSamples writes samples, interleaved when the file has several channels.
+func (w *WAVWriter) WriteSamples(samples []int16) error {
+	if w.err != nil {
+		return w.err
+	}
+	buf := make([]byte, 2*len(samples))
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(buf[2*i:], uint16(s))
+	}
+	n, err := w.w.Write(buf)
+	w.size += int64(n)
+	w.err = err
+	return err
+}
+
+// Close completes the header when possible, and closes the underlying writer if it is
+// an io.Closer.
+func (w *WAVWriter) Close() error {
+	err := w.err
+	if ws, ok := w.w.(io.WriteSeeker); ok && err == nil {
+		if _, err = ws.Seek(0, io.SeekStart); err == nil {
+			if _, err = ws.Write(w.header(w.size)); err == nil {
+				_, err = ws.Seek(0, io.SeekEnd)
+			}
+		}
+	}
+	if c, ok := w.w.(io.Closer); ok {
+		err = errors.Join(err, c.Close())
+	}
+	w.err = errors.New("audio: WAVWriter closed")
+	return err
+}
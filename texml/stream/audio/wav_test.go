@@ -0,0 +1,88 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func checkHeader(t *testing.T, h []byte, rate, channels int, dataSize uint32) {
+	t.Helper()
+	if string(h[0:4]) != "RIFF" || string(h[8:16]) != "WAVEfmt " || string(h[36:40]) != "data" {
+		t.Fatalf("header = %q", h[:wavHeaderSize])
+	}
+	le := binary.LittleEndian
+	for _, f := range []struct {
+		name      string
+		got, want uint32
+	}{
+		{"riff size", le.Uint32(h[4:]), dataSize + wavHeaderSize - 8},
+		{"format", uint32(le.Uint16(h[20:])), 1},
+		{"channels", uint32(le.Uint16(h[22:])), uint32(channels)},
+		{"sample rate", le.Uint32(h[24:]), uint32(rate)},
+		{"byte rate", le.Uint32(h[28:]), uint32(rate * channels * 2)},
+		{"block align", uint32(le.Uint16(h[32:])), uint32(channels * 2)},
+		{"bits per sample", uint32(le.Uint16(h[34:])), 16},
+		{"data size", le.Uint32(h[40:]), dataSize},
+	} {
+		if f.got != f.want {
+			t.Errorf("%s = %d, want %d", f.name, f.got, f.want)
+		}
+	}
+}
+
+func TestWAVWriterStream(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWAVWriter(&buf, 8000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteSamples([]int16{1, -1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// A bytes.Buffer cannot seek, so the sizes stay at their maximum.
+	checkHeader(t, buf.Bytes(), 8000, 1, 0xffffffff-wavHeaderSize+8)
+	if got := buf.Bytes()[wavHeaderSize:]; !bytes.Equal(got, []byte{0x01, 0x00, 0xff, 0xff}) {
+		t.Errorf("data = %x, want little endian samples", got)
+	}
+	if err := w.WriteSamples([]int16{1}); err == nil {
+		t.Error("WriteSamples succeeded after Close")
+	}
+}
+
+func TestWAVWriterFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "call.wav")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWAVWriter(f, 16000, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := w.WriteSamples(make([]int16, 320)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err == nil {
+		t.Error("Close did not close the file")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != wavHeaderSize+3*640 {
+		t.Fatalf("file is %d bytes", len(data))
+	}
+	checkHeader(t, data, 16000, 2, 3*640)
+}
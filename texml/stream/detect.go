@@ -0,0 +1,53 @@
+package stream
+
+// detector analyzes the decoded audio of the stream and returns the frames it
+// synthesizes, which are delivered on Frames after the media frame they came from.
+type detector interface {
+	detect(m *Media, samples []int16) []*Frame
+}
+
+func (s *Session) addDetector(d detector) error {
+	// Check the codec now rather than failing silently on the first media frame.
+	if _, err := s.NewCodec(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx.Err() != nil {
+		return ErrClosed
+	}
+	s.detectors = append(s.detectors, d)
+	return nil
+}
+
+// detect runs the detectors on f, if it is a Media frame.
+func (s *Session) detect(f *Frame) []*Frame {
+	if f.Event != EventMedia || f.Media == nil {
+		return nil
+	}
+	s.mu.Lock()
+	detectors := s.detectors
+	s.mu.Unlock()
+	if len(detectors) == 0 {
+		return nil
+	}
+
+	codec, ok := s.codecs[f.Media.Track]
+	if !ok {
+		var err error
+		if codec, err = s.NewCodec(); err != nil {
+			return nil
+		}
+		s.codecs[f.Media.Track] = codec
+	}
+	samples, err := codec.Decode(f.Media.Payload)
+	if err != nil {
+		return nil
+	}
+
+	var out []*Frame
+	for _, d := range detectors {
+		out = append(out, d.detect(f.Media, samples)...)
+	}
+	return out
+}
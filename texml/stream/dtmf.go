@@ -0,0 +1,50 @@
+package stream
+
+import "github.com/andersryanc/telnyx-go/texml/stream/audio"
+
+// DTMFOptions configures DetectDTMF.
+type DTMFOptions struct {
+	// Track is the track to listen to. It defaults to TrackInbound, the caller.
+	Track string
+	// Detector finds the digits. It defaults to audio.NewDTMFDetector at the sample
+	// rate of the stream codec, and must be used by a single session.
+	Detector *audio.DTMFDetector
+}
+
+// DetectDTMF finds the DTMF digits played in the audio of a track, such as those sent
+// in-band by PBXs that do not signal them, and delivers them on Frames as EventDTMF
+// frames with InBand set. Digits that Telnyx also signals arrive twice, once from
+// each source.
+func (s *Session) DetectDTMF(opts DTMFOptions) error {
+	if opts.Track == "" {
+		opts.Track = TrackInbound
+	}
+	if opts.Detector == nil {
+		codec, err := s.NewCodec()
+		if err != nil {
+			return err
+		}
+		opts.Detector = audio.NewDTMFDetector(codec.SampleRate())
+	}
+	return s.addDetector(&dtmfDetector{s: s, opts: opts})
+}
+
+type dtmfDetector struct {
+	s    *Session
+	opts DTMFOptions
+}
+
+func (d *dtmfDetector) detect(m *Media, samples []int16) []*Frame {
+	if m.Track != d.opts.Track {
+		return nil
+	}
+	var out []*Frame
+	for _, t := range d.opts.Detector.Process(samples) {
+		out = append(out, &Frame{
+			Event:    EventDTMF,
+			StreamID: d.s.StreamID,
+			DTMF:     &DTMF{Digit: t.Digit, InBand: true},
+		})
+	}
+	return out
+}
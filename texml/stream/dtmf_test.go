@@ -0,0 +1,46 @@
+package stream
+
+import "testing"
+
+// tonePair returns ms milliseconds of two sines at 8 kHz, such as a DTMF digit.
+func tonePair(low, high float64, ms int) []int16 {
+	a, b := sine(low, ms, 8000), sine(high, ms, 8000)
+	for i := range a {
+		a[i] += b[i]
+	}
+	return a
+}
+
+func TestDetectDTMF(t *testing.T) {
+	s, conn := connect(t, nil)
+	if err := s.DetectDTMF(DTMFOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 9, then 4 on the outbound track, which is not analyzed, then #.
+	sendSamples(t, conn, TrackInbound, 0, tonePair(852, 1477, 100))
+	sendSamples(t, conn, TrackOutbound, 0, tonePair(770, 1209, 100))
+	sendSamples(t, conn, TrackInbound, 100, sine(0, 60, 0))
+	sendSamples(t, conn, TrackInbound, 160, tonePair(941, 1477, 60))
+	sendSamples(t, conn, TrackInbound, 220, sine(0, 60, 0))
+	send(t, conn, &Frame{Event: EventDTMF, StreamID: "st1", DTMF: &DTMF{Digit: "#"}})
+	send(t, conn, &Frame{Event: EventStop, StreamID: "st1", Stop: &Stop{}})
+
+	var got []DTMF
+	for _, f := range synthesized(s, EventDTMF) {
+		if f.StreamID != "st1" {
+			t.Errorf("StreamID = %q", f.StreamID)
+		}
+		got = append(got, *f.DTMF)
+	}
+	// The digit Telnyx signals arrives along with the one found in the audio.
+	want := []DTMF{{Digit: "9", InBand: true}, {Digit: "#", InBand: true}, {Digit: "#"}}
+	if len(got) != len(want) {
+		t.Fatalf("digits = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("digit %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Event names of the frames exchanged on a stream.
+const (
+	EventConnected = "connected"
+	EventStart     = "start"
+	EventMedia     = "media"
+	EventDTMF      = "dtmf"
+	EventMark      = "mark"
+	EventStop      = "stop"
+	EventError     = "error"
+)
+
+// Frame is a JSON message of the media streaming protocol. Event tells which of the
+// payload fields is set.
+//
+// https://developers.telnyx.com/docs/voice/programmable-voice/media-streaming
+type Frame struct {
+	Event          string `json:"event"`
+	SequenceNumber string `json:"sequence_number,omitempty"`
+	StreamID       string `json:"stream_id,omitempty"`
+	// Version is set on connected frames.
+	Version string `json:"version,omitempty"`
+	Start   *Start `json:"start,omitempty"`
+	Media   *Media `json:"media,omitempty"`
+	DTMF    *DTMF  `json:"dtmf,omitempty"`
+	Mark    *Mark  `json:"mark,omitempty"`
+	Stop    *Stop  `json:"stop,omitempty"`
+	Error   *Error `json:"payload,omitempty"`
+	// Speech is set on the frames synthesized by DetectSpeech.
+	Speech *Speech `json:"speech,omitempty"`
+}
+
+// Start describes a stream. It is sent once, before any media.
+type Start struct {
+	UserID        string      `json:"user_id"`
+	AccountSid    string      `json:"account_sid,omitempty"`
+	CallSid       string      `json:"call_sid,omitempty"`
+	CallControlID string      `json:"call_control_id"`
+	CallSessionID string      `json:"call_session_id,omitempty"`
+	From          string      `json:"from,omitempty"`
+	To            string      `json:"to,omitempty"`
+	ClientState   string      `json:"client_state,omitempty"`
+	Tracks        []string    `json:"tracks,omitempty"`
+	MediaFormat   MediaFormat `json:"media_format"`
+	// CustomParameters holds the <Parameter> nouns of the <Stream>.
+	CustomParameters map[string]string `json:"custom_parameters,omitempty"`
+}
+
+// MediaFormat is the format of the audio in Media payloads.
+type MediaFormat struct {
+	// Encoding is the codec selected by the Codec attribute of <Stream>, e.g. "PCMU".
+	Encoding   string `json:"encoding"`
+	SampleRate int    `json:"sample_rate"`
+	Channels   int    `json:"channels"`
+}
+
+// Media is a chunk of audio.
+type Media struct {
+	// Track is "inbound" or "outbound".
+	Track string
+	// Chunk numbers the chunks of a track, starting at 1.
+	Chunk int
+	// Timestamp is the offset of the chunk from the start of the stream, in
+	// milliseconds.
+	Timestamp int64
+	// Payload is the RTP payload of the chunk, already decoded from base64.
+	Payload []byte
+}
+
+type media struct {
+	Track     string          `json:"track,omitempty"`
+	Chunk     json.RawMessage `json:"chunk,omitempty"`
+	Timestamp json.RawMessage `json:"timestamp,omitempty"`
+	Payload   []byte          `json:"payload"`
+}
+
+func (m *Media) UnmarshalJSON(data []byte) error {
+	var raw media
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	chunk, err := parseNumber(raw.Chunk)
+	if err != nil {
+		return fmt.Errorf("stream: media chunk: %w", err)
+	}
+	timestamp, err := parseNumber(raw.Timestamp)
+	if err != nil {
+		return fmt.Errorf("stream: media timestamp: %w", err)
+	}
+	*m = Media{Track: raw.Track, Chunk: int(chunk), Timestamp: timestamp, Payload: raw.Payload}
+	return nil
+}
+
+// MarshalJSON encodes the chunk and timestamp as strings, as Telnyx does. Both are left
+// out when zero, which is how media sent back to Telnyx is encoded.
+func (m Media) MarshalJSON() ([]byte, error) {
+	raw := media{Track: m.Track, Payload: m.Payload}
+	if m.Chunk != 0 {
+		raw.Chunk = json.RawMessage(strconv.Quote(strconv.Itoa(m.Chunk)))
+	}
+	if m.Timestamp != 0 {
+		raw.Timestamp = json.RawMessage(strconv.Quote(strconv.FormatInt(m.Timestamp, 10)))
+	}
+	return json.Marshal(raw)
+}
+
+// parseNumber parses a JSON number that may be quoted.
+func parseNumber(data json.RawMessage) (int64, error) {
+	data = bytes.Trim(data, `"`)
+	if len(data) == 0 || string(data) == "null" {
+		return 0, nil
+	}
+	return strconv.ParseInt(string(data), 10, 64)
+}
+
+// DTMF is a key pressed by the caller.
+type DTMF struct {
+	Digit string `json:"digit"`
+	// InBand is set on the digits found in the audio by DetectDTMF rather than sent by
+	// Telnyx.
+	InBand bool `json:"-"`
+}
+
+// Mark names a point in the audio sent back on a bidirectional stream.
+type Mark struct {
+	Name string `json:"name"`
+}
+
+// Stop is sent when the stream ends.
+type Stop struct {
+	UserID        string `json:"user_id,omitempty"`
+	CallControlID string `json:"call_control_id,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+// Error is sent when Telnyx rejects a frame sent back on the stream.
+type Error struct {
+	Code   int    `json:"code"`
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("stream: %d %s: %s", e.Code, e.Title, e.Detail)
+}
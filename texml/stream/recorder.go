@@ -0,0 +1,260 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/andersryanc/telnyx-go/texml/stream/audio"
+)
+
+// Track names of Media frames. The tracks a stream carries are selected by the Track
+// attribute of <Stream>.
+const (
+	TrackInbound  = "inbound"
+	TrackOutbound = "outbound"
+)
+
+// RecordingLayout selects how a Recorder lays out the tracks of a stream.
+type RecordingLayout int
+
+const (
+	// SeparateTracks writes each track to its own mono WAV file.
+	SeparateTracks RecordingLayout = iota
+	// StereoTracks writes a single stereo WAV file, with the inbound track on the left
+	// channel and the outbound track on the right.
+	StereoTracks
+)
+
+// stereoChannels maps the channels of a StereoTracks recording to tracks.
+var stereoChannels = [2]string{TrackInbound, TrackOutbound}
+
+// StereoOutput is the track name passed to an OutputFunc for a StereoTracks recording.
+const StereoOutput = "stereo"
+
+// OutputFunc opens the output of a recording. track is TrackInbound or TrackOutbound
+// for SeparateTracks recordings, and StereoOutput for StereoTracks recordings.
+type OutputFunc func(track string) (io.WriteCloser, error)
+
+// FileOutput returns an OutputFunc that creates the file named by pattern, with the
+// track name substituted for its %s verb, e.g. "recordings/call-42-%s.wav".
+func FileOutput(pattern string) OutputFunc {
+	return func(track string) (io.WriteCloser, error) {
+		return os.Create(fmt.Sprintf(pattern, track))
+	}
+}
+
+// WriterOutput returns an OutputFunc that writes a single recording to w. It suits
+// StereoTracks recordings and streams with a single track: when a second track of a
+// SeparateTracks recording appears, the OutputFunc returns an error rather than mix
+// both files in w.
+func WriterOutput(w io.WriteCloser) OutputFunc {
+	var opened string
+	return func(track string) (io.WriteCloser, error) {
+		if opened != "" {
+			return nil, fmt.Errorf("stream: WriterOutput cannot record the %s track, it records the %s track", track, opened)
+		}
+		opened = track
+		return w, nil
+	}
+}
+
+const (
+	// gapTolerance is the drift, in milliseconds, between the timestamp of a chunk and
+	// the audio recorded so far that is not filled with silence.
+	gapTolerance = 5
+	// maxStereoLag is the time, in milliseconds, one channel of a stereo recording can
+	// run ahead of the other before the other is filled with silence, so that a
+	// recording of a single track does not buffer forever.
+	maxStereoLag = 1000
+)
+
+// Recorder writes the audio of a stream to WAV files. Feed it the frames of the
+// session with Write; gaps in the media, such as those left by silence suppression,
+// are filled with silence from the chunk timestamps.
+type Recorder struct {
+	layout     RecordingLayout
+	open       OutputFunc
+	newCodec   func() (audio.Codec, error)
+	sampleRate int
+
+	tracks map[string]*recordedTrack
+	stereo *audio.WAVWriter
+	// pending holds the decoded samples of each stereo channel that have not been
+	// interleaved yet.
+	pending [2][]int16
+	// samples counts the samples of each stereo channel written or pending so far,
+	// including the silence added to a channel before its track appears.
+	samples [2]int64
+}
+
+type recordedTrack struct {
+	codec audio.Codec
+	wav   *audio.WAVWriter
+	// samples counts the samples of a SeparateTracks track written so far.
+	samples int64
+}
+
+// NewRecorder returns a Recorder for the media of s, writing to the outputs opened by
+// open as tracks appear.
+func NewRecorder(s *Session, layout RecordingLayout, open OutputFunc) (*Recorder, error) {
+	codec, err := s.NewCodec()
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{
+		layout:     layout,
+		open:       open,
+		newCodec:   s.NewCodec,
+		sampleRate: codec.SampleRate(),
+		tracks:     map[string]*recordedTrack{},
+	}, nil
+}
+
+// Write records f if it is a Media frame, and ignores it otherwise.
+func (r *Recorder) Write(f *Frame) error {
+	if f.Event != EventMedia || f.Media == nil {
+		return nil
+	}
+	m := f.Media
+	if r.layout == StereoTracks && m.Track != TrackInbound && m.Track != TrackOutbound {
+		return nil
+	}
+
+	t, err := r.track(m.Track)
+	if err != nil {
+		return err
+	}
+	samples, err := t.codec.Decode(m.Payload)
+	if err != nil {
+		return err
+	}
+
+	// Fill the gap between the audio recorded so far and the start of the chunk.
+	expected := m.Timestamp * int64(r.sampleRate) / 1000
+	if gap := expected - r.recorded(m.Track, t); gap > gapTolerance*int64(r.sampleRate)/1000 {
+		if err := r.append(m.Track, t, make([]int16, gap)); err != nil {
+			return err
+		}
+	}
+	return r.append(m.Track, t, samples)
+}
+
+func (r *Recorder) track(name string) (*recordedTrack, error) {
+	if t, ok := r.tracks[name]; ok {
+		return t, nil
+	}
+	codec, err := r.newCodec()
+	if err != nil {
+		return nil, err
+	}
+	t := &recordedTrack{codec: codec}
+
+	switch r.layout {
+	case SeparateTracks:
+		if t.wav, err = r.openWAV(name, 1); err != nil {
+			return nil, err
+		}
+	case StereoTracks:
+		if r.stereo == nil {
+			if r.stereo, err = r.openWAV(StereoOutput, 2); err != nil {
+				return nil, err
+			}
+		}
+	}
+	r.tracks[name] = t
+	return t, nil
+}
+
+func (r *Recorder) openWAV(name string, channels int) (*audio.WAVWriter, error) {
+	w, err := r.open(name)
+	if err != nil {
+		return nil, err
+	}
+	wav, err := audio.NewWAVWriter(w, r.sampleRate, channels)
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	return wav, nil
+}
+
+// recorded returns the number of samples recorded so far for the track name.
+func (r *Recorder) recorded(name string, t *recordedTrack) int64 {
+	if r.layout == StereoTracks {
+		return r.samples[stereoChannel(name)]
+	}
+	return t.samples
+}
+
+func stereoChannel(track string) int {
+	if track == stereoChannels[1] {
+		return 1
+	}
+	return 0
+}
+
+func (r *Recorder) append(name string, t *recordedTrack, samples []int16) error {
+	if r.layout == SeparateTracks {
+		t.samples += int64(len(samples))
+		return t.wav.WriteSamples(samples)
+	}
+
+	ch := stereoChannel(name)
+	r.pending[ch] = append(r.pending[ch], samples...)
+	r.samples[ch] += int64(len(samples))
+
+	// Let a channel run ahead of a silent one only up to maxStereoLag.
+	other := 1 - ch
+	if lag := len(r.pending[ch]) - len(r.pending[other]); lag > maxStereoLag*r.sampleRate/1000 {
+		r.pending[other] = append(r.pending[other], make([]int16, lag)...)
+		r.samples[other] += int64(lag)
+	}
+	return r.flushStereo(false)
+}
+
+// flushStereo interleaves the samples both channels have, or all of them, padding the
+// shorter channel with silence, when all is set.
+func (r *Recorder) flushStereo(all bool) error {
+	n := len(r.pending[0])
+	if len(r.pending[1]) < n {
+		n = len(r.pending[1])
+	}
+	if all {
+		n = len(r.pending[0])
+		if len(r.pending[1]) > n {
+			n = len(r.pending[1])
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+
+	frames := make([]int16, 2*n)
+	for ch := 0; ch < 2; ch++ {
+		for i := 0; i < n && i < len(r.pending[ch]); i++ {
+			frames[2*i+ch] = r.pending[ch][i]
+		}
+		if n < len(r.pending[ch]) {
+			r.pending[ch] = append(r.pending[ch][:0], r.pending[ch][n:]...)
+		} else {
+			r.pending[ch] = r.pending[ch][:0]
+		}
+	}
+	return r.stereo.WriteSamples(frames)
+}
+
+// Close writes the audio still buffered and closes the outputs.
+func (r *Recorder) Close() error {
+	var errs []error
+	if r.stereo != nil {
+		errs = append(errs, r.flushStereo(true), r.stereo.Close())
+	}
+	for _, t := range r.tracks {
+		if t.wav != nil {
+			errs = append(errs, t.wav.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
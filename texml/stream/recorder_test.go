@@ -0,0 +1,196 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// PCMU codes of the recorder tests, and the samples they decode to.
+const (
+	loud     = 0x80
+	quiet    = 0x00
+	loudPCM  = 32124
+	quietPCM = -32124
+)
+
+// mediaFrame returns a media frame of track with 20 ms of code at timestamp ms.
+func mediaFrame(track string, ms int64, code byte) *Frame {
+	return &Frame{Event: EventMedia, Media: &Media{Track: track, Timestamp: ms, Payload: bytes.Repeat([]byte{code}, 160)}}
+}
+
+func newRecorder(t *testing.T, layout RecordingLayout) (*Recorder, string) {
+	t.Helper()
+	dir := t.TempDir()
+	r, err := NewRecorder(&Session{Start: startFrame(nil).Start}, layout, FileOutput(filepath.Join(dir, "call-%s.wav")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, dir
+}
+
+// readWAV returns the samples of a WAV file written by a Recorder.
+func readWAV(t *testing.T, path string) []int16 {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := binary.LittleEndian.Uint32(data[40:]); int(size) != len(data)-44 {
+		t.Errorf("%s: data size %d in the header, %d in the file", path, size, len(data)-44)
+	}
+	samples := make([]int16, (len(data)-44)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(data[44+2*i:]))
+	}
+	return samples
+}
+
+func write(t *testing.T, r *Recorder, frames ...*Frame) {
+	t.Helper()
+	for _, f := range frames {
+		if err := r.Write(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRecorderSeparateTracks(t *testing.T) {
+	r, dir := newRecorder(t, SeparateTracks)
+	write(t, r,
+		&Frame{Event: EventMark, Mark: &Mark{Name: "m"}},
+		mediaFrame(TrackInbound, 0, loud),
+		mediaFrame(TrackOutbound, 0, quiet),
+		// Silence suppression left out 980 ms of the inbound track.
+		mediaFrame(TrackInbound, 1000, loud),
+		// 3 ms of drift is not a gap.
+		mediaFrame(TrackOutbound, 23, quiet),
+	)
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	in := readWAV(t, filepath.Join(dir, "call-inbound.wav"))
+	if len(in) != 8160 {
+		t.Fatalf("inbound has %d samples, want 8160", len(in))
+	}
+	if in[0] != loudPCM || in[160] != 0 || in[7999] != 0 || in[8000] != loudPCM {
+		t.Errorf("inbound samples = %d %d %d %d, want the gap filled with silence", in[0], in[160], in[7999], in[8000])
+	}
+	if out := readWAV(t, filepath.Join(dir, "call-outbound.wav")); len(out) != 320 || out[319] != quietPCM {
+		t.Errorf("outbound has %d samples", len(out))
+	}
+}
+
+func TestRecorderStereo(t *testing.T) {
+	r, dir := newRecorder(t, StereoTracks)
+	write(t, r,
+		mediaFrame(TrackInbound, 0, loud),
+		mediaFrame(TrackOutbound, 0, quiet),
+		mediaFrame("mixed", 0, loud),
+		mediaFrame(TrackOutbound, 20, quiet),
+	)
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	frames := readWAV(t, filepath.Join(dir, "call-stereo.wav"))
+	if len(frames) != 2*320 {
+		t.Fatalf("%d frames, want 320", len(frames)/2)
+	}
+	if frames[0] != loudPCM || frames[1] != quietPCM {
+		t.Errorf("first frame = %d %d, want inbound left and outbound right", frames[0], frames[1])
+	}
+	// Close pads the inbound channel, which ended first.
+	if frames[2*319] != 0 || frames[2*319+1] != quietPCM {
+		t.Errorf("last frame = %d %d", frames[2*319], frames[2*319+1])
+	}
+}
+
+func TestRecorderStereoLateTrack(t *testing.T) {
+	r, dir := newRecorder(t, StereoTracks)
+	// 10 s of the inbound track alone, then 2 s of both.
+	for ms := int64(0); ms < 10000; ms += 20 {
+		write(t, r, mediaFrame(TrackInbound, ms, loud))
+	}
+	for ms := int64(10000); ms < 12000; ms += 20 {
+		write(t, r, mediaFrame(TrackInbound, ms, loud), mediaFrame(TrackOutbound, ms, quiet))
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	frames := readWAV(t, filepath.Join(dir, "call-stereo.wav"))
+	if len(frames) != 2*96000 {
+		t.Fatalf("%d frames, want 96000", len(frames)/2)
+	}
+	for _, i := range []int{0, 79999, 80000, 95999} {
+		want := int16(0)
+		if i >= 80000 {
+			want = quietPCM
+		}
+		if frames[2*i] != loudPCM || frames[2*i+1] != want {
+			t.Errorf("frame %d = %d %d, want %d %d", i, frames[2*i], frames[2*i+1], loudPCM, want)
+		}
+	}
+}
+
+type failingOutput struct{}
+
+func (failingOutput) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+func (failingOutput) Close() error              { return nil }
+
+func TestRecorderOutputError(t *testing.T) {
+	r, err := NewRecorder(&Session{Start: startFrame(nil).Start}, StereoTracks, WriterOutput(failingOutput{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Write(mediaFrame(TrackInbound, 0, loud)); err == nil {
+		t.Error("Write succeeded with a failing output")
+	}
+
+	r, _ = NewRecorder(&Session{Start: startFrame(nil).Start}, SeparateTracks, func(string) (io.WriteCloser, error) {
+		return nil, os.ErrPermission
+	})
+	if err := r.Write(mediaFrame(TrackInbound, 0, loud)); !errors.Is(err, os.ErrPermission) {
+		t.Errorf("Write error = %v, want the error of the OutputFunc", err)
+	}
+}
+
+// closeCounter is an output that counts the bytes written to it and its closes.
+type closeCounter struct {
+	written, closes int
+}
+
+func (c *closeCounter) Write(p []byte) (int, error) { c.written += len(p); return len(p), nil }
+func (c *closeCounter) Close() error                { c.closes++; return nil }
+
+func TestWriterOutputSeparateTracks(t *testing.T) {
+	out := &closeCounter{}
+	r, err := NewRecorder(&Session{Start: startFrame(nil).Start}, SeparateTracks, WriterOutput(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	write(t, r, mediaFrame(TrackInbound, 0, loud))
+	written := out.written
+
+	// A second track is not mixed into the recording of the first.
+	if err := r.Write(mediaFrame(TrackOutbound, 0, quiet)); err == nil || !strings.Contains(err.Error(), "WriterOutput") {
+		t.Errorf("Write of a second track = %v, want the WriterOutput error", err)
+	}
+	if out.written != written {
+		t.Errorf("the second track wrote %d bytes to the output", out.written-written)
+	}
+	write(t, r, mediaFrame(TrackInbound, 20, loud))
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if out.closes != 1 {
+		t.Errorf("output closed %d times, want once", out.closes)
+	}
+}
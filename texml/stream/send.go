@@ -0,0 +1,177 @@
+package stream
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+)
+
+// EventClear is sent on a bidirectional stream to drop the audio that has not been
+// played yet.
+const EventClear = "clear"
+
+// DefaultChunkSize is the size of the media frames SendAudio sends: one second of
+// 8 kHz PCMU or PCMA.
+const DefaultChunkSize = 8000
+
+// ErrClosed is returned when sending on a stream that has ended.
+var ErrClosed = errors.New("stream: session closed")
+
+// playbackMark prefixes the names of the marks SendMedia sends after each chunk, which
+// tell when the audio sent so far has been played. They are not delivered on Frames.
+const playbackMark = "telnyx-go:playback:"
+
+// pendingMark is a mark sent with SendMark that Telnyx has not reported yet.
+type pendingMark struct {
+	name string
+	ack  chan bool
+}
+
+// SendMedia sends a chunk of audio to be played to the call. It requires a <Stream>
+// with BidirectionalMode "rtp", and payload must be encoded with its
+// BidirectionalCodec.
+func (s *Session) SendMedia(payload []byte) error {
+	// Hold the write lock while numbering the chunk, so that the marks go out in the
+	// order they are numbered.
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.mu.Lock()
+	s.chunks++
+	mark := playbackMark + strconv.FormatUint(s.chunks, 10)
+	s.playing = true
+	s.mu.Unlock()
+
+	if err := s.write(&Frame{Event: EventMedia, StreamID: s.StreamID, Media: &Media{Payload: payload}}); err != nil {
+		return err
+	}
+	return s.write(&Frame{Event: EventMark, StreamID: s.StreamID, Mark: &Mark{Name: mark}})
+}
+
+// SendAudio sends the audio read from r until EOF, in chunks of chunkSize bytes, or
+// DefaultChunkSize when chunkSize is 0. Telnyx buffers the audio and plays it in order,
+// so SendAudio returns long before playback ends; follow it with SendMark to know when
+// it does.
+func (s *Session) SendAudio(r io.Reader, chunkSize int) error {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			chunk := make([]byte, n)
+			copy(chunk, buf[:n])
+			if err := s.SendMedia(chunk); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// SendFile sends the file name with SendAudio. The file must hold raw audio in the
+// BidirectionalCodec of the stream, without a container such as WAV.
+func (s *Session) SendFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return s.SendAudio(f, 0)
+}
+
+// SendMark sends a mark after the audio sent so far. The returned channel receives
+// true when playback reaches the mark, or false when the mark is dropped by Clear or
+// the stream ends first, and is then closed.
+func (s *Session) SendMark(name string) (<-chan bool, error) {
+	ack := make(chan bool, 1)
+	s.mu.Lock()
+	if s.ctx.Err() != nil {
+		s.mu.Unlock()
+		return nil, ErrClosed
+	}
+	s.marks = append(s.marks, pendingMark{name, ack})
+	s.mu.Unlock()
+
+	if err := s.send(&Frame{Event: EventMark, StreamID: s.StreamID, Mark: &Mark{Name: name}}); err != nil {
+		s.resolveMarks(func(m pendingMark) bool { return m.ack == ack }, false)
+		return nil, err
+	}
+	return ack, nil
+}
+
+// Clear drops the audio that has been sent but not played yet, for example when the
+// caller starts speaking over it. Pending marks are reported as not reached.
+func (s *Session) Clear() error {
+	s.mu.Lock()
+	cleared := make(map[chan bool]bool, len(s.marks))
+	for _, m := range s.marks {
+		cleared[m.ack] = true
+	}
+	s.playing = false
+	s.mu.Unlock()
+
+	if err := s.send(&Frame{Event: EventClear, StreamID: s.StreamID}); err != nil {
+		return err
+	}
+	s.resolveMarks(func(m pendingMark) bool { return cleared[m.ack] }, false)
+	return nil
+}
+
+func (s *Session) send(f *Frame) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.write(f)
+}
+
+// write sends f; the caller holds writeMu.
+func (s *Session) write(f *Frame) error {
+	if s.ctx.Err() != nil {
+		return ErrClosed
+	}
+	return s.conn.WriteJSON(f)
+}
+
+// playbackReached clears playing when the mark of the last chunk sent is reached.
+func (s *Session) playbackReached(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if name == playbackMark+strconv.FormatUint(s.chunks, 10) {
+		s.playing = false
+	}
+}
+
+// markReached resolves the oldest pending mark called name.
+func (s *Session) markReached(name string) {
+	found := false
+	s.resolveMarks(func(m pendingMark) bool {
+		if found || m.name != name {
+			return false
+		}
+		found = true
+		return true
+	}, true)
+}
+
+// resolveMarks reports the pending marks selected by match as reached or not, and
+// forgets them.
+func (s *Session) resolveMarks(match func(pendingMark) bool, reached bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.marks[:0]
+	for _, m := range s.marks {
+		if match(m) {
+			m.ack <- reached
+			close(m.ack)
+		} else {
+			kept = append(kept, m)
+		}
+	}
+	s.marks = kept
+}
@@ -0,0 +1,167 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSendAudio(t *testing.T) {
+	s, conn := connect(t, nil)
+
+	audio := bytes.Repeat([]byte{0x7f}, 2*DefaultChunkSize+100)
+	go s.SendAudio(bytes.NewReader(audio), 0)
+
+	var sizes []int
+	var sent []byte
+	for len(sent) < len(audio) {
+		f := receive(t, conn)
+		payload, _ := base64.StdEncoding.DecodeString(f["media"].(map[string]interface{})["payload"].(string))
+		sizes = append(sizes, len(payload))
+		sent = append(sent, payload...)
+		// Each chunk is followed by its playback mark.
+		if f := receive(t, conn); f["event"] != EventMark {
+			t.Fatalf("frame after a chunk = %v, want a mark", f)
+		}
+	}
+	if want := []int{DefaultChunkSize, DefaultChunkSize, 100}; !reflect.DeepEqual(sizes, want) {
+		t.Errorf("chunk sizes = %v, want %v", sizes, want)
+	}
+	if !bytes.Equal(sent, audio) {
+		t.Error("the audio sent differs from the audio read")
+	}
+}
+
+func TestSendFile(t *testing.T) {
+	s, conn := connect(t, nil)
+	name := filepath.Join(t.TempDir(), "hello.ulaw")
+	os.WriteFile(name, []byte{1, 2, 3, 4}, 0o644)
+
+	if err := s.SendFile(name); err != nil {
+		t.Fatal(err)
+	}
+	if f := receive(t, conn); f["media"].(map[string]interface{})["payload"] != "AQIDBA==" {
+		t.Errorf("media frame = %v", f)
+	}
+	receive(t, conn)
+	if err := s.SendFile(filepath.Join(t.TempDir(), "missing.ulaw")); err == nil {
+		t.Error("sending a missing file succeeded")
+	}
+}
+
+func TestMarksInOrder(t *testing.T) {
+	s, conn := connect(t, nil)
+
+	first, _ := s.SendMark("chunk")
+	second, _ := s.SendMark("chunk")
+	receive(t, conn)
+	receive(t, conn)
+
+	// Marks with the same name are reached in the order they were sent.
+	send(t, conn, &Frame{Event: EventMark, Mark: &Mark{Name: "chunk"}})
+	next(t, s)
+	if !<-first {
+		t.Error("the first mark was not reached")
+	}
+	select {
+	case <-second:
+		t.Error("the second mark was resolved by the first mark frame")
+	default:
+	}
+	send(t, conn, &Frame{Event: EventMark, Mark: &Mark{Name: "chunk"}})
+	next(t, s)
+	if !<-second {
+		t.Error("the second mark was not reached")
+	}
+}
+
+func TestClear(t *testing.T) {
+	s, conn := connect(t, nil)
+
+	s.SendMedia([]byte{1})
+	ack, _ := s.SendMark("end of prompt")
+	for i := 0; i < 3; i++ {
+		receive(t, conn)
+	}
+
+	if err := s.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if f := receive(t, conn); f["event"] != EventClear || f["stream_id"] != "st1" {
+		t.Errorf("clear frame = %v", f)
+	}
+	reached, ok := <-ack
+	if reached || !ok {
+		t.Errorf("cleared mark reported %v, %v; want false", reached, ok)
+	}
+	if _, ok := <-ack; ok {
+		t.Error("the mark channel was not closed")
+	}
+
+	// A mark sent after Clear is still reported.
+	after, _ := s.SendMark("next prompt")
+	receive(t, conn)
+	send(t, conn, &Frame{Event: EventMark, Mark: &Mark{Name: "next prompt"}})
+	next(t, s)
+	if !<-after {
+		t.Error("the mark sent after Clear was not reached")
+	}
+}
+
+func TestMarksResolvedAtStop(t *testing.T) {
+	s, conn := connect(t, nil)
+
+	ack, _ := s.SendMark("never played")
+	receive(t, conn)
+	send(t, conn, &Frame{Event: EventStop, Stop: &Stop{}})
+	for range s.Frames() {
+	}
+	if <-ack {
+		t.Error("a mark pending at the end of the stream was reported as reached")
+	}
+	if err := s.SendMedia([]byte{1}); err != ErrClosed {
+		t.Errorf("SendMedia after the end = %v, want ErrClosed", err)
+	}
+}
+
+func playing(s *Session) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.playing
+}
+
+func TestPlaybackMarks(t *testing.T) {
+	s, conn := connect(t, nil)
+
+	var marks []string
+	for i := 0; i < 2; i++ {
+		if err := s.SendMedia([]byte{1}); err != nil {
+			t.Fatal(err)
+		}
+		receive(t, conn)
+		marks = append(marks, receive(t, conn)["mark"].(map[string]interface{})["name"].(string))
+	}
+	if marks[0] == marks[1] || !playing(s) {
+		t.Fatalf("playback marks = %q, playing = %v", marks, playing(s))
+	}
+
+	// The playback marks are not delivered on Frames.
+	send(t, conn, &Frame{Event: EventMark, Mark: &Mark{Name: marks[0]}})
+	send(t, conn, &Frame{Event: EventDTMF, DTMF: &DTMF{Digit: "1"}})
+	if f := next(t, s); f.Event != EventDTMF {
+		t.Errorf("frame = %+v, want the DTMF frame", f)
+	}
+	if !playing(s) {
+		t.Error("playing was cleared before the last chunk was played")
+	}
+
+	send(t, conn, &Frame{Event: EventMark, Mark: &Mark{Name: marks[1]}})
+	send(t, conn, &Frame{Event: EventDTMF, DTMF: &DTMF{Digit: "2"}})
+	next(t, s)
+	if playing(s) {
+		t.Error("playing is still set after the last chunk was played")
+	}
+}
@@ -0,0 +1,241 @@
+// Package stream receives the media streams started by the <Stream> verb on a
+// WebSocket server:
+//
+//	http.Handle("/stream", stream.NewHandler(func(s *stream.Session) {
+//		for f := range s.Frames() {
+//			if f.Event == stream.EventMedia {
+//				process(f.Media.Payload)
+//			}
+//		}
+//	}))
+//
+// https://developers.telnyx.com/docs/voice/programmable-voice/media-streaming
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andersryanc/telnyx-go/texml"
+	"github.com/andersryanc/telnyx-go/texml/stream/audio"
+	"github.com/gorilla/websocket"
+)
+
+// NameParameter is the custom parameter that carries the Name of a <Stream> built with
+// Verb to the Session.
+const NameParameter = "stream_name"
+
+// Verb returns a <Stream> to url that sends name and params in the custom parameters of
+// its start frame, where they become Session.Name and Session.Params.
+func Verb(url, name string, params map[string]string) texml.VoiceStream {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	v := texml.VoiceStream{Url: url, Name: name}
+	if name != "" {
+		v.InnerElements = append(v.InnerElements, texml.VoiceParameter{Name: NameParameter, Value: name})
+	}
+	for _, k := range keys {
+		v.InnerElements = append(v.InnerElements, texml.VoiceParameter{Name: k, Value: params[k]})
+	}
+	return v
+}
+
+// Handler is an http.Handler that accepts media stream connections and calls a
+// function with each of them.
+type Handler struct {
+	// Upgrader upgrades the HTTP requests to WebSocket connections.
+	Upgrader websocket.Upgrader
+	// StartTimeout bounds the wait for the start frame. It defaults to 10 seconds.
+	StartTimeout time.Duration
+
+	serve func(*Session)
+}
+
+// NewHandler returns a Handler that calls serve with each stream once its start frame
+// has arrived. The connection is closed when serve returns.
+func NewHandler(serve func(s *Session)) *Handler {
+	return &Handler{serve: serve}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied with an HTTP error.
+		return
+	}
+
+	timeout := h.StartTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	s, err := accept(r.Context(), conn, timeout)
+	if err != nil {
+		conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseProtocolError, err.Error()), time.Now().Add(time.Second))
+		conn.Close()
+		return
+	}
+	defer s.Close()
+
+	go s.readLoop()
+	h.serve(s)
+}
+
+// Session is a media stream. Its frames are read from the connection by a background
+// goroutine and delivered on Frames, which must be drained for the stream to make
+// progress.
+type Session struct {
+	// StreamID identifies the stream in the frames Telnyx sends.
+	StreamID string
+	// Name is the Name of the <Stream>, when it was built with Verb.
+	Name string
+	// Params holds the custom parameters of the <Stream>, without NameParameter.
+	Params map[string]string
+	// Start is the start frame of the stream.
+	Start *Start
+
+	conn    *websocket.Conn
+	ctx     context.Context
+	cancel  context.CancelFunc
+	frames  chan *Frame
+	writeMu sync.Mutex
+
+	mu        sync.Mutex
+	err       error
+	marks     []pendingMark
+	detectors []detector
+	// playing is set when media is sent, and cleared by Clear or when the playback
+	// mark of the last chunk is reached.
+	playing bool
+	// chunks numbers the chunks sent with SendMedia, and their playback marks.
+	chunks uint64
+
+	// codecs decode the tracks analyzed by the detectors. They are only used by
+	// readLoop.
+	codecs map[string]audio.Codec
+}
+
+// accept reads the frames preceding media, up to and including the start frame.
+func accept(ctx context.Context, conn *websocket.Conn, timeout time.Duration) (*Session, error) {
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	defer conn.SetReadDeadline(time.Time{})
+
+	for {
+		var f Frame
+		if err := conn.ReadJSON(&f); err != nil {
+			return nil, fmt.Errorf("stream: read start frame: %w", err)
+		}
+		switch f.Event {
+		case EventConnected:
+			continue
+		case EventStart:
+		default:
+			return nil, fmt.Errorf("stream: expected start frame, got %q", f.Event)
+		}
+		if f.Start == nil {
+			return nil, errors.New("stream: start frame without start payload")
+		}
+
+		s := &Session{
+			StreamID: f.StreamID,
+			Params:   map[string]string{},
+			Start:    f.Start,
+			conn:     conn,
+			frames:   make(chan *Frame, 64),
+			codecs:   map[string]audio.Codec{},
+		}
+		for k, v := range f.Start.CustomParameters {
+			if k == NameParameter {
+				s.Name = v
+			} else {
+				s.Params[k] = v
+			}
+		}
+		s.ctx, s.cancel = context.WithCancel(ctx)
+		return s, nil
+	}
+}
+
+func (s *Session) readLoop() {
+	defer close(s.frames)
+	defer s.resolveMarks(func(pendingMark) bool { return true }, false)
+	defer s.cancel()
+
+	for {
+		f := new(Frame)
+		if err := s.conn.ReadJSON(f); err != nil {
+			if s.ctx.Err() == nil && !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				s.setErr(err)
+			}
+			return
+		}
+		if f.Event == EventMark && f.Mark != nil {
+			if strings.HasPrefix(f.Mark.Name, playbackMark) {
+				s.playbackReached(f.Mark.Name)
+				continue
+			}
+			s.markReached(f.Mark.Name)
+		}
+		for _, f := range append([]*Frame{f}, s.detect(f)...) {
+			select {
+			case s.frames <- f:
+			case <-s.ctx.Done():
+				return
+			}
+		}
+		if f.Event == EventStop {
+			return
+		}
+	}
+}
+
+// Frames returns the frames received after the start frame, along with those
+// synthesized by detectors such as DetectSpeech. The channel is closed when the stream
+// stops or the connection is lost.
+func (s *Session) Frames() <-chan *Frame {
+	return s.frames
+}
+
+// NewCodec returns an audio.Codec for the media format of the stream. Codecs keep
+// state, so each track needs its own.
+func (s *Session) NewCodec() (audio.Codec, error) {
+	return audio.NewCodec(s.Start.MediaFormat.Encoding, s.Start.MediaFormat.SampleRate)
+}
+
+// Context returns a context that is canceled when the stream ends.
+func (s *Session) Context() context.Context {
+	return s.ctx
+}
+
+// Err returns the error that ended the stream, or nil if it ended normally.
+func (s *Session) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *Session) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// Close closes the connection. Telnyx stops streaming the call, but the call goes on.
+func (s *Session) Close() error {
+	s.cancel()
+	s.writeMu.Lock()
+	s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	s.writeMu.Unlock()
+	return s.conn.Close()
+}
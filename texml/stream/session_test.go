@@ -0,0 +1,242 @@
+package stream
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andersryanc/telnyx-go/texml"
+	"github.com/gorilla/websocket"
+)
+
+// startFrame is the start frame of a PCMU stream with the given custom parameters.
+func startFrame(params map[string]string) *Frame {
+	return &Frame{
+		Event:    EventStart,
+		StreamID: "st1",
+		Start: &Start{
+			CallControlID:    "v3:abc",
+			CallSid:          "v3:abc",
+			Tracks:           []string{"inbound", "outbound"},
+			MediaFormat:      MediaFormat{Encoding: "PCMU", SampleRate: 8000, Channels: 1},
+			CustomParameters: params,
+		},
+	}
+}
+
+// serve starts a server running h and returns a WebSocket connection to it, the Telnyx
+// end of the stream.
+func serve(t *testing.T, h *Handler) *websocket.Conn {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// connect starts a Handler that hands its Session to the test, sends the connected
+// and start frames, and returns both ends of the stream. The Session is closed when
+// the test ends.
+func connect(t *testing.T, params map[string]string) (*Session, *websocket.Conn) {
+	t.Helper()
+	sessions := make(chan *Session)
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	conn := serve(t, NewHandler(func(s *Session) {
+		sessions <- s
+		<-done
+	}))
+	send(t, conn, &Frame{Event: EventConnected, Version: "1.0.0"})
+	send(t, conn, startFrame(params))
+	select {
+	case s := <-sessions:
+		return s, conn
+	case <-time.After(5 * time.Second):
+		t.Fatal("no session was started")
+		return nil, nil
+	}
+}
+
+func send(t *testing.T, conn *websocket.Conn, f *Frame) {
+	t.Helper()
+	if err := conn.WriteJSON(f); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// receive reads the next frame sent by the Session.
+func receive(t *testing.T, conn *websocket.Conn) map[string]interface{} {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var f map[string]interface{}
+	if err := conn.ReadJSON(&f); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// next returns the next frame delivered by s.
+func next(t *testing.T, s *Session) *Frame {
+	t.Helper()
+	select {
+	case f, ok := <-s.Frames():
+		if !ok {
+			t.Fatal("Frames was closed")
+		}
+		return f
+	case <-time.After(5 * time.Second):
+		t.Fatal("no frame was delivered")
+		return nil
+	}
+}
+
+func TestSession(t *testing.T) {
+	s, conn := connect(t, map[string]string{NameParameter: "agent-assist", "tenant": "acme"})
+
+	if s.StreamID != "st1" || s.Name != "agent-assist" || !reflect.DeepEqual(s.Params, map[string]string{"tenant": "acme"}) {
+		t.Errorf("session = %q %q %v", s.StreamID, s.Name, s.Params)
+	}
+	if s.Start.CallControlID != "v3:abc" || s.Start.MediaFormat.Encoding != "PCMU" {
+		t.Errorf("Start = %+v", s.Start)
+	}
+	if codec, err := s.NewCodec(); err != nil || codec == nil {
+		t.Errorf("NewCodec = %v, %v", codec, err)
+	}
+
+	// Telnyx sends chunk and timestamp as strings.
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"event":"media","sequence_number":"3","stream_id":"st1",
+		"media":{"track":"inbound","chunk":"2","timestamp":"20","payload":"AQID"}}`))
+	f := next(t, s)
+	if f.Event != EventMedia || f.Media.Track != "inbound" || f.Media.Chunk != 2 || f.Media.Timestamp != 20 || string(f.Media.Payload) != "\x01\x02\x03" {
+		t.Errorf("media frame = %+v %+v", f, f.Media)
+	}
+
+	send(t, conn, &Frame{Event: EventDTMF, StreamID: "st1", DTMF: &DTMF{Digit: "5"}})
+	if f := next(t, s); f.DTMF == nil || f.DTMF.Digit != "5" || f.DTMF.InBand {
+		t.Errorf("dtmf frame = %+v", f)
+	}
+
+	send(t, conn, &Frame{Event: EventStop, StreamID: "st1", Stop: &Stop{Reason: "call_hangup"}})
+	if f := next(t, s); f.Event != EventStop || f.Stop.Reason != "call_hangup" {
+		t.Errorf("stop frame = %+v", f)
+	}
+	if _, ok := <-s.Frames(); ok {
+		t.Error("Frames is still open after the stop frame")
+	}
+	select {
+	case <-s.Context().Done():
+	case <-time.After(5 * time.Second):
+		t.Error("the context was not canceled when the stream stopped")
+	}
+	if s.Err() != nil {
+		t.Errorf("Err() = %v after a normal stop", s.Err())
+	}
+}
+
+func TestSessionMarks(t *testing.T) {
+	s, conn := connect(t, nil)
+
+	if err := s.SendMedia([]byte{0xff, 0xff}); err != nil {
+		t.Fatal(err)
+	}
+	f := receive(t, conn)
+	if f["event"] != EventMedia || f["stream_id"] != "st1" {
+		t.Errorf("media frame = %v", f)
+	}
+	if m := f["media"].(map[string]interface{}); m["payload"] != "//8=" || m["chunk"] != nil || m["timestamp"] != nil {
+		t.Errorf("media = %v, want only the payload", m)
+	}
+	receive(t, conn) // playback mark
+
+	ack, err := s.SendMark("greeting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := receive(t, conn); f["event"] != EventMark || f["mark"].(map[string]interface{})["name"] != "greeting" {
+		t.Errorf("mark frame = %v", f)
+	}
+	send(t, conn, &Frame{Event: EventMark, StreamID: "st1", Mark: &Mark{Name: "greeting"}})
+	if f := next(t, s); f.Event != EventMark || f.Mark.Name != "greeting" {
+		t.Errorf("mark frame delivered = %+v", f)
+	}
+	if reached := <-ack; !reached {
+		t.Error("the mark was reported as not reached")
+	}
+}
+
+func TestSessionConnectionLost(t *testing.T) {
+	s, conn := connect(t, nil)
+	conn.UnderlyingConn().Close()
+
+	for range s.Frames() {
+	}
+	if s.Err() == nil {
+		t.Error("Err() = nil after the connection was lost")
+	}
+	if _, err := s.SendMark("late"); err != ErrClosed {
+		t.Errorf("SendMark after the end = %v, want ErrClosed", err)
+	}
+}
+
+func TestHandlerRejectsMissingStart(t *testing.T) {
+	served := false
+	conn := serve(t, NewHandler(func(s *Session) { served = true }))
+	send(t, conn, &Frame{Event: EventConnected})
+	send(t, conn, &Frame{Event: EventMedia, Media: &Media{Payload: []byte{1}}})
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err := conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseProtocolError) || !strings.Contains(err.Error(), `expected start frame, got "media"`) {
+		t.Errorf("read error = %v, want a protocol error close", err)
+	}
+	if served {
+		t.Error("a stream without a start frame was served")
+	}
+}
+
+func TestHandlerStartTimeout(t *testing.T) {
+	h := NewHandler(func(s *Session) { t.Error("a stream without a start frame was served") })
+	h.StartTimeout = 20 * time.Millisecond
+	conn := serve(t, h)
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseProtocolError) {
+		t.Errorf("read error = %v, want a protocol error close", err)
+	}
+}
+
+func TestVerb(t *testing.T) {
+	v := Verb("wss://example.com/stream", "agent-assist", map[string]string{"b": "2", "a": "1"})
+	want := texml.VoiceStream{Url: "wss://example.com/stream", Name: "agent-assist", InnerElements: []texml.Element{
+		texml.VoiceParameter{Name: NameParameter, Value: "agent-assist"},
+		texml.VoiceParameter{Name: "a", Value: "1"},
+		texml.VoiceParameter{Name: "b", Value: "2"},
+	}}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Verb = %#v, want %#v", v, want)
+	}
+}
+
+func TestMediaJSON(t *testing.T) {
+	var m Media
+	if err := json.Unmarshal([]byte(`{"track":"outbound","chunk":7,"timestamp":"140","payload":"AA=="}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Chunk != 7 || m.Timestamp != 140 || len(m.Payload) != 1 {
+		t.Errorf("Media = %+v", m)
+	}
+	if err := json.Unmarshal([]byte(`{"chunk":"one"}`), &m); err == nil {
+		t.Error("a chunk that is not a number was decoded")
+	}
+	data, _ := json.Marshal(Media{Track: "inbound", Chunk: 3, Timestamp: 60, Payload: []byte{0}})
+	if string(data) != `{"track":"inbound","chunk":"3","timestamp":"60","payload":"AA=="}` {
+		t.Errorf("Marshal = %s", data)
+	}
+}
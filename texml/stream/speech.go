@@ -0,0 +1,96 @@
+package stream
+
+import (
+	"time"
+
+	"github.com/andersryanc/telnyx-go/texml/stream/audio"
+)
+
+// Events of the frames synthesized by DetectSpeech. Telnyx does not send them.
+const (
+	EventSpeechStarted = "speech_started"
+	EventSpeechStopped = "speech_stopped"
+)
+
+// Speech is the payload of EventSpeechStarted and EventSpeechStopped frames.
+type Speech struct {
+	// Track is the track the speech was detected on.
+	Track string `json:"track"`
+	// Timestamp is the offset of the change from the start of the stream, in
+	// milliseconds, on the same clock as Media.Timestamp.
+	Timestamp int64 `json:"timestamp"`
+}
+
+// SpeechOptions configures DetectSpeech.
+type SpeechOptions struct {
+	// Track is the track to listen to. It defaults to TrackInbound, the caller.
+	Track string
+	// VAD detects the speech. It defaults to audio.NewVAD at the sample rate of the
+	// stream codec, and must be used by a single session.
+	VAD *audio.VAD
+	// BargeIn calls Clear when speech starts while audio sent with SendMedia may still
+	// be playing, so that the caller can interrupt a prompt.
+	BargeIn bool
+}
+
+// DetectSpeech runs a voice activity detector on the decoded audio of a track, and
+// delivers EventSpeechStarted and EventSpeechStopped frames on Frames after the media
+// frame that completes each change.
+func (s *Session) DetectSpeech(opts SpeechOptions) error {
+	if opts.Track == "" {
+		opts.Track = TrackInbound
+	}
+	if opts.VAD == nil {
+		codec, err := s.NewCodec()
+		if err != nil {
+			return err
+		}
+		opts.VAD = audio.NewVAD(codec.SampleRate())
+	}
+	return s.addDetector(&speechDetector{s: s, opts: opts})
+}
+
+type speechDetector struct {
+	s    *Session
+	opts SpeechOptions
+	// analyzed is the duration of the audio given to the VAD so far.
+	analyzed time.Duration
+}
+
+func (d *speechDetector) detect(m *Media, samples []int16) []*Frame {
+	if m.Track != d.opts.Track {
+		return nil
+	}
+	start := d.analyzed
+	d.analyzed += time.Duration(len(samples)) * time.Second / time.Duration(d.opts.VAD.SampleRate())
+
+	var out []*Frame
+	for _, a := range d.opts.VAD.Process(samples) {
+		event := EventSpeechStopped
+		if a.Speaking {
+			event = EventSpeechStarted
+			if d.opts.BargeIn {
+				d.s.bargeIn()
+			}
+		}
+		// Offsets are relative to the analyzed audio, which skips the gaps left by
+		// silence suppression; anchor them on the chunk timestamp instead.
+		ts := m.Timestamp + (a.Offset - start).Milliseconds()
+		out = append(out, &Frame{
+			Event:    event,
+			StreamID: d.s.StreamID,
+			Speech:   &Speech{Track: m.Track, Timestamp: ts},
+		})
+	}
+	return out
+}
+
+// bargeIn clears the audio sent to the call, if any may still be playing.
+func (s *Session) bargeIn() {
+	s.mu.Lock()
+	playing := s.playing
+	s.mu.Unlock()
+	if playing {
+		s.Clear()
+	}
+}
@@ -0,0 +1,127 @@
+package stream
+
+import (
+	"math"
+	"testing"
+
+	"github.com/andersryanc/telnyx-go/texml/stream/audio"
+	"github.com/gorilla/websocket"
+)
+
+// sine returns ms milliseconds of a sine of freq Hz at 8 kHz, or silence when amp is 0.
+func sine(freq float64, ms int, amp float64) []int16 {
+	samples := make([]int16, 8*ms)
+	for i := range samples {
+		samples[i] = int16(amp * math.Sin(2*math.Pi*freq*float64(i)/8000))
+	}
+	return samples
+}
+
+// sendSamples sends samples as 20 ms PCMU chunks of track, the first at timestamp ms.
+func sendSamples(t *testing.T, conn *websocket.Conn, track string, ms int64, samples []int16) {
+	t.Helper()
+	codec, _ := audio.NewCodec("PCMU", 8000)
+	for i := 0; i < len(samples); i, ms = i+160, ms+20 {
+		end := i + 160
+		if end > len(samples) {
+			end = len(samples)
+		}
+		payload, _ := codec.Encode(samples[i:end])
+		if err := conn.WriteJSON(&Frame{Event: EventMedia, StreamID: "st1", Media: &Media{Track: track, Timestamp: ms, Payload: payload}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// synthesized returns the frames with events delivered on Frames until the stream
+// stops.
+func synthesized(s *Session, events ...string) []*Frame {
+	var frames []*Frame
+	for f := range s.Frames() {
+		for _, e := range events {
+			if f.Event == e {
+				frames = append(frames, f)
+			}
+		}
+	}
+	return frames
+}
+
+func TestDetectSpeech(t *testing.T) {
+	s, conn := connect(t, nil)
+	if err := s.DetectSpeech(SpeechOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	sendSamples(t, conn, TrackInbound, 0, sine(300, 1000, 0))
+	// The outbound track is not analyzed.
+	sendSamples(t, conn, TrackOutbound, 1000, sine(300, 500, 8000))
+	// Silence suppression left a gap from 1 s to 3 s.
+	sendSamples(t, conn, TrackInbound, 3000, sine(300, 1000, 8000))
+	sendSamples(t, conn, TrackInbound, 4000, sine(300, 1000, 0))
+	send(t, conn, &Frame{Event: EventStop, StreamID: "st1", Stop: &Stop{}})
+
+	frames := synthesized(s, EventSpeechStarted, EventSpeechStopped)
+	if len(frames) != 2 {
+		t.Fatalf("%d speech frames, want 2", len(frames))
+	}
+	for i, want := range []struct {
+		event string
+		ts    int64
+	}{{EventSpeechStarted, 3000}, {EventSpeechStopped, 4000}} {
+		f := frames[i]
+		if f.Event != want.event || f.StreamID != "st1" || f.Speech.Track != TrackInbound || f.Speech.Timestamp != want.ts {
+			t.Errorf("frame %d = %s %+v, want %s at %d", i, f.Event, f.Speech, want.event, want.ts)
+		}
+	}
+}
+
+func TestDetectSpeechUnsupportedCodec(t *testing.T) {
+	s, conn := connect(t, nil)
+	s.Start.MediaFormat.Encoding = "AMR-WB"
+	if err := s.DetectSpeech(SpeechOptions{}); err == nil {
+		t.Error("DetectSpeech succeeded without a codec")
+	}
+	conn.Close()
+}
+
+func TestBargeIn(t *testing.T) {
+	s, conn := connect(t, nil)
+	if err := s.DetectSpeech(SpeechOptions{BargeIn: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The caller speaks while a prompt is playing.
+	s.SendMedia([]byte{0xff})
+	receive(t, conn)
+	receive(t, conn)
+	sendSamples(t, conn, TrackInbound, 0, sine(300, 200, 8000))
+	if f := receive(t, conn); f["event"] != EventClear {
+		t.Errorf("frame = %v, want clear", f)
+	}
+	for f := next(t, s); f.Event != EventSpeechStarted; f = next(t, s) {
+	}
+}
+
+func TestBargeInAfterPlayback(t *testing.T) {
+	s, conn := connect(t, nil)
+	if err := s.DetectSpeech(SpeechOptions{BargeIn: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.SendMedia([]byte{0xff})
+	receive(t, conn)
+	mark := receive(t, conn)["mark"].(map[string]interface{})["name"].(string)
+	// The prompt has been played when the caller speaks.
+	send(t, conn, &Frame{Event: EventMark, StreamID: "st1", Mark: &Mark{Name: mark}})
+	sendSamples(t, conn, TrackInbound, 0, sine(300, 200, 8000))
+	for f := next(t, s); f.Event != EventSpeechStarted; f = next(t, s) {
+	}
+
+	// Barge-in happens before the speech frame is delivered, so a clear would have
+	// been sent by now.
+	s.SendMark("check")
+	if f := receive(t, conn); f["event"] != EventMark {
+		t.Errorf("frame = %v, want the mark and no clear", f)
+	}
+}
@@ -0,0 +1,178 @@
+package texml
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/beevik/etree"
+)
+
+// Template is a precompiled TeXML document whose text and attribute values may contain
+// text/template actions, such as {{.CallerName}}. A Template is parsed once and can be
+// executed concurrently with different data for each call.
+//
+// Substituted values are always written as XML text or attribute values, so they are
+// escaped when rendered and can never add elements to the document.
+type Template struct {
+	verbs []*templateNode
+}
+
+type templateNode struct {
+	name      string
+	text      templateValue
+	optAttr   map[string]templateValue
+	paramAttr map[string]templateValue
+	children  []*templateNode
+}
+
+// templateValue is either a constant string or a parsed template.
+type templateValue struct {
+	raw  string
+	tmpl *template.Template
+}
+
+// ParseTemplate compiles the text and attribute values of every element in verbs.
+// Missing keys in the data passed to Execute are reported as errors.
+func ParseTemplate(verbs []Element) (*Template, error) {
+	return ParseTemplateFuncs(verbs, nil)
+}
+
+// ParseTemplateFuncs is like ParseTemplate but makes funcs available to every action.
+func ParseTemplateFuncs(verbs []Element, funcs template.FuncMap) (*Template, error) {
+	nodes, err := parseTemplateNodes(verbs, "Response", funcs)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{verbs: nodes}, nil
+}
+
+// MustParseTemplate is like ParseTemplate but panics if the template cannot be parsed.
+// It is intended for package level variables.
+func MustParseTemplate(verbs []Element) *Template {
+	t, err := ParseTemplate(verbs)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Execute renders the template with data and returns the TeXML document, like Voice.
+func (t *Template) Execute(data interface{}) (string, error) {
+	doc, response := CreateDocument()
+	for _, node := range t.verbs {
+		el, err := node.execute(data)
+		if err != nil {
+			return "", err
+		}
+		response.AddChild(el)
+	}
+	return ToXML(doc)
+}
+
+func parseTemplateNodes(elements []Element, path string, funcs template.FuncMap) ([]*templateNode, error) {
+	nodes := make([]*templateNode, 0, len(elements))
+	for i, element := range elements {
+		node, err := parseTemplateNode(element, fmt.Sprintf("%s/%s[%d]", path, element.GetName(), i), funcs)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func parseTemplateNode(element Element, path string, funcs template.FuncMap) (*templateNode, error) {
+	node := &templateNode{name: element.GetName()}
+
+	var err error
+	if node.text, err = parseTemplateValue(path, element.GetText(), funcs); err != nil {
+		return nil, err
+	}
+
+	optAttr, paramAttr := element.GetAttr()
+	if node.optAttr, err = parseTemplateAttrs(path, optAttr, funcs); err != nil {
+		return nil, err
+	}
+	if node.paramAttr, err = parseTemplateAttrs(path, paramAttr, funcs); err != nil {
+		return nil, err
+	}
+
+	if node.children, err = parseTemplateNodes(element.GetInnerElements(), path, funcs); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func parseTemplateAttrs(path string, attrs map[string]string, funcs template.FuncMap) (map[string]templateValue, error) {
+	values := make(map[string]templateValue, len(attrs))
+	for k, v := range attrs {
+		value, err := parseTemplateValue(path+"@"+k, v, funcs)
+		if err != nil {
+			return nil, err
+		}
+		values[k] = value
+	}
+	return values, nil
+}
+
+func parseTemplateValue(name, s string, funcs template.FuncMap) (templateValue, error) {
+	if !strings.Contains(s, "{{") {
+		return templateValue{raw: s}, nil
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(funcs).Parse(s)
+	if err != nil {
+		return templateValue{}, fmt.Errorf("texml: %w", err)
+	}
+	return templateValue{raw: s, tmpl: tmpl}, nil
+}
+
+func (v templateValue) execute(data interface{}) (string, error) {
+	if v.tmpl == nil {
+		return v.raw, nil
+	}
+	var buf bytes.Buffer
+	if err := v.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("texml: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func (n *templateNode) execute(data interface{}) (*etree.Element, error) {
+	text, err := n.text.execute(data)
+	if err != nil {
+		return nil, err
+	}
+	optAttr, err := executeTemplateAttrs(n.optAttr, data)
+	if err != nil {
+		return nil, err
+	}
+	paramAttr, err := executeTemplateAttrs(n.paramAttr, data)
+	if err != nil {
+		return nil, err
+	}
+
+	el := etree.NewElement(n.name)
+	addPropertyToElement(el, text, optAttr, paramAttr)
+	for _, child := range n.children {
+		childEl, err := child.execute(data)
+		if err != nil {
+			return nil, err
+		}
+		el.AddChild(childEl)
+	}
+	return el, nil
+}
+
+func executeTemplateAttrs(values map[string]templateValue, data interface{}) (map[string]string, error) {
+	attrs := make(map[string]string, len(values))
+	for k, v := range values {
+		s, err := v.execute(data)
+		if err != nil {
+			return nil, err
+		}
+		attrs[k] = s
+	}
+	return attrs, nil
+}
@@ -0,0 +1,151 @@
+package texml
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+
+	"github.com/beevik/etree"
+)
+
+// readResponse parses a rendered TeXML document and returns its <Response> element.
+func readResponse(t *testing.T, xml string) *etree.Element {
+	t.Helper()
+	doc := etree.NewDocument()
+	if err := doc.ReadFromString(xml); err != nil {
+		t.Fatalf("parse %s: %v", xml, err)
+	}
+	root := doc.Root()
+	if root == nil || root.Tag != "Response" {
+		t.Fatalf("document %s has no <Response> root", xml)
+	}
+	return root
+}
+
+func TestTemplateExecute(t *testing.T) {
+	tmpl := MustParseTemplate([]Element{
+		VoiceSay{Message: "Hello {{.Name}}, you are caller number {{.Position}}."},
+		VoiceEnqueue{Name: "{{.Queue}}", WaitUrl: "/wait?queue={{.Queue | urlquery}}"},
+		VoiceGather{Action: "/menu", InnerElements: []Element{
+			VoicePlay{Url: "{{.Greeting}}", OptionalAttributes: map[string]string{"loop": "{{.Loop}}"}},
+		}},
+	})
+
+	xml, err := tmpl.Execute(map[string]interface{}{
+		"Name": "Ada", "Position": 3, "Queue": "sales & support", "Greeting": "https://example.com/hi.mp3", "Loop": 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := readResponse(t, xml)
+
+	if got := response.SelectElement("Say").Text(); got != "Hello Ada, you are caller number 3." {
+		t.Errorf("Say = %q", got)
+	}
+	enqueue := response.SelectElement("Enqueue")
+	if got := enqueue.Text(); got != "sales & support" {
+		t.Errorf("Enqueue = %q", got)
+	}
+	if got := enqueue.SelectAttrValue("waitUrl", ""); got != "/wait?queue=sales+%26+support" {
+		t.Errorf("Enqueue waitUrl = %q", got)
+	}
+	play := response.FindElement("Gather/Play")
+	if play == nil {
+		t.Fatalf("no Gather/Play in %s", xml)
+	}
+	if play.Text() != "https://example.com/hi.mp3" || play.SelectAttrValue("loop", "") != "2" {
+		t.Errorf("Play = %q loop %q", play.Text(), play.SelectAttrValue("loop", ""))
+	}
+}
+
+func TestTemplateEscapes(t *testing.T) {
+	tmpl := MustParseTemplate([]Element{VoiceSay{Message: "Hello {{.}}", Voice: "{{.}}"}})
+	const name = `</Say><Hangup/><Say voice="x">`
+	xml, err := tmpl.Execute(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := readResponse(t, xml)
+	if n := len(response.ChildElements()); n != 1 {
+		t.Fatalf("substitution added elements: %s", xml)
+	}
+	say := response.SelectElement("Say")
+	if say.Text() != "Hello "+name || say.SelectAttrValue("voice", "") != name {
+		t.Errorf("Say = %q voice %q", say.Text(), say.SelectAttrValue("voice", ""))
+	}
+}
+
+func TestTemplateWithoutActions(t *testing.T) {
+	verbs := []Element{VoiceSay{Message: "Goodbye"}, VoiceHangup{}}
+	want, err := Voice(verbs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := MustParseTemplate(verbs).Execute(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("Execute = %s, want %s", got, want)
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	tmpl, err := ParseTemplateFuncs([]Element{VoiceSay{Message: "{{shout .}}"}}, template.FuncMap{"shout": strings.ToUpper})
+	if err != nil {
+		t.Fatal(err)
+	}
+	xml, err := tmpl.Execute("hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readResponse(t, xml).SelectElement("Say").Text(); got != "HELLO" {
+		t.Errorf("Say = %q, want HELLO", got)
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	if _, err := ParseTemplate([]Element{VoiceDial{InnerElements: []Element{VoiceNumber{PhoneNumber: "{{.Number"}}}}); err == nil ||
+		!strings.Contains(err.Error(), "Response/Dial[0]/Number[0]") {
+		t.Errorf("ParseTemplate error = %v, want the path of the element", err)
+	}
+
+	tmpl := MustParseTemplate([]Element{VoiceSay{Message: "Hello {{.Name}}"}})
+	if _, err := tmpl.Execute(map[string]string{}); err == nil {
+		t.Error("Execute with a missing key succeeded")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustParseTemplate did not panic")
+		}
+	}()
+	MustParseTemplate([]Element{VoiceSay{Message: "{{"}})
+}
+
+func TestTemplateConcurrentExecute(t *testing.T) {
+	tmpl := MustParseTemplate([]Element{VoiceSay{Message: "caller {{.}}"}})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			xml, err := tmpl.Execute(i)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			doc := etree.NewDocument()
+			if err := doc.ReadFromString(xml); err != nil {
+				t.Error(err)
+				return
+			}
+			want := "caller " + string(rune('0'+i))
+			if got := doc.FindElement("Response/Say").Text(); got != want {
+				t.Errorf("Say = %q, want %q", got, want)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,279 @@
+package texml
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+// NoteKind classifies an entry in a ConversionReport.
+type NoteKind string
+
+const (
+	// NoteDropped means the TwiML element or attribute has no TeXML equivalent and was
+	// removed from the output.
+	NoteDropped NoteKind = "dropped"
+	// NoteChanged means the TwiML value was translated to a different TeXML value or
+	// attribute.
+	NoteChanged NoteKind = "changed"
+	// NoteUnverified means the attribute is not known to this package and was copied to
+	// OptionalAttributes unchanged.
+	NoteUnverified NoteKind = "unverified"
+)
+
+// ConversionNote describes one thing ConvertTwiML could not translate one to one.
+type ConversionNote struct {
+	Kind NoteKind
+	// Path locates the TwiML element, e.g. "Response/Gather[0]/Say[1]".
+	Path string
+	// Attribute is empty when the note is about the element itself.
+	Attribute string
+	Reason    string
+}
+
+func (n ConversionNote) String() string {
+	location := n.Path
+	if n.Attribute != "" {
+		location += "@" + n.Attribute
+	}
+	return fmt.Sprintf("%s: %s: %s", n.Kind, location, n.Reason)
+}
+
+// ConversionReport lists everything ConvertTwiML dropped or changed.
+type ConversionReport struct {
+	Notes []ConversionNote
+}
+
+// Lossless reports whether nothing was dropped during conversion.
+func (r ConversionReport) Lossless() bool {
+	for _, n := range r.Notes {
+		if n.Kind == NoteDropped {
+			return false
+		}
+	}
+	return true
+}
+
+func (r ConversionReport) String() string {
+	lines := make([]string, 0, len(r.Notes))
+	for _, n := range r.Notes {
+		lines = append(lines, n.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *ConversionReport) add(kind NoteKind, path, attr, reason string) {
+	r.Notes = append(r.Notes, ConversionNote{Kind: kind, Path: path, Attribute: attr, Reason: reason})
+}
+
+// twilioOnlyVerbs are TwiML verbs and nouns that have no TeXML equivalent.
+var twilioOnlyVerbs = map[string]string{
+	"Pay":           "Twilio <Pay> is not supported by TeXML",
+	"Prompt":        "Twilio <Pay> prompts are not supported by TeXML",
+	"Connect":       "Twilio <Connect> is not supported by TeXML",
+	"Echo":          "Twilio <Echo> is not supported by TeXML",
+	"Sms":           "send messages through the messaging API instead",
+	"Message":       "send messages through the messaging API instead",
+	"Client":        "Twilio Client endpoints cannot be dialed from TeXML",
+	"Application":   "Twilio applications cannot be dialed from TeXML",
+	"WhatsApp":      "WhatsApp endpoints cannot be dialed from TeXML",
+	"Siprec":        "<Siprec> is not implemented by this package",
+	"Transcription": "<Transcription> is not implemented by this package",
+	"Config":        "Twilio <Config> is not supported by TeXML",
+}
+
+// twilioOnlyAttrs are TwiML attributes, per verb, that have no TeXML equivalent.
+var twilioOnlyAttrs = map[string][]string{
+	"Dial": {"answerOnBridge", "recordingTrack", "sequential", "referUrl", "referMethod", "events", "trim"},
+	"Number": {"byoc", "amdStatusCallback", "amdStatusCallbackMethod", "machineDetectionSpeechThreshold",
+		"machineDetectionSpeechEndThreshold", "machineDetectionSilenceTimeout"},
+	"Sip": {"amdStatusCallback", "amdStatusCallbackMethod", "machineDetectionSpeechThreshold",
+		"machineDetectionSpeechEndThreshold", "machineDetectionSilenceTimeout"},
+	"Queue":      {"reservationSid", "postWorkActivitySid"},
+	"Conference": {"coach", "region", "eventCallbackUrl", "jitterBufferSize", "participantLabel"},
+	"Enqueue":    {"workflowSid"},
+	"Gather": {"speechTimeout", "hints", "profanityFilter", "speechModel", "enhanced",
+		"partialResultCallback", "partialResultCallbackMethod", "actionOnEmptyResult", "bargeIn", "debug"},
+	"Play":   {"digits"},
+	"Record": {"transcribe", "transcribeCallback", "recordingStatusCallbackEvent"},
+	"Stream": {"statusCallback", "statusCallbackMethod"},
+}
+
+// twilioOnlyConferenceEvents are values of the statusCallbackEvent attribute of TwiML
+// <Conference> that TeXML does not send.
+var twilioOnlyConferenceEvents = map[string]bool{"modify": true}
+
+// textFields names the field that holds the text content of each element type.
+var textFields = map[reflect.Type]string{
+	reflect.TypeOf(VoiceDial{}):       "Number",
+	reflect.TypeOf(VoiceNumber{}):     "PhoneNumber",
+	reflect.TypeOf(VoiceSip{}):        "SipUrl",
+	reflect.TypeOf(VoiceQueue{}):      "Name",
+	reflect.TypeOf(VoiceConference{}): "Name",
+	reflect.TypeOf(VoiceEnqueue{}):    "Name",
+	reflect.TypeOf(VoicePlay{}):       "Url",
+	reflect.TypeOf(VoiceRedirect{}):   "Url",
+	reflect.TypeOf(VoiceReferSip{}):   "SipUrl",
+	reflect.TypeOf(VoiceSay{}):        "Message",
+}
+
+// isTextField reports whether key names the field holding the text content of t, which
+// TwiML never sets through an attribute.
+func isTextField(t reflect.Type, key string) bool {
+	field, ok := textFields[t]
+	return ok && formatAttrKey(field) == key
+}
+
+// ConvertTwiML parses a TwiML document and returns the equivalent TeXML verbs, ready to
+// be passed to Voice. Verbs, nouns and attributes without a TeXML equivalent are
+// dropped, and every deviation from the source document is listed in the report.
+//
+// Documents built with the Twilio Go SDK can be converted by rendering them with
+// twiml.Voice first.
+func ConvertTwiML(twiml string) ([]Element, ConversionReport, error) {
+	var report ConversionReport
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromString(twiml); err != nil {
+		return nil, report, fmt.Errorf("texml: parse TwiML: %w", err)
+	}
+	root := doc.Root()
+	if root == nil || root.Tag != "Response" {
+		return nil, report, fmt.Errorf("texml: TwiML document must have a <Response> root element")
+	}
+
+	verbs := convertTwiMLChildren(root, "", "Response", &report)
+	return verbs, report, nil
+}
+
+func convertTwiMLChildren(parent *etree.Element, parentVerb, path string, report *ConversionReport) []Element {
+	var elements []Element
+	for i, child := range parent.ChildElements() {
+		childPath := fmt.Sprintf("%s/%s[%d]", path, child.Tag, i)
+		if element, ok := convertTwiMLElement(child, parentVerb, childPath, report); ok {
+			elements = append(elements, element)
+		}
+	}
+	return elements
+}
+
+func convertTwiMLElement(el *etree.Element, parentVerb, path string, report *ConversionReport) (Element, bool) {
+	if reason, ok := twilioOnlyVerbs[el.Tag]; ok {
+		report.add(NoteDropped, path, "", reason)
+		return nil, false
+	}
+	t := lookupVerb(parentVerb, el.Tag)
+	if t == nil {
+		report.add(NoteDropped, path, "", "unknown TwiML verb")
+		return nil, false
+	}
+
+	v := reflect.New(t).Elem()
+
+	text := strings.TrimSpace(el.Text())
+	if el.Tag == "Say" {
+		text = strings.Join(strings.Fields(innerText(el)), " ")
+	}
+	if text != "" {
+		if field, ok := textFields[t]; ok {
+			v.FieldByName(field).SetString(text)
+		} else {
+			report.add(NoteDropped, path, "", "text content is not supported")
+		}
+	}
+
+	optional := map[string]string{}
+	for _, attr := range el.Attr {
+		convertTwiMLAttr(v, el.Tag, attr.Key, attr.Value, optional, path, report)
+	}
+	if len(optional) != 0 {
+		v.FieldByName("OptionalAttributes").Set(reflect.ValueOf(optional))
+	}
+
+	switch field := v.FieldByName("InnerElements"); {
+	case len(el.ChildElements()) == 0:
+	case el.Tag == "Say":
+		// SSML children would otherwise be reported as unknown verbs.
+		report.add(NoteDropped, path, "", "SSML markup is not supported, only the text was kept")
+	case !field.IsValid():
+		report.add(NoteDropped, path, "", "takes no children")
+	default:
+		if children := convertTwiMLChildren(el, el.Tag, path, report); len(children) != 0 {
+			field.Set(reflect.ValueOf(children))
+		}
+	}
+
+	return v.Interface().(Element), true
+}
+
+func convertTwiMLAttr(v reflect.Value, verb, key, value string, optional map[string]string, path string, report *ConversionReport) {
+	for _, name := range twilioOnlyAttrs[verb] {
+		if name == key {
+			report.add(NoteDropped, path, key, "attribute is not supported by TeXML")
+			return
+		}
+	}
+
+	switch {
+	case verb == "Dial" && key == "record" && strings.HasSuffix(value, "-dual"):
+		// Twilio encodes dual channel recording in the record value, TeXML uses a
+		// separate recordingChannels attribute.
+		v.FieldByName("Record").SetString(strings.TrimSuffix(value, "-dual"))
+		v.FieldByName("RecordingChannels").SetString("dual")
+		report.add(NoteChanged, path, key, fmt.Sprintf("%q was split into record and recordingChannels=\"dual\"", value))
+		return
+	case verb == "Gather" && key == "input":
+		if value != "dtmf" {
+			report.add(NoteDropped, path, key, "speech recognition is not supported, only DTMF is gathered")
+		}
+		return
+	case (verb == "Number" || verb == "Sip") && key == "machineDetectionTimeout":
+		// TwiML counts the timeout in seconds, TeXML in milliseconds.
+		seconds, err := strconv.Atoi(value)
+		if err != nil {
+			report.add(NoteDropped, path, key, fmt.Sprintf("%q is not a number of seconds", value))
+			return
+		}
+		v.FieldByName("MachineDetectionTimeout").SetString(strconv.Itoa(seconds * 1000))
+		report.add(NoteChanged, path, key, fmt.Sprintf("%s seconds were converted to %d milliseconds", value, seconds*1000))
+		return
+	case verb == "Conference" && key == "statusCallbackEvent":
+		var events, dropped []string
+		for _, event := range strings.Fields(value) {
+			if twilioOnlyConferenceEvents[event] {
+				dropped = append(dropped, event)
+			} else {
+				events = append(events, event)
+			}
+		}
+		v.FieldByName("StatusCallbackEvent").SetString(strings.Join(events, " "))
+		if len(dropped) != 0 {
+			report.add(NoteChanged, path, key, fmt.Sprintf("events %q are not supported by TeXML and were removed", strings.Join(dropped, " ")))
+		}
+		return
+	}
+
+	if field := fieldByKey(v, key); field.IsValid() && !isTextField(v.Type(), key) {
+		field.SetString(value)
+		return
+	}
+	optional[key] = value
+	report.add(NoteUnverified, path, key, "attribute is unknown to this package and was copied as is")
+}
+
+// innerText returns the text content of el and all of its descendants.
+func innerText(el *etree.Element) string {
+	var sb strings.Builder
+	for _, token := range el.Child {
+		switch t := token.(type) {
+		case *etree.CharData:
+			sb.WriteString(t.Data)
+		case *etree.Element:
+			sb.WriteString(innerText(t))
+		}
+	}
+	return sb.String()
+}
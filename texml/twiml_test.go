@@ -0,0 +1,177 @@
+package texml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// findNote returns the note of report about path and attr, or nil.
+func findNote(report ConversionReport, path, attr string) *ConversionNote {
+	for i, n := range report.Notes {
+		if n.Path == path && n.Attribute == attr {
+			return &report.Notes[i]
+		}
+	}
+	return nil
+}
+
+func TestConvertTwiML(t *testing.T) {
+	verbs, report, err := ConvertTwiML(`<?xml version="1.0" encoding="UTF-8"?>
+<Response>
+  <Gather input="dtmf" action="/menu" numDigits="1">
+    <Say voice="alice">Press 1 for sales.</Say>
+  </Gather>
+  <Dial callerId="+13125550100" record="record-from-answer-dual">
+    <Number>+13125550199</Number>
+  </Dial>
+  <Enqueue waitUrl="/wait">support</Enqueue>
+  <Hangup/>
+</Response>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Element{
+		VoiceGather{Action: "/menu", NumDigits: "1", InnerElements: []Element{
+			VoiceSay{Message: "Press 1 for sales.", Voice: "alice"},
+		}},
+		VoiceDial{CallerId: "+13125550100", Record: "record-from-answer", RecordingChannels: "dual", InnerElements: []Element{
+			VoiceNumber{PhoneNumber: "+13125550199"},
+		}},
+		VoiceEnqueue{Name: "support", WaitUrl: "/wait"},
+		VoiceHangup{},
+	}
+	if !reflect.DeepEqual(verbs, want) {
+		t.Errorf("ConvertTwiML = %#v, want %#v", verbs, want)
+	}
+	if !report.Lossless() {
+		t.Errorf("report is not lossless:\n%s", report)
+	}
+	if n := findNote(report, "Response/Dial[1]", "record"); n == nil || n.Kind != NoteChanged {
+		t.Errorf("no change note for the dual recording in:\n%s", report)
+	}
+}
+
+func TestConvertTwiMLDropped(t *testing.T) {
+	verbs, report, err := ConvertTwiML(`<Response>
+  <Pay/>
+  <Gather input="speech" speechTimeout="auto"><Say>Say something</Say></Gather>
+  <Say>Hello <emphasis>there</emphasis></Say>
+  <Dial answerOnBridge="true"><Client>alice</Client></Dial>
+  <Shout/>
+</Response>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Lossless() {
+		t.Error("report is lossless")
+	}
+
+	want := []Element{
+		VoiceGather{InnerElements: []Element{VoiceSay{Message: "Say something"}}},
+		VoiceSay{Message: "Hello there"},
+		VoiceDial{},
+	}
+	if !reflect.DeepEqual(verbs, want) {
+		t.Errorf("ConvertTwiML = %#v, want %#v", verbs, want)
+	}
+
+	for _, dropped := range []struct{ path, attr string }{
+		{"Response/Pay[0]", ""},
+		{"Response/Gather[1]", "input"},
+		{"Response/Gather[1]", "speechTimeout"},
+		{"Response/Say[2]", ""},
+		{"Response/Dial[3]", "answerOnBridge"},
+		{"Response/Dial[3]/Client[0]", ""},
+		{"Response/Shout[4]", ""},
+	} {
+		if n := findNote(report, dropped.path, dropped.attr); n == nil || n.Kind != NoteDropped {
+			t.Errorf("no dropped note for %s@%s in:\n%s", dropped.path, dropped.attr, report)
+		}
+	}
+}
+
+func TestConvertTwiMLChildlessElement(t *testing.T) {
+	verbs, report, err := ConvertTwiML(`<Response><Start><Stream url="wss://example.com/stream">
+  <Parameter name="a" value="b"><Pause/></Parameter>
+</Stream></Start></Response>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Element{VoiceStart{InnerElements: []Element{VoiceStream{Url: "wss://example.com/stream", InnerElements: []Element{
+		VoiceParameter{Name: "a", Value: "b"},
+	}}}}}
+	if !reflect.DeepEqual(verbs, want) {
+		t.Errorf("ConvertTwiML = %#v, want %#v", verbs, want)
+	}
+	if n := findNote(report, "Response/Start[0]/Stream[0]/Parameter[0]", ""); n == nil || n.Kind != NoteDropped || n.Reason != "takes no children" {
+		t.Errorf("no dropped note for the children of <Parameter> in:\n%s", report)
+	}
+}
+
+func TestConvertTwiMLRenamedValues(t *testing.T) {
+	verbs, report, err := ConvertTwiML(`<Response>
+  <Dial>
+    <Number machineDetection="Enable" machineDetectionTimeout="30">+13125550199</Number>
+    <Sip machineDetectionTimeout="soon">sip:agent@example.com</Sip>
+    <Conference statusCallback="/events" statusCallbackEvent="start end modify join">room</Conference>
+  </Dial>
+</Response>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Element{VoiceDial{InnerElements: []Element{
+		VoiceNumber{PhoneNumber: "+13125550199", MachineDetection: "Enable", MachineDetectionTimeout: "30000"},
+		VoiceSip{SipUrl: "sip:agent@example.com"},
+		VoiceConference{Name: "room", StatusCallback: "/events", StatusCallbackEvent: "start end join"},
+	}}}
+	if !reflect.DeepEqual(verbs, want) {
+		t.Errorf("ConvertTwiML = %#v, want %#v", verbs, want)
+	}
+
+	tests := []struct {
+		path string
+		attr string
+		kind NoteKind
+	}{
+		{"Response/Dial[0]/Number[0]", "machineDetectionTimeout", NoteChanged},
+		{"Response/Dial[0]/Sip[1]", "machineDetectionTimeout", NoteDropped},
+		{"Response/Dial[0]/Conference[2]", "statusCallbackEvent", NoteChanged},
+	}
+	for _, tt := range tests {
+		if n := findNote(report, tt.path, tt.attr); n == nil || n.Kind != tt.kind {
+			t.Errorf("no %s note for %s@%s in:\n%s", tt.kind, tt.path, tt.attr, report)
+		}
+	}
+}
+
+func TestConvertTwiMLUnverified(t *testing.T) {
+	verbs, report, err := ConvertTwiML(`<Response><Play futureOption="1">https://example.com/a.mp3</Play></Response>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Element{VoicePlay{Url: "https://example.com/a.mp3", OptionalAttributes: map[string]string{"futureOption": "1"}}}
+	if !reflect.DeepEqual(verbs, want) {
+		t.Errorf("ConvertTwiML = %#v, want %#v", verbs, want)
+	}
+	n := findNote(report, "Response/Play[0]", "futureOption")
+	if n == nil || n.Kind != NoteUnverified {
+		t.Errorf("no unverified note in:\n%s", report)
+	}
+	if !report.Lossless() {
+		t.Error("an unverified attribute made the report lossy")
+	}
+	if got := n.String(); !strings.HasPrefix(got, "unverified: Response/Play[0]@futureOption: ") {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestConvertTwiMLErrors(t *testing.T) {
+	for _, doc := range []string{`<Response>`, `<Document/>`, ``} {
+		if _, _, err := ConvertTwiML(doc); err == nil {
+			t.Errorf("ConvertTwiML(%q) succeeded", doc)
+		}
+	}
+}
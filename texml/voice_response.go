@@ -169,6 +169,7 @@ func (m VoiceSip) GetInnerElements() []Element {
 //
 // https://developers.telnyx.com/docs/voice/programmable-voice/texml-verbs/dial#queue-attributes
 type VoiceQueue struct {
+	Name               string
 	Url                string
 	Method             string
 	InnerElements      []Element
@@ -180,7 +181,7 @@ func (m VoiceQueue) GetName() string {
 }
 
 func (m VoiceQueue) GetText() string {
-	return ""
+	return m.Name
 }
 
 func (m VoiceQueue) GetAttr() (map[string]string, map[string]string) {
@@ -269,6 +270,7 @@ func (m VoiceConference) GetInnerElements() []Element {
 //
 // The <Enqueue> verb enqueues the current call in a call queue.
 type VoiceEnqueue struct {
+	Name               string
 	Action             string
 	Method             string
 	WaitUrl            string
@@ -282,7 +284,7 @@ func (m VoiceEnqueue) GetName() string {
 }
 
 func (m VoiceEnqueue) GetText() string {
-	return ""
+	return m.Name
 }
 
 func (m VoiceEnqueue) GetAttr() (map[string]string, map[string]string) {
@@ -750,6 +752,36 @@ func (m VoiceStream) GetInnerElements() []Element {
 	return m.InnerElements
 }
 
+// VoiceParameter <Parameter> TeXML Noun
+//
+// A custom parameter nested in <Stream>. Name and Value are sent to the WebSocket server
+// in the custom parameters of the stream's start frame.
+type VoiceParameter struct {
+	Name               string
+	Value              string
+	OptionalAttributes map[string]string
+}
+
+func (m VoiceParameter) GetName() string {
+	return "Parameter"
+}
+
+func (m VoiceParameter) GetText() string {
+	return ""
+}
+
+func (m VoiceParameter) GetAttr() (map[string]string, map[string]string) {
+	paramsAttr := map[string]string{
+		"Name":  m.Name,
+		"Value": m.Value,
+	}
+	return m.OptionalAttributes, paramsAttr
+}
+
+func (m VoiceParameter) GetInnerElements() []Element {
+	return nil
+}
+
 // VoiceStart <Start> TeXML Verb
 //
 // https://developers.telnyx.com/docs/voice/programmable-voice/texml-verbs/stream
@@ -0,0 +1,95 @@
+package telnyx
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// SignatureHeader holds the base64 Ed25519 signature of a webhook.
+	SignatureHeader = "Telnyx-Signature-Ed25519"
+	// TimestampHeader holds the Unix time at which a webhook was signed.
+	TimestampHeader = "Telnyx-Timestamp"
+
+	// DefaultWebhookTolerance is the maximum age of a webhook accepted by
+	// VerifyWebhookRequest.
+	DefaultWebhookTolerance = 5 * time.Minute
+)
+
+// ErrInvalidSignature is returned when a webhook signature does not match its payload,
+// or when the webhook is older than the allowed tolerance.
+var ErrInvalidSignature = errors.New("telnyx: invalid webhook signature")
+
+// ParsePublicKey decodes the base64 public key shown in the Mission Control Portal.
+func ParsePublicKey(key string) (ed25519.PublicKey, error) {
+	data, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("telnyx: decode public key: %w", err)
+	}
+	if len(data) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("telnyx: public key must be %d bytes, got %d", ed25519.PublicKeySize, len(data))
+	}
+	return ed25519.PublicKey(data), nil
+}
+
+// VerifyWebhook checks that signature is the signature of timestamp and payload by the
+// key pair of publicKey, and that timestamp is no older than tolerance. A tolerance of 0
+// disables the age check.
+func VerifyWebhook(payload []byte, signature, timestamp string, publicKey ed25519.PublicKey, tolerance time.Duration) error {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 {
+		ts, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return ErrInvalidSignature
+		}
+		if age := time.Since(time.Unix(ts, 0)); age > tolerance || age < -tolerance {
+			return ErrInvalidSignature
+		}
+	}
+	if !ed25519.Verify(publicKey, signedPayload(timestamp, payload), sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyWebhookRequest reads the body of r and verifies its signature headers with
+// DefaultWebhookTolerance. It returns the body, and leaves r.Body readable again.
+func VerifyWebhookRequest(r *http.Request, publicKey ed25519.PublicKey) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	err = VerifyWebhook(body, r.Header.Get(SignatureHeader), r.Header.Get(TimestampHeader), publicKey, DefaultWebhookTolerance)
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// SignWebhook returns the signature and timestamp headers for payload, as Telnyx would
+// send them. It is meant for tests and local mock servers.
+func SignWebhook(payload []byte, privateKey ed25519.PrivateKey, at time.Time) (signature, timestamp string) {
+	timestamp = strconv.FormatInt(at.Unix(), 10)
+	sig := ed25519.Sign(privateKey, signedPayload(timestamp, payload))
+	return base64.StdEncoding.EncodeToString(sig), timestamp
+}
+
+func signedPayload(timestamp string, payload []byte) []byte {
+	signed := make([]byte, 0, len(timestamp)+1+len(payload))
+	signed = append(signed, timestamp...)
+	signed = append(signed, '|')
+	return append(signed, payload...)
+}
@@ -0,0 +1,90 @@
+package telnyx
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerifyWebhook(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	payload := []byte(`{"data":{"event_type":"call.answered"}}`)
+	now := time.Now()
+	sig, ts := SignWebhook(payload, priv, now)
+
+	if err := VerifyWebhook(payload, sig, ts, pub, DefaultWebhookTolerance); err != nil {
+		t.Errorf("valid webhook: %v", err)
+	}
+
+	oldSig, oldTs := SignWebhook(payload, priv, now.Add(-10*time.Minute))
+	futureSig, futureTs := SignWebhook(payload, priv, now.Add(10*time.Minute))
+	tests := []struct {
+		name             string
+		payload          []byte
+		signature, stamp string
+		key              ed25519.PublicKey
+	}{
+		{"tampered payload", []byte(`{"data":{"event_type":"call.hangup"}}`), sig, ts, pub},
+		{"other key", payload, sig, ts, otherPub},
+		{"other timestamp", payload, sig, strconv.FormatInt(now.Unix()+1, 10), pub},
+		{"too old", payload, oldSig, oldTs, pub},
+		{"in the future", payload, futureSig, futureTs, pub},
+		{"signature not base64", payload, "***", ts, pub},
+		{"timestamp not a number", payload, sig, "now", pub},
+	}
+	for _, tt := range tests {
+		if err := VerifyWebhook(tt.payload, tt.signature, tt.stamp, tt.key, DefaultWebhookTolerance); err != ErrInvalidSignature {
+			t.Errorf("%s: error = %v, want ErrInvalidSignature", tt.name, err)
+		}
+	}
+
+	if err := VerifyWebhook(payload, oldSig, oldTs, pub, 0); err != nil {
+		t.Errorf("old webhook with no tolerance: %v", err)
+	}
+}
+
+func TestVerifyWebhookRequest(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	payload := []byte(`{"data":{}}`)
+	sig, ts := SignWebhook(payload, priv, time.Now())
+
+	r := httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(payload))
+	r.Header.Set(SignatureHeader, sig)
+	r.Header.Set(TimestampHeader, ts)
+	body, err := VerifyWebhookRequest(r, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(body, payload) {
+		t.Errorf("body = %s", body)
+	}
+	if again, _ := io.ReadAll(r.Body); !bytes.Equal(again, payload) {
+		t.Errorf("r.Body read again = %s, want the payload", again)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(payload))
+	if _, err := VerifyWebhookRequest(r, pub); err != ErrInvalidSignature {
+		t.Errorf("unsigned request: error = %v", err)
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(nil)
+	key, err := ParsePublicKey(base64.StdEncoding.EncodeToString(pub))
+	if err != nil || !key.Equal(pub) {
+		t.Errorf("ParsePublicKey = %v, %v", key, err)
+	}
+	if _, err := ParsePublicKey("not base64!"); err == nil {
+		t.Error("invalid base64 was accepted")
+	}
+	if _, err := ParsePublicKey(base64.StdEncoding.EncodeToString(pub[:16])); err == nil {
+		t.Error("a short key was accepted")
+	}
+}